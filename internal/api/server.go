@@ -0,0 +1,429 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+const (
+	// sseKeepAliveInterval — период комментариев-пингов, чтобы простаивающий поток событий не закрывали прокси и клиенты.
+	sseKeepAliveInterval = 15 * time.Second
+
+	// subscriberBuffer — размер буфера событий подписчика; медленный клиент теряет промежуточные снимки, а не тормозит синхронизацию.
+	subscriberBuffer = 64
+)
+
+// DatabaseBrowser предоставляет список баз и таблиц remote сервера.
+type DatabaseBrowser interface {
+	ListDatabases(isRemote bool) (models.DatabaseList, error)
+	ListTables(databaseName string, isRemote bool) ([]models.Table, error)
+}
+
+// NameValidator опционально реализуется DatabaseBrowser для проверки имен баз в плане.
+type NameValidator interface {
+	ValidateDatabaseName(name string) error
+}
+
+// PlanRunner выполняет план синхронизации с возможностью отмены через ctx.
+type PlanRunner interface {
+	ExecutePlanContext(ctx context.Context, plan *models.SyncPlan, runtime models.RuntimeOptions, observer models.ProgressObserver) ([]models.SyncResult, error)
+}
+
+// Server — локальный HTTP API для запуска и наблюдения за синхронизациями без TUI.
+// Одновременно для одной локальной базы может выполняться только один запуск.
+type Server struct {
+	token   string
+	browser DatabaseBrowser
+	runner  PlanRunner
+	runtime models.RuntimeOptions
+
+	mu       sync.Mutex
+	runs     map[string]*syncRun
+	active   map[string]string
+	sequence int
+	wg       sync.WaitGroup
+}
+
+// syncRun хранит состояние запуска и подписчиков на его прогресс.
+type syncRun struct {
+	mu          sync.Mutex
+	status      models.SyncRun
+	cancel      context.CancelFunc
+	subscribers map[chan models.ProgressSnapshot]struct{}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer создает API; token обязателен для всех запросов.
+func NewServer(token string, browser DatabaseBrowser, runner PlanRunner, runtime models.RuntimeOptions) *Server {
+	return &Server{
+		token:   token,
+		browser: browser,
+		runner:  runner,
+		runtime: runtime,
+		runs:    make(map[string]*syncRun),
+		active:  make(map[string]string),
+	}
+}
+
+// Handler возвращает маршруты API, защищенные токеном.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/databases", s.handleDatabases)
+	mux.HandleFunc("GET /v1/databases/{name}/tables", s.handleTables)
+	mux.HandleFunc("GET /v1/runs", s.handleListRuns)
+	mux.HandleFunc("POST /v1/runs", s.handleSubmitRun)
+	mux.HandleFunc("GET /v1/runs/{id}", s.handleGetRun)
+	mux.HandleFunc("GET /v1/runs/{id}/events", s.handleRunEvents)
+	mux.HandleFunc("POST /v1/runs/{id}/cancel", s.handleCancelRun)
+	return s.authenticate(mux)
+}
+
+// Shutdown отменяет активные запуски и ждет их завершения или отмены ctx.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	for _, run := range s.runs {
+		run.cancel()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// authenticate пропускает запросы с заголовком Authorization: Bearer <token>. EventSource в браузере
+// не умеет задавать заголовки, поэтому для него токен принимается и в параметре ?token=.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if provided == "" {
+			provided = r.URL.Query().Get("token")
+		}
+		if s.token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleDatabases(w http.ResponseWriter, r *http.Request) {
+	databases, err := s.browser.ListDatabases(true)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("failed to list databases: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, databases)
+}
+
+func (s *Server) handleTables(w http.ResponseWriter, r *http.Request) {
+	tables, err := s.browser.ListTables(r.PathValue("name"), true)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, fmt.Errorf("failed to list tables: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, tables)
+}
+
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	runs := make([]models.SyncRun, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, run.snapshot())
+	}
+	s.mu.Unlock()
+	sort.Slice(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	writeJSON(w, http.StatusOK, runs)
+}
+
+func (s *Server) handleSubmitRun(w http.ResponseWriter, r *http.Request) {
+	var plan models.SyncPlan
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&plan); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid sync plan: %w", err))
+		return
+	}
+	databases, err := s.planDatabases(plan)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	run, err := s.startRun(plan, databases)
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run.snapshot())
+}
+
+func (s *Server) planDatabases(plan models.SyncPlan) ([]string, error) {
+	if len(plan.Targets) == 0 {
+		return nil, errors.New("sync plan has no targets")
+	}
+	validator, _ := s.browser.(NameValidator)
+	seen := make(map[string]struct{}, len(plan.Targets))
+	databases := make([]string, 0, len(plan.Targets))
+	for _, target := range plan.Targets {
+		name := target.DatabaseName
+		if validator != nil {
+			if err := validator.ValidateDatabaseName(name); err != nil {
+				return nil, err
+			}
+		} else if name == "" {
+			return nil, errors.New("database name cannot be empty")
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("database '%s' is listed twice", name)
+		}
+		seen[name] = struct{}{}
+		databases = append(databases, name)
+	}
+	return databases, nil
+}
+
+// startRun регистрирует запуск и выполняет план в фоне; база, занятая другим запуском, приводит к ошибке.
+func (s *Server) startRun(plan models.SyncPlan, databases []string) (*syncRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range databases {
+		if id, ok := s.active[name]; ok {
+			return nil, fmt.Errorf("database '%s' is already being synced by run %s", name, id)
+		}
+	}
+
+	s.sequence++
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	if plan.CreatedAt.IsZero() {
+		plan.CreatedAt = now
+	}
+	run := &syncRun{
+		status: models.SyncRun{
+			ID:        fmt.Sprintf("run-%d", s.sequence),
+			State:     models.SyncRunRunning,
+			Databases: databases,
+			Plan:      plan,
+			StartedAt: now,
+		},
+		cancel:      cancel,
+		subscribers: make(map[chan models.ProgressSnapshot]struct{}),
+	}
+	s.runs[run.status.ID] = run
+	for _, name := range databases {
+		s.active[name] = run.status.ID
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		results, err := s.runner.ExecutePlanContext(ctx, &plan, s.runtime, run.publish)
+		run.finish(results, err, ctx.Err() != nil)
+
+		s.mu.Lock()
+		for _, name := range databases {
+			if s.active[name] == run.status.ID {
+				delete(s.active, name)
+			}
+		}
+		s.mu.Unlock()
+	}()
+	return run, nil
+}
+
+func (s *Server) lookupRun(w http.ResponseWriter, r *http.Request) *syncRun {
+	s.mu.Lock()
+	run, ok := s.runs[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run %s not found", r.PathValue("id")))
+		return nil
+	}
+	return run
+}
+
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	if run := s.lookupRun(w, r); run != nil {
+		writeJSON(w, http.StatusOK, run.snapshot())
+	}
+}
+
+func (s *Server) handleCancelRun(w http.ResponseWriter, r *http.Request) {
+	run := s.lookupRun(w, r)
+	if run == nil {
+		return
+	}
+	if status := run.snapshot(); status.Finished() {
+		writeError(w, http.StatusConflict, fmt.Errorf("run %s is already %s", status.ID, status.State))
+		return
+	}
+	run.cancel()
+	writeJSON(w, http.StatusAccepted, run.snapshot())
+}
+
+// handleRunEvents отдает прогресс запуска как Server-Sent Events: событие progress на каждый снимок
+// и итоговое событие done с состоянием запуска.
+func (s *Server) handleRunEvents(w http.ResponseWriter, r *http.Request) {
+	run := s.lookupRun(w, r)
+	if run == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	events, last, unsubscribe := run.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if last != nil {
+		writeEvent(w, "progress", last)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case snapshot, ok := <-events:
+			if !ok {
+				writeEvent(w, "done", run.snapshot())
+				flusher.Flush()
+				return
+			}
+			writeEvent(w, "progress", snapshot)
+			flusher.Flush()
+		}
+	}
+}
+
+func (r *syncRun) snapshot() models.SyncRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.status
+	status.Databases = append([]string(nil), r.status.Databases...)
+	status.Results = append([]models.SyncResult(nil), r.status.Results...)
+	if r.status.Progress != nil {
+		progress := *r.status.Progress
+		status.Progress = &progress
+	}
+	return status
+}
+
+// publish запоминает последний снимок и рассылает его подписчикам без блокировки синхронизации.
+func (r *syncRun) publish(snapshot models.ProgressSnapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.Progress = &snapshot
+	for subscriber := range r.subscribers {
+		select {
+		case subscriber <- snapshot:
+		default:
+		}
+	}
+}
+
+// subscribe возвращает канал снимков и последний известный снимок; канал закрывается по завершении запуска.
+func (r *syncRun) subscribe() (<-chan models.ProgressSnapshot, *models.ProgressSnapshot, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make(chan models.ProgressSnapshot, subscriberBuffer)
+	var last *models.ProgressSnapshot
+	if r.status.Progress != nil {
+		progress := *r.status.Progress
+		last = &progress
+	}
+	if r.status.Finished() {
+		close(events)
+		return events, last, func() {}
+	}
+	r.subscribers[events] = struct{}{}
+	return events, last, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if _, ok := r.subscribers[events]; ok {
+			delete(r.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+func (r *syncRun) finish(results []models.SyncResult, err error, cancelled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status.Results = results
+	r.status.FinishedAt = time.Now()
+	switch {
+	case cancelled:
+		r.status.State = models.SyncRunCancelled
+	case err != nil:
+		r.status.State = models.SyncRunFailed
+	default:
+		r.status.State = models.SyncRunSucceeded
+		for _, result := range results {
+			if !result.Success {
+				r.status.State = models.SyncRunFailed
+				err = fmt.Errorf("%s: %s", result.DatabaseName, result.Error)
+				break
+			}
+		}
+	}
+	if err != nil {
+		r.status.Error = err.Error()
+	}
+	for subscriber := range r.subscribers {
+		delete(r.subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event string, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
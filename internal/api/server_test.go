@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+const testToken = "secret-token"
+
+type fakeBrowser struct{}
+
+func (fakeBrowser) ListDatabases(isRemote bool) (models.DatabaseList, error) {
+	return models.DatabaseList{{Name: "catalog", Size: 2048, Tables: 2}}, nil
+}
+
+func (fakeBrowser) ListTables(databaseName string, isRemote bool) ([]models.Table, error) {
+	if databaseName != "catalog" {
+		return nil, errors.New("unknown database")
+	}
+	return []models.Table{{Name: "products", Rows: 10}}, nil
+}
+
+func (fakeBrowser) ValidateDatabaseName(name string) error {
+	if name == "mysql" {
+		return errors.New("cannot sync system database 'mysql'")
+	}
+	return nil
+}
+
+// blockingRunner публикует прогресс и ждет release или отмены ctx.
+type blockingRunner struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRunner) ExecutePlanContext(ctx context.Context, plan *models.SyncPlan, runtime models.RuntimeOptions, observer models.ProgressObserver) ([]models.SyncResult, error) {
+	observer(models.ProgressSnapshot{Phase: models.SyncPhaseDump, DatabaseName: plan.Targets[0].DatabaseName, Percent: 42})
+	r.started <- struct{}{}
+	select {
+	case <-ctx.Done():
+		return []models.SyncResult{{DatabaseName: plan.Targets[0].DatabaseName, Error: "mysqlsh killed"}}, ctx.Err()
+	case <-r.release:
+		return []models.SyncResult{{DatabaseName: plan.Targets[0].DatabaseName, Success: true}}, nil
+	}
+}
+
+func newTestAPI(t *testing.T) (*httptest.Server, *blockingRunner) {
+	t.Helper()
+	runner := &blockingRunner{started: make(chan struct{}, 4), release: make(chan struct{})}
+	server := NewServer(testToken, fakeBrowser{}, runner, models.RuntimeOptions{Force: true})
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(func() {
+		httpServer.Close()
+		_ = server.Shutdown(context.Background())
+	})
+	return httpServer, runner
+}
+
+func doRequest(t *testing.T, method string, url string, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("failed to decode %s %s: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestServerRequiresToken(t *testing.T) {
+	httpServer, _ := newTestAPI(t)
+
+	resp, err := http.Get(httpServer.URL + "/v1/databases")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("request without token must be rejected, got %d", resp.StatusCode)
+	}
+
+	var tables []models.Table
+	if status := doRequest(t, http.MethodGet, httpServer.URL+"/v1/databases/catalog/tables", "", &tables); status != http.StatusOK || len(tables) != 1 {
+		t.Fatalf("unexpected tables response: %d %+v", status, tables)
+	}
+}
+
+func TestServerRunsPlanAndStreamsProgress(t *testing.T) {
+	httpServer, runner := newTestAPI(t)
+	plan := `{"targets":[{"database_name":"catalog","replace_entire_database":true}]}`
+
+	var run models.SyncRun
+	if status := doRequest(t, http.MethodPost, httpServer.URL+"/v1/runs", plan, &run); status != http.StatusAccepted || run.State != models.SyncRunRunning {
+		t.Fatalf("unexpected submit response: %d %+v", status, run)
+	}
+	<-runner.started
+
+	var conflict errorResponse
+	if status := doRequest(t, http.MethodPost, httpServer.URL+"/v1/runs", plan, &conflict); status != http.StatusConflict || !strings.Contains(conflict.Error, run.ID) {
+		t.Fatalf("second run of the same database must conflict, got %d %+v", status, conflict)
+	}
+	if status := doRequest(t, http.MethodPost, httpServer.URL+"/v1/runs", `{"targets":[{"database_name":"mysql"}]}`, &conflict); status != http.StatusBadRequest {
+		t.Fatalf("system database must be rejected, got %d", status)
+	}
+
+	resp, err := http.Get(httpServer.URL + "/v1/runs/" + run.ID + "/events?token=" + testToken)
+	if err != nil {
+		t.Fatalf("GET events error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+	close(runner.release)
+
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "event: ") || strings.HasPrefix(line, "data: ") {
+			events = append(events, line)
+		}
+	}
+	stream := strings.Join(events, "\n")
+	if !strings.Contains(stream, "event: progress\ndata: {\"phase\":\"dump\",\"database_name\":\"catalog\"") || !strings.Contains(stream, "event: done\ndata: {\"id\":\""+run.ID+"\",\"state\":\"succeeded\"") {
+		t.Fatalf("unexpected event stream:\n%s", stream)
+	}
+
+	var finished models.SyncRun
+	if status := doRequest(t, http.MethodGet, httpServer.URL+"/v1/runs/"+run.ID, "", &finished); status != http.StatusOK || len(finished.Results) != 1 || !finished.Results[0].Success {
+		t.Fatalf("unexpected finished run: %d %+v", status, finished)
+	}
+	if status := doRequest(t, http.MethodPost, httpServer.URL+"/v1/runs", plan, &run); status != http.StatusAccepted {
+		t.Fatalf("database must be free after the run finished, got %d", status)
+	}
+}
+
+func TestServerCancelsRun(t *testing.T) {
+	httpServer, runner := newTestAPI(t)
+
+	var run models.SyncRun
+	doRequest(t, http.MethodPost, httpServer.URL+"/v1/runs", `{"targets":[{"database_name":"catalog"}]}`, &run)
+	<-runner.started
+	if status := doRequest(t, http.MethodPost, httpServer.URL+"/v1/runs/"+run.ID+"/cancel", "", nil); status != http.StatusAccepted {
+		t.Fatalf("cancel must be accepted, got %d", status)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for run.State == models.SyncRunRunning && time.Now().Before(deadline) {
+		doRequest(t, http.MethodGet, httpServer.URL+"/v1/runs/"+run.ID, "", &run)
+		time.Sleep(5 * time.Millisecond)
+	}
+	if run.State != models.SyncRunCancelled || run.Error == "" {
+		t.Fatalf("run must be cancelled, got %+v", run)
+	}
+	var runs []models.SyncRun
+	if status := doRequest(t, http.MethodGet, httpServer.URL+"/v1/runs", "", &runs); status != http.StatusOK || len(runs) != 1 {
+		t.Fatalf("unexpected runs list: %d %+v", status, runs)
+	}
+}
@@ -1,9 +1,24 @@
 package cli
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 
+	"db-sync-cli/internal/api"
 	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
 	"db-sync-cli/internal/services"
 	"db-sync-cli/internal/tui"
 	"db-sync-cli/internal/updater"
@@ -23,7 +38,9 @@ var rootCmd = &cobra.Command{
 	Use:   "dbsync",
 	Short: "MySQL database synchronization tool",
 	Long: `dbsync is a CLI tool for synchronizing MySQL databases between remote and local servers.
-Uses MySQL Shell (mysqlsh) for fast parallel dump and restore operations.
+Uses MySQL Shell (mysqlsh) for fast parallel dump and restore operations,
+falling back to mydumper/myloader, mysqldump/mysql or the built-in native engine
+when it is not installed.
 
 	Run without arguments to launch the full-screen terminal UI.`,
 	Version: version.Version,
@@ -69,10 +86,29 @@ func loadCLIConfig(cmd *cobra.Command) (*config.Config, error) {
 	if threads > 0 {
 		cfg.Dump.Threads = threads
 	}
+	if workDir, _ := cmd.Flags().GetString("work-dir"); workDir != "" {
+		cfg.Dump.WorkDir = workDir
+	}
+	// cleanup сам показывает и удаляет брошенные дампы.
+	if cmd.Name() != "cleanup" {
+		sweepStaleDumps(cfg)
+	}
 
 	return cfg, nil
 }
 
+// sweepStaleDumps удаляет дампы, брошенные упавшими или убитыми запусками. Сообщение идет
+// в stderr, чтобы не портить вывод команд вроде diff --format json.
+func sweepStaleDumps(cfg *config.Config) {
+	removed, reclaimed, err := services.NewMySQLShellService(cfg, nil).SweepStaleDumps()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Stale dump cleanup failed: %v\n", err)
+	}
+	if removed > 0 {
+		fmt.Fprintf(os.Stderr, "🧹 Removed %d stale dumps, reclaimed %s\n", removed, formatBytes(reclaimed))
+	}
+}
+
 func runTUI(cmd *cobra.Command) error {
 	cfg, err := loadCLIConfig(cmd)
 	if err != nil {
@@ -115,11 +151,25 @@ func executeSyncOperation(cfg *config.Config, dbService *services.DatabaseServic
 		}
 	}
 
+	confirmedDatabases, confirmed, err := confirmProtectedDatabases(cfg, []string{databaseName}, nil)
+	if err != nil {
+		return fmt.Errorf("confirmation failed: %w", err)
+	}
+	if !confirmed {
+		fmt.Printf("❌ Operation cancelled\n")
+		return nil
+	}
+
 	// Выполняем синхронизацию
-	syncResult, err := shellService.ExecuteSync(databaseName)
+	plan := &models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: databaseName, ReplaceEntireDatabase: true}}, ConfirmedDatabases: confirmedDatabases, CreatedAt: time.Now()}
+	results, err := shellService.ExecutePlan(plan, models.RuntimeOptions{}, nil)
 	if err != nil {
+		if len(results) > 0 && results[0].FailureType == models.FailureTypePolicy {
+			printSyncResult(&results[0])
+		}
 		return fmt.Errorf("sync failed: %w", err)
 	}
+	syncResult := &results[0]
 
 	// Показываем результат
 	fmt.Printf("\n✅ Done! %s in %s (dump: %s, restore: %s)\n",
@@ -134,6 +184,132 @@ func executeSyncOperation(cfg *config.Config, dbService *services.DatabaseServic
 	return nil
 }
 
+// syncCmd команда синхронизации между именованными endpoints
+var syncCmd = &cobra.Command{
+	Use:   "sync <database>[:table,table...]...",
+	Short: "Sync databases from a source endpoint to a destination endpoint",
+	Long: `Dump databases from the source endpoint and replace them on the destination endpoint.
+Endpoints are the configured "remote" and "local" servers plus the named servers from
+the endpoints file (DBSYNC_ENDPOINTS_FILE, default ~/.dbsync.endpoints.json), each with
+its own direct or proxy transport. A destination that is not localhost must be marked
+"writable" in the endpoints file, and the confirmation asks to type its name.
+Protected databases (DBSYNC_SAFETY_PROTECTED_DATABASES and built-in names like mysql or prod) ask to
+type their name even with --force unless they are listed in --confirm.
+Before the confirmation the dump size and the restored size are compared with free space in
+the dump directory and the local datadir (DBSYNC_PREFLIGHT_DISK=block|warn|off).
+--consistent (or DBSYNC_DUMP_CONSISTENT=true) dumps under FTWRL or a backup lock and records the
+snapshot GTID; without RELOAD or BACKUP_ADMIN on the source the dump falls back with a warning.
+Append :table,table to a database to sync only the listed tables.`,
+	Example: `  dbsync sync shop
+  dbsync sync shop catalog:products,prices --from prod-replica --to staging`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		consistent, _ := cmd.Flags().GetBool("consistent")
+		source, destination, err := resolveRoute(cfg, from, to)
+		if err != nil {
+			return err
+		}
+
+		dbService := services.NewDatabaseService(cfg)
+		plan := models.SyncPlan{TransportMode: models.TransportModeDirect, CreatedAt: time.Now()}
+		if source.MySQL().HasProxy() {
+			plan.TransportMode = models.TransportModeProxy
+		}
+		databases := make([]string, 0, len(args))
+		for _, arg := range args {
+			target, err := parseScheduleTarget(arg)
+			if err != nil {
+				return err
+			}
+			if err := dbService.ValidateDatabaseName(target.DatabaseName); err != nil {
+				return err
+			}
+			target.Source, target.Destination = from, to
+			target.Consistent = consistent
+			plan.Targets = append(plan.Targets, target)
+			databases = append(databases, target.DatabaseName)
+		}
+
+		fmt.Printf("Route: %s (%s) → %s (%s)\n", source.Name, source.MySQL().Address(), destination.Name, destination.MySQL().Address())
+		shellService := services.NewMySQLShellService(cfg, dbService)
+		preflight, err := shellService.CheckDiskSpace(&plan)
+		if err != nil {
+			fmt.Printf("⚠️  Disk space preflight skipped: %v\n", err)
+		}
+		printDiskPreflight(preflight)
+		if preflight != nil && preflight.Blocking {
+			return fmt.Errorf("not enough disk space for the sync (set DBSYNC_PREFLIGHT_DISK=warn to continue anyway)")
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			message := fmt.Sprintf("This will replace %s on '%s'", strings.Join(databases, ", "), destination.Name)
+			var confirmed bool
+			if destination.MySQL().IsLocalhost() {
+				confirmed, err = promptForConfirmation(message)
+			} else {
+				fmt.Printf("⚠️  Destination '%s' (%s) is not localhost\n", destination.Name, destination.MySQL().Address())
+				confirmed, err = promptForTypedConfirmation(message, destination.Name)
+			}
+			if err != nil {
+				return fmt.Errorf("confirmation failed: %w", err)
+			}
+			if !confirmed {
+				fmt.Printf("❌ Operation cancelled\n")
+				return nil
+			}
+		}
+		preconfirmed, _ := cmd.Flags().GetStringSlice("confirm")
+		confirmedDatabases, confirmed, err := confirmProtectedDatabases(cfg, databases, preconfirmed)
+		if err != nil {
+			return fmt.Errorf("confirmation failed: %w", err)
+		}
+		if !confirmed {
+			fmt.Printf("❌ Operation cancelled\n")
+			return nil
+		}
+		plan.ConfirmedDatabases = confirmedDatabases
+
+		results, err := shellService.ExecutePlan(&plan, models.RuntimeOptions{Force: force, Threads: cfg.Dump.Threads}, nil)
+		for index := range results {
+			printSyncResult(&results[index])
+		}
+		if err != nil {
+			return fmt.Errorf("sync failed: %w", err)
+		}
+		return nil
+	},
+}
+
+// resolveRoute находит endpoints маршрута и проверяет, что в приемник можно писать.
+// Пустые имена означают встроенные remote и local.
+func resolveRoute(cfg *config.Config, from string, to string) (config.Endpoint, config.Endpoint, error) {
+	if from == "" {
+		from = config.EndpointRemote
+	}
+	if to == "" {
+		to = config.EndpointLocal
+	}
+	source, err := cfg.ResolveEndpoint(from)
+	if err != nil {
+		return config.Endpoint{}, config.Endpoint{}, err
+	}
+	destination, err := cfg.ResolveEndpoint(to)
+	if err != nil {
+		return config.Endpoint{}, config.Endpoint{}, err
+	}
+	if err := services.ValidateRoute(source, destination); err != nil {
+		return config.Endpoint{}, config.Endpoint{}, err
+	}
+	return source, destination, nil
+}
+
 // listCmd команда получения списка БД
 var listCmd = &cobra.Command{
 	Use:   "list",
@@ -227,14 +403,782 @@ var configCmd = &cobra.Command{
 			fmt.Printf("Local Proxy: %s\n", cfg.Local.RedactedProxyURL())
 		}
 		fmt.Printf("Dump Timeout: %s\n", cfg.Dump.Timeout)
-		fmt.Printf("\n--- MySQL Shell Settings ---\n")
+		fmt.Printf("\n--- Dump Settings ---\n")
+		fmt.Printf("Engine: %s\n", cfg.Dump.Engine)
+		fmt.Printf("Stream Copy: %t\n", cfg.Dump.Copy)
+		fmt.Printf("Batch Size: %d\n", cfg.Dump.BatchSize)
 		fmt.Printf("Threads: %d\n", cfg.Dump.Threads)
 		fmt.Printf("Compress: %v (zstd)\n", cfg.Dump.Compress)
+		if webhooks := cfg.Notify.WebhookURLs(); len(webhooks) > 0 {
+			fmt.Printf("\n--- Notifications ---\n")
+			fmt.Printf("Webhooks: %d (retries: %d, timeout: %s)\n", len(webhooks), cfg.Notify.Retries, cfg.Notify.Timeout)
+		}
+		if cfg.Backup.Enabled {
+			fmt.Printf("\n--- Local Backups ---\n")
+			fmt.Printf("Directory: %s (keep: %d)\n", cfg.Backup.ResolvedDir(), cfg.Backup.Keep)
+		}
+		if cfg.Verify.Enabled {
+			fmt.Printf("\n--- Verification ---\n")
+			fmt.Printf("Checksum: %v (tables up to %d MB)\n", cfg.Verify.Checksum, cfg.Verify.ChecksumMaxMB)
+			fmt.Printf("Fail on mismatch: %v\n", cfg.Verify.FailOnMismatch)
+		}
+		if cfg.Incremental.Enabled {
+			fmt.Printf("\n--- Incremental Sync ---\n")
+			fmt.Printf("Column: %s (or AUTO_INCREMENT primary key)\n", cfg.Incremental.Column)
+			fmt.Printf("Watermarks: %s\n", cfg.Incremental.ResolvedDir())
+		}
+		if cfg.Smart.Enabled {
+			fmt.Printf("\n--- Smart Sync ---\n")
+			fmt.Printf("Checksum: %v\n", cfg.Smart.Checksum)
+			fmt.Printf("Snapshots: %s\n", cfg.Smart.ResolvedDir())
+		}
+
+		return nil
+	},
+}
+
+// endpointsCmd команда списка endpoints
+var endpointsCmd = &cobra.Command{
+	Use:   "endpoints",
+	Short: "List source and destination endpoints",
+	Long: `List the built-in "remote" and "local" endpoints and the named servers from the
+endpoints file with their transport and whether they may be used as a destination.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		endpoints, err := cfg.ListEndpoints()
+		if err != nil {
+			return err
+		}
+		printEndpoints(endpoints)
+		fmt.Printf("Endpoints file: %s\n", cfg.Endpoints.ResolvedFile())
+		return nil
+	},
+}
+
+// restoreBackupCmd команда восстановления локальной БД из бэкапа
+var restoreBackupCmd = &cobra.Command{
+	Use:   "restore-backup <database>",
+	Short: "Restore a local database from an automatic pre-sync backup",
+	Long: `Restore a local database from the backup taken before the last sync overwrote it.
+By default the latest backup is used; pass --backup to pick a specific one.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+		databaseName := args[0]
+
+		dbService := services.NewDatabaseService(cfg)
+		shellService := services.NewMySQLShellService(cfg, dbService)
+
+		backups, err := shellService.ListLocalBackups(databaseName)
+		if err != nil {
+			return err
+		}
+		listOnly, _ := cmd.Flags().GetBool("list")
+		if listOnly {
+			printLocalBackups(databaseName, backups)
+			return nil
+		}
+
+		backupPath, _ := cmd.Flags().GetString("backup")
+		if backupPath == "" {
+			if len(backups) == 0 {
+				return fmt.Errorf("no local backups found for database '%s'", databaseName)
+			}
+			backupPath = backups[0].Path
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			message := fmt.Sprintf("This will replace the local database '%s' with backup %s", databaseName, backupPath)
+			confirmed, err := promptForConfirmation(message)
+			if err != nil {
+				return fmt.Errorf("confirmation failed: %w", err)
+			}
+
+			if !confirmed {
+				fmt.Printf("❌ Operation cancelled\n")
+				return nil
+			}
+		}
+
+		confirmedDatabases, confirmed, err := confirmProtectedTarget(cmd, cfg, databaseName)
+		if err != nil {
+			return fmt.Errorf("confirmation failed: %w", err)
+		}
+		if !confirmed {
+			fmt.Printf("❌ Operation cancelled\n")
+			return nil
+		}
+		shellService.SetConfirmedDatabases(confirmedDatabases)
+
+		backup, err := shellService.RestoreLocalBackup(databaseName, backupPath, nil)
+		if err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+
+		fmt.Printf("\n✅ Restored '%s' from %s (%s)\n", databaseName, backup.Path, formatBytes(backup.SizeBytes))
+		return nil
+	},
+}
+
+// cleanupCmd команда удаления брошенных дампов
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Remove dump directories left behind by crashed or killed runs",
+	Long: `Find dump directories, unpacked archives and downloaded files in the work directory
+(DBSYNC_DUMP_WORK_DIR or --work-dir, default is the system temp directory) and in the system
+temp directory that belong to runs which are no longer alive, report them and remove them.
+Entries created by older versions have no lock file and are treated as stale after a day.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		shellService := services.NewMySQLShellService(cfg, nil)
+		stale, err := shellService.FindStaleDumps()
+		if err != nil {
+			return err
+		}
+		printStaleDumps(stale)
+		if len(stale) == 0 {
+			return nil
+		}
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if dryRun {
+			return nil
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			confirmed, err := promptForConfirmation(fmt.Sprintf("This will remove %d stale dumps", len(stale)))
+			if err != nil {
+				return fmt.Errorf("confirmation failed: %w", err)
+			}
+			if !confirmed {
+				fmt.Printf("❌ Operation cancelled\n")
+				return nil
+			}
+		}
+
+		reclaimed, err := shellService.RemoveStaleDumps(stale)
+		fmt.Printf("✅ Reclaimed %s\n", formatBytes(reclaimed))
+		if err != nil {
+			return fmt.Errorf("cleanup failed: %w", err)
+		}
+		return nil
+	},
+}
+
+// exportCmd команда упаковки дампа remote БД в переносимый архив
+var exportCmd = &cobra.Command{
+	Use:   "export <database>",
+	Short: "Dump a remote database into a portable .dbsync archive",
+	Long: `Dump a remote database and pack it into a single tar+zstd archive with a manifest
+(source host, server version, tables, row counts, timestamp and dbsync version).
+The archive can be shared and restored elsewhere with 'dbsync import'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+		databaseName := args[0]
+
+		toBucket, _ := cmd.Flags().GetBool("to-bucket")
+		if toBucket && !cfg.Storage.Enabled() {
+			return fmt.Errorf("object storage is not configured: set DBSYNC_STORAGE_BUCKET")
+		}
+		outputPath, _ := cmd.Flags().GetString("output")
+		if outputPath == "" {
+			outputPath = fmt.Sprintf("%s_%s.dbsync", databaseName, time.Now().Format("20060102T150405"))
+			// Без -o архив для бакета живет только до загрузки.
+			if toBucket {
+				if err := os.MkdirAll(cfg.Dump.ResolvedWorkDir(), 0755); err != nil {
+					return fmt.Errorf("failed to create work directory: %w", err)
+				}
+				outputPath = filepath.Join(cfg.Dump.ResolvedWorkDir(), outputPath)
+				defer os.Remove(outputPath)
+			}
+		}
+
+		dbService := services.NewDatabaseService(cfg)
+		shellService := services.NewMySQLShellService(cfg, dbService)
+		manifest, err := shellService.ExportDatabase(databaseName, outputPath, nil)
+		if err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+
+		if toBucket {
+			object, err := shellService.UploadArchive(outputPath, manifest, nil)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n✅ Exported '%s' (%d tables, ~%d rows, %s dump) to s3://%s/%s\n", databaseName, len(manifest.Tables), manifest.TotalRows(), formatBytes(manifest.DumpSize()), cfg.Storage.Bucket, object.Key)
+			return nil
+		}
+
+		fmt.Printf("\n✅ Exported '%s' (%d tables, ~%d rows, %s dump) to %s\n", databaseName, len(manifest.Tables), manifest.TotalRows(), formatBytes(manifest.DumpSize()), outputPath)
+		return nil
+	},
+}
+
+// importCmd команда восстановления локальной БД из архива dbsync export
+var importCmd = &cobra.Command{
+	Use:   "import <file.dbsync>",
+	Short: "Restore a local database from a .dbsync archive",
+	Long: `Unpack an archive created by 'dbsync export', verify the checksum of every dump file
+and load it into the local server. By default the database keeps its original name;
+pass --as to restore it under another name.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+		archivePath := args[0]
+
+		manifest, err := services.ReadArchiveManifest(archivePath)
+		if err != nil {
+			return err
+		}
+		databaseName, _ := cmd.Flags().GetString("as")
+		if databaseName == "" {
+			databaseName = manifest.DatabaseName
+		}
+		fmt.Printf("📦 Archive of '%s' from %s:%d (MySQL %s), created %s by dbsync %s\n",
+			manifest.DatabaseName, manifest.SourceHost, manifest.SourcePort, manifest.ServerVersion,
+			manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"), manifest.DbsyncVersion)
+		fmt.Printf("   %d tables, ~%d rows, %s dump (%s engine)\n", len(manifest.Tables), manifest.TotalRows(), formatBytes(manifest.DumpSize()), manifest.DumpEngine)
+
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			message := fmt.Sprintf("This will replace the local database '%s' with the archive contents", databaseName)
+			confirmed, err := promptForConfirmation(message)
+			if err != nil {
+				return fmt.Errorf("confirmation failed: %w", err)
+			}
+
+			if !confirmed {
+				fmt.Printf("❌ Operation cancelled\n")
+				return nil
+			}
+		}
+
+		dbService := services.NewDatabaseService(cfg)
+		shellService := services.NewMySQLShellService(cfg, dbService)
+		confirmedDatabases, confirmed, err := confirmProtectedTarget(cmd, cfg, databaseName)
+		if err != nil {
+			return fmt.Errorf("confirmation failed: %w", err)
+		}
+		if !confirmed {
+			fmt.Printf("❌ Operation cancelled\n")
+			return nil
+		}
+		shellService.SetConfirmedDatabases(confirmedDatabases)
+
+		if _, err := shellService.ImportArchive(archivePath, databaseName, nil); err != nil {
+			return fmt.Errorf("import failed: %w", err)
+		}
+
+		fmt.Printf("\n✅ Imported '%s' from %s\n", databaseName, archivePath)
+		return nil
+	},
+}
+
+// pullCmd команда восстановления локальной БД из архива в S3-совместимом хранилище
+var pullCmd = &cobra.Command{
+	Use:   "pull <database> --from-bucket",
+	Short: "Restore a local database from an archive in object storage",
+	Long: `Download an archive uploaded with 'dbsync export --to-bucket' from the configured
+S3-compatible bucket and restore it locally. The newest archive of the database is used
+unless --key selects a specific object; --list shows the available archives.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+		databaseName := args[0]
 
+		fromBucket, _ := cmd.Flags().GetBool("from-bucket")
+		if !fromBucket {
+			return fmt.Errorf("pull requires --from-bucket; use 'dbsync' to sync from the remote server")
+		}
+
+		dbService := services.NewDatabaseService(cfg)
+		shellService := services.NewMySQLShellService(cfg, dbService)
+
+		listOnly, _ := cmd.Flags().GetBool("list")
+		key, _ := cmd.Flags().GetString("key")
+		if listOnly || key == "" {
+			archives, err := shellService.ListBucketArchives(databaseName)
+			if err != nil {
+				return err
+			}
+			if listOnly {
+				printBucketArchives(databaseName, cfg.Storage.Bucket, archives)
+				return nil
+			}
+			if len(archives) == 0 {
+				return fmt.Errorf("no archives of '%s' found in bucket %s", databaseName, cfg.Storage.Bucket)
+			}
+			key = archives[0].Key
+		}
+
+		localName, _ := cmd.Flags().GetString("as")
+		if localName == "" {
+			localName = databaseName
+		}
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			message := fmt.Sprintf("This will replace the local database '%s' with s3://%s/%s", localName, cfg.Storage.Bucket, key)
+			confirmed, err := promptForConfirmation(message)
+			if err != nil {
+				return fmt.Errorf("confirmation failed: %w", err)
+			}
+
+			if !confirmed {
+				fmt.Printf("❌ Operation cancelled\n")
+				return nil
+			}
+		}
+
+		confirmedDatabases, confirmed, err := confirmProtectedTarget(cmd, cfg, localName)
+		if err != nil {
+			return fmt.Errorf("confirmation failed: %w", err)
+		}
+		if !confirmed {
+			fmt.Printf("❌ Operation cancelled\n")
+			return nil
+		}
+		shellService.SetConfirmedDatabases(confirmedDatabases)
+
+		manifest, err := shellService.PullFromBucket(databaseName, key, localName, nil)
+		if err != nil {
+			return fmt.Errorf("pull failed: %w", err)
+		}
+
+		fmt.Printf("\n✅ Pulled '%s' (%d tables, exported %s from %s) into local '%s'\n", manifest.DatabaseName, len(manifest.Tables), manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"), manifest.SourceHost, localName)
+		return nil
+	},
+}
+
+// diffCmd команда сравнения схем remote и local
+var diffCmd = &cobra.Command{
+	Use:   "diff <database>",
+	Short: "Compare remote and local schema of a database",
+	Long: `Compare tables, columns, indexes and row counts of a database on the remote and local servers.
+Use --format json for machine-readable output or --format sql for ALTER statements
+that reproduce local schema changes on top of the remote schema.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" && format != "sql" {
+			return fmt.Errorf("unsupported format %q (expected text, json or sql)", format)
+		}
+
+		dbService := services.NewDatabaseService(cfg)
+		diff, err := dbService.DiffSchema(args[0])
+		if err != nil {
+			return fmt.Errorf("diff failed: %w", err)
+		}
+
+		switch format {
+		case "json":
+			content, err := json.MarshalIndent(diff, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode diff: %w", err)
+			}
+			fmt.Println(string(content))
+		case "sql":
+			for _, statement := range services.SchemaAlterStatements(diff) {
+				fmt.Println(statement)
+			}
+		default:
+			printSchemaDiff(diff)
+		}
+
+		return nil
+	},
+}
+
+// doctorCmd команда диагностики окружения и привилегий
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check dump tools, server compatibility and privileges before syncing",
+	Long: `Check the environment of a sync route before running it: the dump engine and the
+MySQL Shell version (8.4 or newer), proxy reachability, connections and server versions,
+source privileges (SELECT, SHOW VIEW, TRIGGER, EVENT and the locks the dump engine takes),
+destination privileges, local_infile, and sql_mode and lower_case_table_names mismatches.
+With DBSYNC_DUMP_CONSISTENT=true it also checks that the source user can lock a consistent snapshot.
+Every problem comes with a hint on how to fix it. Exits with an error when a check fails.`,
+	Example: `  dbsync doctor
+  dbsync doctor --from prod-replica --to staging --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unsupported format %q (expected text or json)", format)
+		}
+
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		shellService := services.NewMySQLShellService(cfg, services.NewDatabaseService(cfg))
+		report, err := shellService.Diagnose(from, to)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			content, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode report: %w", err)
+			}
+			fmt.Println(string(content))
+		} else {
+			printDoctorReport(report)
+		}
+		if failures := report.Failures(); failures > 0 {
+			return fmt.Errorf("%d checks failed", failures)
+		}
+		return nil
+	},
+}
+
+// followCmd команда непрерывной синхронизации по binlog
+var followCmd = &cobra.Command{
+	Use:   "follow <database>",
+	Short: "Keep a local database close to live by tailing the remote binlog",
+	Long: `Run a full sync of the database, then connect to the remote server as a replication client
+and apply row events of the database to the local copy until interrupted.
+Requires binlog_format=ROW and binlog_row_image=FULL on the remote and a user with
+REPLICATION SLAVE and REPLICATION CLIENT privileges. Use --resume to continue from the
+saved binlog position without the initial sync.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+		databaseName := args[0]
+
+		dbService := services.NewDatabaseService(cfg)
+		if err := dbService.ValidateDatabaseName(databaseName); err != nil {
+			return err
+		}
+		shellService := services.NewMySQLShellService(cfg, dbService)
+		shellService.SetQuiet(true)
+
+		resume, _ := cmd.Flags().GetBool("resume")
+		force, _ := cmd.Flags().GetBool("force")
+		if !resume && !force {
+			message := fmt.Sprintf("This will replace the local database '%s' and then follow remote changes", databaseName)
+			confirmed, err := promptForConfirmation(message)
+			if err != nil {
+				return fmt.Errorf("confirmation failed: %w", err)
+			}
+
+			if !confirmed {
+				fmt.Printf("❌ Operation cancelled\n")
+				return nil
+			}
+		}
+
+		confirmedDatabases, confirmed, err := confirmProtectedTarget(cmd, cfg, databaseName)
+		if err != nil {
+			return fmt.Errorf("confirmation failed: %w", err)
+		}
+		if !confirmed {
+			fmt.Printf("❌ Operation cancelled\n")
+			return nil
+		}
+		shellService.SetConfirmedDatabases(confirmedDatabases)
+
+		target := models.SyncTarget{DatabaseName: databaseName, ReplaceEntireDatabase: true}
+		follow := func(ctx context.Context, observer models.FollowObserver) error {
+			return shellService.Follow(ctx, target, resume, observer)
+		}
+
+		plain, _ := cmd.Flags().GetBool("plain")
+		if !plain {
+			return tui.RunFollow(cfg, databaseName, follow)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return follow(ctx, printFollowStatus)
+	},
+}
+
+// scheduleCmd группа команд управления расписаниями
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manage scheduled syncs executed by the daemon",
+	Long:  `Bind cron expressions to saved sync plans. Schedules are executed by "dbsync daemon".`,
+}
+
+// scheduleAddCmd команда добавления расписания
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add <name> <database>[:table,table...]...",
+	Short: "Save a sync plan and run it on a cron schedule",
+	Long: `Save a sync plan of one or more databases and bind it to a cron expression.
+The expression uses the standard five fields (minute hour day month weekday) or
+descriptors like @daily and @hourly, evaluated in the local time zone of the daemon.
+--consistent saves the targets with consistent-snapshot dumps (see "dbsync sync --help").
+Append :table,table to a database to sync only the listed tables.`,
+	Example: `  dbsync schedule add catalog-morning catalog --cron "0 7 * * 1-5"
+  dbsync schedule add orders-hourly shop:orders,order_items --cron @hourly
+  dbsync schedule add staging-nightly shop --from prod-replica --to staging --cron @daily`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		consistent, _ := cmd.Flags().GetBool("consistent")
+		source, _, err := resolveRoute(cfg, from, to)
+		if err != nil {
+			return err
+		}
+
+		dbService := services.NewDatabaseService(cfg)
+		plan := models.SyncPlan{TransportMode: models.TransportModeDirect, CreatedAt: time.Now()}
+		if source.MySQL().HasProxy() {
+			plan.TransportMode = models.TransportModeProxy
+		}
+		for _, arg := range args[1:] {
+			target, err := parseScheduleTarget(arg)
+			if err != nil {
+				return err
+			}
+			if err := dbService.ValidateDatabaseName(target.DatabaseName); err != nil {
+				return err
+			}
+			target.Source, target.Destination = from, to
+			target.Consistent = consistent
+			plan.Targets = append(plan.Targets, target)
+		}
+		// Daemon не может спросить подтверждение, поэтому защищенные БД подтверждаются при сохранении.
+		plan.ConfirmedDatabases, _ = cmd.Flags().GetStringSlice("confirm")
+		for _, target := range plan.Targets {
+			if cfg.Safety.IsProtected(target.DatabaseName) && !slices.Contains(plan.ConfirmedDatabases, target.DatabaseName) {
+				return fmt.Errorf("database %q is protected by the safety policy; pass --confirm %s to schedule it", target.DatabaseName, target.DatabaseName)
+			}
+		}
+
+		cronExpr, _ := cmd.Flags().GetString("cron")
+		schedule := models.Schedule{Name: args[0], Cron: cronExpr, Plan: plan, CreatedAt: time.Now()}
+		if err := services.NewScheduleStore(cfg).Add(schedule); err != nil {
+			return err
+		}
+
+		next, _ := services.NextScheduleRun(cronExpr, time.Now())
+		fmt.Printf("✅ Schedule '%s' saved, next run %s\n", schedule.Name, next.Format("2006-01-02 15:04 MST"))
+		fmt.Printf("💡 Start \"dbsync daemon\" to execute schedules\n")
+		return nil
+	},
+}
+
+// scheduleListCmd команда списка расписаний
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List schedules with next run and last result",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		statuses, err := services.NewScheduleStore(cfg).Statuses(time.Now())
+		if err != nil {
+			return err
+		}
+		printSchedules(statuses)
 		return nil
 	},
 }
 
+// scheduleRemoveCmd команда удаления расписания
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a schedule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := services.NewScheduleStore(cfg).Remove(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Schedule '%s' removed\n", args[0])
+		return nil
+	},
+}
+
+// daemonCmd команда фонового выполнения расписаний
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run scheduled syncs until interrupted",
+	Long: `Run a long-lived process that executes saved schedules through the regular sync plan.
+A run is skipped while the previous run of the same schedule is still going.
+Results are appended to runs.jsonl in the schedule directory. Schedules added or
+removed while the daemon runs are picked up within 30 seconds.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		dbService := services.NewDatabaseService(cfg)
+		shellService := services.NewMySQLShellService(cfg, dbService)
+		shellService.SetQuiet(true)
+		store := services.NewScheduleStore(cfg)
+
+		statuses, err := store.Statuses(time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🕒 dbsync daemon started, %d schedule(s) in %s\n", len(statuses), cfg.Schedule.ResolvedDir())
+
+		logf := func(format string, args ...any) {
+			fmt.Printf("[%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+		}
+		daemon := services.NewScheduleDaemon(store, shellService, models.RuntimeOptions{Force: true, Threads: cfg.Dump.Threads}, logf)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		err = daemon.Run(ctx)
+		fmt.Printf("🛑 dbsync daemon stopped\n")
+		return err
+	},
+}
+
+// serveCmd команда локального HTTP API
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Expose a localhost HTTP API to trigger and watch syncs",
+	Long: `Start a localhost HTTP/JSON API for IDE plugins and dashboards.
+Every request needs the token as "Authorization: Bearer <token>" (or ?token= for EventSource).
+
+  GET  /v1/databases                 remote databases
+  GET  /v1/databases/{name}/tables   remote tables of a database
+  POST /v1/runs                      submit a sync plan (models.SyncPlan JSON)
+  GET  /v1/runs                      list runs
+  GET  /v1/runs/{id}                 run state and results
+  GET  /v1/runs/{id}/events          progress as Server-Sent Events
+  POST /v1/runs/{id}/cancel          cancel a run
+
+Only one run per local database may be active at a time.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadCLIConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if addr, _ := cmd.Flags().GetString("addr"); addr != "" {
+			cfg.Serve.Addr = addr
+		}
+		if token, _ := cmd.Flags().GetString("token"); token != "" {
+			cfg.Serve.Token = token
+		}
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+		token := cfg.Serve.Token
+		if token == "" {
+			if token, err = generateAPIToken(); err != nil {
+				return err
+			}
+		}
+
+		dbService := services.NewDatabaseService(cfg)
+		shellService := services.NewMySQLShellService(cfg, dbService)
+		shellService.SetQuiet(true)
+		apiServer := api.NewServer(token, dbService, shellService, models.RuntimeOptions{Force: true, Threads: cfg.Dump.Threads})
+		httpServer := &http.Server{Addr: cfg.Serve.Addr, Handler: apiServer.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+		listener, err := net.Listen("tcp", cfg.Serve.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", cfg.Serve.Addr, err)
+		}
+		fmt.Printf("🌐 dbsync API listening on http://%s\n", listener.Addr())
+		if cfg.Serve.Token == "" {
+			fmt.Printf("🔑 Token: %s\n", token)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- httpServer.Serve(listener)
+		}()
+
+		select {
+		case err := <-serveErr:
+			return fmt.Errorf("api server failed: %w", err)
+		case <-ctx.Done():
+		}
+		fmt.Printf("🛑 Stopping API, cancelling active runs...\n")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Dump.Timeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to stop api server: %w", err)
+		}
+		return apiServer.Shutdown(shutdownCtx)
+	},
+}
+
+// generateAPIToken создает случайный токен для запуска API без сохраненного токена.
+func generateAPIToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// parseScheduleTarget разбирает аргумент вида database или database:table,table.
+func parseScheduleTarget(arg string) (models.SyncTarget, error) {
+	databaseName, tables, hasTables := strings.Cut(arg, ":")
+	target := models.SyncTarget{DatabaseName: databaseName, ReplaceEntireDatabase: true}
+	if !hasTables {
+		return target, nil
+	}
+	for _, table := range strings.Split(tables, ",") {
+		if table = strings.TrimSpace(table); table != "" {
+			target.SelectedTables = append(target.SelectedTables, table)
+		}
+	}
+	if len(target.SelectedTables) == 0 {
+		return models.SyncTarget{}, fmt.Errorf("no tables listed for database '%s'", databaseName)
+	}
+	target.ReplaceEntireDatabase = false
+	return target, nil
+}
+
 // versionCmd команда показа версии
 var versionCmd = &cobra.Command{
 	Use:   "version",
@@ -322,6 +1266,7 @@ func init() {
 	// Глобальные флаги
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default is .env)")
+	rootCmd.PersistentFlags().String("work-dir", "", "directory for temporary dumps (default from DBSYNC_DUMP_WORK_DIR or the system temp directory)")
 
 	// Флаги для синхронизации (теперь в rootCmd)
 	rootCmd.Flags().Bool("dry-run", false, "show what would be done without executing")
@@ -332,12 +1277,93 @@ func init() {
 	upgradeCmd.Flags().Bool("check-only", false, "only check for updates without installing")
 	upgradeCmd.Flags().Bool("force", false, "skip confirmation prompt for update")
 
+	// Флаги для синхронизации между endpoints
+	syncCmd.Flags().String("from", "", "source endpoint (default remote)")
+	syncCmd.Flags().String("to", "", "destination endpoint (default local)")
+	syncCmd.Flags().Bool("force", false, "skip confirmation prompt")
+	syncCmd.Flags().Int("threads", 8, "number of threads for parallel dump/restore")
+	syncCmd.Flags().StringSlice("confirm", nil, "protected databases confirmed for replacement without the typed prompt")
+	syncCmd.Flags().Bool("consistent", false, "dump under FTWRL or a backup lock and record the snapshot GTID")
+
+	// Флаги для сравнения схем
+	diffCmd.Flags().String("format", "text", "output format: text, json or sql")
+
+	// Флаги для диагностики
+	doctorCmd.Flags().String("from", "", "source endpoint (default remote)")
+	doctorCmd.Flags().String("to", "", "destination endpoint (default local)")
+	doctorCmd.Flags().String("format", "text", "output format: text or json")
+
+	// Флаги для follow режима
+	followCmd.Flags().Bool("resume", false, "continue from the saved binlog position without the initial sync")
+	followCmd.Flags().Bool("plain", false, "print status lines instead of the terminal UI")
+	followCmd.Flags().Bool("force", false, "skip confirmation prompt")
+	followCmd.Flags().StringSlice("confirm", nil, "protected databases confirmed for replacement without the typed prompt")
+	followCmd.Flags().Int("threads", 8, "number of threads for the initial sync")
+
+	// Флаги для расписаний и daemon
+	scheduleAddCmd.Flags().String("cron", "", "cron expression, e.g. \"0 7 * * 1-5\" or @daily")
+	_ = scheduleAddCmd.MarkFlagRequired("cron")
+	scheduleAddCmd.Flags().String("from", "", "source endpoint (default remote)")
+	scheduleAddCmd.Flags().String("to", "", "destination endpoint (default local)")
+	scheduleAddCmd.Flags().StringSlice("confirm", nil, "protected databases the schedule may replace")
+	scheduleAddCmd.Flags().Bool("consistent", false, "dump under FTWRL or a backup lock and record the snapshot GTID")
+	daemonCmd.Flags().Int("threads", 8, "number of threads for parallel dump/restore")
+
+	// Флаги для HTTP API
+	serveCmd.Flags().String("addr", "", "listen address (loopback only, default from DBSYNC_SERVE_ADDR)")
+	serveCmd.Flags().String("token", "", "API token (default from DBSYNC_SERVE_TOKEN, generated when empty)")
+	serveCmd.Flags().Int("threads", 8, "number of threads for parallel dump/restore")
+
+	// Флаги для восстановления из бэкапа
+	restoreBackupCmd.Flags().Bool("list", false, "list available backups without restoring")
+	restoreBackupCmd.Flags().String("backup", "", "path of the backup to restore (default is the latest)")
+	restoreBackupCmd.Flags().Bool("force", false, "skip confirmation prompt")
+	restoreBackupCmd.Flags().StringSlice("confirm", nil, "protected databases confirmed for replacement without the typed prompt")
+	restoreBackupCmd.Flags().Int("threads", 8, "number of threads for parallel restore")
+
+	// Флаги для очистки брошенных дампов
+	cleanupCmd.Flags().Bool("dry-run", false, "only report stale dumps")
+	cleanupCmd.Flags().Bool("force", false, "skip confirmation prompt")
+
+	// Флаги для переносимых архивов
+	exportCmd.Flags().StringP("output", "o", "", "archive path (default <database>_<timestamp>.dbsync)")
+	exportCmd.Flags().Int("threads", 8, "number of threads for parallel dump")
+	exportCmd.Flags().Bool("to-bucket", false, "upload the archive to the configured S3-compatible bucket")
+	importCmd.Flags().String("as", "", "local database name (default is the exported database name)")
+	importCmd.Flags().Bool("force", false, "skip confirmation prompt")
+	importCmd.Flags().StringSlice("confirm", nil, "protected databases confirmed for replacement without the typed prompt")
+	importCmd.Flags().Int("threads", 8, "number of threads for parallel restore")
+	pullCmd.Flags().Bool("from-bucket", false, "download the archive from the configured S3-compatible bucket")
+	pullCmd.Flags().String("key", "", "object key of the archive (default is the newest archive of the database)")
+	pullCmd.Flags().String("as", "", "local database name (default is the pulled database name)")
+	pullCmd.Flags().Bool("list", false, "list archives in the bucket without restoring")
+	pullCmd.Flags().Bool("force", false, "skip confirmation prompt")
+	pullCmd.Flags().StringSlice("confirm", nil, "protected databases confirmed for replacement without the typed prompt")
+	pullCmd.Flags().Int("threads", 8, "number of threads for parallel restore")
+
 	// Добавляем команды
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(endpointsCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(upgradeCmd)
 	rootCmd.AddCommand(tuiCmd)
 	rootCmd.AddCommand(textCmd)
+	rootCmd.AddCommand(restoreBackupCmd)
+	rootCmd.AddCommand(cleanupCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(pullCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(followCmd)
+	rootCmd.AddCommand(scheduleCmd)
+	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(serveCmd)
+
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"db-sync-cli/internal/config"
 	"db-sync-cli/internal/models"
 )
 
@@ -73,8 +74,17 @@ func printSyncResult(result *models.SyncResult) {
 	}
 	if result.Success {
 		fmt.Printf("Successfully synchronized database '%s'\n", result.DatabaseName)
+		if result.Source != "" || result.Destination != "" {
+			fmt.Printf("Route: %s\n", formatRoute(result.Source, result.Destination))
+		}
+	} else if result.FailureType == models.FailureTypePolicy {
+		fmt.Printf("Safety policy refused to synchronize database '%s': %s\n", result.DatabaseName, result.Error)
+		return
 	} else {
 		fmt.Printf("Failed to synchronize database '%s': %s\n", result.DatabaseName, result.Error)
+		printServerTuning(result.ServerTuning)
+		printVerification(result.Verification)
+		printBackupStatus(result)
 		return
 	}
 	if result.LogicalSize > 0 {
@@ -89,4 +99,348 @@ func printSyncResult(result *models.SyncResult) {
 	if result.Traffic.TotalBytes() > 0 {
 		fmt.Printf("Network I/O: %s\n", formatBytes(result.Traffic.TotalBytes()))
 	}
+	printIncremental(result.Incremental)
+	printSmartSync(result.SmartSync)
+	printSnapshot(result.Snapshot)
+	printCompatibility(result.Compatibility)
+	printServerTuning(result.ServerTuning)
+	printVerification(result.Verification)
+	printBackupStatus(result)
+	for _, hook := range result.Hooks {
+		status := "OK"
+		if !hook.Success {
+			status = "FAILED"
+		}
+		fmt.Printf("Hook %s %s (%s): %s in %s\n", hook.Point, hook.Name, hook.Kind, status, formatDuration(hook.Duration))
+	}
+	if slowest := result.SlowestTables(5); len(slowest) > 0 {
+		fmt.Println("Slowest tables:")
+		for _, table := range slowest {
+			fmt.Printf("  %s: %s (dump %s, load %s)\n", table.Name, formatDuration(table.Duration()), formatDuration(table.DumpDuration), formatDuration(table.LoadDuration))
+		}
+	}
+}
+
+func printBackupStatus(result *models.SyncResult) {
+	if result.Backup == nil {
+		return
+	}
+	fmt.Printf("Local backup: %s (%s)\n", result.Backup.Path, formatBytes(result.Backup.SizeBytes))
+	switch {
+	case result.RolledBack:
+		fmt.Printf("Rolled back local database '%s' from backup\n", result.DatabaseName)
+	case result.RollbackError != "":
+		fmt.Printf("Rollback failed: %s\n", result.RollbackError)
+	}
+}
+
+func printIncremental(plan *models.IncrementalPlan) {
+	if plan == nil {
+		return
+	}
+	if !plan.Active() {
+		fmt.Printf("Incremental: full resync (%s)\n", plan.Reason)
+		return
+	}
+	fmt.Printf("Incremental: %d tables upserted past watermark (%s)\n", len(plan.Incremental), strings.Join(plan.IncrementalTableNames(), ", "))
+	if len(plan.Full) > 0 {
+		fmt.Printf("Full: %d tables (%s)\n", len(plan.Full), strings.Join(plan.Full, ", "))
+	}
+}
+
+func printSmartSync(plan *models.SmartSyncPlan) {
+	if plan == nil || len(plan.Tables) == 0 {
+		return
+	}
+	skipped := plan.SkippedTables()
+	if len(skipped) == 0 {
+		fmt.Printf("Smart sync: all %d tables refreshed\n", len(plan.Tables))
+		return
+	}
+	fmt.Printf("Smart sync: %d unchanged tables skipped, saved %s (%s)\n", len(skipped), formatBytes(plan.SavedBytes()), strings.Join(skipped, ", "))
+}
+
+func printSnapshot(snapshot *models.DumpSnapshot) {
+	if snapshot == nil || (!snapshot.Requested && !snapshot.Consistent) {
+		return
+	}
+	if !snapshot.Consistent {
+		fmt.Printf("Snapshot: not consistent (%s)\n", snapshot.Warning)
+		return
+	}
+	line := "Snapshot: consistent"
+	if snapshot.Lock != "" {
+		line += " (" + string(snapshot.Lock) + ")"
+	}
+	if snapshot.GTIDExecuted != "" {
+		line += ", GTID " + snapshot.GTIDExecuted
+	} else if snapshot.BinlogFile != "" {
+		line += fmt.Sprintf(", binlog %s:%d", snapshot.BinlogFile, snapshot.BinlogPosition)
+	}
+	fmt.Println(line)
+}
+
+func printCompatibility(fixes []models.CompatibilityFix) {
+	if len(fixes) == 0 {
+		return
+	}
+	fmt.Printf("Compatibility: %d fixes applied\n", len(fixes))
+	for _, fix := range fixes {
+		fmt.Printf("  %s: %s\n", fix.Object, fix.Fix)
+	}
+}
+
+func printServerTuning(variables []models.ServerVariable) {
+	if len(variables) == 0 {
+		return
+	}
+	fmt.Printf("Server tuning: %d variables changed for load\n", len(variables))
+	for _, variable := range variables {
+		status := "restored"
+		switch {
+		case variable.Error != "":
+			status = "restore failed: " + variable.Error
+		case !variable.Restored:
+			status = "not restored"
+		}
+		fmt.Printf("  %s: %s → %s, %s\n", variable.Name, variable.Original, variable.Applied, status)
+	}
+}
+
+func printDiskPreflight(preflight *models.DiskPreflight) {
+	if preflight == nil {
+		return
+	}
+	fmt.Printf("Disk: dump ~%s, restore ~%s\n", formatBytes(preflight.DumpBytes), formatBytes(preflight.RestoreBytes))
+	for _, check := range preflight.Checks {
+		marker := "  "
+		switch check.Status {
+		case models.DiskSpaceInsufficient:
+			marker = "❌"
+		case models.DiskSpaceLow:
+			marker = "⚠️ "
+		}
+		fmt.Printf("  %s %s %s: needs ~%s, %s free\n", marker, check.Purpose, check.Path, formatBytes(check.RequiredBytes), formatBytes(check.FreeBytes))
+	}
+}
+
+func printVerification(verification *models.VerificationResult) {
+	if verification == nil {
+		return
+	}
+	if verification.Error != "" {
+		fmt.Printf("Verification error: %s\n", verification.Error)
+		return
+	}
+	mismatched := verification.MismatchedTables()
+	if len(mismatched) == 0 {
+		fmt.Printf("Verification: %d tables match remote (%s)\n", len(verification.Tables), formatDuration(verification.Duration))
+		return
+	}
+	fmt.Printf("Verification: %d/%d tables differ from remote\n", len(mismatched), len(verification.Tables))
+	for _, table := range mismatched {
+		fmt.Printf("  %s: %s (rows remote %d, local %d)\n", table.Name, strings.Join(table.Mismatches, ", "), table.RemoteRows, table.LocalRows)
+	}
+}
+
+func printLocalBackups(databaseName string, backups []models.LocalBackup) {
+	if len(backups) == 0 {
+		fmt.Printf("No local backups found for '%s'\n", databaseName)
+		return
+	}
+	fmt.Printf("Local backups of '%s' (%d):\n", databaseName, len(backups))
+	for _, backup := range backups {
+		fmt.Printf("  %s  %10s  %s\n", backup.CreatedAt.Format("2006-01-02 15:04:05"), formatBytes(backup.SizeBytes), backup.Path)
+	}
+}
+
+func printStaleDumps(stale []models.StaleDump) {
+	if len(stale) == 0 {
+		fmt.Printf("No stale dumps found\n")
+		return
+	}
+	var total int64
+	for _, dump := range stale {
+		owner := "no lock file"
+		if dump.PID != 0 {
+			owner = fmt.Sprintf("pid %d exited", dump.PID)
+		}
+		fmt.Printf("  %s  %s  %s (%s)\n", dump.ModifiedAt.Format("2006-01-02 15:04"), formatBytes(dump.SizeBytes), dump.Path, owner)
+		total += dump.SizeBytes
+	}
+	fmt.Printf("%d stale dumps, %s\n", len(stale), formatBytes(total))
+}
+
+func printBucketArchives(databaseName string, bucket string, archives []models.StorageObject) {
+	if len(archives) == 0 {
+		fmt.Printf("No archives of '%s' found in bucket %s\n", databaseName, bucket)
+		return
+	}
+	fmt.Printf("Archives of '%s' in bucket %s (%d):\n", databaseName, bucket, len(archives))
+	for _, archive := range archives {
+		fmt.Printf("  %s  %10s  %s\n", archive.LastModified.Local().Format("2006-01-02 15:04:05"), formatBytes(archive.Size), archive.Key)
+	}
+}
+
+func printSchemaDiff(diff *models.SchemaDiff) {
+	if !diff.LocalExists {
+		fmt.Printf("Local database '%s' does not exist; all %d remote tables are missing locally\n", diff.DatabaseName, len(diff.MissingTables))
+		return
+	}
+	if diff.Empty() {
+		fmt.Printf("Local database '%s' matches remote schema and row counts\n", diff.DatabaseName)
+		return
+	}
+
+	fmt.Printf("Schema diff for '%s' (local compared to remote):\n", diff.DatabaseName)
+	for _, table := range diff.MissingTables {
+		fmt.Printf("  - %s: missing locally (%d rows on remote)\n", table.Name, table.Rows)
+	}
+	for _, table := range diff.ExtraTables {
+		fmt.Printf("  + %s: only local (%d rows)\n", table.Name, table.Rows)
+	}
+	for _, table := range diff.Tables {
+		fmt.Printf("  ~ %s\n", table.Name)
+		if table.EngineChanged {
+			fmt.Printf("      engine: %s -> %s\n", table.RemoteEngine, table.LocalEngine)
+		}
+		for _, column := range table.MissingColumns {
+			fmt.Printf("      - column %s %s\n", column.Name, column.Type)
+		}
+		for _, column := range table.ExtraColumns {
+			fmt.Printf("      + column %s %s\n", column.Name, column.Type)
+		}
+		for _, change := range table.ChangedColumns {
+			fmt.Printf("      ~ column %s: %s -> %s\n", change.Name, describeColumn(change.Remote), describeColumn(change.Local))
+		}
+		for _, index := range table.MissingIndexes {
+			fmt.Printf("      - index %s (%s)\n", index.Name, strings.Join(index.Columns, ", "))
+		}
+		for _, index := range table.ExtraIndexes {
+			fmt.Printf("      + index %s (%s)\n", index.Name, strings.Join(index.Columns, ", "))
+		}
+		for _, change := range table.ChangedIndexes {
+			fmt.Printf("      ~ index %s: (%s) -> (%s)\n", change.Name, strings.Join(change.Remote.Columns, ", "), strings.Join(change.Local.Columns, ", "))
+		}
+		if delta := table.RowDelta(); delta != 0 {
+			approx := ""
+			if table.RowsApproximate {
+				approx = " (approx.)"
+			}
+			fmt.Printf("      rows: remote %d, local %d (%+d)%s\n", table.RemoteRows, table.LocalRows, delta, approx)
+		}
+	}
+}
+
+func describeColumn(column models.ColumnSchema) string {
+	parts := []string{column.Type}
+	if column.Nullable {
+		parts = append(parts, "NULL")
+	} else {
+		parts = append(parts, "NOT NULL")
+	}
+	if column.Default != nil {
+		parts = append(parts, "DEFAULT "+*column.Default)
+	}
+	if column.Extra != "" {
+		parts = append(parts, column.Extra)
+	}
+	return strings.Join(parts, " ")
+}
+
+// printFollowStatus печатает строку состояния follow режима; для CI и логов вместо TUI.
+func printDoctorReport(report *models.DoctorReport) {
+	fmt.Printf("Route: %s\n", formatRoute(report.Source, report.Destination))
+	for _, check := range report.Checks {
+		marker := "✅"
+		switch check.Status {
+		case models.DoctorStatusWarn:
+			marker = "⚠️ "
+		case models.DoctorStatusFail:
+			marker = "❌"
+		}
+		fmt.Printf("%s %-24s %s\n", marker, check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Printf("   → %s\n", check.Hint)
+		}
+	}
+	if failures := report.Failures(); failures > 0 {
+		fmt.Printf("\n%d of %d checks failed\n", failures, len(report.Checks))
+		return
+	}
+	fmt.Printf("\nAll required checks passed\n")
+}
+
+func printFollowStatus(status models.FollowStatus) {
+	switch status.Phase {
+	case models.FollowPhaseInitialSync:
+		fmt.Printf("[%s] initial sync: %s\n", status.Timestamp.Format("15:04:05"), status.Message)
+	case models.FollowPhaseFailed:
+		fmt.Printf("[%s] ❌ %s\n", status.Timestamp.Format("15:04:05"), status.Message)
+	default:
+		fmt.Printf("[%s] %s %s lag=%s transactions=%d rows=%d\n", status.Timestamp.Format("15:04:05"), status.Phase, status.Position, formatDuration(status.Lag.Truncate(time.Second)), status.Transactions, status.RowsApplied)
+	}
+}
+
+// printSchedules печатает расписания с ближайшим запуском и итогом последнего.
+// formatRoute возвращает маршрут цели; пустые имена означают встроенные remote и local.
+func formatRoute(source string, destination string) string {
+	if source == "" {
+		source = config.EndpointRemote
+	}
+	if destination == "" {
+		destination = config.EndpointLocal
+	}
+	return source + " → " + destination
+}
+
+func printEndpoints(endpoints []config.Endpoint) {
+	fmt.Printf("Endpoints (%d):\n", len(endpoints))
+	for _, endpoint := range endpoints {
+		mysqlConfig := endpoint.MySQL()
+		transport := string(models.TransportModeDirect)
+		if mysqlConfig.HasProxy() {
+			transport = fmt.Sprintf("%s via %s", models.TransportModeProxy, mysqlConfig.RedactedProxyURL())
+		}
+		access := "source only"
+		switch {
+		case endpoint.CanWrite() && mysqlConfig.IsLocalhost():
+			access = "destination (localhost)"
+		case endpoint.CanWrite():
+			access = "destination (remote, writable)"
+		}
+		fmt.Printf("  %-16s %-28s %s, %s\n", endpoint.Name, mysqlConfig.Address(), transport, access)
+	}
+}
+
+func printSchedules(statuses []models.ScheduleStatus) {
+	if len(statuses) == 0 {
+		fmt.Println("No schedules. Add one with: dbsync schedule add <name> <database> --cron \"0 7 * * *\"")
+		return
+	}
+	fmt.Printf("Schedules (%d):\n", len(statuses))
+	for _, status := range statuses {
+		databases := make([]string, 0, len(status.Plan.Targets))
+		for _, target := range status.Plan.Targets {
+			name := target.DatabaseName
+			if target.UsesTableSelection() {
+				name += ":" + strings.Join(target.SelectedTables, ",")
+			}
+			databases = append(databases, name)
+		}
+		fmt.Printf("  %s  [%s]  %s\n", status.Name, status.Cron, strings.Join(databases, " "))
+		if !status.NextRun.IsZero() {
+			fmt.Printf("    next run: %s\n", status.NextRun.Format("2006-01-02 15:04 MST"))
+		}
+		if last := status.LastRun; last != nil {
+			line := fmt.Sprintf("    last run: %s %s", last.StartedAt.Format("2006-01-02 15:04"), last.Status)
+			if last.Status != models.ScheduleRunSkipped {
+				line += " in " + formatDuration(last.Duration().Truncate(time.Second))
+			}
+			if last.Error != "" {
+				line += ": " + last.Error
+			}
+			fmt.Println(line)
+		}
+	}
 }
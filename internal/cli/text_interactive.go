@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -171,6 +172,46 @@ func promptForConfirmation(message string) (bool, error) {
 	}
 }
 
+// promptForTypedConfirmation требует ввести expected целиком; любой другой ответ отменяет операцию.
+func promptForTypedConfirmation(message string, expected string) (bool, error) {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("%s\nType '%s' to continue: ", message, expected)
+	raw, err := reader.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(raw) == expected, nil
+}
+
+// confirmProtectedDatabases требует ввести имя каждой защищенной БД, которой нет в preconfirmed (--confirm).
+// --force этот шаг не пропускает: без терминала защищенную БД подтверждает только --confirm.
+func confirmProtectedDatabases(cfg *config.Config, databases []string, preconfirmed []string) ([]string, bool, error) {
+	confirmed := append([]string(nil), preconfirmed...)
+	for _, databaseName := range databases {
+		if !cfg.Safety.IsProtected(databaseName) || slices.Contains(confirmed, databaseName) {
+			continue
+		}
+		fmt.Printf("🛡️  Database '%s' is protected by the safety policy\n", databaseName)
+		ok, err := promptForTypedConfirmation(fmt.Sprintf("This will drop and replace the protected database '%s'", databaseName), databaseName)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return nil, false, nil
+		}
+		confirmed = append(confirmed, databaseName)
+	}
+	return confirmed, true, nil
+}
+
+// confirmProtectedTarget подтверждает защищенную БД команды, которая заменяет одну локальную БД
+// вне плана синхронизации: import, pull, restore-backup и follow. Подтверждения передаются
+// сервису через SetConfirmedDatabases.
+func confirmProtectedTarget(cmd *cobra.Command, cfg *config.Config, databaseName string) ([]string, bool, error) {
+	preconfirmed, _ := cmd.Flags().GetStringSlice("confirm")
+	return confirmProtectedDatabases(cfg, []string{databaseName}, preconfirmed)
+}
+
 func printConnectionStatus(dbService *services.DatabaseService) error {
 	fmt.Println("Checking MySQL server connections...")
 	remoteInfo, remoteErr := dbService.TestConnection(true)
@@ -198,6 +239,9 @@ func printCurrentConfig(cfg *config.Config) {
 	}
 	fmt.Printf("Local MySQL: %s:%d (user: %s)\n", cfg.Local.Host, cfg.Local.Port, cfg.Local.User)
 	fmt.Printf("Dump Timeout: %s\n", cfg.Dump.Timeout)
+	fmt.Printf("Dump Engine: %s\n", cfg.Dump.Engine)
+	fmt.Printf("Stream Copy: %t\n", cfg.Dump.Copy)
+	fmt.Printf("Dump Batch Size: %d\n", cfg.Dump.BatchSize)
 	fmt.Printf("Threads: %d\n", cfg.Dump.Threads)
 	fmt.Printf("Compress: %v\n", cfg.Dump.Compress)
 	fmt.Printf("Network Compress: %v\n", cfg.Dump.NetworkCompress)
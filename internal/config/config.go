@@ -2,9 +2,13 @@ package config
 
 import (
 	"fmt"
+	"math"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -28,6 +32,51 @@ type Config struct {
 
 	// Настройки логирования
 	Log LogConfig `mapstructure:"log"`
+
+	// Настройки уведомлений
+	Notify NotifyConfig `mapstructure:"notify"`
+
+	// Настройки hooks
+	Hooks HooksConfig `mapstructure:"hooks"`
+
+	// Настройки именованных endpoints
+	Endpoints EndpointsConfig `mapstructure:"endpoints"`
+
+	// Правила безопасности перед перезаписью БД
+	Safety SafetyConfig `mapstructure:"safety"`
+
+	// Проверка свободного места перед дампом и загрузкой
+	Preflight PreflightConfig `mapstructure:"preflight"`
+
+	// Настройки локального MySQL на время загрузки дампа
+	Tuning TuningConfig `mapstructure:"tuning"`
+
+	// Исправления несовместимостей источника и состав объектов дампа
+	Compat CompatConfig `mapstructure:"compat"`
+
+	// Настройки локальных бэкапов
+	Backup BackupConfig `mapstructure:"backup"`
+
+	// Настройки проверки после синхронизации
+	Verify VerifyConfig `mapstructure:"verify"`
+
+	// Настройки инкрементальной синхронизации
+	Incremental IncrementalConfig `mapstructure:"incremental"`
+
+	// Настройки пропуска неизменившихся таблиц
+	Smart SmartConfig `mapstructure:"smart"`
+
+	// Настройки follow режима по binlog
+	Follow FollowConfig `mapstructure:"follow"`
+
+	// Настройки расписаний daemon
+	Schedule ScheduleConfig `mapstructure:"schedule"`
+
+	// Настройки локального HTTP API
+	Serve ServeConfig `mapstructure:"serve"`
+
+	// Настройки S3-совместимого хранилища архивов
+	Storage StorageConfig `mapstructure:"storage"`
 }
 
 // MySQLConfig содержит настройки подключения к MySQL
@@ -46,10 +95,37 @@ type DumpConfig struct {
 	Compress         bool          `mapstructure:"compress"`
 	NetworkCompress  bool          `mapstructure:"network_compress"`
 	NetworkZstdLevel int           `mapstructure:"network_zstd_level"`
+	Engine           string        `mapstructure:"engine"`
+	Copy             bool          `mapstructure:"copy"`
+	Consistent       bool          `mapstructure:"consistent"`
+	BatchSize        int           `mapstructure:"batch_size"`
+	WorkDir          string        `mapstructure:"work_dir"`
 }
 
 const defaultDumpNetworkZstdLevel = 7
 
+// Движки дампа: auto выбирает mysqlsh, а без него — mydumper/myloader, mysqldump/mysql
+// или встроенный native движок, которому не нужны внешние утилиты.
+const (
+	DumpEngineAuto       = "auto"
+	DumpEngineMySQLShell = "mysqlsh"
+	DumpEngineMydumper   = "mydumper"
+	DumpEngineMysqldump  = "mysqldump"
+	DumpEngineNative     = "native"
+)
+
+// ResolvedWorkDir возвращает директорию для дампов, распакованных архивов и скачанных файлов;
+// по умолчанию это системная временная директория.
+func (d DumpConfig) ResolvedWorkDir() string {
+	if dir := strings.TrimSpace(d.WorkDir); dir != "" {
+		return expandHomePath(dir)
+	}
+	return os.TempDir()
+}
+
+// DumpEngines перечисляет допустимые значения dump.engine.
+var DumpEngines = []string{DumpEngineAuto, DumpEngineMySQLShell, DumpEngineMydumper, DumpEngineMysqldump, DumpEngineNative}
+
 // CLIConfig содержит настройки CLI интерфейса
 type CLIConfig struct {
 	DefaultCharset     string `mapstructure:"default_charset"`
@@ -63,6 +139,329 @@ type LogConfig struct {
 	Format string `mapstructure:"format"`
 }
 
+// NotifyConfig содержит настройки webhook-уведомлений о завершении плана.
+// Webhooks — список URL через запятую; тип payload задается префиксом
+// slack+, telegram+ или json+, иначе определяется по хосту.
+type NotifyConfig struct {
+	Webhooks string        `mapstructure:"webhooks"`
+	Retries  int           `mapstructure:"retries"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+const defaultNotifyTimeout = 10 * time.Second
+
+// HooksConfig содержит путь к JSON-файлу с pre/post-sync hooks по базам данных.
+type HooksConfig struct {
+	File string `mapstructure:"file"`
+}
+
+// ResolvedFile возвращает путь к файлу hooks с учетом значения по умолчанию.
+func (h HooksConfig) ResolvedFile() string {
+	if file := strings.TrimSpace(h.File); file != "" {
+		return expandHomePath(file)
+	}
+	return DefaultHooksPath()
+}
+
+// EndpointsConfig содержит путь к JSON-файлу с именованными MySQL серверами.
+type EndpointsConfig struct {
+	File string `mapstructure:"file"`
+}
+
+// ResolvedFile возвращает путь к файлу endpoints с учетом значения по умолчанию.
+func (e EndpointsConfig) ResolvedFile() string {
+	if file := strings.TrimSpace(e.File); file != "" {
+		return expandHomePath(file)
+	}
+	return DefaultEndpointsPath()
+}
+
+// BackupConfig содержит настройки бэкапа локальной БД перед DROP.
+type BackupConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Keep    int    `mapstructure:"keep"`
+	Dir     string `mapstructure:"dir"`
+}
+
+const defaultBackupKeep = 3
+
+// ResolvedDir возвращает директорию бэкапов с учетом значения по умолчанию.
+func (b BackupConfig) ResolvedDir() string {
+	if dir := strings.TrimSpace(b.Dir); dir != "" {
+		return expandHomePath(dir)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dbsync-backups")
+	}
+	return filepath.Join(homeDir, ".dbsync", "backups")
+}
+
+// PreflightConfig содержит настройки проверки свободного места перед синхронизацией.
+// Disk — block (остановить цель), warn (только предупредить) или off. HeadroomPercent — запас
+// сверх оценки, при нехватке которого выводится предупреждение. В Dir хранятся коэффициенты
+// сжатия прошлых дампов, по которым оценивается размер следующего.
+type PreflightConfig struct {
+	Disk            string `mapstructure:"disk"`
+	HeadroomPercent int    `mapstructure:"headroom_percent"`
+	Dir             string `mapstructure:"dir"`
+}
+
+// Режимы preflight.disk.
+const (
+	PreflightDiskBlock = "block"
+	PreflightDiskWarn  = "warn"
+	PreflightDiskOff   = "off"
+)
+
+const defaultPreflightHeadroomPercent = 10
+
+// ResolvedDir возвращает директорию истории дампов с учетом значения по умолчанию.
+func (p PreflightConfig) ResolvedDir() string {
+	if dir := strings.TrimSpace(p.Dir); dir != "" {
+		return expandHomePath(dir)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dbsync-dump-history")
+	}
+	return filepath.Join(homeDir, ".dbsync", "dump-history")
+}
+
+// TuningConfig содержит настройки сессии тюнинга локального MySQL на время загрузки дампа.
+// Mode safe меняет только то, без чего загрузка не работает (local_infile), fast дополнительно
+// отключает redo log (MySQL 8.0.21+), ослабляет innodb_flush_log_at_trx_commit и увеличивает
+// буферы InnoDB до BufferSizeMB. Исходные значения возвращаются после загрузки.
+type TuningConfig struct {
+	Mode         string `mapstructure:"mode"`
+	BufferSizeMB int    `mapstructure:"buffer_size_mb"`
+}
+
+// Режимы tuning.mode.
+const (
+	TuningModeSafe = "safe"
+	TuningModeFast = "fast"
+)
+
+const defaultTuningBufferSizeMB = 256
+
+// CompatConfig содержит исправления несовместимостей объектов источника с локальным сервером.
+// Definers: keep оставляет DEFINER как есть, strip убирает его (mysqlsh strip_definers, объекты
+// переходят на SQL SECURITY INVOKER), rewrite заменяет на DefinerAccount (user@host или CURRENT_USER).
+// Остальные флаги — опции compatibility mysqlsh; Skip* исключают объекты схемы из дампа.
+type CompatConfig struct {
+	Definers              string `mapstructure:"definers"`
+	DefinerAccount        string `mapstructure:"definer_account"`
+	StripRestrictedGrants bool   `mapstructure:"strip_restricted_grants"`
+	SkipInvalidAccounts   bool   `mapstructure:"skip_invalid_accounts"`
+	ForceInnoDB           bool   `mapstructure:"force_innodb"`
+	SkipRoutines          bool   `mapstructure:"skip_routines"`
+	SkipEvents            bool   `mapstructure:"skip_events"`
+	SkipTriggers          bool   `mapstructure:"skip_triggers"`
+}
+
+// Режимы compat.definers.
+const (
+	DefinersKeep    = "keep"
+	DefinersStrip   = "strip"
+	DefinersRewrite = "rewrite"
+)
+
+// DefinerCurrentUser — DEFINER=CURRENT_USER: объект принадлежит пользователю, который его загрузил.
+const DefinerCurrentUser = "CURRENT_USER"
+
+// MySQLShellOptions возвращает значения опции compatibility mysqlsh, включенные в конфигурации.
+func (c CompatConfig) MySQLShellOptions() []string {
+	var options []string
+	if c.Definers == DefinersStrip {
+		options = append(options, "strip_definers")
+	}
+	if c.StripRestrictedGrants {
+		options = append(options, "strip_restricted_grants")
+	}
+	if c.SkipInvalidAccounts {
+		options = append(options, "skip_invalid_accounts")
+	}
+	if c.ForceInnoDB {
+		options = append(options, "force_innodb")
+	}
+	return options
+}
+
+// DefinerClause возвращает значение DEFINER для режима rewrite: CURRENT_USER или `user`@`host`.
+func (c CompatConfig) DefinerClause() string {
+	account := strings.TrimSpace(c.DefinerAccount)
+	if account == "" || strings.EqualFold(strings.TrimSuffix(account, "()"), DefinerCurrentUser) {
+		return DefinerCurrentUser
+	}
+	user, host, _ := strings.Cut(account, "@")
+	quote := func(part string) string {
+		part = strings.Trim(strings.TrimSpace(part), "`'\"")
+		return "`" + strings.ReplaceAll(part, "`", "``") + "`"
+	}
+	return quote(user) + "@" + quote(host)
+}
+
+// VerifyConfig содержит настройки сверки remote и local после восстановления.
+// ChecksumMaxMB ограничивает размер таблиц, для которых выполняется CHECKSUM TABLE.
+type VerifyConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	Checksum       bool `mapstructure:"checksum"`
+	ChecksumMaxMB  int  `mapstructure:"checksum_max_mb"`
+	FailOnMismatch bool `mapstructure:"fail_on_mismatch"`
+}
+
+const defaultVerifyChecksumMaxMB = 256
+
+// ChecksumMaxBytes возвращает лимит размера таблицы для CHECKSUM TABLE в байтах.
+func (v VerifyConfig) ChecksumMaxBytes() int64 {
+	return int64(v.ChecksumMaxMB) * 1024 * 1024
+}
+
+// IncrementalConfig содержит настройки инкрементальной синхронизации таблиц.
+// Column — имя timestamp-колонки, по которой догружаются измененные строки;
+// таблицы без нее используют монотонный AUTO_INCREMENT первичный ключ.
+type IncrementalConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Column  string `mapstructure:"column"`
+	Dir     string `mapstructure:"dir"`
+}
+
+const defaultIncrementalColumn = "updated_at"
+
+// ResolvedDir возвращает директорию high-water marks с учетом значения по умолчанию.
+func (i IncrementalConfig) ResolvedDir() string {
+	if dir := strings.TrimSpace(i.Dir); dir != "" {
+		return expandHomePath(dir)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dbsync-watermarks")
+	}
+	return filepath.Join(homeDir, ".dbsync", "watermarks")
+}
+
+// SmartConfig содержит настройки smart sync: таблицы, не изменившиеся на remote с прошлой
+// синхронизации, остаются локально как есть. Checksum добавляет сверку через CHECKSUM TABLE.
+type SmartConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Checksum bool   `mapstructure:"checksum"`
+	Dir      string `mapstructure:"dir"`
+}
+
+// ResolvedDir возвращает директорию снимков таблиц с учетом значения по умолчанию.
+func (s SmartConfig) ResolvedDir() string {
+	if dir := strings.TrimSpace(s.Dir); dir != "" {
+		return expandHomePath(dir)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dbsync-snapshots")
+	}
+	return filepath.Join(homeDir, ".dbsync", "snapshots")
+}
+
+// FollowConfig содержит настройки follow режима: dbsync подключается к remote как реплика
+// с ServerID (0 — вычислить автоматически) и хранит позицию binlog в Dir.
+type FollowConfig struct {
+	ServerID int    `mapstructure:"server_id"`
+	Dir      string `mapstructure:"dir"`
+}
+
+// ResolvedDir возвращает директорию позиций binlog с учетом значения по умолчанию.
+func (f FollowConfig) ResolvedDir() string {
+	if dir := strings.TrimSpace(f.Dir); dir != "" {
+		return expandHomePath(dir)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dbsync-follow")
+	}
+	return filepath.Join(homeDir, ".dbsync", "follow")
+}
+
+// ScheduleConfig содержит настройки расписаний: список расписаний и история запусков daemon хранятся в Dir.
+type ScheduleConfig struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// ResolvedDir возвращает директорию расписаний с учетом значения по умолчанию.
+func (s ScheduleConfig) ResolvedDir() string {
+	if dir := strings.TrimSpace(s.Dir); dir != "" {
+		return expandHomePath(dir)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "dbsync-schedules")
+	}
+	return filepath.Join(homeDir, ".dbsync", "schedules")
+}
+
+// ServeConfig содержит настройки локального HTTP API: адрес должен быть loopback, а пустой Token
+// означает, что токен генерируется при каждом запуске.
+type ServeConfig struct {
+	Addr  string `mapstructure:"addr"`
+	Token string `mapstructure:"token"`
+}
+
+const defaultServeAddr = "127.0.0.1:7717"
+
+// StorageConfig описывает S3-совместимое хранилище архивов dbsync export. Пустой Endpoint означает AWS S3
+// в Region; PathStyle адресует бакет в пути URL, как требуют MinIO и большинство self-hosted хранилищ.
+type StorageConfig struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	Prefix    string `mapstructure:"prefix"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	PathStyle bool   `mapstructure:"path_style"`
+}
+
+const defaultStorageRegion = "us-east-1"
+
+// Enabled сообщает, настроен ли бакет для архивов.
+func (s StorageConfig) Enabled() bool {
+	return strings.TrimSpace(s.Bucket) != ""
+}
+
+// ResolvedEndpoint возвращает адрес S3 API с учетом значения по умолчанию.
+func (s StorageConfig) ResolvedEndpoint() string {
+	if endpoint := strings.TrimSpace(s.Endpoint); endpoint != "" {
+		return strings.TrimRight(endpoint, "/")
+	}
+	return fmt.Sprintf("https://s3.%s.amazonaws.com", s.Region)
+}
+
+// DefaultHooksPath возвращает путь к файлу hooks по умолчанию.
+func DefaultHooksPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".dbsync.hooks.json"
+	}
+	return filepath.Join(homeDir, ".dbsync.hooks.json")
+}
+
+// DefaultEndpointsPath возвращает путь к файлу endpoints по умолчанию.
+func DefaultEndpointsPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ".dbsync.endpoints.json"
+	}
+	return filepath.Join(homeDir, ".dbsync.endpoints.json")
+}
+
+func expandHomePath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+}
+
 // Load загружает конфигурацию из переменных окружения и файлов
 func Load() (*Config, error) {
 	// Пытаемся загрузить .env файл из нескольких возможных местоположений
@@ -93,6 +492,11 @@ func Load() (*Config, error) {
 	v.BindEnv("dump.timeout", "DBSYNC_DUMP_TIMEOUT")
 	v.BindEnv("dump.threads", "DBSYNC_DUMP_THREADS")
 	v.BindEnv("dump.compress", "DBSYNC_DUMP_COMPRESS")
+	v.BindEnv("dump.engine", "DBSYNC_DUMP_ENGINE")
+	v.BindEnv("dump.copy", "DBSYNC_DUMP_COPY")
+	v.BindEnv("dump.consistent", "DBSYNC_DUMP_CONSISTENT")
+	v.BindEnv("dump.batch_size", "DBSYNC_DUMP_BATCH_SIZE")
+	v.BindEnv("dump.work_dir", "DBSYNC_DUMP_WORK_DIR")
 	v.BindEnv("dump.network_compress", "DBSYNC_DUMP_NETWORK_COMPRESS")
 	v.BindEnv("dump.network_zstd_level", "DBSYNC_DUMP_NETWORK_ZSTD_LEVEL")
 
@@ -102,6 +506,54 @@ func Load() (*Config, error) {
 
 	v.BindEnv("log.level", "DBSYNC_LOG_LEVEL")
 	v.BindEnv("log.format", "DBSYNC_LOG_FORMAT")
+
+	v.BindEnv("notify.webhooks", "DBSYNC_NOTIFY_WEBHOOKS")
+	v.BindEnv("notify.retries", "DBSYNC_NOTIFY_RETRIES")
+	v.BindEnv("notify.timeout", "DBSYNC_NOTIFY_TIMEOUT")
+
+	v.BindEnv("hooks.file", "DBSYNC_HOOKS_FILE")
+	v.BindEnv("endpoints.file", "DBSYNC_ENDPOINTS_FILE")
+	v.BindEnv("safety.protected_databases", "DBSYNC_SAFETY_PROTECTED_DATABASES")
+	v.BindEnv("safety.allowed_hosts", "DBSYNC_SAFETY_ALLOWED_HOSTS")
+
+	v.BindEnv("backup.enabled", "DBSYNC_BACKUP_ENABLED")
+	v.BindEnv("backup.keep", "DBSYNC_BACKUP_KEEP")
+	v.BindEnv("backup.dir", "DBSYNC_BACKUP_DIR")
+	v.BindEnv("preflight.disk", "DBSYNC_PREFLIGHT_DISK")
+	v.BindEnv("preflight.headroom_percent", "DBSYNC_PREFLIGHT_HEADROOM_PERCENT")
+	v.BindEnv("preflight.dir", "DBSYNC_PREFLIGHT_DIR")
+	v.BindEnv("tuning.mode", "DBSYNC_TUNING_MODE")
+	v.BindEnv("tuning.buffer_size_mb", "DBSYNC_TUNING_BUFFER_SIZE_MB")
+	v.BindEnv("compat.definers", "DBSYNC_COMPAT_DEFINERS")
+	v.BindEnv("compat.definer_account", "DBSYNC_COMPAT_DEFINER_ACCOUNT")
+	v.BindEnv("compat.strip_restricted_grants", "DBSYNC_COMPAT_STRIP_RESTRICTED_GRANTS")
+	v.BindEnv("compat.skip_invalid_accounts", "DBSYNC_COMPAT_SKIP_INVALID_ACCOUNTS")
+	v.BindEnv("compat.force_innodb", "DBSYNC_COMPAT_FORCE_INNODB")
+	v.BindEnv("compat.skip_routines", "DBSYNC_COMPAT_SKIP_ROUTINES")
+	v.BindEnv("compat.skip_events", "DBSYNC_COMPAT_SKIP_EVENTS")
+	v.BindEnv("compat.skip_triggers", "DBSYNC_COMPAT_SKIP_TRIGGERS")
+	v.BindEnv("verify.enabled", "DBSYNC_VERIFY_ENABLED")
+	v.BindEnv("verify.checksum", "DBSYNC_VERIFY_CHECKSUM")
+	v.BindEnv("verify.checksum_max_mb", "DBSYNC_VERIFY_CHECKSUM_MAX_MB")
+	v.BindEnv("verify.fail_on_mismatch", "DBSYNC_VERIFY_FAIL_ON_MISMATCH")
+	v.BindEnv("incremental.enabled", "DBSYNC_INCREMENTAL_ENABLED")
+	v.BindEnv("incremental.column", "DBSYNC_INCREMENTAL_COLUMN")
+	v.BindEnv("incremental.dir", "DBSYNC_INCREMENTAL_DIR")
+	v.BindEnv("smart.enabled", "DBSYNC_SMART_ENABLED")
+	v.BindEnv("smart.checksum", "DBSYNC_SMART_CHECKSUM")
+	v.BindEnv("smart.dir", "DBSYNC_SMART_DIR")
+	v.BindEnv("follow.server_id", "DBSYNC_FOLLOW_SERVER_ID")
+	v.BindEnv("follow.dir", "DBSYNC_FOLLOW_DIR")
+	v.BindEnv("schedule.dir", "DBSYNC_SCHEDULE_DIR")
+	v.BindEnv("serve.addr", "DBSYNC_SERVE_ADDR")
+	v.BindEnv("serve.token", "DBSYNC_SERVE_TOKEN")
+	v.BindEnv("storage.endpoint", "DBSYNC_STORAGE_ENDPOINT")
+	v.BindEnv("storage.region", "DBSYNC_STORAGE_REGION")
+	v.BindEnv("storage.bucket", "DBSYNC_STORAGE_BUCKET")
+	v.BindEnv("storage.prefix", "DBSYNC_STORAGE_PREFIX")
+	v.BindEnv("storage.access_key", "DBSYNC_STORAGE_ACCESS_KEY")
+	v.BindEnv("storage.secret_key", "DBSYNC_STORAGE_SECRET_KEY")
+	v.BindEnv("storage.path_style", "DBSYNC_STORAGE_PATH_STYLE")
 	// Попытка загрузить конфигурацию из файла
 	v.SetConfigName(".env")
 	v.SetConfigType("dotenv")
@@ -149,6 +601,11 @@ func LoadForTest() (*Config, error) {
 	v.BindEnv("dump.timeout", "DBSYNC_DUMP_TIMEOUT")
 	v.BindEnv("dump.threads", "DBSYNC_DUMP_THREADS")
 	v.BindEnv("dump.compress", "DBSYNC_DUMP_COMPRESS")
+	v.BindEnv("dump.engine", "DBSYNC_DUMP_ENGINE")
+	v.BindEnv("dump.copy", "DBSYNC_DUMP_COPY")
+	v.BindEnv("dump.consistent", "DBSYNC_DUMP_CONSISTENT")
+	v.BindEnv("dump.batch_size", "DBSYNC_DUMP_BATCH_SIZE")
+	v.BindEnv("dump.work_dir", "DBSYNC_DUMP_WORK_DIR")
 	v.BindEnv("dump.network_compress", "DBSYNC_DUMP_NETWORK_COMPRESS")
 	v.BindEnv("dump.network_zstd_level", "DBSYNC_DUMP_NETWORK_ZSTD_LEVEL")
 
@@ -159,6 +616,54 @@ func LoadForTest() (*Config, error) {
 	v.BindEnv("log.level", "DBSYNC_LOG_LEVEL")
 	v.BindEnv("log.format", "DBSYNC_LOG_FORMAT")
 
+	v.BindEnv("notify.webhooks", "DBSYNC_NOTIFY_WEBHOOKS")
+	v.BindEnv("notify.retries", "DBSYNC_NOTIFY_RETRIES")
+	v.BindEnv("notify.timeout", "DBSYNC_NOTIFY_TIMEOUT")
+
+	v.BindEnv("hooks.file", "DBSYNC_HOOKS_FILE")
+	v.BindEnv("endpoints.file", "DBSYNC_ENDPOINTS_FILE")
+	v.BindEnv("safety.protected_databases", "DBSYNC_SAFETY_PROTECTED_DATABASES")
+	v.BindEnv("safety.allowed_hosts", "DBSYNC_SAFETY_ALLOWED_HOSTS")
+
+	v.BindEnv("backup.enabled", "DBSYNC_BACKUP_ENABLED")
+	v.BindEnv("backup.keep", "DBSYNC_BACKUP_KEEP")
+	v.BindEnv("backup.dir", "DBSYNC_BACKUP_DIR")
+	v.BindEnv("preflight.disk", "DBSYNC_PREFLIGHT_DISK")
+	v.BindEnv("preflight.headroom_percent", "DBSYNC_PREFLIGHT_HEADROOM_PERCENT")
+	v.BindEnv("preflight.dir", "DBSYNC_PREFLIGHT_DIR")
+	v.BindEnv("tuning.mode", "DBSYNC_TUNING_MODE")
+	v.BindEnv("tuning.buffer_size_mb", "DBSYNC_TUNING_BUFFER_SIZE_MB")
+	v.BindEnv("compat.definers", "DBSYNC_COMPAT_DEFINERS")
+	v.BindEnv("compat.definer_account", "DBSYNC_COMPAT_DEFINER_ACCOUNT")
+	v.BindEnv("compat.strip_restricted_grants", "DBSYNC_COMPAT_STRIP_RESTRICTED_GRANTS")
+	v.BindEnv("compat.skip_invalid_accounts", "DBSYNC_COMPAT_SKIP_INVALID_ACCOUNTS")
+	v.BindEnv("compat.force_innodb", "DBSYNC_COMPAT_FORCE_INNODB")
+	v.BindEnv("compat.skip_routines", "DBSYNC_COMPAT_SKIP_ROUTINES")
+	v.BindEnv("compat.skip_events", "DBSYNC_COMPAT_SKIP_EVENTS")
+	v.BindEnv("compat.skip_triggers", "DBSYNC_COMPAT_SKIP_TRIGGERS")
+	v.BindEnv("verify.enabled", "DBSYNC_VERIFY_ENABLED")
+	v.BindEnv("verify.checksum", "DBSYNC_VERIFY_CHECKSUM")
+	v.BindEnv("verify.checksum_max_mb", "DBSYNC_VERIFY_CHECKSUM_MAX_MB")
+	v.BindEnv("verify.fail_on_mismatch", "DBSYNC_VERIFY_FAIL_ON_MISMATCH")
+	v.BindEnv("incremental.enabled", "DBSYNC_INCREMENTAL_ENABLED")
+	v.BindEnv("incremental.column", "DBSYNC_INCREMENTAL_COLUMN")
+	v.BindEnv("incremental.dir", "DBSYNC_INCREMENTAL_DIR")
+	v.BindEnv("smart.enabled", "DBSYNC_SMART_ENABLED")
+	v.BindEnv("smart.checksum", "DBSYNC_SMART_CHECKSUM")
+	v.BindEnv("smart.dir", "DBSYNC_SMART_DIR")
+	v.BindEnv("follow.server_id", "DBSYNC_FOLLOW_SERVER_ID")
+	v.BindEnv("follow.dir", "DBSYNC_FOLLOW_DIR")
+	v.BindEnv("schedule.dir", "DBSYNC_SCHEDULE_DIR")
+	v.BindEnv("serve.addr", "DBSYNC_SERVE_ADDR")
+	v.BindEnv("serve.token", "DBSYNC_SERVE_TOKEN")
+	v.BindEnv("storage.endpoint", "DBSYNC_STORAGE_ENDPOINT")
+	v.BindEnv("storage.region", "DBSYNC_STORAGE_REGION")
+	v.BindEnv("storage.bucket", "DBSYNC_STORAGE_BUCKET")
+	v.BindEnv("storage.prefix", "DBSYNC_STORAGE_PREFIX")
+	v.BindEnv("storage.access_key", "DBSYNC_STORAGE_ACCESS_KEY")
+	v.BindEnv("storage.secret_key", "DBSYNC_STORAGE_SECRET_KEY")
+	v.BindEnv("storage.path_style", "DBSYNC_STORAGE_PATH_STYLE")
+
 	// НЕ читаем файлы конфигурации в тестах
 
 	var config Config
@@ -194,6 +699,11 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("dump.timeout", "300s")
 	v.SetDefault("dump.threads", 8)
 	v.SetDefault("dump.compress", true)
+	v.SetDefault("dump.engine", DumpEngineAuto)
+	v.SetDefault("dump.copy", false)
+	v.SetDefault("dump.consistent", false)
+	v.SetDefault("dump.batch_size", 1)
+	v.SetDefault("dump.work_dir", "")
 	v.SetDefault("dump.network_compress", true)
 	v.SetDefault("dump.network_zstd_level", 7)
 
@@ -205,6 +715,73 @@ func setDefaults(v *viper.Viper) {
 	// Настройки логирования
 	v.SetDefault("log.level", "info")
 	v.SetDefault("log.format", "text")
+
+	// Настройки уведомлений
+	v.SetDefault("notify.webhooks", "")
+	v.SetDefault("notify.retries", 3)
+	v.SetDefault("notify.timeout", "10s")
+
+	// Настройки hooks
+	v.SetDefault("hooks.file", "")
+
+	// Именованные endpoints
+	v.SetDefault("endpoints.file", "")
+
+	// Правила безопасности
+	v.SetDefault("safety.protected_databases", "")
+	v.SetDefault("safety.allowed_hosts", "")
+
+	// Проверка свободного места
+	v.SetDefault("preflight.disk", PreflightDiskBlock)
+	v.SetDefault("preflight.headroom_percent", defaultPreflightHeadroomPercent)
+	v.SetDefault("preflight.dir", "")
+
+	// Тюнинг локального MySQL на время загрузки
+	v.SetDefault("tuning.mode", TuningModeSafe)
+	v.SetDefault("tuning.buffer_size_mb", defaultTuningBufferSizeMB)
+	v.SetDefault("compat.definers", DefinersKeep)
+	v.SetDefault("compat.definer_account", DefinerCurrentUser)
+	v.SetDefault("compat.strip_restricted_grants", false)
+	v.SetDefault("compat.skip_invalid_accounts", false)
+	v.SetDefault("compat.force_innodb", false)
+	v.SetDefault("compat.skip_routines", false)
+	v.SetDefault("compat.skip_events", false)
+	v.SetDefault("compat.skip_triggers", false)
+
+	// Настройки локальных бэкапов
+	v.SetDefault("backup.enabled", false)
+	v.SetDefault("backup.keep", defaultBackupKeep)
+	v.SetDefault("backup.dir", "")
+
+	// Проверка после синхронизации
+	v.SetDefault("verify.enabled", false)
+	v.SetDefault("verify.checksum", true)
+	v.SetDefault("verify.checksum_max_mb", defaultVerifyChecksumMaxMB)
+	v.SetDefault("verify.fail_on_mismatch", false)
+
+	// Инкрементальная синхронизация
+	v.SetDefault("incremental.enabled", false)
+	v.SetDefault("incremental.column", defaultIncrementalColumn)
+	v.SetDefault("incremental.dir", "")
+
+	// Пропуск неизменившихся таблиц
+	v.SetDefault("smart.enabled", false)
+	v.SetDefault("smart.checksum", false)
+	v.SetDefault("smart.dir", "")
+	v.SetDefault("follow.server_id", 0)
+	v.SetDefault("follow.dir", "")
+	v.SetDefault("schedule.dir", "")
+	v.SetDefault("serve.addr", defaultServeAddr)
+	v.SetDefault("serve.token", "")
+
+	// S3-совместимое хранилище архивов
+	v.SetDefault("storage.endpoint", "")
+	v.SetDefault("storage.region", defaultStorageRegion)
+	v.SetDefault("storage.bucket", "")
+	v.SetDefault("storage.prefix", "")
+	v.SetDefault("storage.access_key", "")
+	v.SetDefault("storage.secret_key", "")
+	v.SetDefault("storage.path_style", true)
 }
 
 // Validate валидирует конфигурацию
@@ -237,15 +814,192 @@ func validate(config *Config) error {
 		return fmt.Errorf("dump.network_zstd_level must be between 1 and 22")
 	}
 
+	config.Dump.Engine = strings.ToLower(strings.TrimSpace(config.Dump.Engine))
+	if config.Dump.Engine == "" {
+		config.Dump.Engine = DumpEngineAuto
+	}
+	if !slices.Contains(DumpEngines, config.Dump.Engine) {
+		return fmt.Errorf("dump.engine must be one of %s", strings.Join(DumpEngines, ", "))
+	}
+
+	if config.Dump.BatchSize < 0 {
+		return fmt.Errorf("dump.batch_size must not be negative")
+	}
+	if config.Dump.BatchSize == 0 {
+		config.Dump.BatchSize = 1
+	}
+
+	if err := validateNotifyConfig(&config.Notify); err != nil {
+		return err
+	}
+
+	if config.Backup.Keep < 0 {
+		return fmt.Errorf("backup.keep must not be negative")
+	}
+	if config.Backup.Keep == 0 {
+		config.Backup.Keep = defaultBackupKeep
+	}
+
+	if config.Verify.ChecksumMaxMB < 0 {
+		return fmt.Errorf("verify.checksum_max_mb must not be negative")
+	}
+	if config.Verify.ChecksumMaxMB == 0 {
+		config.Verify.ChecksumMaxMB = defaultVerifyChecksumMaxMB
+	}
+
+	config.Incremental.Column = strings.TrimSpace(config.Incremental.Column)
+	if config.Incremental.Column == "" {
+		config.Incremental.Column = defaultIncrementalColumn
+	}
+
+	if config.Follow.ServerID < 0 || int64(config.Follow.ServerID) > math.MaxUint32 {
+		return fmt.Errorf("follow.server_id must be between 0 and %d", uint32(math.MaxUint32))
+	}
+
+	config.Serve.Addr = strings.TrimSpace(config.Serve.Addr)
+	if config.Serve.Addr == "" {
+		config.Serve.Addr = defaultServeAddr
+	}
+	if err := validateLoopbackAddr("serve.addr", config.Serve.Addr); err != nil {
+		return err
+	}
+
+	if err := validateStorageConfig(&config.Storage); err != nil {
+		return err
+	}
+
+	if err := validateSafetyConfig(config.Safety); err != nil {
+		return err
+	}
+
+	config.Preflight.Disk = strings.ToLower(strings.TrimSpace(config.Preflight.Disk))
+	if config.Preflight.Disk == "" {
+		config.Preflight.Disk = PreflightDiskBlock
+	}
+	if !slices.Contains([]string{PreflightDiskBlock, PreflightDiskWarn, PreflightDiskOff}, config.Preflight.Disk) {
+		return fmt.Errorf("preflight.disk must be one of block, warn, off")
+	}
+	if config.Preflight.HeadroomPercent < 0 {
+		return fmt.Errorf("preflight.headroom_percent must not be negative")
+	}
+
+	config.Tuning.Mode = strings.ToLower(strings.TrimSpace(config.Tuning.Mode))
+	if config.Tuning.Mode == "" {
+		config.Tuning.Mode = TuningModeSafe
+	}
+	if !slices.Contains([]string{TuningModeSafe, TuningModeFast}, config.Tuning.Mode) {
+		return fmt.Errorf("tuning.mode must be one of safe, fast")
+	}
+	if config.Tuning.BufferSizeMB <= 0 {
+		config.Tuning.BufferSizeMB = defaultTuningBufferSizeMB
+	}
+
+	config.Compat.Definers = strings.ToLower(strings.TrimSpace(config.Compat.Definers))
+	if config.Compat.Definers == "" {
+		config.Compat.Definers = DefinersKeep
+	}
+	if !slices.Contains([]string{DefinersKeep, DefinersStrip, DefinersRewrite}, config.Compat.Definers) {
+		return fmt.Errorf("compat.definers must be one of keep, strip, rewrite")
+	}
+	config.Compat.DefinerAccount = strings.TrimSpace(config.Compat.DefinerAccount)
+	if config.Compat.DefinerAccount == "" {
+		config.Compat.DefinerAccount = DefinerCurrentUser
+	}
+	if config.Compat.DefinerClause() != DefinerCurrentUser {
+		user, host, found := strings.Cut(config.Compat.DefinerAccount, "@")
+		if !found || strings.Trim(user, "`'\" ") == "" || strings.Trim(host, "`'\" ") == "" {
+			return fmt.Errorf("compat.definer_account must be user@host or CURRENT_USER")
+		}
+	}
+
 	return nil
 }
 
+func validateStorageConfig(storage *StorageConfig) error {
+	storage.Region = strings.TrimSpace(storage.Region)
+	if storage.Region == "" {
+		storage.Region = defaultStorageRegion
+	}
+	storage.Bucket = strings.TrimSpace(storage.Bucket)
+	storage.Prefix = strings.Trim(strings.TrimSpace(storage.Prefix), "/")
+	if endpoint := strings.TrimSpace(storage.Endpoint); endpoint != "" {
+		parsed, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("storage.endpoint is invalid: %w", err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("storage.endpoint must be a full http(s) URL like http://localhost:9000")
+		}
+	}
+	return nil
+}
+
+// validateLoopbackAddr проверяет, что HTTP API слушает только локальный интерфейс.
+func validateLoopbackAddr(name string, addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%s must be host:port: %w", name, err)
+	}
+	if strings.EqualFold(host, "localhost") {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("%s must be a loopback address, got %q", name, host)
+}
+
 func normalizeDumpConfig(dump *DumpConfig) {
 	if dump.NetworkZstdLevel == 0 {
 		dump.NetworkZstdLevel = defaultDumpNetworkZstdLevel
 	}
 }
 
+func validateNotifyConfig(notify *NotifyConfig) error {
+	if notify.Timeout == 0 {
+		notify.Timeout = defaultNotifyTimeout
+	}
+	if notify.Retries < 0 {
+		return fmt.Errorf("notify.retries must not be negative")
+	}
+	for _, raw := range notify.WebhookURLs() {
+		_, target := SplitWebhookKind(raw)
+		parsed, err := url.Parse(target)
+		if err != nil {
+			return fmt.Errorf("notify.webhooks contains invalid URL: %w", err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("notify.webhooks entry %q must be a full http(s) URL", raw)
+		}
+	}
+	return nil
+}
+
+// WebhookURLs возвращает список настроенных webhook URL.
+func (n NotifyConfig) WebhookURLs() []string {
+	var urls []string
+	for _, part := range strings.Split(n.Webhooks, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			urls = append(urls, part)
+		}
+	}
+	return urls
+}
+
+// SplitWebhookKind отделяет явный префикс типа (slack+, telegram+, json+) от URL.
+func SplitWebhookKind(raw string) (string, string) {
+	kind, rest, found := strings.Cut(raw, "+")
+	if !found {
+		return "", raw
+	}
+	switch strings.ToLower(kind) {
+	case "slack", "telegram", "json":
+		return strings.ToLower(kind), rest
+	default:
+		return "", raw
+	}
+}
+
 func validateProxyURL(fieldName string, raw string) error {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -269,6 +1023,25 @@ func validateProxyURL(fieldName string, raw string) error {
 	}
 }
 
+// IsLocalhost сообщает, что сервер находится на этой машине: loopback адрес без proxy.
+// Пустой host, как и у клиентов MySQL, означает localhost.
+func (m MySQLConfig) IsLocalhost() bool {
+	if m.HasProxy() {
+		return false
+	}
+	host := strings.TrimSpace(m.Host)
+	if host == "" || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// Address возвращает host:port сервера.
+func (m MySQLConfig) Address() string {
+	return net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+}
+
 func (m MySQLConfig) HasProxy() bool {
 	return strings.TrimSpace(m.ProxyURL) != ""
 }
@@ -317,7 +1090,8 @@ func (m MySQLConfig) GetMysqldumpArgs(database string) []string {
 	return args
 }
 
-// GetMysqlArgs возвращает аргументы для mysql
+// GetMysqlArgs возвращает аргументы для mysql и утилит с опциями подключения в его стиле;
+// пустое имя базы не добавляется.
 func (m MySQLConfig) GetMysqlArgs(database string) []string {
 	args := []string{
 		fmt.Sprintf("--host=%s", m.Host),
@@ -329,7 +1103,9 @@ func (m MySQLConfig) GetMysqlArgs(database string) []string {
 		args = append(args, fmt.Sprintf("--password=%s", m.Password))
 	}
 
-	args = append(args, database)
+	if database != "" {
+		args = append(args, database)
+	}
 	return args
 }
 
@@ -216,6 +216,110 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid notify webhook",
+			config: &Config{
+				Remote: MySQLConfig{
+					Host: "remote.example.com",
+					Port: 3306,
+				},
+				Local: MySQLConfig{
+					Host: "localhost",
+					Port: 3306,
+				},
+				Notify: NotifyConfig{
+					Webhooks: "https://hooks.slack.com/services/T/B/X, slack+hooks.example.com/path",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative backup keep",
+			config: &Config{
+				Remote: MySQLConfig{
+					Host: "remote.example.com",
+					Port: 3306,
+				},
+				Local: MySQLConfig{
+					Host: "localhost",
+					Port: 3306,
+				},
+				Backup: BackupConfig{
+					Keep: -1,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "non-loopback serve address",
+			config: &Config{
+				Remote: MySQLConfig{
+					Host: "remote.example.com",
+					Port: 3306,
+				},
+				Local: MySQLConfig{
+					Host: "localhost",
+					Port: 3306,
+				},
+				Serve: ServeConfig{
+					Addr: "0.0.0.0:7717",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "storage endpoint without scheme",
+			config: &Config{
+				Remote: MySQLConfig{
+					Host: "remote.example.com",
+					Port: 3306,
+				},
+				Local: MySQLConfig{
+					Host: "localhost",
+					Port: 3306,
+				},
+				Storage: StorageConfig{
+					Endpoint: "minio.local:9000",
+					Bucket:   "dumps",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown tuning mode",
+			config: &Config{
+				Remote: MySQLConfig{
+					Host: "remote.example.com",
+					Port: 3306,
+				},
+				Local: MySQLConfig{
+					Host: "localhost",
+					Port: 3306,
+				},
+				Tuning: TuningConfig{
+					Mode: "turbo",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "definer account without host",
+			config: &Config{
+				Remote: MySQLConfig{
+					Host: "remote.example.com",
+					Port: 3306,
+				},
+				Local: MySQLConfig{
+					Host: "localhost",
+					Port: 3306,
+				},
+				Compat: CompatConfig{
+					Definers:       DefinersRewrite,
+					DefinerAccount: "app",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -279,7 +383,41 @@ func TestConfig_ToEnvString(t *testing.T) {
 	assertContains("DBSYNC_DUMP_THREADS=12")
 	assertContains("DBSYNC_DUMP_NETWORK_COMPRESS=true")
 	assertContains("DBSYNC_DUMP_NETWORK_ZSTD_LEVEL=9")
+	assertContains("DBSYNC_DUMP_ENGINE=auto")
+	assertContains("DBSYNC_DUMP_COPY=false")
+	assertContains("DBSYNC_DUMP_CONSISTENT=false")
+	assertContains("DBSYNC_DUMP_BATCH_SIZE=1")
+	assertContains("DBSYNC_DUMP_WORK_DIR=")
 	assertContains("DBSYNC_LOG_FORMAT=json")
+	assertContains("# Notifications")
+	assertContains("DBSYNC_NOTIFY_TIMEOUT=10s")
+	assertContains("# Verification")
+	assertContains("DBSYNC_VERIFY_CHECKSUM_MAX_MB=256")
+	assertContains("# Incremental Sync")
+	assertContains("DBSYNC_INCREMENTAL_COLUMN=updated_at")
+	assertContains("# Smart Sync")
+	assertContains("DBSYNC_SMART_CHECKSUM=false")
+	assertContains("# Follow")
+	assertContains("DBSYNC_FOLLOW_SERVER_ID=0")
+	assertContains("# Schedules")
+	assertContains("DBSYNC_SCHEDULE_DIR=")
+	assertContains("# HTTP API")
+	assertContains("DBSYNC_SERVE_ADDR=127.0.0.1:7717")
+	assertContains("# Object Storage")
+	assertContains("DBSYNC_STORAGE_REGION=us-east-1")
+	assertContains("DBSYNC_STORAGE_BUCKET=")
+	assertContains("# Endpoints")
+	assertContains("DBSYNC_ENDPOINTS_FILE=")
+	assertContains("# Safety")
+	assertContains("# Disk Preflight")
+	assertContains("DBSYNC_PREFLIGHT_DISK=block")
+	assertContains("# Load Tuning")
+	assertContains("DBSYNC_TUNING_MODE=safe")
+	assertContains("# Compatibility")
+	assertContains("DBSYNC_COMPAT_DEFINERS=keep")
+	assertContains("DBSYNC_COMPAT_DEFINER_ACCOUNT=CURRENT_USER")
+	assertContains("DBSYNC_COMPAT_SKIP_ROUTINES=false")
+	assertContains("DBSYNC_SAFETY_ALLOWED_HOSTS=")
 }
 
 func TestConfig_SaveEnvRoundTrip(t *testing.T) {
@@ -355,6 +493,143 @@ func TestConfig_SaveEnvRoundTrip(t *testing.T) {
 	}
 }
 
+func TestConfig_ResolveEndpoint(t *testing.T) {
+	endpointsPath := filepath.Join(t.TempDir(), "endpoints.json")
+	content := `{"endpoints": [
+		{"name": "prod-replica", "host": "replica.internal", "user": "reader", "proxy_url": "socks5://bastion:1080"},
+		{"name": "staging", "host": "staging.internal", "port": 3307, "user": "dbsync", "writable": true}
+	]}`
+	if err := os.WriteFile(endpointsPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		Remote:    MySQLConfig{Host: "prod.example.com", Port: 3306, User: "reader"},
+		Local:     MySQLConfig{Host: "127.0.0.1", Port: 3306, User: "root"},
+		Endpoints: EndpointsConfig{File: endpointsPath},
+	}
+
+	replica, err := cfg.ResolveEndpoint("prod-replica")
+	if err != nil {
+		t.Fatalf("ResolveEndpoint() error = %v", err)
+	}
+	if replica.Port != 3306 || !replica.MySQL().HasProxy() || replica.CanWrite() {
+		t.Fatalf("unexpected prod-replica endpoint %+v", replica)
+	}
+	staging, err := cfg.ResolveEndpoint("staging")
+	if err != nil || staging.MySQL().Address() != "staging.internal:3307" || !staging.CanWrite() {
+		t.Fatalf("unexpected staging endpoint %+v, err %v", staging, err)
+	}
+	if remote, err := cfg.ResolveEndpoint(EndpointRemote); err != nil || remote.Host != "prod.example.com" || remote.CanWrite() {
+		t.Fatalf("unexpected built-in remote %+v, err %v", remote, err)
+	}
+	if local, err := cfg.ResolveEndpoint(EndpointLocal); err != nil || !local.CanWrite() || !local.MySQL().IsLocalhost() {
+		t.Fatalf("unexpected built-in local %+v, err %v", local, err)
+	}
+	if _, err := cfg.ResolveEndpoint("qa"); err == nil || !strings.Contains(err.Error(), "known: remote, local, prod-replica, staging") {
+		t.Fatalf("expected unknown endpoint error, got %v", err)
+	}
+
+	for name, invalid := range map[string]string{
+		"reserved":  `{"endpoints": [{"name": "local", "host": "db"}]}`,
+		"duplicate": `{"endpoints": [{"name": "qa", "host": "db"}, {"name": "qa", "host": "db2"}]}`,
+		"no host":   `{"endpoints": [{"name": "qa"}]}`,
+		"bad proxy": `{"endpoints": [{"name": "qa", "host": "db", "proxy_url": "bastion:1080"}]}`,
+	} {
+		if err := os.WriteFile(endpointsPath, []byte(invalid), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cfg.ListEndpoints(); err == nil {
+			t.Fatalf("%s: expected invalid endpoints file error", name)
+		}
+	}
+}
+
+func TestMySQLConfig_IsLocalhost(t *testing.T) {
+	tests := []struct {
+		config MySQLConfig
+		want   bool
+	}{
+		{config: MySQLConfig{Host: "localhost"}, want: true},
+		{config: MySQLConfig{Host: "127.0.0.1"}, want: true},
+		{config: MySQLConfig{Host: "::1"}, want: true},
+		{config: MySQLConfig{Host: "127.0.0.1", ProxyURL: "socks5://bastion:1080"}, want: false},
+		{config: MySQLConfig{Host: "staging.internal"}, want: false},
+		{config: MySQLConfig{Host: "10.0.0.5"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := tt.config.IsLocalhost(); got != tt.want {
+			t.Errorf("IsLocalhost(%+v) = %v, want %v", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestSafetyConfig(t *testing.T) {
+	safety := SafetyConfig{ProtectedDatabases: "prod_*, Billing", AllowedHosts: "*.staging.internal,10.20.0.0/16,qa-db"}
+
+	for _, name := range []string{"mysql", "prod_shop", "billing"} {
+		if !safety.IsProtected(name) {
+			t.Errorf("IsProtected(%q) = false, want true", name)
+		}
+	}
+	if safety.IsProtected("shop") {
+		t.Error("IsProtected(shop) = true, want false")
+	}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "", want: true},
+		{host: "127.0.0.1", want: true},
+		{host: "db1.staging.internal", want: true},
+		{host: "10.20.3.4", want: true},
+		{host: "QA-DB", want: true},
+		{host: "10.21.0.1", want: false},
+		{host: "prod.example.com", want: false},
+	}
+	for _, tt := range tests {
+		if got := safety.DestinationAllowed(MySQLConfig{Host: tt.host}); got != tt.want {
+			t.Errorf("DestinationAllowed(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+	if safety.DestinationAllowed(MySQLConfig{Host: "127.0.0.1", ProxyURL: "socks5://bastion:1080"}) {
+		t.Error("loopback behind a proxy must not be allowed implicitly")
+	}
+
+	if err := validateSafetyConfig(SafetyConfig{AllowedHosts: "10.0.0.0/33"}); err == nil {
+		t.Error("expected invalid CIDR error")
+	}
+	if err := validateSafetyConfig(SafetyConfig{ProtectedDatabases: "prod_["}); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
+
+func TestCompatConfig(t *testing.T) {
+	compat := CompatConfig{Definers: DefinersStrip, ForceInnoDB: true, SkipInvalidAccounts: true}
+	if got := strings.Join(compat.MySQLShellOptions(), ","); got != "strip_definers,skip_invalid_accounts,force_innodb" {
+		t.Errorf("MySQLShellOptions() = %q", got)
+	}
+	if options := (CompatConfig{Definers: DefinersRewrite}).MySQLShellOptions(); len(options) != 0 {
+		t.Errorf("rewrite is done by dbsync, not by mysqlsh: %v", options)
+	}
+
+	tests := []struct {
+		account string
+		want    string
+	}{
+		{account: "", want: "CURRENT_USER"},
+		{account: "current_user()", want: "CURRENT_USER"},
+		{account: "app@localhost", want: "`app`@`localhost`"},
+		{account: "'app'@'%'", want: "`app`@`%`"},
+		{account: "we`ird@%", want: "`we``ird`@`%`"},
+	}
+	for _, tt := range tests {
+		if got := (CompatConfig{DefinerAccount: tt.account}).DefinerClause(); got != tt.want {
+			t.Errorf("DefinerClause(%q) = %q, want %q", tt.account, got, tt.want)
+		}
+	}
+}
+
 // Вспомогательные функции
 func clearEnvVars() {
 	envVars := []string{
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// EndpointRemote и EndpointLocal — встроенные endpoints из настроек DBSYNC_REMOTE_* и DBSYNC_LOCAL_*.
+const (
+	EndpointRemote = "remote"
+	EndpointLocal  = "local"
+)
+
+const defaultEndpointPort = 3306
+
+var endpointNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Endpoint — именованный MySQL сервер, который может быть источником или приемником синхронизации.
+type Endpoint struct {
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	User     string `json:"user"`
+	Password string `json:"password,omitempty"`
+	ProxyURL string `json:"proxy_url,omitempty"`
+	// Writable разрешает удалять и перезаписывать БД на сервере, который не является localhost.
+	Writable bool `json:"writable,omitempty"`
+
+	// readOnly запрещает запись во встроенный remote, даже если он доступен через localhost.
+	readOnly bool
+}
+
+type endpointsFile struct {
+	Endpoints []Endpoint `json:"endpoints"`
+}
+
+// MySQL возвращает настройки подключения к endpoint.
+func (e Endpoint) MySQL() MySQLConfig {
+	return MySQLConfig{Host: e.Host, Port: e.Port, User: e.User, Password: e.Password, ProxyURL: e.ProxyURL}
+}
+
+// CanWrite сообщает, можно ли использовать endpoint как приемник синхронизации.
+func (e Endpoint) CanWrite() bool {
+	return !e.readOnly && (e.Writable || e.MySQL().IsLocalhost())
+}
+
+// LoadEndpoints читает JSON-файл endpoints; отсутствие файла означает пустой список.
+func LoadEndpoints(path string) ([]Endpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read endpoints file: %w", err)
+	}
+
+	var file endpointsFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse endpoints file %s: %w", path, err)
+	}
+	if err := validateEndpoints(file.Endpoints); err != nil {
+		return nil, fmt.Errorf("invalid endpoints file %s: %w", path, err)
+	}
+	return file.Endpoints, nil
+}
+
+func validateEndpoints(endpoints []Endpoint) error {
+	seen := make(map[string]bool, len(endpoints))
+	for index := range endpoints {
+		endpoint := &endpoints[index]
+		endpoint.Name = strings.TrimSpace(endpoint.Name)
+		endpoint.Host = strings.TrimSpace(endpoint.Host)
+		endpoint.ProxyURL = strings.TrimSpace(endpoint.ProxyURL)
+		if !endpointNamePattern.MatchString(endpoint.Name) {
+			return fmt.Errorf("endpoint #%d: name %q must contain only letters, digits, '.', '_' and '-'", index+1, endpoint.Name)
+		}
+		if endpoint.Name == EndpointRemote || endpoint.Name == EndpointLocal {
+			return fmt.Errorf("endpoint %q: name is reserved for the built-in endpoint", endpoint.Name)
+		}
+		if seen[endpoint.Name] {
+			return fmt.Errorf("endpoint %q is defined more than once", endpoint.Name)
+		}
+		seen[endpoint.Name] = true
+		if endpoint.Host == "" {
+			return fmt.Errorf("endpoint %q: host is required", endpoint.Name)
+		}
+		if endpoint.Port == 0 {
+			endpoint.Port = defaultEndpointPort
+		}
+		if endpoint.Port < 1 || endpoint.Port > 65535 {
+			return fmt.Errorf("endpoint %q: port must be between 1 and 65535", endpoint.Name)
+		}
+		if err := validateProxyURL(fmt.Sprintf("endpoint %q proxy_url", endpoint.Name), endpoint.ProxyURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListEndpoints возвращает встроенные remote и local, затем endpoints из файла.
+// Встроенный remote доступен только для чтения, local — приемник по умолчанию.
+func (c *Config) ListEndpoints() ([]Endpoint, error) {
+	endpoints := []Endpoint{
+		builtinEndpoint(EndpointRemote, c.Remote, false),
+		builtinEndpoint(EndpointLocal, c.Local, true),
+	}
+	custom, err := LoadEndpoints(c.Endpoints.ResolvedFile())
+	if err != nil {
+		return nil, err
+	}
+	return append(endpoints, custom...), nil
+}
+
+// ResolveEndpoint находит endpoint по имени.
+func (c *Config) ResolveEndpoint(name string) (Endpoint, error) {
+	endpoints, err := c.ListEndpoints()
+	if err != nil {
+		return Endpoint{}, err
+	}
+	names := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if endpoint.Name == name {
+			return endpoint, nil
+		}
+		names = append(names, endpoint.Name)
+	}
+	return Endpoint{}, fmt.Errorf("unknown endpoint %q (known: %s)", name, strings.Join(names, ", "))
+}
+
+func builtinEndpoint(name string, mysqlConfig MySQLConfig, writable bool) Endpoint {
+	return Endpoint{
+		Name:     name,
+		Host:     mysqlConfig.Host,
+		Port:     mysqlConfig.Port,
+		User:     mysqlConfig.User,
+		Password: mysqlConfig.Password,
+		ProxyURL: mysqlConfig.ProxyURL,
+		Writable: writable,
+		readOnly: !writable,
+	}
+}
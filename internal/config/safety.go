@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"strings"
+
+	"db-sync-cli/pkg/utils"
+)
+
+// SafetyConfig содержит правила, которые проверяются перед DROP БД в приемнике.
+// ProtectedDatabases — glob-шаблоны имен через запятую (например prod_*,billing), которые
+// дополняют встроенный список utils.IsDangerous. AllowedHosts — хосты, IP и CIDR приемников
+// через запятую, разрешенные помимо loopback; допускаются шаблоны вида *.staging.internal.
+type SafetyConfig struct {
+	ProtectedDatabases string `mapstructure:"protected_databases"`
+	AllowedHosts       string `mapstructure:"allowed_hosts"`
+}
+
+// IsProtected сообщает, что перезапись БД требует подтверждения вводом ее имени.
+func (s SafetyConfig) IsProtected(databaseName string) bool {
+	if utils.IsDangerous(databaseName) {
+		return true
+	}
+	name := strings.ToLower(databaseName)
+	for _, pattern := range splitList(s.ProtectedDatabases) {
+		if matched, _ := path.Match(strings.ToLower(pattern), name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// DestinationAllowed сообщает, что в сервер можно писать: loopback без proxy или хост из AllowedHosts.
+func (s SafetyConfig) DestinationAllowed(destination MySQLConfig) bool {
+	if destination.IsLocalhost() {
+		return true
+	}
+	host := strings.ToLower(strings.Trim(strings.TrimSpace(destination.Host), "[]"))
+	ip := net.ParseIP(host)
+	for _, entry := range splitList(s.AllowedHosts) {
+		entry = strings.ToLower(entry)
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(entry, host); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func validateSafetyConfig(safety SafetyConfig) error {
+	for _, pattern := range splitList(safety.ProtectedDatabases) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("safety.protected_databases has invalid pattern %q", pattern)
+		}
+	}
+	for _, entry := range splitList(safety.AllowedHosts) {
+		if strings.Contains(entry, "/") {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return fmt.Errorf("safety.allowed_hosts has invalid CIDR %q", entry)
+			}
+			continue
+		}
+		if _, err := path.Match(entry, ""); err != nil {
+			return fmt.Errorf("safety.allowed_hosts has invalid pattern %q", entry)
+		}
+	}
+	return nil
+}
+
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -61,6 +61,11 @@ var envSections = []struct {
 			{Key: "DBSYNC_DUMP_COMPRESS", Value: func(c *Config) string { return strconv.FormatBool(c.Dump.Compress) }},
 			{Key: "DBSYNC_DUMP_NETWORK_COMPRESS", Value: func(c *Config) string { return strconv.FormatBool(c.Dump.NetworkCompress) }},
 			{Key: "DBSYNC_DUMP_NETWORK_ZSTD_LEVEL", Value: func(c *Config) string { return strconv.Itoa(c.Dump.NetworkZstdLevel) }},
+			{Key: "DBSYNC_DUMP_ENGINE", Value: func(c *Config) string { return c.Dump.Engine }},
+			{Key: "DBSYNC_DUMP_COPY", Value: func(c *Config) string { return strconv.FormatBool(c.Dump.Copy) }},
+			{Key: "DBSYNC_DUMP_CONSISTENT", Value: func(c *Config) string { return strconv.FormatBool(c.Dump.Consistent) }},
+			{Key: "DBSYNC_DUMP_BATCH_SIZE", Value: func(c *Config) string { return strconv.Itoa(c.Dump.BatchSize) }},
+			{Key: "DBSYNC_DUMP_WORK_DIR", Value: func(c *Config) string { return c.Dump.WorkDir }},
 		},
 	},
 	{
@@ -84,6 +89,171 @@ var envSections = []struct {
 			{Key: "DBSYNC_LOG_FORMAT", Value: func(c *Config) string { return c.Log.Format }},
 		},
 	},
+	{
+		Title: "Notifications",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_NOTIFY_WEBHOOKS", Value: func(c *Config) string { return c.Notify.Webhooks }},
+			{Key: "DBSYNC_NOTIFY_RETRIES", Value: func(c *Config) string { return strconv.Itoa(c.Notify.Retries) }},
+			{Key: "DBSYNC_NOTIFY_TIMEOUT", Value: func(c *Config) string { return c.Notify.Timeout.String() }},
+		},
+	},
+	{
+		Title: "Hooks",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_HOOKS_FILE", Value: func(c *Config) string { return c.Hooks.File }},
+		},
+	},
+	{
+		Title: "Endpoints",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_ENDPOINTS_FILE", Value: func(c *Config) string { return c.Endpoints.File }},
+		},
+	},
+	{
+		Title: "Safety",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_SAFETY_PROTECTED_DATABASES", Value: func(c *Config) string { return c.Safety.ProtectedDatabases }},
+			{Key: "DBSYNC_SAFETY_ALLOWED_HOSTS", Value: func(c *Config) string { return c.Safety.AllowedHosts }},
+		},
+	},
+	{
+		Title: "Disk Preflight",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_PREFLIGHT_DISK", Value: func(c *Config) string { return c.Preflight.Disk }},
+			{Key: "DBSYNC_PREFLIGHT_HEADROOM_PERCENT", Value: func(c *Config) string { return strconv.Itoa(c.Preflight.HeadroomPercent) }},
+			{Key: "DBSYNC_PREFLIGHT_DIR", Value: func(c *Config) string { return c.Preflight.Dir }},
+		},
+	},
+	{
+		Title: "Load Tuning",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_TUNING_MODE", Value: func(c *Config) string { return c.Tuning.Mode }},
+			{Key: "DBSYNC_TUNING_BUFFER_SIZE_MB", Value: func(c *Config) string { return strconv.Itoa(c.Tuning.BufferSizeMB) }},
+		},
+	},
+	{
+		Title: "Compatibility",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_COMPAT_DEFINERS", Value: func(c *Config) string { return c.Compat.Definers }},
+			{Key: "DBSYNC_COMPAT_DEFINER_ACCOUNT", Value: func(c *Config) string { return c.Compat.DefinerAccount }},
+			{Key: "DBSYNC_COMPAT_STRIP_RESTRICTED_GRANTS", Value: func(c *Config) string { return strconv.FormatBool(c.Compat.StripRestrictedGrants) }},
+			{Key: "DBSYNC_COMPAT_SKIP_INVALID_ACCOUNTS", Value: func(c *Config) string { return strconv.FormatBool(c.Compat.SkipInvalidAccounts) }},
+			{Key: "DBSYNC_COMPAT_FORCE_INNODB", Value: func(c *Config) string { return strconv.FormatBool(c.Compat.ForceInnoDB) }},
+			{Key: "DBSYNC_COMPAT_SKIP_ROUTINES", Value: func(c *Config) string { return strconv.FormatBool(c.Compat.SkipRoutines) }},
+			{Key: "DBSYNC_COMPAT_SKIP_EVENTS", Value: func(c *Config) string { return strconv.FormatBool(c.Compat.SkipEvents) }},
+			{Key: "DBSYNC_COMPAT_SKIP_TRIGGERS", Value: func(c *Config) string { return strconv.FormatBool(c.Compat.SkipTriggers) }},
+		},
+	},
+	{
+		Title: "Local Backups",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_BACKUP_ENABLED", Value: func(c *Config) string { return strconv.FormatBool(c.Backup.Enabled) }},
+			{Key: "DBSYNC_BACKUP_KEEP", Value: func(c *Config) string { return strconv.Itoa(c.Backup.Keep) }},
+			{Key: "DBSYNC_BACKUP_DIR", Value: func(c *Config) string { return c.Backup.Dir }},
+		},
+	},
+	{
+		Title: "Verification",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_VERIFY_ENABLED", Value: func(c *Config) string { return strconv.FormatBool(c.Verify.Enabled) }},
+			{Key: "DBSYNC_VERIFY_CHECKSUM", Value: func(c *Config) string { return strconv.FormatBool(c.Verify.Checksum) }},
+			{Key: "DBSYNC_VERIFY_CHECKSUM_MAX_MB", Value: func(c *Config) string { return strconv.Itoa(c.Verify.ChecksumMaxMB) }},
+			{Key: "DBSYNC_VERIFY_FAIL_ON_MISMATCH", Value: func(c *Config) string { return strconv.FormatBool(c.Verify.FailOnMismatch) }},
+		},
+	},
+	{
+		Title: "Incremental Sync",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_INCREMENTAL_ENABLED", Value: func(c *Config) string { return strconv.FormatBool(c.Incremental.Enabled) }},
+			{Key: "DBSYNC_INCREMENTAL_COLUMN", Value: func(c *Config) string { return c.Incremental.Column }},
+			{Key: "DBSYNC_INCREMENTAL_DIR", Value: func(c *Config) string { return c.Incremental.Dir }},
+		},
+	},
+	{
+		Title: "Smart Sync",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_SMART_ENABLED", Value: func(c *Config) string { return strconv.FormatBool(c.Smart.Enabled) }},
+			{Key: "DBSYNC_SMART_CHECKSUM", Value: func(c *Config) string { return strconv.FormatBool(c.Smart.Checksum) }},
+			{Key: "DBSYNC_SMART_DIR", Value: func(c *Config) string { return c.Smart.Dir }},
+		},
+	},
+	{
+		Title: "Follow",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_FOLLOW_SERVER_ID", Value: func(c *Config) string { return strconv.Itoa(c.Follow.ServerID) }},
+			{Key: "DBSYNC_FOLLOW_DIR", Value: func(c *Config) string { return c.Follow.Dir }},
+		},
+	},
+	{
+		Title: "Schedules",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_SCHEDULE_DIR", Value: func(c *Config) string { return c.Schedule.Dir }},
+		},
+	},
+	{
+		Title: "HTTP API",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_SERVE_ADDR", Value: func(c *Config) string { return c.Serve.Addr }},
+			{Key: "DBSYNC_SERVE_TOKEN", Value: func(c *Config) string { return c.Serve.Token }},
+		},
+	},
+	{
+		Title: "Object Storage",
+		Pairs: []struct {
+			Key   string
+			Value func(*Config) string
+		}{
+			{Key: "DBSYNC_STORAGE_ENDPOINT", Value: func(c *Config) string { return c.Storage.Endpoint }},
+			{Key: "DBSYNC_STORAGE_REGION", Value: func(c *Config) string { return c.Storage.Region }},
+			{Key: "DBSYNC_STORAGE_BUCKET", Value: func(c *Config) string { return c.Storage.Bucket }},
+			{Key: "DBSYNC_STORAGE_PREFIX", Value: func(c *Config) string { return c.Storage.Prefix }},
+			{Key: "DBSYNC_STORAGE_ACCESS_KEY", Value: func(c *Config) string { return c.Storage.AccessKey }},
+			{Key: "DBSYNC_STORAGE_SECRET_KEY", Value: func(c *Config) string { return c.Storage.SecretKey }},
+			{Key: "DBSYNC_STORAGE_PATH_STYLE", Value: func(c *Config) string { return strconv.FormatBool(c.Storage.PathStyle) }},
+		},
+	},
 }
 
 // ToEnvString сериализует конфигурацию в .env-совместимый текст.
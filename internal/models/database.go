@@ -77,6 +77,10 @@ type Table struct {
 	Engine       string `json:"engine,omitempty"`
 	Collation    string `json:"collation,omitempty"`
 	DataFree     int64  `json:"data_free_bytes,omitempty"`
+	// Type — information_schema.TABLES.TABLE_TYPE: BASE TABLE, VIEW или SYSTEM VIEW.
+	Type string `json:"table_type,omitempty"`
+	// UpdateTime — information_schema.TABLES.UPDATE_TIME; нулевое значение означает, что сервер его не знает.
+	UpdateTime time.Time `json:"update_time,omitempty"`
 }
 
 // TableDependency представляет внешнюю зависимость таблицы.
@@ -89,6 +93,78 @@ type TableDependency struct {
 	Reason          string `json:"reason,omitempty"`
 }
 
+// ColumnSchema описывает колонку таблицы из information_schema.COLUMNS.
+type ColumnSchema struct {
+	Name      string  `json:"name"`
+	Position  int     `json:"position"`
+	Type      string  `json:"type"`
+	Nullable  bool    `json:"nullable"`
+	Default   *string `json:"default,omitempty"`
+	Extra     string  `json:"extra,omitempty"`
+	Collation string  `json:"collation,omitempty"`
+}
+
+// IndexSchema описывает индекс таблицы из information_schema.STATISTICS.
+type IndexSchema struct {
+	Name    string   `json:"name"`
+	Unique  bool     `json:"unique"`
+	Type    string   `json:"type,omitempty"`
+	Columns []string `json:"columns"`
+}
+
+// TableSchema описывает структуру таблицы для сравнения remote и local.
+type TableSchema struct {
+	Name       string         `json:"name"`
+	Engine     string         `json:"engine,omitempty"`
+	Collation  string         `json:"collation,omitempty"`
+	Rows       int64          `json:"rows"`
+	RowsApprox bool           `json:"rows_approximate,omitempty"`
+	Columns    []ColumnSchema `json:"columns"`
+	Indexes    []IndexSchema  `json:"indexes,omitempty"`
+}
+
+// ColumnChange описывает колонку, которая отличается на remote и local.
+type ColumnChange struct {
+	Name   string       `json:"name"`
+	Remote ColumnSchema `json:"remote"`
+	Local  ColumnSchema `json:"local"`
+}
+
+// IndexChange описывает индекс, который отличается на remote и local.
+type IndexChange struct {
+	Name   string      `json:"name"`
+	Remote IndexSchema `json:"remote"`
+	Local  IndexSchema `json:"local"`
+}
+
+// TableDiff содержит различия одной таблицы, присутствующей на обеих сторонах.
+type TableDiff struct {
+	Name            string         `json:"name"`
+	MissingColumns  []ColumnSchema `json:"missing_columns,omitempty"`
+	ExtraColumns    []ColumnSchema `json:"extra_columns,omitempty"`
+	ChangedColumns  []ColumnChange `json:"changed_columns,omitempty"`
+	MissingIndexes  []IndexSchema  `json:"missing_indexes,omitempty"`
+	ExtraIndexes    []IndexSchema  `json:"extra_indexes,omitempty"`
+	ChangedIndexes  []IndexChange  `json:"changed_indexes,omitempty"`
+	EngineChanged   bool           `json:"engine_changed,omitempty"`
+	RemoteEngine    string         `json:"remote_engine,omitempty"`
+	LocalEngine     string         `json:"local_engine,omitempty"`
+	RemoteRows      int64          `json:"remote_rows"`
+	LocalRows       int64          `json:"local_rows"`
+	RowsApproximate bool           `json:"rows_approximate,omitempty"`
+}
+
+// SchemaDiff описывает, чем локальная БД отличается от remote.
+// Missing* — есть на remote, но нет локально; Extra* — есть только локально.
+type SchemaDiff struct {
+	DatabaseName  string        `json:"database_name"`
+	LocalExists   bool          `json:"local_exists"`
+	MissingTables []TableSchema `json:"missing_tables,omitempty"`
+	ExtraTables   []TableSchema `json:"extra_tables,omitempty"`
+	Tables        []TableDiff   `json:"tables,omitempty"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+}
+
 // RuntimeOptions содержит runtime-only опции выполнения.
 type RuntimeOptions struct {
 	DryRun     bool   `json:"dry_run"`
@@ -114,6 +190,9 @@ const (
 	SyncPhasePlanning   SyncPhase = "planning"
 	SyncPhaseDump       SyncPhase = "dump"
 	SyncPhaseRestore    SyncPhase = "restore"
+	SyncPhaseHooks      SyncPhase = "hooks"
+	SyncPhaseBackup     SyncPhase = "backup"
+	SyncPhaseVerify     SyncPhase = "verify"
 	SyncPhaseCleanup    SyncPhase = "cleanup"
 	SyncPhaseDone       SyncPhase = "done"
 	SyncPhaseFailed     SyncPhase = "failed"
@@ -125,6 +204,13 @@ type SyncTarget struct {
 	SelectedTables        []string `json:"selected_tables,omitempty"`
 	AutoIncludedTables    []string `json:"auto_included_tables,omitempty"`
 	ReplaceEntireDatabase bool     `json:"replace_entire_database"`
+	ForceFull             bool     `json:"force_full,omitempty"`
+	SkipTables            []string `json:"skip_tables,omitempty"`
+	// Consistent требует согласованный снимок источника; dump.consistent включает его для всех целей.
+	Consistent bool `json:"consistent,omitempty"`
+	// Source и Destination — имена endpoints; пустые значения означают встроенные remote и local.
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
 }
 
 // SyncPlan описывает итоговый план синхронизации.
@@ -135,7 +221,9 @@ type SyncPlan struct {
 	EstimatedTransferBytes int64         `json:"estimated_transfer_bytes,omitempty"`
 	EstimatedDumpBytes     int64         `json:"estimated_dump_bytes,omitempty"`
 	EstimatedDuration      time.Duration `json:"estimated_duration,omitempty"`
-	CreatedAt              time.Time     `json:"created_at"`
+	// ConfirmedDatabases — защищенные БД, перезапись которых подтверждена вводом имени.
+	ConfirmedDatabases []string  `json:"confirmed_databases,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
 }
 
 // TrafficMetrics хранит сетевые метрики выполнения.
@@ -148,20 +236,353 @@ type TrafficMetrics struct {
 	SampleWindow          time.Duration `json:"sample_window,omitempty"`
 }
 
+// TableSyncState описывает состояние отдельной таблицы внутри target.
+type TableSyncState string
+
+const (
+	TableStatePending  TableSyncState = "pending"
+	TableStateDumping  TableSyncState = "dumping"
+	TableStateDumped   TableSyncState = "dumped"
+	TableStateLoading  TableSyncState = "loading"
+	TableStateIndexing TableSyncState = "indexing"
+	TableStateDone     TableSyncState = "done"
+)
+
+// TableProgress хранит прогресс синхронизации одной таблицы.
+type TableProgress struct {
+	Name         string         `json:"name"`
+	State        TableSyncState `json:"state"`
+	SourceBytes  int64          `json:"source_bytes,omitempty"`
+	DumpBytes    int64          `json:"dump_bytes,omitempty"`
+	LoadedBytes  int64          `json:"loaded_bytes,omitempty"`
+	Rows         int64          `json:"rows,omitempty"`
+	RowsTotal    int64          `json:"rows_total,omitempty"`
+	RowsApprox   bool           `json:"rows_approximate,omitempty"`
+	View         bool           `json:"view,omitempty"`
+	DumpDuration time.Duration  `json:"dump_duration,omitempty"`
+	LoadDuration time.Duration  `json:"load_duration,omitempty"`
+}
+
+// HookPoint описывает момент запуска пользовательского hook.
+type HookPoint string
+
+const (
+	HookBeforeDump   HookPoint = "before_dump"
+	HookAfterDump    HookPoint = "after_dump"
+	HookAfterRestore HookPoint = "after_restore"
+	HookOnFailure    HookPoint = "on_failure"
+)
+
+// HookPoints возвращает все точки запуска hooks в порядке выполнения.
+func HookPoints() []HookPoint {
+	return []HookPoint{HookBeforeDump, HookAfterDump, HookAfterRestore, HookOnFailure}
+}
+
+// HookResult хранит результат выполнения одного hook.
+type HookResult struct {
+	Point    HookPoint     `json:"point"`
+	Name     string        `json:"name"`
+	Kind     string        `json:"kind"`
+	Success  bool          `json:"success"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// LocalBackup описывает резервную копию локальной БД, снятую перед перезаписью.
+type LocalBackup struct {
+	DatabaseName string    `json:"database_name"`
+	Path         string    `json:"path"`
+	SizeBytes    int64     `json:"size_bytes,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// StaleDump описывает дамп, распакованный архив или скачанный файл, брошенный завершившимся
+// запуском dbsync. PID равен 0 у записей без lock-файла, оставленных версиями до lock-файлов.
+type StaleDump struct {
+	Path       string    `json:"path"`
+	SizeBytes  int64     `json:"size_bytes"`
+	PID        int       `json:"pid,omitempty"`
+	ModifiedAt time.Time `json:"modified_at"`
+}
+
+// ArchiveManifest описывает переносимый архив дампа, созданный dbsync export.
+type ArchiveManifest struct {
+	FormatVersion int    `json:"format_version"`
+	DatabaseName  string `json:"database_name"`
+	SourceHost    string `json:"source_host"`
+	SourcePort    int    `json:"source_port"`
+	ServerVersion string `json:"server_version,omitempty"`
+	DumpEngine    string `json:"dump_engine"`
+	// Tables содержит статистику information_schema на момент экспорта; для InnoDB строки приблизительные.
+	Tables        []ArchiveTable `json:"tables"`
+	Files         []ArchiveFile  `json:"files"`
+	CreatedAt     time.Time      `json:"created_at"`
+	DbsyncVersion string         `json:"dbsync_version"`
+}
+
+// ArchiveTable хранит статистику таблицы источника в архиве.
+type ArchiveTable struct {
+	Name       string `json:"name"`
+	Rows       int64  `json:"rows"`
+	RowsApprox bool   `json:"rows_approximate,omitempty"`
+	DataSize   int64  `json:"data_size_bytes,omitempty"`
+}
+
+// ArchiveFile хранит размер и SHA-256 файла дампа для проверки при импорте.
+type ArchiveFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// TotalRows суммирует строки таблиц архива.
+func (m ArchiveManifest) TotalRows() int64 {
+	var total int64
+	for _, table := range m.Tables {
+		total += table.Rows
+	}
+	return total
+}
+
+// DumpSize суммирует размер файлов дампа в архиве.
+func (m ArchiveManifest) DumpSize() int64 {
+	var total int64
+	for _, file := range m.Files {
+		total += file.Size
+	}
+	return total
+}
+
+// StorageObject описывает архив в S3-совместимом хранилище.
+type StorageObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// TableFingerprint хранит данные таблицы для сверки remote и local после синхронизации.
+type TableFingerprint struct {
+	Rows     int64  `json:"rows"`
+	Checksum string `json:"checksum,omitempty"`
+	DDL      string `json:"ddl,omitempty"`
+}
+
+// TableVerification хранит результат сверки одной таблицы.
+type TableVerification struct {
+	Name            string   `json:"name"`
+	RemoteRows      int64    `json:"remote_rows"`
+	LocalRows       int64    `json:"local_rows"`
+	RemoteChecksum  string   `json:"remote_checksum,omitempty"`
+	LocalChecksum   string   `json:"local_checksum,omitempty"`
+	ChecksumSkipped bool     `json:"checksum_skipped,omitempty"`
+	Mismatches      []string `json:"mismatches,omitempty"`
+}
+
+// VerificationResult хранит итог проверки локальной копии после восстановления.
+type VerificationResult struct {
+	Tables   []TableVerification `json:"tables"`
+	Error    string              `json:"error,omitempty"`
+	Duration time.Duration       `json:"duration"`
+}
+
+// WatermarkKind описывает колонку, по которой отслеживается high-water mark таблицы.
+type WatermarkKind string
+
+const (
+	WatermarkPrimaryKey WatermarkKind = "primary_key"
+	WatermarkTimestamp  WatermarkKind = "timestamp"
+)
+
+// IncrementalColumn описывает колонку, пригодную для инкрементальной синхронизации таблицы.
+type IncrementalColumn struct {
+	Column string        `json:"column"`
+	Kind   WatermarkKind `json:"kind"`
+}
+
+// TableWatermark хранит high-water mark таблицы после последней успешной синхронизации.
+type TableWatermark struct {
+	Column   string        `json:"column"`
+	Kind     WatermarkKind `json:"kind"`
+	Value    string        `json:"value"`
+	SyncedAt time.Time     `json:"synced_at"`
+}
+
+// IncrementalTable описывает таблицу, которая догружается строками после high-water mark.
+type IncrementalTable struct {
+	Name   string        `json:"name"`
+	Column string        `json:"column"`
+	Kind   WatermarkKind `json:"kind"`
+	Since  string        `json:"since"`
+}
+
+// IncrementalPlan описывает, какие таблицы цели пойдут инкрементально, а какие полностью.
+// Reason объясняет, почему вся цель синхронизируется полностью.
+type IncrementalPlan struct {
+	DatabaseName string             `json:"database_name"`
+	Incremental  []IncrementalTable `json:"incremental,omitempty"`
+	Full         []string           `json:"full,omitempty"`
+	Reason       string             `json:"reason,omitempty"`
+}
+
+// TableSnapshot фиксирует состояние remote таблицы на момент успешной синхронизации.
+type TableSnapshot struct {
+	Rows       int64     `json:"rows"`
+	DataLength int64     `json:"data_length"`
+	UpdateTime time.Time `json:"update_time,omitempty"`
+	Checksum   string    `json:"checksum,omitempty"`
+	SyncedAt   time.Time `json:"synced_at"`
+}
+
+// TableSyncAction описывает решение smart sync по таблице.
+type TableSyncAction string
+
+const (
+	TableActionSkip    TableSyncAction = "skip"
+	TableActionRefresh TableSyncAction = "refresh"
+)
+
+// TableDecision хранит решение smart sync по одной таблице и его причину.
+type TableDecision struct {
+	Name   string          `json:"name"`
+	Action TableSyncAction `json:"action"`
+	Reason string          `json:"reason"`
+	Bytes  int64           `json:"bytes,omitempty"`
+}
+
+// SmartSyncPlan описывает, какие таблицы цели будут пропущены как неизменившиеся.
+// Reason объясняет, почему smart sync не применяется ко всей цели.
+type SmartSyncPlan struct {
+	DatabaseName string          `json:"database_name"`
+	Tables       []TableDecision `json:"tables,omitempty"`
+	Reason       string          `json:"reason,omitempty"`
+}
+
+// BinlogPosition описывает позицию в binlog remote сервера; GTIDSet заполняется при gtid_mode=ON.
+type BinlogPosition struct {
+	File     string `json:"file"`
+	Position uint32 `json:"position"`
+	GTIDSet  string `json:"gtid_set,omitempty"`
+}
+
+// BinlogStatus содержит текущую позицию и настройки binlog, нужные follow режиму.
+type BinlogStatus struct {
+	Position BinlogPosition `json:"position"`
+	Format   string         `json:"format"`
+	RowImage string         `json:"row_image"`
+}
+
+// FollowPhase описывает этап follow режима.
+type FollowPhase string
+
+const (
+	FollowPhaseInitialSync FollowPhase = "initial_sync"
+	FollowPhaseStreaming   FollowPhase = "streaming"
+	FollowPhaseStopped     FollowPhase = "stopped"
+	FollowPhaseFailed      FollowPhase = "failed"
+)
+
+// FollowStatus хранит срез состояния follow режима: позицию, отставание и счетчики примененных событий.
+type FollowStatus struct {
+	Phase        FollowPhase    `json:"phase"`
+	DatabaseName string         `json:"database_name"`
+	Position     BinlogPosition `json:"position"`
+	Lag          time.Duration  `json:"lag"`
+	Transactions int64          `json:"transactions"`
+	RowsApplied  int64          `json:"rows_applied"`
+	LastEventAt  time.Time      `json:"last_event_at,omitempty"`
+	Message      string         `json:"message,omitempty"`
+	Timestamp    time.Time      `json:"timestamp"`
+}
+
+// FollowObserver получает события follow режима.
+type FollowObserver func(FollowStatus)
+
+// ScheduleRunStatus описывает итог запуска расписания.
+type ScheduleRunStatus string
+
+const (
+	ScheduleRunSuccess ScheduleRunStatus = "success"
+	ScheduleRunFailed  ScheduleRunStatus = "failed"
+	ScheduleRunSkipped ScheduleRunStatus = "skipped"
+)
+
+// ScheduleRun хранит результат одного запуска расписания daemon'ом.
+type ScheduleRun struct {
+	ScheduleName string            `json:"schedule_name"`
+	Status       ScheduleRunStatus `json:"status"`
+	StartedAt    time.Time         `json:"started_at"`
+	FinishedAt   time.Time         `json:"finished_at"`
+	Error        string            `json:"error,omitempty"`
+	Results      []SyncResult      `json:"results,omitempty"`
+}
+
+// Duration возвращает длительность запуска.
+func (r ScheduleRun) Duration() time.Duration {
+	if r.FinishedAt.Before(r.StartedAt) {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Schedule связывает cron выражение с сохраненным планом синхронизации.
+type Schedule struct {
+	Name      string       `json:"name"`
+	Cron      string       `json:"cron"`
+	Plan      SyncPlan     `json:"plan"`
+	CreatedAt time.Time    `json:"created_at"`
+	LastRun   *ScheduleRun `json:"last_run,omitempty"`
+}
+
+// ScheduleStatus описывает расписание вместе с ближайшим временем запуска.
+type ScheduleStatus struct {
+	Schedule
+	NextRun time.Time `json:"next_run"`
+}
+
+// SyncRunState описывает состояние запуска плана через HTTP API.
+type SyncRunState string
+
+const (
+	SyncRunRunning   SyncRunState = "running"
+	SyncRunSucceeded SyncRunState = "succeeded"
+	SyncRunFailed    SyncRunState = "failed"
+	SyncRunCancelled SyncRunState = "cancelled"
+)
+
+// SyncRun хранит состояние запуска плана, отправленного через HTTP API.
+type SyncRun struct {
+	ID         string            `json:"id"`
+	State      SyncRunState      `json:"state"`
+	Databases  []string          `json:"databases"`
+	Plan       SyncPlan          `json:"plan"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at,omitempty"`
+	Progress   *ProgressSnapshot `json:"progress,omitempty"`
+	Results    []SyncResult      `json:"results,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// Finished сообщает, что запуск завершен.
+func (r SyncRun) Finished() bool {
+	return r.State != SyncRunRunning
+}
+
 // ProgressSnapshot хранит срез состояния во время синхронизации.
 type ProgressSnapshot struct {
-	Phase          SyncPhase      `json:"phase"`
-	DatabaseName   string         `json:"database_name"`
-	TableName      string         `json:"table_name,omitempty"`
-	Message        string         `json:"message,omitempty"`
-	Current        int64          `json:"current,omitempty"`
-	Total          int64          `json:"total,omitempty"`
-	Percent        float64        `json:"percent,omitempty"`
-	BytesCompleted int64          `json:"bytes_completed,omitempty"`
-	BytesTotal     int64          `json:"bytes_total,omitempty"`
-	ETA            time.Duration  `json:"eta,omitempty"`
-	Traffic        TrafficMetrics `json:"traffic,omitempty"`
-	Timestamp      time.Time      `json:"timestamp"`
+	Phase          SyncPhase       `json:"phase"`
+	DatabaseName   string          `json:"database_name"`
+	TableName      string          `json:"table_name,omitempty"`
+	Message        string          `json:"message,omitempty"`
+	Current        int64           `json:"current,omitempty"`
+	Total          int64           `json:"total,omitempty"`
+	Percent        float64         `json:"percent,omitempty"`
+	BytesCompleted int64           `json:"bytes_completed,omitempty"`
+	BytesTotal     int64           `json:"bytes_total,omitempty"`
+	ETA            time.Duration   `json:"eta,omitempty"`
+	Traffic        TrafficMetrics  `json:"traffic,omitempty"`
+	Tables         []TableProgress `json:"tables,omitempty"`
+	Timestamp      time.Time       `json:"timestamp"`
 }
 
 // ProgressObserver получает события выполнения синхронизации.
@@ -179,25 +600,189 @@ type SyncOptions struct {
 	Runtime        RuntimeOptions
 }
 
+// FailureType классифицирует неуспешный SyncResult.
+type FailureType string
+
+const (
+	// FailureTypeError — ошибка подключения, дампа, загрузки или hooks.
+	FailureTypeError FailureType = "error"
+	// FailureTypePolicy — план остановлен правилами безопасности до DROP.
+	FailureTypePolicy FailureType = "policy_violation"
+)
+
+// PolicyRule — правило безопасности, которое проверяется перед перезаписью БД в приемнике.
+type PolicyRule string
+
+const (
+	PolicyRuleProtectedDatabase PolicyRule = "protected_database"
+	PolicyRuleDestinationHost   PolicyRule = "destination_host"
+	PolicyRuleSameServer        PolicyRule = "same_server"
+)
+
+// PolicyViolation описывает нарушенное правило безопасности.
+type PolicyViolation struct {
+	Rule    PolicyRule `json:"rule"`
+	Message string     `json:"message"`
+}
+
+// DiskSpaceStatus — итог проверки свободного места на одном разделе.
+type DiskSpaceStatus string
+
+const (
+	DiskSpaceOK           DiskSpaceStatus = "ok"
+	DiskSpaceLow          DiskSpaceStatus = "low"
+	DiskSpaceInsufficient DiskSpaceStatus = "insufficient"
+)
+
+// DiskSpaceCheck сравнивает оценку нужного места со свободным местом раздела.
+// Purpose — dump (директория дампов) или datadir (@@datadir локального сервера).
+type DiskSpaceCheck struct {
+	Purpose       string          `json:"purpose"`
+	Path          string          `json:"path"`
+	RequiredBytes int64           `json:"required_bytes"`
+	FreeBytes     int64           `json:"free_bytes"`
+	Status        DiskSpaceStatus `json:"status"`
+}
+
+// DiskPreflight — оценка места для дампа и загрузки плана или цели.
+// Blocking означает, что при preflight.disk=block синхронизация не начнется.
+type DiskPreflight struct {
+	DumpBytes    int64            `json:"dump_bytes"`
+	RestoreBytes int64            `json:"restore_bytes"`
+	Checks       []DiskSpaceCheck `json:"checks,omitempty"`
+	Blocking     bool             `json:"blocking"`
+}
+
+// ServerIdentity содержит @@hostname и server_uuid сервера MySQL.
+type ServerIdentity struct {
+	Hostname   string `json:"hostname"`
+	ServerUUID string `json:"server_uuid,omitempty"`
+}
+
+// ServerSettings содержит версию, привилегии текущего пользователя и настройки сервера,
+// от которых зависит дамп и загрузка.
+type ServerSettings struct {
+	Version             string   `json:"version"`
+	Grants              []string `json:"grants"`
+	LocalInfile         bool     `json:"local_infile"`
+	SQLMode             string   `json:"sql_mode"`
+	LowerCaseTableNames int      `json:"lower_case_table_names"`
+}
+
+// DoctorStatus — итог одной проверки dbsync doctor.
+type DoctorStatus string
+
+const (
+	DoctorStatusOK   DoctorStatus = "ok"
+	DoctorStatusWarn DoctorStatus = "warn"
+	DoctorStatusFail DoctorStatus = "fail"
+)
+
+// DoctorCheck — результат проверки окружения; Hint подсказывает, как исправить проблему.
+type DoctorCheck struct {
+	Name   string       `json:"name"`
+	Status DoctorStatus `json:"status"`
+	Detail string       `json:"detail"`
+	Hint   string       `json:"hint,omitempty"`
+}
+
+// DoctorReport содержит результаты всех проверок dbsync doctor.
+type DoctorReport struct {
+	Source      string        `json:"source"`
+	Destination string        `json:"destination"`
+	Checks      []DoctorCheck `json:"checks"`
+	CheckedAt   time.Time     `json:"checked_at"`
+}
+
+// Failures возвращает число проверок, из-за которых синхронизация не пройдет.
+func (r *DoctorReport) Failures() int {
+	count := 0
+	for _, check := range r.Checks {
+		if check.Status == DoctorStatusFail {
+			count++
+		}
+	}
+	return count
+}
+
+// SnapshotLock — способ, которым движок дампа получает согласованный снимок источника.
+type SnapshotLock string
+
+const (
+	// SnapshotLockFTWRL — FLUSH TABLES WITH READ LOCK на время старта транзакций дампа (RELOAD).
+	SnapshotLockFTWRL SnapshotLock = "ftwrl"
+	// SnapshotLockBackup — LOCK INSTANCE FOR BACKUP и LOCK TABLES (BACKUP_ADMIN и LOCK TABLES).
+	SnapshotLockBackup SnapshotLock = "backup_lock"
+	// SnapshotLockTransaction — одна транзакция REPEATABLE READ, согласованная только для InnoDB.
+	SnapshotLockTransaction SnapshotLock = "single_transaction"
+)
+
+// DumpSnapshot описывает, был ли дамп согласованным снимком источника, и позицию этого снимка.
+// Warning объясняет, почему запрошенный согласованный дамп снят без блокировки.
+type DumpSnapshot struct {
+	Requested      bool         `json:"requested"`
+	Consistent     bool         `json:"consistent"`
+	Lock           SnapshotLock `json:"lock,omitempty"`
+	GTIDExecuted   string       `json:"gtid_executed,omitempty"`
+	BinlogFile     string       `json:"binlog_file,omitempty"`
+	BinlogPosition int64        `json:"binlog_position,omitempty"`
+	Warning        string       `json:"warning,omitempty"`
+}
+
+// CompatibilityFix — исправление несовместимости объекта источника, примененное при дампе.
+// Schema пуста у исправлений, не относящихся к одной схеме (учетные записи, пропущенные типы объектов).
+type CompatibilityFix struct {
+	Schema string `json:"schema,omitempty"`
+	Object string `json:"object"`
+	Fix    string `json:"fix"`
+}
+
+// ServerVariable — глобальная переменная локального MySQL, измененная на время загрузки дампа.
+// Restored=false с пустым Error означает, что восстановление не выполнялось.
+type ServerVariable struct {
+	Name     string `json:"name"`
+	Original string `json:"original"`
+	Applied  string `json:"applied"`
+	Restored bool   `json:"restored"`
+	Error    string `json:"error,omitempty"`
+}
+
 // SyncResult содержит результат синхронизации
 type SyncResult struct {
-	Success            bool               `json:"success"`
-	DatabaseName       string             `json:"database_name"`
-	Duration           time.Duration      `json:"duration"`
-	DumpDuration       time.Duration      `json:"dump_duration"`
-	RestoreDuration    time.Duration      `json:"restore_duration"`
-	DumpSize           int64              `json:"dump_size_bytes"`
-	TablesCount        int                `json:"tables_count"`
-	Error              string             `json:"error,omitempty"`
-	StartTime          time.Time          `json:"start_time"`
-	EndTime            time.Time          `json:"end_time"`
-	SelectedTables     []string           `json:"selected_tables,omitempty"`
-	AutoIncludedTables []string           `json:"auto_included_tables,omitempty"`
-	TransportMode      TransportMode      `json:"transport_mode,omitempty"`
-	LogicalSize        int64              `json:"logical_size_bytes,omitempty"`
-	IndexSize          int64              `json:"index_size_bytes,omitempty"`
-	DumpSizeOnDisk     int64              `json:"dump_size_on_disk_bytes,omitempty"`
-	CompressionRatio   float64            `json:"compression_ratio,omitempty"`
-	Traffic            TrafficMetrics     `json:"traffic,omitempty"`
-	Progress           []ProgressSnapshot `json:"progress,omitempty"`
+	Success            bool                `json:"success"`
+	DatabaseName       string              `json:"database_name"`
+	Duration           time.Duration       `json:"duration"`
+	DumpDuration       time.Duration       `json:"dump_duration"`
+	RestoreDuration    time.Duration       `json:"restore_duration"`
+	DumpSize           int64               `json:"dump_size_bytes"`
+	TablesCount        int                 `json:"tables_count"`
+	Error              string              `json:"error,omitempty"`
+	StartTime          time.Time           `json:"start_time"`
+	EndTime            time.Time           `json:"end_time"`
+	SelectedTables     []string            `json:"selected_tables,omitempty"`
+	AutoIncludedTables []string            `json:"auto_included_tables,omitempty"`
+	TransportMode      TransportMode       `json:"transport_mode,omitempty"`
+	LogicalSize        int64               `json:"logical_size_bytes,omitempty"`
+	IndexSize          int64               `json:"index_size_bytes,omitempty"`
+	DumpSizeOnDisk     int64               `json:"dump_size_on_disk_bytes,omitempty"`
+	CompressionRatio   float64             `json:"compression_ratio,omitempty"`
+	Traffic            TrafficMetrics      `json:"traffic,omitempty"`
+	Tables             []TableProgress     `json:"tables,omitempty"`
+	Hooks              []HookResult        `json:"hooks,omitempty"`
+	Backup             *LocalBackup        `json:"backup,omitempty"`
+	RolledBack         bool                `json:"rolled_back,omitempty"`
+	RollbackError      string              `json:"rollback_error,omitempty"`
+	Verification       *VerificationResult `json:"verification,omitempty"`
+	Incremental        *IncrementalPlan    `json:"incremental,omitempty"`
+	SmartSync          *SmartSyncPlan      `json:"smart_sync,omitempty"`
+	StreamCopy         bool                `json:"stream_copy,omitempty"`
+	Batch              []string            `json:"batch,omitempty"`
+	ServerTuning       []ServerVariable    `json:"server_tuning,omitempty"`
+	Snapshot           *DumpSnapshot       `json:"snapshot,omitempty"`
+	Compatibility      []CompatibilityFix  `json:"compatibility,omitempty"`
+	Source             string              `json:"source,omitempty"`
+	Destination        string              `json:"destination,omitempty"`
+	FailureType        FailureType         `json:"failure_type,omitempty"`
+	PolicyViolations   []PolicyViolation   `json:"policy_violations,omitempty"`
+	Progress           []ProgressSnapshot  `json:"progress,omitempty"`
 }
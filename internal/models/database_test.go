@@ -208,6 +208,46 @@ func TestProgressSnapshot_HasETA(t *testing.T) {
 	assert.False(t, ProgressSnapshot{}.HasETA())
 }
 
+func TestSyncResult_SlowestTables(t *testing.T) {
+	result := SyncResult{Tables: []TableProgress{
+		{Name: "users", DumpDuration: time.Second, LoadDuration: time.Second},
+		{Name: "events", DumpDuration: 30 * time.Second, LoadDuration: 50 * time.Second},
+		{Name: "empty"},
+		{Name: "orders", DumpDuration: 5 * time.Second, LoadDuration: 4 * time.Second},
+	}}
+
+	slowest := result.SlowestTables(2)
+	assert.Len(t, slowest, 2)
+	assert.Equal(t, "events", slowest[0].Name)
+	assert.Equal(t, 80*time.Second, slowest[0].Duration())
+	assert.Equal(t, "orders", slowest[1].Name)
+	assert.Len(t, result.SlowestTables(0), 3)
+}
+
+func TestVerificationResult_Passed(t *testing.T) {
+	var missing *VerificationResult
+	assert.False(t, missing.Passed())
+
+	result := &VerificationResult{Tables: []TableVerification{
+		{Name: "users", RemoteRows: 3, LocalRows: 3},
+		{Name: "orders", RemoteRows: 10, LocalRows: 9, Mismatches: []string{"rows"}},
+	}}
+	assert.False(t, result.Passed())
+	assert.Len(t, result.MismatchedTables(), 1)
+	assert.Equal(t, "orders", result.MismatchedTables()[0].Name)
+
+	result.Tables = result.Tables[:1]
+	assert.True(t, result.Passed())
+	result.Error = "failed to query remote"
+	assert.False(t, result.Passed())
+}
+
+func TestBinlogPosition_String(t *testing.T) {
+	assert.Equal(t, "unknown", BinlogPosition{}.String())
+	assert.Equal(t, "binlog.000042:1337", BinlogPosition{File: "binlog.000042", Position: 1337}.String())
+	assert.Equal(t, "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-77", BinlogPosition{File: "binlog.000042", Position: 1337, GTIDSet: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-77"}.String())
+}
+
 func startTimeFromUnix(value int64) time.Time {
 	return time.Unix(value, 0)
 }
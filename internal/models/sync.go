@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"time"
+)
 
 // UsesTableSelection сообщает, что target синхронизируется по списку таблиц.
 func (t SyncTarget) UsesTableSelection() bool {
@@ -60,3 +64,178 @@ func (r SyncResult) DurationOrZero() time.Duration {
 	}
 	return r.EndTime.Sub(r.StartTime)
 }
+
+// Duration возвращает суммарное время дампа и загрузки таблицы.
+func (t TableProgress) Duration() time.Duration {
+	return t.DumpDuration + t.LoadDuration
+}
+
+// IsFinished сообщает, что таблица полностью восстановлена локально.
+func (t TableProgress) IsFinished() bool {
+	return t.State == TableStateDone
+}
+
+// SlowestTables возвращает до limit таблиц с наибольшим суммарным временем обработки.
+func (r SyncResult) SlowestTables(limit int) []TableProgress {
+	tables := make([]TableProgress, 0, len(r.Tables))
+	for _, table := range r.Tables {
+		if table.Duration() > 0 {
+			tables = append(tables, table)
+		}
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		if tables[i].Duration() == tables[j].Duration() {
+			return tables[i].Name < tables[j].Name
+		}
+		return tables[i].Duration() > tables[j].Duration()
+	})
+	if limit > 0 && len(tables) > limit {
+		tables = tables[:limit]
+	}
+	return tables
+}
+
+// Matches сообщает, что таблица совпала по всем проверкам.
+func (t TableVerification) Matches() bool {
+	return len(t.Mismatches) == 0
+}
+
+// MismatchedTables возвращает таблицы, не прошедшие сверку.
+func (v *VerificationResult) MismatchedTables() []TableVerification {
+	if v == nil {
+		return nil
+	}
+	var tables []TableVerification
+	for _, table := range v.Tables {
+		if !table.Matches() {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// Passed сообщает, что проверка выполнена полностью и расхождений нет.
+func (v *VerificationResult) Passed() bool {
+	return v != nil && v.Error == "" && len(v.MismatchedTables()) == 0
+}
+
+// HasSchemaChanges сообщает, что у таблицы отличаются колонки, индексы или движок.
+func (d TableDiff) HasSchemaChanges() bool {
+	return len(d.MissingColumns) > 0 || len(d.ExtraColumns) > 0 || len(d.ChangedColumns) > 0 ||
+		len(d.MissingIndexes) > 0 || len(d.ExtraIndexes) > 0 || len(d.ChangedIndexes) > 0 || d.EngineChanged
+}
+
+// RowDelta возвращает разницу строк local - remote.
+func (d TableDiff) RowDelta() int64 {
+	return d.LocalRows - d.RemoteRows
+}
+
+// Empty сообщает, что локальная БД совпадает с remote по структуре и числу строк.
+func (d *SchemaDiff) Empty() bool {
+	if d == nil {
+		return true
+	}
+	return len(d.MissingTables) == 0 && len(d.ExtraTables) == 0 && len(d.Tables) == 0
+}
+
+// ChangedTables возвращает таблицы с отличиями в структуре.
+func (d *SchemaDiff) ChangedTables() []TableDiff {
+	if d == nil {
+		return nil
+	}
+	var tables []TableDiff
+	for _, table := range d.Tables {
+		if table.HasSchemaChanges() {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+// Active сообщает, что хотя бы одна таблица цели синхронизируется инкрементально.
+func (p *IncrementalPlan) Active() bool {
+	return p != nil && len(p.Incremental) > 0
+}
+
+// IncrementalTableNames возвращает имена таблиц, которые догружаются по high-water mark.
+func (p *IncrementalPlan) IncrementalTableNames() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.Incremental))
+	for _, table := range p.Incremental {
+		names = append(names, table.Name)
+	}
+	return names
+}
+
+// SkippedTables возвращает таблицы, которые остаются локально без перезагрузки.
+func (p *SmartSyncPlan) SkippedTables() []string {
+	return p.tablesWithAction(TableActionSkip)
+}
+
+// RefreshedTables возвращает таблицы, которые будут выгружены и загружены заново.
+func (p *SmartSyncPlan) RefreshedTables() []string {
+	return p.tablesWithAction(TableActionRefresh)
+}
+
+// SavedBytes возвращает объем данных пропущенных таблиц.
+func (p *SmartSyncPlan) SavedBytes() int64 {
+	if p == nil {
+		return 0
+	}
+	var total int64
+	for _, table := range p.Tables {
+		if table.Action == TableActionSkip {
+			total += table.Bytes
+		}
+	}
+	return total
+}
+
+func (p *SmartSyncPlan) tablesWithAction(action TableSyncAction) []string {
+	if p == nil {
+		return nil
+	}
+	var names []string
+	for _, table := range p.Tables {
+		if table.Action == action {
+			names = append(names, table.Name)
+		}
+	}
+	return names
+}
+
+// Without возвращает копию плана без указанных таблиц.
+func (p *IncrementalPlan) Without(tableNames []string) *IncrementalPlan {
+	if p == nil {
+		return nil
+	}
+	excluded := make(map[string]bool, len(tableNames))
+	for _, tableName := range tableNames {
+		excluded[tableName] = true
+	}
+	filtered := &IncrementalPlan{DatabaseName: p.DatabaseName, Reason: p.Reason}
+	for _, table := range p.Incremental {
+		if !excluded[table.Name] {
+			filtered.Incremental = append(filtered.Incremental, table)
+		}
+	}
+	for _, tableName := range p.Full {
+		if !excluded[tableName] {
+			filtered.Full = append(filtered.Full, tableName)
+		}
+	}
+	return filtered
+}
+
+// String возвращает позицию в виде file:pos или GTID set, если он известен.
+func (p BinlogPosition) String() string {
+	if p.GTIDSet != "" {
+		return p.GTIDSet
+	}
+	if p.File == "" {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Position)
+}
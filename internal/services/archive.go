@@ -0,0 +1,383 @@
+package services
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"db-sync-cli/internal/models"
+	"db-sync-cli/internal/version"
+)
+
+const (
+	archiveFormatVersion = 1
+	archiveManifestName  = "manifest.json"
+	// archiveDumpPrefix — каталог файлов дампа внутри архива.
+	archiveDumpPrefix = "dump/"
+)
+
+// ExportDatabase снимает дамп remote БД и упаковывает его с манифестом в один tar+zstd архив.
+func (s *MySQLShellService) ExportDatabase(databaseName string, outputPath string, observer models.ProgressObserver) (*models.ArchiveManifest, error) {
+	if err := s.dbService.ValidateDatabaseName(databaseName); err != nil {
+		return nil, fmt.Errorf("invalid database name: %w", err)
+	}
+	connInfo, err := s.dbService.TestConnection(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote server: %w", err)
+	}
+	tables, err := s.dbService.ListTables(databaseName, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote tables: %w", err)
+	}
+
+	_, dumpDir, _, err := s.createDumpTarget(models.SyncTarget{DatabaseName: databaseName, ReplaceEntireDatabase: true}, nil, false, observer)
+	if err != nil {
+		return nil, fmt.Errorf("dump creation failed: %w", err)
+	}
+	defer removeWorkDir(dumpDir)
+	engine, err := s.dumpEngineForDir(dumpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &models.ArchiveManifest{
+		FormatVersion: archiveFormatVersion,
+		DatabaseName:  databaseName,
+		SourceHost:    s.config.Remote.Host,
+		SourcePort:    s.config.Remote.Port,
+		ServerVersion: connInfo.Version,
+		DumpEngine:    engine.Name(),
+		Tables:        make([]models.ArchiveTable, 0, len(tables)),
+		CreatedAt:     time.Now().UTC(),
+		DbsyncVersion: version.Version,
+	}
+	for _, table := range tables {
+		manifest.Tables = append(manifest.Tables, models.ArchiveTable{Name: table.Name, Rows: table.Rows, RowsApprox: table.RowsApprox, DataSize: table.DataSize})
+	}
+
+	s.printStatusf("🗜️  Packing %s...", outputPath)
+	if err := writeDumpArchive(dumpDir, outputPath, manifest, s.archiveProgress(models.SyncPhaseDump, databaseName, "Packing archive", observer)); err != nil {
+		return nil, err
+	}
+	s.printStatusf("\r✅ Exported %s → %s (%s)                    \n", databaseName, outputPath, FormatSize(fileSize(outputPath)))
+	return manifest, nil
+}
+
+// ImportArchive распаковывает архив dbsync export, проверяет контрольные суммы файлов и загружает дамп
+// в локальную БД asName; пустое имя означает исходное имя БД.
+func (s *MySQLShellService) ImportArchive(archivePath string, asName string, observer models.ProgressObserver) (*models.ArchiveManifest, error) {
+	service, cleanup, err := s.routeService(models.SyncTarget{DatabaseName: asName})
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return service.importArchive(archivePath, asName, observer)
+}
+
+func (s *MySQLShellService) importArchive(archivePath string, asName string, observer models.ProgressObserver) (*models.ArchiveManifest, error) {
+	extractDir, _, err := s.createWorkTemp("dbsync_import_", false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import directory: %w", err)
+	}
+	defer removeWorkDir(extractDir)
+
+	s.printStatusf("📂 Unpacking %s...", archivePath)
+	manifest, err := extractDumpArchive(archivePath, extractDir, s.archiveProgress(models.SyncPhaseRestore, asName, "Verifying archive", observer))
+	if err != nil {
+		return nil, err
+	}
+	databaseName := asName
+	if databaseName == "" {
+		databaseName = manifest.DatabaseName
+	}
+	if err := s.dbService.ValidateDatabaseName(databaseName); err != nil {
+		return manifest, fmt.Errorf("invalid database name: %w", err)
+	}
+	s.printStatusf("\r✅ Verified %d files of %s (%s)                    \n", len(manifest.Files), manifest.DatabaseName, FormatSize(manifest.DumpSize()))
+
+	tables := make([]models.Table, 0, len(manifest.Tables))
+	for _, table := range manifest.Tables {
+		tables = append(tables, models.Table{DatabaseName: databaseName, Name: table.Name, Rows: table.Rows, RowsApprox: table.RowsApprox, DataSize: table.DataSize})
+	}
+	tracker := newTableProgressTracker(databaseName, tables)
+	if err := s.restoreDumpTuned(extractDir, manifest.DatabaseName, databaseName, observer, tracker); err != nil {
+		return manifest, fmt.Errorf("restore failed: %w", err)
+	}
+	return manifest, nil
+}
+
+// ReadArchiveManifest читает манифест из начала архива без распаковки дампа.
+func ReadArchiveManifest(archivePath string) (*models.ArchiveManifest, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer decoder.Close()
+	return readArchiveManifest(tar.NewReader(decoder))
+}
+
+// archiveProgress печатает прогресс упаковки в строке статуса и пересылает его в observer.
+func (s *MySQLShellService) archiveProgress(phase models.SyncPhase, databaseName string, message string, observer models.ProgressObserver) func(done int64, total int64) {
+	var lastPrinted time.Time
+	return func(done int64, total int64) {
+		now := time.Now()
+		if done < total && now.Sub(lastPrinted) < 250*time.Millisecond {
+			return
+		}
+		lastPrinted = now
+		percent := 100.0
+		if total > 0 {
+			percent = float64(done) * 100 / float64(total)
+		}
+		s.printStatusf("\r%s... %.0f%% (%s / %s)", message, percent, FormatSize(done), FormatSize(total))
+		if observer != nil {
+			observer(models.ProgressSnapshot{Phase: phase, DatabaseName: databaseName, Message: message, Percent: percent, BytesCompleted: done, BytesTotal: total, Timestamp: now})
+		}
+	}
+}
+
+// writeDumpArchive записывает манифест и файлы дампа в tar+zstd архив. Архив пишется во временный
+// файл рядом с outputPath и переименовывается только после успешной записи.
+func writeDumpArchive(dumpDir string, outputPath string, manifest *models.ArchiveManifest, progress func(done int64, total int64)) error {
+	files, err := hashDumpFiles(dumpDir)
+	if err != nil {
+		return err
+	}
+	manifest.Files = files
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(outputPath), filepath.Base(outputPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	encoder, err := zstd.NewWriter(temp)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	writer := tar.NewWriter(encoder)
+	modTime := manifest.CreatedAt
+	if err := writer.WriteHeader(&tar.Header{Name: archiveManifestName, Mode: 0644, Size: int64(len(manifestData)), ModTime: modTime}); err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+	if _, err := writer.Write(manifestData); err != nil {
+		return fmt.Errorf("failed to write archive manifest: %w", err)
+	}
+
+	total := manifest.DumpSize()
+	var done int64
+	for _, file := range files {
+		if err := writer.WriteHeader(&tar.Header{Name: archiveDumpPrefix + file.Path, Mode: 0644, Size: file.Size, ModTime: modTime}); err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", file.Path, err)
+		}
+		source, err := os.Open(filepath.Join(dumpDir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			return fmt.Errorf("failed to read dump file: %w", err)
+		}
+		written, err := io.Copy(writer, source)
+		source.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", file.Path, err)
+		}
+		done += written
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := os.Rename(temp.Name(), outputPath); err != nil {
+		return fmt.Errorf("failed to save archive: %w", err)
+	}
+	return nil
+}
+
+// hashDumpFiles возвращает файлы дампа в стабильном порядке с размерами и SHA-256.
+func hashDumpFiles(dumpDir string) ([]models.ArchiveFile, error) {
+	var files []models.ArchiveFile
+	err := filepath.WalkDir(dumpDir, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(dumpDir, filePath)
+		if err != nil {
+			return err
+		}
+		size, sum, err := hashFile(filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, models.ArchiveFile{Path: filepath.ToSlash(relative), Size: size, SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash dump files: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func hashFile(filePath string) (int64, string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// extractDumpArchive распаковывает файлы дампа в dir и сверяет каждый файл с манифестом.
+// Файлы вне манифеста, пути за пределами dir и недостающие файлы считаются повреждением архива.
+func extractDumpArchive(archivePath string, dir string, progress func(done int64, total int64)) (*models.ArchiveManifest, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer decoder.Close()
+	reader := tar.NewReader(decoder)
+
+	manifest, err := readArchiveManifest(reader)
+	if err != nil {
+		return nil, err
+	}
+	expected := make(map[string]models.ArchiveFile, len(manifest.Files))
+	for _, archiveFile := range manifest.Files {
+		expected[archiveFile.Path] = archiveFile
+	}
+
+	total := manifest.DumpSize()
+	var done int64
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("archive entry %s is not a regular file", header.Name)
+		}
+		name, ok := strings.CutPrefix(header.Name, archiveDumpPrefix)
+		if !ok || name == "" || name != path.Clean(name) || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			return nil, fmt.Errorf("archive contains unexpected entry %s", header.Name)
+		}
+		want, ok := expected[name]
+		if !ok {
+			return nil, fmt.Errorf("archive contains file %s missing from the manifest", name)
+		}
+		delete(expected, name)
+
+		size, sum, err := extractArchiveFile(reader, filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		if size != want.Size || sum != want.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch for %s: archive is corrupted", name)
+		}
+		done += size
+		if progress != nil {
+			progress(done, total)
+		}
+	}
+	if len(expected) > 0 {
+		missing := make([]string, 0, len(expected))
+		for name := range expected {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("archive is truncated: missing %s", strings.Join(missing, ", "))
+	}
+	return manifest, nil
+}
+
+func readArchiveManifest(reader *tar.Reader) (*models.ArchiveManifest, error) {
+	header, err := reader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if header.Name != archiveManifestName {
+		return nil, fmt.Errorf("not a dbsync archive: first entry is %s", header.Name)
+	}
+	var manifest models.ArchiveManifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode archive manifest: %w", err)
+	}
+	if manifest.FormatVersion != archiveFormatVersion {
+		return nil, fmt.Errorf("unsupported archive format version %d", manifest.FormatVersion)
+	}
+	if manifest.DatabaseName == "" {
+		return nil, fmt.Errorf("archive manifest has no database name")
+	}
+	return &manifest, nil
+}
+
+func extractArchiveFile(reader io.Reader, target string) (int64, string, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, "", err
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return 0, "", err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func fileSize(filePath string) int64 {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
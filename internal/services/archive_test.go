@@ -0,0 +1,144 @@
+package services
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"db-sync-cli/internal/models"
+)
+
+func TestDumpArchiveRoundTrip(t *testing.T) {
+	dumpDir := t.TempDir()
+	files := map[string]string{
+		"@.json":                 `{"version":"2.0.1"}`,
+		"shop@orders@0.tsv.zst":  strings.Repeat("order-row\n", 1000),
+		"chunks/shop@users.json": `{"options":{}}`,
+		dumpEngineMarker:         "mysqlsh\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dumpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "out", "shop.dbsync")
+	manifest := &models.ArchiveManifest{
+		FormatVersion: archiveFormatVersion,
+		DatabaseName:  "shop",
+		SourceHost:    "db.example.com",
+		SourcePort:    3306,
+		DumpEngine:    "mysqlsh",
+		Tables:        []models.ArchiveTable{{Name: "orders", Rows: 1000}},
+		CreatedAt:     time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		DbsyncVersion: "4.0.3",
+	}
+	var lastDone, lastTotal int64
+	if err := writeDumpArchive(dumpDir, archivePath, manifest, func(done int64, total int64) { lastDone, lastTotal = done, total }); err != nil {
+		t.Fatalf("writeDumpArchive failed: %v", err)
+	}
+	if len(manifest.Files) != len(files) || lastDone != lastTotal || lastTotal != manifest.DumpSize() {
+		t.Fatalf("unexpected manifest files %+v or progress %d/%d", manifest.Files, lastDone, lastTotal)
+	}
+	if entries, _ := filepath.Glob(filepath.Join(filepath.Dir(archivePath), "*.tmp")); len(entries) > 0 {
+		t.Fatalf("temporary archive files left behind: %v", entries)
+	}
+
+	read, err := ReadArchiveManifest(archivePath)
+	if err != nil || read.DatabaseName != "shop" || read.SourceHost != "db.example.com" || read.TotalRows() != 1000 {
+		t.Fatalf("unexpected manifest %+v, err %v", read, err)
+	}
+
+	extractDir := t.TempDir()
+	extracted, err := extractDumpArchive(archivePath, extractDir, nil)
+	if err != nil {
+		t.Fatalf("extractDumpArchive failed: %v", err)
+	}
+	if extracted.DumpEngine != "mysqlsh" {
+		t.Fatalf("unexpected dump engine %q", extracted.DumpEngine)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(extractDir, filepath.FromSlash(name)))
+		if err != nil || string(data) != content {
+			t.Fatalf("file %s was not restored: %q, %v", name, data, err)
+		}
+	}
+}
+
+func TestExtractDumpArchiveRejectsCorruption(t *testing.T) {
+	manifest := models.ArchiveManifest{
+		FormatVersion: archiveFormatVersion,
+		DatabaseName:  "shop",
+		Files: []models.ArchiveFile{
+			{Path: "@.json", Size: 2, SHA256: "0000000000000000000000000000000000000000000000000000000000000000"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		entries map[string]string
+		want    string
+	}{
+		{name: "checksum", entries: map[string]string{"dump/@.json": "{}"}, want: "checksum mismatch for @.json"},
+		{name: "traversal", entries: map[string]string{"dump/../evil": "x"}, want: "unexpected entry dump/../evil"},
+		{name: "extra file", entries: map[string]string{"dump/extra.tsv": "x"}, want: "missing from the manifest"},
+		{name: "truncated", entries: map[string]string{}, want: "archive is truncated: missing @.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := writeTestArchive(t, manifest, tt.entries)
+			_, err := extractDumpArchive(archivePath, t.TempDir(), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func writeTestArchive(t *testing.T, manifest models.ArchiveManifest, entries map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "test.dbsync")
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	encoder, err := zstd.NewWriter(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := tar.NewWriter(encoder)
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name string, content string) {
+		if err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(archiveManifestName, string(manifestData))
+	for name, content := range entries {
+		write(name, content)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
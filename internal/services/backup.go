@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+const (
+	// backupTimestampLayout — метка времени в именах архивов бакета; при разборе имен директорий бэкапов
+	// принимает и новые имена с долями секунды, и старые без них.
+	backupTimestampLayout = "20060102T150405"
+	// backupDirLayout — имя новой директории бэкапа: миллисекунды разводят бэкапы одной секунды.
+	backupDirLayout  = "20060102T150405.000"
+	backupDoneMarker = "@.done.json"
+)
+
+// BackupLocalDatabase снимает локальный дамп БД выбранным движком перед перезаписью и чистит старые копии.
+func (s *MySQLShellService) BackupLocalDatabase(databaseName string, observer models.ProgressObserver) (*models.LocalBackup, error) {
+	if err := s.dbService.ValidateDatabaseName(databaseName); err != nil {
+		return nil, fmt.Errorf("invalid database name: %w", err)
+	}
+	engine, err := s.dumpEngine()
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now()
+	databaseDir := s.backupDatabaseDir(databaseName)
+	if err := os.MkdirAll(databaseDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	backupDir, createdAt := newBackupDir(databaseDir, createdAt)
+
+	s.printStatusf("💾 Backing up local %s...", databaseName)
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseBackup, DatabaseName: databaseName, Message: "Backing up local database", Timestamp: createdAt})
+	}
+
+	err = engine.Dump(DumpRequest{
+		Conn:         s.config.Local,
+		DatabaseName: databaseName,
+		Dir:          backupDir,
+		Threads:      s.config.Dump.Threads,
+		Compress:     true,
+		Phase:        models.SyncPhaseBackup,
+	}, observer)
+	if err == nil {
+		err = writeDumpEngineMarker(backupDir, engine)
+	}
+	if err != nil {
+		os.RemoveAll(backupDir)
+		return nil, err
+	}
+
+	backup := &models.LocalBackup{
+		DatabaseName: databaseName,
+		Path:         backupDir,
+		SizeBytes:    directorySize(backupDir),
+		CreatedAt:    createdAt,
+	}
+	s.printStatusf("\r✅ Backed up local %s → %s (%s)\n", databaseName, backupDir, FormatSize(backup.SizeBytes))
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseBackup, DatabaseName: databaseName, Message: "Local backup complete", Percent: 100, BytesCompleted: backup.SizeBytes, BytesTotal: backup.SizeBytes, Timestamp: time.Now()})
+	}
+
+	if err := pruneLocalBackups(databaseDir, s.config.Backup.Keep); err != nil {
+		s.printStatusf("⚠️  Failed to prune old backups: %v\n", err)
+	}
+	return backup, nil
+}
+
+// ListLocalBackups возвращает завершенные бэкапы базы, начиная с самого нового.
+func (s *MySQLShellService) ListLocalBackups(databaseName string) ([]models.LocalBackup, error) {
+	return listLocalBackups(s.backupDatabaseDir(databaseName), databaseName)
+}
+
+// RestoreLocalBackup восстанавливает локальную БД из бэкапа; пустой путь означает последний бэкап.
+func (s *MySQLShellService) RestoreLocalBackup(databaseName string, backupPath string, observer models.ProgressObserver) (*models.LocalBackup, error) {
+	service, cleanup, err := s.routeService(models.SyncTarget{DatabaseName: databaseName})
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return service.restoreLocalBackup(databaseName, backupPath, observer)
+}
+
+func (s *MySQLShellService) restoreLocalBackup(databaseName string, backupPath string, observer models.ProgressObserver) (*models.LocalBackup, error) {
+	var backup *models.LocalBackup
+	if backupPath == "" {
+		backups, err := s.ListLocalBackups(databaseName)
+		if err != nil {
+			return nil, err
+		}
+		if len(backups) == 0 {
+			return nil, fmt.Errorf("no local backups found for database '%s'", databaseName)
+		}
+		backup = &backups[0]
+	} else {
+		if !isCompleteDump(backupPath) {
+			return nil, fmt.Errorf("backup %s is incomplete or missing", backupPath)
+		}
+		backup = &models.LocalBackup{DatabaseName: databaseName, Path: backupPath, SizeBytes: directorySize(backupPath)}
+	}
+
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseBackup, DatabaseName: databaseName, Message: "Restoring local backup", Timestamp: time.Now()})
+	}
+	if err := s.restoreDumpTuned(backup.Path, databaseName, databaseName, observer, newTableProgressTracker(databaseName, nil)); err != nil {
+		return backup, fmt.Errorf("failed to restore backup %s: %w", backup.Path, err)
+	}
+	return backup, nil
+}
+
+// rollbackFromBackup возвращает локальную БД к состоянию до синхронизации.
+func (s *MySQLShellService) rollbackFromBackup(result *models.SyncResult, observer models.ProgressObserver) {
+	if result.Backup == nil {
+		return
+	}
+	s.printStatusf("↩️  Rolling back %s from local backup...\n", result.DatabaseName)
+	// Откат не должен прерываться отменой синхронизации, иначе локальная БД останется пустой.
+	if _, err := s.withContext(context.Background()).RestoreLocalBackup(result.DatabaseName, result.Backup.Path, observer); err != nil {
+		result.RollbackError = err.Error()
+		return
+	}
+	result.RolledBack = true
+}
+
+func listLocalBackups(databaseDir string, databaseName string) ([]models.LocalBackup, error) {
+	entries, err := os.ReadDir(databaseDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	backups := make([]models.LocalBackup, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(backupTimestampLayout, entry.Name(), time.Local)
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(databaseDir, entry.Name())
+		// Незавершенный дамп без маркера не годится для восстановления.
+		if !isCompleteDump(path) {
+			continue
+		}
+		backups = append(backups, models.LocalBackup{
+			DatabaseName: databaseName,
+			Path:         path,
+			SizeBytes:    directorySize(path),
+			CreatedAt:    createdAt,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// newBackupDir возвращает еще не занятую директорию бэкапа для createdAt; если бэкап с той же
+// миллисекундой уже есть, метка сдвигается вперед.
+func newBackupDir(databaseDir string, createdAt time.Time) (string, time.Time) {
+	createdAt = createdAt.Truncate(time.Millisecond)
+	for {
+		path := filepath.Join(databaseDir, createdAt.Format(backupDirLayout))
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path, createdAt
+		}
+		createdAt = createdAt.Add(time.Millisecond)
+	}
+}
+
+func pruneLocalBackups(databaseDir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	entries, err := os.ReadDir(databaseDir)
+	if err != nil {
+		return err
+	}
+	type backupDir struct {
+		name      string
+		createdAt time.Time
+	}
+	dirs := make([]backupDir, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if createdAt, err := time.ParseInLocation(backupTimestampLayout, entry.Name(), time.Local); err == nil {
+			dirs = append(dirs, backupDir{name: entry.Name(), createdAt: createdAt})
+		}
+	}
+	// Старые имена без долей секунды и новые с миллисекундами сравниваются по времени, а не как строки.
+	sort.Slice(dirs, func(i, j int) bool {
+		return dirs[i].createdAt.After(dirs[j].createdAt)
+	})
+	var pruneErrors []error
+	for _, dir := range dirs[min(keep, len(dirs)):] {
+		if err := os.RemoveAll(filepath.Join(databaseDir, dir.name)); err != nil {
+			pruneErrors = append(pruneErrors, err)
+		}
+	}
+	return errors.Join(pruneErrors...)
+}
+
+func directorySize(root string) int64 {
+	var total int64
+	_ = filepath.Walk(root, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+)
+
+func writeTestBackup(t *testing.T, databaseDir string, name string, complete bool) string {
+	t.Helper()
+	path := filepath.Join(databaseDir, name)
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		t.Fatalf("create backup dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "shop@orders@@0.tsv.zst"), []byte("data"), 0o600); err != nil {
+		t.Fatalf("write backup chunk: %v", err)
+	}
+	if complete {
+		if err := os.WriteFile(filepath.Join(path, backupDoneMarker), []byte("{}"), 0o600); err != nil {
+			t.Fatalf("write done marker: %v", err)
+		}
+	}
+	return path
+}
+
+func TestListLocalBackupsNewestFirstAndSkipsIncomplete(t *testing.T) {
+	databaseDir := t.TempDir()
+	writeTestBackup(t, databaseDir, "20260101T100000", true)
+	newest := writeTestBackup(t, databaseDir, "20260103T100000", true)
+	writeTestBackup(t, databaseDir, "20260104T100000", false)
+	writeTestBackup(t, databaseDir, "not-a-backup", true)
+
+	backups, err := listLocalBackups(databaseDir, "shop")
+	if err != nil {
+		t.Fatalf("listLocalBackups() error = %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 complete backups, got %+v", backups)
+	}
+	if backups[0].Path != newest || backups[0].DatabaseName != "shop" || backups[0].SizeBytes != 6 {
+		t.Fatalf("unexpected newest backup: %+v", backups[0])
+	}
+
+	missing, err := listLocalBackups(filepath.Join(databaseDir, "missing"), "shop")
+	if err != nil || len(missing) != 0 {
+		t.Fatalf("missing directory should produce no backups, got %+v err=%v", missing, err)
+	}
+}
+
+func TestPruneLocalBackupsKeepsNewest(t *testing.T) {
+	databaseDir := t.TempDir()
+	for _, name := range []string{"20260101T100000", "20260102T100000", "20260103T100000", "20260104T100000"} {
+		writeTestBackup(t, databaseDir, name, true)
+	}
+
+	if err := pruneLocalBackups(databaseDir, 2); err != nil {
+		t.Fatalf("pruneLocalBackups() error = %v", err)
+	}
+	entries, err := os.ReadDir(databaseDir)
+	if err != nil {
+		t.Fatalf("read backup dir: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, ",") != "20260103T100000,20260104T100000" {
+		t.Fatalf("unexpected backups after prune: %v", names)
+	}
+}
+
+func TestBackupDirsWithinOneSecond(t *testing.T) {
+	databaseDir := t.TempDir()
+	createdAt := time.Date(2026, 1, 5, 10, 0, 0, 250*int(time.Millisecond), time.Local)
+	first, firstAt := newBackupDir(databaseDir, createdAt)
+	writeTestBackup(t, databaseDir, filepath.Base(first), true)
+	second, secondAt := newBackupDir(databaseDir, createdAt)
+	writeTestBackup(t, databaseDir, filepath.Base(second), true)
+	if filepath.Base(first) != "20260105T100000.250" || filepath.Base(second) != "20260105T100000.251" || !secondAt.After(firstAt) {
+		t.Fatalf("backups of the same millisecond must get distinct directories, got %s and %s", first, second)
+	}
+	writeTestBackup(t, databaseDir, "20260105T100000", true)
+	writeTestBackup(t, databaseDir, "20260104T235959.999", true)
+
+	backups, err := listLocalBackups(databaseDir, "shop")
+	if err != nil || len(backups) != 4 || backups[0].Path != second || backups[1].Path != first {
+		t.Fatalf("sub-second backups must be listed newest first, got %+v, %v", backups, err)
+	}
+	if err := pruneLocalBackups(databaseDir, 3); err != nil {
+		t.Fatalf("pruneLocalBackups() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(databaseDir, "20260104T235959.999")); !os.IsNotExist(err) {
+		t.Fatalf("oldest backup must be pruned, stat err = %v", err)
+	}
+}
+
+func TestRestoreLocalBackupWithoutBackupsFails(t *testing.T) {
+	service := NewMySQLShellService(&config.Config{Backup: config.BackupConfig{Dir: t.TempDir()}}, nil)
+	service.SetQuiet(true)
+
+	_, err := service.RestoreLocalBackup("shop", "", nil)
+	if err == nil || !strings.Contains(err.Error(), "no local backups found") {
+		t.Fatalf("expected missing backup error, got %v", err)
+	}
+
+	incomplete := writeTestBackup(t, t.TempDir(), "20260101T100000", false)
+	if _, err := service.RestoreLocalBackup("shop", incomplete, nil); err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Fatalf("expected incomplete backup error, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// batchableTarget сообщает, можно ли выгрузить цель вместе с другими одним mysqlsh dump-schemas.
+// Пакет собирается только из целиком заменяемых БД: staging-загрузки и потоковое копирование идут по одной.
+func (s *MySQLShellService) batchableTarget(target models.SyncTarget) bool {
+	if s.config.Dump.BatchSize <= 1 || s.config.Dump.Copy || s.config.Incremental.Enabled || s.config.Smart.Enabled {
+		return false
+	}
+	if !target.ReplaceEntireDatabase || len(target.EffectiveTables()) > 0 || len(target.SkipTables) > 0 {
+		return false
+	}
+	engine, err := s.dumpEngine()
+	return err == nil && engine.Name() == config.DumpEngineMySQLShell
+}
+
+// planBatches делит цели плана на пакеты подряд идущих batchable целей размером до dump.batch_size.
+// В пакет попадают только цели с одинаковыми source и destination и одинаковым требованием
+// согласованного снимка; остальные выполняются по одной, порядок плана сохраняется.
+func (s *MySQLShellService) planBatches(targets []models.SyncTarget) [][]models.SyncTarget {
+	batches := make([][]models.SyncTarget, 0, len(targets))
+	for _, target := range targets {
+		last := len(batches) - 1
+		if s.batchableTarget(target) && last >= 0 && len(batches[last]) < s.config.Dump.BatchSize && s.batchableTarget(batches[last][0]) && sameRoute(batches[last][0], target) &&
+			s.snapshotRequested(batches[last][0]) == s.snapshotRequested(target) {
+			batches[last] = append(batches[last], target)
+			continue
+		}
+		batches = append(batches, []models.SyncTarget{target})
+	}
+	return batches
+}
+
+// executeBatch выполняет пакет целей: общий mysqlsh dump-schemas и общий load-dump с фильтром схем,
+// а hooks, бэкапы, проверка и результаты остаются отдельными для каждой БД.
+func (s *MySQLShellService) executeBatch(targets []models.SyncTarget, observer models.ProgressObserver) ([]models.SyncResult, error) {
+	if len(targets) == 1 {
+		result, err := s.executeTarget(targets[0], observer)
+		return []models.SyncResult{*result}, err
+	}
+
+	runs := make([]*targetRun, 0, len(targets))
+	for _, target := range targets {
+		run := s.newTargetRun(target, observer)
+		run.dumpResult.Batch = batchDatabaseNames(targets)
+		run.batched = true
+		runs = append(runs, run)
+	}
+	var dumpDir string
+	defer func() {
+		if dumpDir != "" {
+			removeWorkDir(dumpDir)
+		}
+	}()
+
+	// Ошибка одной цели до загрузки останавливает весь пакет: общий дамп без нее не снимается.
+	failBatch := func(failed *targetRun, err error) ([]models.SyncResult, error) {
+		results := make([]models.SyncResult, 0, len(runs))
+		for _, run := range runs {
+			runErr := err
+			if failed != nil && run != failed {
+				runErr = fmt.Errorf("batch aborted by %s: %w", failed.target.DatabaseName, err)
+			}
+			result, _ := run.fail(runErr)
+			results = append(results, *result)
+		}
+		return results, err
+	}
+
+	for _, run := range runs {
+		if err := run.prepare(); err != nil {
+			return failBatch(run, err)
+		}
+	}
+	// Дампы всех БД пакета лежат на диске одновременно до конца загрузки.
+	diskTargets := make([]models.SyncTarget, 0, len(runs))
+	for _, run := range runs {
+		if target, ok := run.diskTarget(); ok {
+			diskTargets = append(diskTargets, target)
+		}
+	}
+	if err := s.checkTargetsDiskSpace(diskTargets, false); err != nil {
+		return failBatch(nil, fmt.Errorf("validation failed: %w", err))
+	}
+	var err error
+	dumpDir, err = s.createBatchDump(runs)
+	if err != nil {
+		return failBatch(nil, fmt.Errorf("dump creation failed: %w", err))
+	}
+	for _, run := range runs {
+		if err := run.afterDump(); err != nil {
+			return failBatch(run, err)
+		}
+	}
+	if err := s.restoreBatch(dumpDir, runs); err != nil {
+		return failBatch(nil, fmt.Errorf("restore failed: %w", err))
+	}
+
+	// Все БД уже загружены, поэтому ошибка проверки одной из них не мешает завершить остальные.
+	results := make([]models.SyncResult, 0, len(runs))
+	var firstErr error
+	for _, run := range runs {
+		result, err := run.finish()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		results = append(results, *result)
+	}
+	return results, firstErr
+}
+
+func batchDatabaseNames(targets []models.SyncTarget) []string {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.DatabaseName)
+	}
+	return names
+}
+
+// createBatchDump снимает один дамп mysqlsh для всех БД пакета и раскладывает объем, трафик
+// и per-table прогресс по результатам целей.
+func (s *MySQLShellService) createBatchDump(runs []*targetRun) (string, error) {
+	startTime := time.Now()
+	observer := runs[0].observer
+	names := runs[0].dumpResult.Batch
+	stats := make([]targetStats, len(runs))
+	var logicalSize int64
+	var tablesCount int
+	for i, run := range runs {
+		var err error
+		if stats[i], err = s.collectTargetStats(run.target); err != nil {
+			return "", fmt.Errorf("%s: %w", run.target.DatabaseName, err)
+		}
+		run.tracker = newTableProgressTracker(run.target.DatabaseName, stats[i].tables)
+		logicalSize += stats[i].logicalSize
+		tablesCount += stats[i].tablesCount
+	}
+
+	engine := mysqlShellEngine{service: s}
+	// Один снимок на весь пакет: схемы пакета согласованы и между собой.
+	snapshot := s.planSnapshot(runs[0].target, engine.Name())
+	s.warnSnapshot(fmt.Sprintf("batch of %d databases", len(runs)), snapshot)
+	fixes := s.newCompatFixes(engine.Name())
+	dumpDir, err := s.createWorkDir(fmt.Sprintf("%s_batch_%d", engine.Name(), time.Now().Unix()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create dump directory: %w", err)
+	}
+
+	conn, tunnel, cleanup, err := s.remoteDumpConn()
+	if err != nil {
+		removeWorkDir(dumpDir)
+		return "", err
+	}
+	defer cleanup()
+
+	s.printStatusf("📦 Dumping %d databases (%d tables) in one batch...", len(runs), tablesCount)
+	stopLiveProgress := make(chan struct{})
+	defer close(stopLiveProgress)
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseDump, DatabaseName: runs[0].target.DatabaseName, Message: "Streaming batch dump", BytesTotal: logicalSize, Traffic: tunnel.Metrics(), Timestamp: time.Now()})
+		// Общие снимки идут без имени БД, per-table прогресс — отдельно для каждой БД пакета.
+		go emitTrafficSnapshots(stopLiveProgress, 250*time.Millisecond, "", logicalSize, tunnel.Metrics, observer)
+		for _, run := range runs {
+			tracker := run.tracker
+			go emitTableProgressSnapshots(stopLiveProgress, models.SyncPhaseDump, run.target.DatabaseName, tracker, func(now time.Time) bool {
+				return tracker.ObserveDumpDir(dumpDir, now)
+			}, observer)
+		}
+	}
+
+	err = engine.Dump(DumpRequest{
+		Conn:            conn,
+		Schemas:         names,
+		Dir:             dumpDir,
+		Threads:         s.effectiveDumpThreads(logicalSize),
+		Compress:        s.config.Dump.Compress,
+		NetworkCompress: s.config.Dump.NetworkCompress,
+		TableCount:      tablesCount,
+		Phase:           models.SyncPhaseDump,
+		MetricsFn:       tunnel.Metrics,
+		Consistent:      snapshot.Consistent,
+		Compat:          &s.config.Compat,
+		Fixes:           fixes,
+	}, observer)
+	if err == nil && s.config.Compat.Definers == config.DefinersRewrite {
+		err = rewriteDumpDefiners(dumpDir, s.config.Compat.DefinerClause(), fixes)
+	}
+	if err == nil {
+		err = writeDumpEngineMarker(dumpDir, engine)
+	}
+	if err != nil {
+		removeWorkDir(dumpDir)
+		return "", err
+	}
+
+	endTime := time.Now()
+	traffic := tunnel.Metrics()
+	readDumpSnapshot(engine.Name(), dumpDir, "", snapshot)
+	s.printStatusf("\r✅ Dumped %d databases (%d tables) in %v\n", len(runs), tablesCount, endTime.Sub(startTime).Round(time.Second))
+	for i, run := range runs {
+		run.tracker.ObserveDumpDir(dumpDir, endTime)
+		run.tracker.FinishDump(endTime)
+		tables := run.tracker.Snapshot(endTime)
+		var dumpSize int64
+		for _, table := range tables {
+			dumpSize += table.DumpBytes
+		}
+
+		result := run.dumpResult
+		result.Duration = endTime.Sub(startTime)
+		result.DumpSize = dumpSize
+		result.DumpSizeOnDisk = dumpSize
+		result.LogicalSize = stats[i].logicalSize
+		result.IndexSize = stats[i].indexSize
+		result.TablesCount = stats[i].tablesCount
+		result.TransportMode = tunnel.TransportMode()
+		result.Traffic = shareTraffic(traffic, stats[i].logicalSize, logicalSize)
+		runSnapshot := *snapshot
+		result.Snapshot = &runSnapshot
+		result.Compatibility = fixes.forSchema(run.target.DatabaseName)
+		if stats[i].logicalSize > 0 {
+			result.CompressionRatio = float64(dumpSize) / float64(stats[i].logicalSize)
+		}
+		run.env.DumpDir = dumpDir
+		if observer != nil {
+			observer(models.ProgressSnapshot{Phase: models.SyncPhaseDump, DatabaseName: run.target.DatabaseName, Message: "Dump complete", Percent: 100, BytesCompleted: dumpSize, BytesTotal: dumpSize, Traffic: result.Traffic, Tables: tables, Timestamp: endTime})
+		}
+	}
+	return dumpDir, nil
+}
+
+// shareTraffic выделяет цели долю общего трафика туннеля пропорционально ее логическому размеру.
+func shareTraffic(traffic models.TrafficMetrics, part int64, total int64) models.TrafficMetrics {
+	if total <= 0 {
+		return traffic
+	}
+	fraction := float64(part) / float64(total)
+	traffic.BytesIn = int64(float64(traffic.BytesIn) * fraction)
+	traffic.BytesOut = int64(float64(traffic.BytesOut) * fraction)
+	return traffic
+}
+
+// restoreBatch пересоздает локальные БД пакета и загружает их одним load-dump с фильтром схем.
+func (s *MySQLShellService) restoreBatch(dumpDir string, runs []*targetRun) error {
+	startTime := time.Now()
+	observer := runs[0].observer
+	for _, run := range runs {
+		run.localReplaced = true
+		if err := s.prepareLocalDatabase(run.target.DatabaseName); err != nil {
+			return fmt.Errorf("%s: %w", run.target.DatabaseName, err)
+		}
+	}
+
+	s.printStatusf("🔄 Restoring %d databases...", len(runs))
+	stopTableProgress := make(chan struct{})
+	defer close(stopTableProgress)
+	if observer != nil {
+		progressFile := loadProgressFilePath(dumpDir)
+		for _, run := range runs {
+			tracker := run.tracker
+			go emitTableProgressSnapshots(stopTableProgress, models.SyncPhaseRestore, run.target.DatabaseName, tracker, func(now time.Time) bool {
+				return tracker.ObserveLoadProgress(progressFile, now)
+			}, observer)
+		}
+	}
+
+	tuning, err := s.withLoadTuning(config.DumpEngineMySQLShell, func() error {
+		return mysqlShellEngine{service: s}.Load(LoadRequest{
+			Schemas: runs[0].dumpResult.Batch,
+			Dir:     dumpDir,
+			Threads: s.config.Dump.Threads,
+		}, observer)
+	})
+	for _, run := range runs {
+		run.tuning = tuning
+	}
+	if err != nil {
+		return err
+	}
+
+	finishedAt := time.Now()
+	s.printStatusf("\r✅ Restored %d databases in %v                    \n", len(runs), finishedAt.Sub(startTime).Round(time.Second))
+	for _, run := range runs {
+		run.tracker.ObserveLoadProgress(loadProgressFilePath(dumpDir), finishedAt)
+		run.tracker.FinishLoad(finishedAt)
+		run.restoreDuration = finishedAt.Sub(startTime)
+		if observer != nil {
+			observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: run.target.DatabaseName, Message: "Restore complete", Percent: 100, Tables: run.tracker.Snapshot(finishedAt), Timestamp: finishedAt})
+		}
+	}
+	return nil
+}
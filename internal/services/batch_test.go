@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func TestPlanBatches(t *testing.T) {
+	installFakeTools(t, map[string]string{"mysqlsh": ""})
+	service := NewMySQLShellService(&config.Config{Dump: config.DumpConfig{Engine: config.DumpEngineAuto, BatchSize: 2}}, nil)
+	targets := []models.SyncTarget{
+		{DatabaseName: "a", ReplaceEntireDatabase: true},
+		{DatabaseName: "b", ReplaceEntireDatabase: true},
+		{DatabaseName: "c", ReplaceEntireDatabase: true},
+		{DatabaseName: "d", SelectedTables: []string{"users"}},
+		{DatabaseName: "e", ReplaceEntireDatabase: true},
+	}
+
+	var groups []string
+	for _, batch := range service.planBatches(targets) {
+		groups = append(groups, strings.Join(batchDatabaseNames(batch), "+"))
+	}
+	if got := strings.Join(groups, " "); got != "a+b c d e" {
+		t.Fatalf("planBatches() = %q, want %q", got, "a+b c d e")
+	}
+
+	service.config.Smart.Enabled = true
+	if batches := service.planBatches(targets); len(batches) != len(targets) {
+		t.Fatalf("staged syncs must not be batched, got %d batches", len(batches))
+	}
+	service.config.Smart.Enabled = false
+	service.config.Dump.Engine = config.DumpEngineMysqldump
+	installFakeTools(t, map[string]string{"mysqlsh": "", "mysqldump": "", "mysql": ""})
+	if batches := service.planBatches(targets); len(batches) != len(targets) {
+		t.Fatalf("only mysqlsh dumps can be batched, got %d batches", len(batches))
+	}
+
+	service.config.Dump.Engine = config.DumpEngineAuto
+	routed := []models.SyncTarget{
+		{DatabaseName: "a", ReplaceEntireDatabase: true},
+		{DatabaseName: "b", ReplaceEntireDatabase: true, Destination: "staging"},
+		{DatabaseName: "c", ReplaceEntireDatabase: true, Destination: "staging"},
+	}
+	if batches := service.planBatches(routed); len(batches) != 2 || len(batches[1]) != 2 {
+		t.Fatalf("targets with different destinations must not share a batch, got %d batches", len(batches))
+	}
+}
+
+func TestBatchDumpAndLoadArgs(t *testing.T) {
+	service := NewMySQLShellService(&config.Config{}, nil)
+	joined := strings.Join(service.mysqlShellDumpArgs("mysql://reader@127.0.0.1:41000", DumpRequest{Schemas: []string{"a", "b"}, Dir: "/tmp/batch", Threads: 4}), " ")
+	if !strings.Contains(joined, "util dump-schemas a,b --outputUrl=/tmp/batch --threads=4") {
+		t.Fatalf("batch dump must list all schemas: %s", joined)
+	}
+
+	traffic := shareTraffic(models.TrafficMetrics{Mode: models.TransportModeProxy, BytesIn: 1000, BytesOut: 100}, 250, 1000)
+	if traffic.BytesIn != 250 || traffic.BytesOut != 25 || traffic.Mode != models.TransportModeProxy {
+		t.Fatalf("unexpected traffic share: %+v", traffic)
+	}
+}
+
+func TestExecutePlanAbortsWholeBatch(t *testing.T) {
+	installFakeTools(t, map[string]string{"mysqlsh": ""})
+	dbService := &mocks.MockDatabaseService{ValidateNameError: errors.New("bad name")}
+	service := NewMySQLShellService(&config.Config{Dump: config.DumpConfig{BatchSize: 4}}, dbService)
+	service.SetQuiet(true)
+
+	plan := &models.SyncPlan{Targets: []models.SyncTarget{
+		{DatabaseName: "a", ReplaceEntireDatabase: true},
+		{DatabaseName: "b", ReplaceEntireDatabase: true},
+		{DatabaseName: "c", SelectedTables: []string{"users"}},
+	}}
+	results, err := service.ExecutePlan(plan, models.RuntimeOptions{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "bad name") {
+		t.Fatalf("expected batch to fail validation, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("failed batch must return a result per database and stop the plan, got %+v", results)
+	}
+	if results[0].DatabaseName != "a" || !strings.HasPrefix(results[0].Error, "validation failed") || strings.Join(results[0].Batch, ",") != "a,b" {
+		t.Fatalf("unexpected failed target result: %+v", results[0])
+	}
+	if results[1].DatabaseName != "b" || results[1].Success || !strings.HasPrefix(results[1].Error, "batch aborted by a: ") {
+		t.Fatalf("other batch targets must be aborted, got %+v", results[1])
+	}
+}
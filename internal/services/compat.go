@@ -0,0 +1,215 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+var (
+	// mysqlShellCompatNotePattern — NOTE, которые mysqlsh печатает на каждое исправление compatibility:
+	// "View `shop`.`v` had definer clause removed", "Table `shop`.`t` had unsupported engine MyISAM
+	// changed to InnoDB", "User 'app'@'%' had restricted privileges (SUPER) removed".
+	mysqlShellCompatNotePattern = regexp.MustCompile("^NOTE: (Table|View|Function|Procedure|Event|Trigger|User|Account) ((?:`(?:[^`]|``)*`)(?:\\.`(?:[^`]|``)*`)*|'[^']*'@'[^']*') (.+?)\\.?$")
+	dumpDefinerPattern          = regexp.MustCompile("DEFINER=(`(?:[^`]|``)*`@`(?:[^`]|``)*`)")
+)
+
+// compatFixes собирает исправления совместимости одного дампа; вывод mysqlsh разбирается
+// одновременно из stdout и stderr.
+type compatFixes struct {
+	mu    sync.Mutex
+	fixes []models.CompatibilityFix
+}
+
+// newCompatFixes создает сборщик исправлений дампа движком engineName. Пропущенные типы объектов
+// и выбор native в auto попадают в отчет сразу: их определяет сам дамп.
+func (s *MySQLShellService) newCompatFixes(engineName string) *compatFixes {
+	fixes := &compatFixes{}
+	if s.nativeFallback(engineName) {
+		fixes.add(models.CompatibilityFix{Object: "dump engine", Fix: "native selected by auto: " + nativeFallbackReason})
+	}
+	compat := s.config.Compat
+	for _, skipped := range []struct {
+		skip   bool
+		object string
+		env    string
+	}{
+		{compat.SkipRoutines, "routines", "DBSYNC_COMPAT_SKIP_ROUTINES"},
+		{compat.SkipEvents, "events", "DBSYNC_COMPAT_SKIP_EVENTS"},
+		{compat.SkipTriggers, "triggers", "DBSYNC_COMPAT_SKIP_TRIGGERS"},
+	} {
+		if skipped.skip {
+			fixes.add(models.CompatibilityFix{Object: skipped.object, Fix: "skipped by " + skipped.env})
+		}
+	}
+	return fixes
+}
+
+// add добавляет исправление; несколько исправлений одного объекта объединяются в одну запись.
+func (c *compatFixes) add(fix models.CompatibilityFix) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.fixes {
+		existing := &c.fixes[i]
+		if existing.Schema == fix.Schema && existing.Object == fix.Object {
+			if !strings.Contains(existing.Fix, fix.Fix) {
+				existing.Fix += ", " + fix.Fix
+			}
+			return
+		}
+	}
+	c.fixes = append(c.fixes, fix)
+}
+
+// forSchema возвращает исправления схемы schema и общие для всего дампа.
+func (c *compatFixes) forSchema(schema string) []models.CompatibilityFix {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var fixes []models.CompatibilityFix
+	for _, fix := range c.fixes {
+		if fix.Schema == "" || fix.Schema == schema {
+			fixes = append(fixes, fix)
+		}
+	}
+	return fixes
+}
+
+// inspectMySQLShell записывает исправление из NOTE строки вывода mysqlsh.
+func (c *compatFixes) inspectMySQLShell(line string) {
+	if fix, ok := parseMySQLShellCompatNote(line); ok {
+		c.add(fix)
+	}
+}
+
+func parseMySQLShellCompatNote(line string) (models.CompatibilityFix, bool) {
+	match := mysqlShellCompatNotePattern.FindStringSubmatch(line)
+	if match == nil {
+		return models.CompatibilityFix{}, false
+	}
+	fix := models.CompatibilityFix{
+		Object: strings.ToLower(match[1]) + " " + match[2],
+		Fix:    strings.TrimPrefix(match[3], "had "),
+	}
+	if strings.HasPrefix(match[2], "`") {
+		schema, _, _ := strings.Cut(strings.TrimPrefix(match[2], "`"), "`.")
+		fix.Schema = strings.TrimSuffix(strings.ReplaceAll(schema, "``", "`"), "`")
+	}
+	return fix, true
+}
+
+// mysqlShellCompatArgs переводит compat в опции util dump-schemas и copy-schemas. DEFINER rewrite
+// mysqlsh не умеет: его выполняет rewriteDumpDefiners по файлам дампа.
+func mysqlShellCompatArgs(compat config.CompatConfig) []string {
+	var args []string
+	if options := compat.MySQLShellOptions(); len(options) > 0 {
+		args = append(args, "--compatibility="+strings.Join(options, ","))
+	}
+	if compat.SkipRoutines {
+		args = append(args, "--routines=false")
+	}
+	if compat.SkipEvents {
+		args = append(args, "--events=false")
+	}
+	if compat.SkipTriggers {
+		args = append(args, "--triggers=false")
+	}
+	return args
+}
+
+// ignoredCompat перечисляет настройки compat, которые движок engineName не применяет.
+// Native сам создает представления без DEFINER, поэтому режим definers для него не важен.
+func ignoredCompat(compat config.CompatConfig, engineName string) []string {
+	if engineName == config.DumpEngineMySQLShell {
+		return nil
+	}
+	var ignored []string
+	if compat.Definers != "" && compat.Definers != config.DefinersKeep && engineName != config.DumpEngineNative {
+		ignored = append(ignored, "definers="+compat.Definers)
+	}
+	for _, option := range compat.MySQLShellOptions() {
+		if option != "strip_definers" {
+			ignored = append(ignored, option)
+		}
+	}
+	return ignored
+}
+
+// warnIgnoredCompat предупреждает, что исправления совместимости не будут применены движком дампа.
+func (s *MySQLShellService) warnIgnoredCompat(engineName string) {
+	if ignored := ignoredCompat(s.config.Compat, engineName); len(ignored) > 0 {
+		s.printStatusf("⚠️  The %s engine does not apply %s, objects are dumped as is (use DBSYNC_DUMP_ENGINE=mysqlsh)\n", engineName, strings.Join(ignored, ", "))
+	}
+}
+
+// rewriteDumpDefiners заменяет DEFINER в DDL файлах дампа mysqlsh на clause. Файлы схем называются
+// <schema>.sql и <schema>@<object>.sql; общие файлы дампа начинаются с @ и не меняются.
+// mysqlsh проверяет контрольные суммы только у файлов данных, поэтому DDL можно править до загрузки.
+func rewriteDumpDefiners(dir string, clause string, fixes *compatFixes) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read dump directory: %w", err)
+	}
+	counts := make(map[[2]string]int)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") || strings.HasPrefix(name, "@") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		schema, _, _ := strings.Cut(strings.TrimSuffix(name, ".sql"), "@")
+		if decoded, err := url.PathUnescape(schema); err == nil {
+			schema = decoded
+		}
+		changed := false
+		rewritten := dumpDefinerPattern.ReplaceAllStringFunc(string(data), func(definer string) string {
+			account := strings.TrimPrefix(definer, "DEFINER=")
+			if account == clause {
+				return definer
+			}
+			counts[[2]string{schema, account}]++
+			changed = true
+			return "DEFINER=" + clause
+		})
+		if !changed {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", name, err)
+		}
+		if err := os.WriteFile(path, []byte(rewritten), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to rewrite definers in %s: %w", name, err)
+		}
+	}
+
+	keys := make([][2]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return slices.Compare(keys[i][:], keys[j][:]) < 0 })
+	for _, key := range keys {
+		objects := "objects"
+		if counts[key] == 1 {
+			objects = "object"
+		}
+		fixes.add(models.CompatibilityFix{
+			Schema: key[0],
+			Object: "definer " + key[1],
+			Fix:    fmt.Sprintf("rewritten to %s in %d %s", clause, counts[key], objects),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+func TestParseMySQLShellCompatNote(t *testing.T) {
+	tests := []struct {
+		line string
+		want models.CompatibilityFix
+	}{
+		{"NOTE: View `shop`.`sales_report` had definer clause removed", models.CompatibilityFix{Schema: "shop", Object: "view `shop`.`sales_report`", Fix: "definer clause removed"}},
+		{"NOTE: Table `shop`.`logs` had unsupported engine MyISAM changed to InnoDB", models.CompatibilityFix{Schema: "shop", Object: "table `shop`.`logs`", Fix: "unsupported engine MyISAM changed to InnoDB"}},
+		{"NOTE: User 'app'@'10.%' had restricted privileges (SUPER) removed", models.CompatibilityFix{Object: "user 'app'@'10.%'", Fix: "restricted privileges (SUPER) removed"}},
+	}
+	for _, tt := range tests {
+		got, ok := parseMySQLShellCompatNote(tt.line)
+		if !ok || got != tt.want {
+			t.Errorf("parseMySQLShellCompatNote(%q) = %+v, %v; want %+v", tt.line, got, ok, tt.want)
+		}
+	}
+	if _, ok := parseMySQLShellCompatNote("NOTE: Backup lock is not supported in MySQL 5.7"); ok {
+		t.Error("notes without a fixed object must be ignored")
+	}
+}
+
+func TestCompatFixes(t *testing.T) {
+	service := NewMySQLShellService(&config.Config{Compat: config.CompatConfig{SkipEvents: true}}, nil)
+	fixes := service.newCompatFixes(config.DumpEngineMySQLShell)
+	fixes.inspectMySQLShell("NOTE: Procedure `shop`.`refresh` had definer clause removed")
+	fixes.inspectMySQLShell("NOTE: Procedure `shop`.`refresh` had SQL SECURITY characteristic set to INVOKER")
+	fixes.inspectMySQLShell("NOTE: View `crm`.`leads` had definer clause removed")
+
+	want := []models.CompatibilityFix{
+		{Object: "events", Fix: "skipped by DBSYNC_COMPAT_SKIP_EVENTS"},
+		{Schema: "shop", Object: "procedure `shop`.`refresh`", Fix: "definer clause removed, SQL SECURITY characteristic set to INVOKER"},
+	}
+	if got := fixes.forSchema("shop"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("forSchema(shop) = %+v, want %+v", got, want)
+	}
+	native := service.newCompatFixes(config.DumpEngineNative).forSchema("shop")
+	if len(native) != 2 || native[0].Object != "dump engine" || native[1].Object != "events" {
+		t.Fatalf("native chosen by auto must be reported along with skipped events: %+v", native)
+	}
+	service.config.Dump.Engine = config.DumpEngineNative
+	if native := service.newCompatFixes(config.DumpEngineNative).forSchema("shop"); len(native) != 1 {
+		t.Fatalf("explicit native engine is not a fallback: %+v", native)
+	}
+}
+
+func TestMySQLShellCompatArgs(t *testing.T) {
+	service := NewMySQLShellService(&config.Config{}, nil)
+	compat := config.CompatConfig{Definers: config.DefinersStrip, ForceInnoDB: true, SkipRoutines: true, SkipTriggers: true}
+	request := DumpRequest{DatabaseName: "shop", Dir: "/tmp/dump", Compat: &compat}
+
+	joined := strings.Join(service.mysqlShellDumpArgs("mysql://reader@127.0.0.1:3306", request), " ")
+	if !strings.Contains(joined, "--compatibility=strip_definers,force_innodb --routines=false --triggers=false") || strings.Contains(joined, "--events=") {
+		t.Fatalf("unexpected compatibility args: %s", joined)
+	}
+	request.Compat = nil
+	if joined := strings.Join(service.mysqlShellDumpArgs("mysql://reader@127.0.0.1:3306", request), " "); strings.Contains(joined, "--compatibility") || strings.Contains(joined, "=false --compression") {
+		t.Fatalf("local backups must be dumped as is: %s", joined)
+	}
+
+	request.Compat = &compat
+	request.Conn = config.MySQLConfig{Host: "127.0.0.1", Port: 3306, User: "reader"}
+	if joined := strings.Join(mydumperArgs(request), " "); strings.Contains(joined, "--routines") || strings.Contains(joined, "--triggers") || !strings.Contains(joined, "--events") {
+		t.Fatalf("unexpected mydumper object args: %s", joined)
+	}
+	joined = strings.Join(mysqldumpArgs(request), " ")
+	if strings.Contains(joined, "--routines") || strings.Contains(joined, " --triggers") || !strings.Contains(joined, "--events --skip-triggers") {
+		t.Fatalf("unexpected mysqldump object args: %s", joined)
+	}
+
+	if ignored := ignoredCompat(compat, config.DumpEngineMydumper); !reflect.DeepEqual(ignored, []string{"definers=strip", "force_innodb"}) {
+		t.Fatalf("unexpected ignored options for mydumper: %v", ignored)
+	}
+	if ignored := ignoredCompat(compat, config.DumpEngineNative); !reflect.DeepEqual(ignored, []string{"force_innodb"}) {
+		t.Fatalf("native strips view definers itself, got %v", ignored)
+	}
+}
+
+func TestRewriteDumpDefiners(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"shop.sql":            "CREATE DEFINER=`prod_app`@`10.%` PROCEDURE `refresh`() BEGIN END;;\nCREATE DEFINER=`prod_app`@`10.%` EVENT `cleanup` ON SCHEDULE EVERY 1 DAY DO DELETE FROM logs;;\n",
+		"shop@report.sql":     "/*!50013 DEFINER=`admin`@`%` SQL SECURITY DEFINER */\n/*!50001 VIEW `report` AS select 1 */;\n",
+		"shop@orders.tsv.zst": "DEFINER=`prod_app`@`10.%`",
+		"@.post.sql":          "DEFINER=`prod_app`@`10.%`",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fixes := &compatFixes{}
+	if err := rewriteDumpDefiners(dir, config.DefinerCurrentUser, fixes); err != nil {
+		t.Fatalf("rewriteDumpDefiners() error = %v", err)
+	}
+	schemaDDL, _ := os.ReadFile(filepath.Join(dir, "shop.sql"))
+	if strings.Contains(string(schemaDDL), "prod_app") || strings.Count(string(schemaDDL), "DEFINER=CURRENT_USER") != 2 {
+		t.Fatalf("definers were not rewritten: %s", schemaDDL)
+	}
+	for _, name := range []string{"shop@orders.tsv.zst", "@.post.sql"} {
+		if data, _ := os.ReadFile(filepath.Join(dir, name)); string(data) != files[name] {
+			t.Fatalf("%s must stay untouched, got %s", name, data)
+		}
+	}
+	want := []models.CompatibilityFix{
+		{Schema: "shop", Object: "definer `admin`@`%`", Fix: "rewritten to CURRENT_USER in 1 object"},
+		{Schema: "shop", Object: "definer `prod_app`@`10.%`", Fix: "rewritten to CURRENT_USER in 2 objects"},
+	}
+	if got := fixes.forSchema("shop"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("fixes = %+v, want %+v", got, want)
+	}
+}
+
+func TestMySQLShellDumpCollectsCompatNotes(t *testing.T) {
+	service := NewMySQLShellService(&config.Config{}, nil)
+	service.SetQuiet(true)
+	installFakeTools(t, map[string]string{"mysqlsh": "echo 'NOTE: View `shop`.`report` had definer clause removed'; echo 'NOTE: Table `shop`.`logs` had unsupported engine MyISAM changed to InnoDB' >&2"})
+
+	fixes := &compatFixes{}
+	err := mysqlShellEngine{service: service}.Dump(DumpRequest{DatabaseName: "shop", Dir: t.TempDir(), Phase: models.SyncPhaseDump, Compat: &config.CompatConfig{Definers: config.DefinersStrip, ForceInnoDB: true}, Fixes: fixes}, nil)
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+	if got := fixes.forSchema("shop"); len(got) != 2 {
+		t.Fatalf("notes from stdout and stderr must be collected without an observer, got %+v", got)
+	}
+}
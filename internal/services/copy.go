@@ -0,0 +1,237 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// mysqlShellCopyMinVersion — первая версия MySQL Shell с util.copySchemas и util.copyTables.
+var mysqlShellCopyMinVersion = [3]int{8, 1, 0}
+
+var (
+	mysqlShellVersionPattern = regexp.MustCompile(`Ver\s+(\d+)\.(\d+)\.(\d+)`)
+	// mysqlShellCopyUnsupportedPattern распознает ответ mysqlsh, который не знает команду copy-schemas.
+	mysqlShellCopyUnsupportedPattern = regexp.MustCompile(`(?i)(copy-schemas|copySchemas).*(invalid|unknown|not (found|supported|available)|no (such )?function)|(invalid|unknown|no (such )?function).*(copy-schemas|copySchemas)`)
+
+	errStreamCopyUnsupported = errors.New("streaming copy is not supported")
+)
+
+// streamCopyAvailable проверяет, можно ли синхронизировать цель потоковым копированием без директории дампа.
+// Копирование пересоздает локальную БД целиком, поэтому staging-загрузки (incremental, smart sync) идут через дамп.
+// Решение принимается до удаления локальной БД: после него откатиться на дамп уже нельзя.
+func (s *MySQLShellService) streamCopyAvailable(target models.SyncTarget, incremental *models.IncrementalPlan) error {
+	engine, err := s.dumpEngine()
+	if err != nil {
+		return err
+	}
+	if engine.Name() != config.DumpEngineMySQLShell {
+		return fmt.Errorf("%w by the %s dump engine", errStreamCopyUnsupported, engine.Name())
+	}
+	if incremental.Active() || len(target.SkipTables) > 0 {
+		return fmt.Errorf("%w for staged loads", errStreamCopyUnsupported)
+	}
+	// DEFINER переписывается в файлах дампа, а copy-schemas их не создает.
+	if s.config.Compat.Definers == config.DefinersRewrite {
+		return fmt.Errorf("%w with DEFINER rewrite", errStreamCopyUnsupported)
+	}
+	version, err := s.mysqlShellVersion()
+	if err != nil {
+		return err
+	}
+	if compareVersions(version, mysqlShellCopyMinVersion) < 0 {
+		return fmt.Errorf("%w by mysqlsh %d.%d.%d, need %d.%d.%d or newer", errStreamCopyUnsupported,
+			version[0], version[1], version[2], mysqlShellCopyMinVersion[0], mysqlShellCopyMinVersion[1], mysqlShellCopyMinVersion[2])
+	}
+	return s.probeMySQLShellCopy()
+}
+
+// probeMySQLShellCopy спрашивает у mysqlsh справку по util copy-schemas: сборки, где команды нет,
+// отвечают ошибкой, и это видно без подключения к серверу.
+func (s *MySQLShellService) probeMySQLShellCopy() error {
+	mysqlshPath, err := s.findMySQLShell()
+	if err != nil {
+		return err
+	}
+	output, err := s.mysqlshCommand(mysqlshPath, "--", "util", "copy-schemas", "--help").CombinedOutput()
+	if err != nil || mysqlShellCopyUnsupportedPattern.Match(output) {
+		return fmt.Errorf("%w by this mysqlsh build: %s", errStreamCopyUnsupported, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// mysqlShellVersion возвращает версию установленного mysqlsh из вывода --version.
+func (s *MySQLShellService) mysqlShellVersion() ([3]int, error) {
+	mysqlshPath, err := s.findMySQLShell()
+	if err != nil {
+		return [3]int{}, err
+	}
+	output, err := s.mysqlshCommand(mysqlshPath, "--version").CombinedOutput()
+	if err != nil {
+		return [3]int{}, fmt.Errorf("failed to get mysqlsh version: %w", err)
+	}
+	return parseMySQLShellVersion(string(output))
+}
+
+func parseMySQLShellVersion(output string) ([3]int, error) {
+	match := mysqlShellVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return [3]int{}, fmt.Errorf("unexpected mysqlsh version output: %s", strings.TrimSpace(output))
+	}
+	var version [3]int
+	for i := range version {
+		version[i], _ = strconv.Atoi(match[i+1])
+	}
+	return version, nil
+}
+
+func compareVersions(a [3]int, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
+
+// localCopyURI строит URI локального сервера с паролем: util copy-schemas принимает цель только позиционным аргументом.
+func (s *MySQLShellService) localCopyURI() string {
+	local := s.config.Local
+	return (&url.URL{
+		Scheme: "mysql",
+		User:   url.UserPassword(local.User, local.Password),
+		Host:   net.JoinHostPort(local.Host, strconv.Itoa(local.Port)),
+	}).String()
+}
+
+// mysqlShellCopyArgs строит аргументы util copy-schemas: опции дампа читают remote через туннель,
+// опции загрузки пишут в локальный сервер, данные не касаются диска.
+func (s *MySQLShellService) mysqlShellCopyArgs(conn config.MySQLConfig, databaseName string, tables []string, threads int, consistent bool) []string {
+	args := []string{
+		"--uri", s.buildURI(conn, conn.Host, conn.Port),
+		fmt.Sprintf("--password=%s", conn.Password),
+	}
+	args = append(args, s.transportCompressionArgs()...)
+	args = append(args,
+		"--", "util", "copy-schemas", databaseName, s.localCopyURI(),
+		fmt.Sprintf("--threads=%d", max(threads, 1)),
+	)
+	args = append(args, mysqlShellConsistencyArgs(consistent)...)
+	args = append(args, mysqlShellCompatArgs(s.config.Compat)...)
+	args = append(args,
+		"--deferTableIndexes=all", // Создаём индексы после данных
+		"--ignoreVersion",         // Игнорируем разницу версий MySQL
+		"--skipBinlog=true",       // Пропускаем запись в binlog
+	)
+	if len(tables) > 0 {
+		qualified := make([]string, 0, len(tables))
+		for _, tableName := range tables {
+			qualified = append(qualified, fmt.Sprintf("%s.%s", databaseName, tableName))
+		}
+		args = append(args, "--includeTables="+strings.Join(qualified, ","))
+	}
+	return args
+}
+
+// copyTarget переносит цель из remote в локальную БД одним util copy-schemas через proxy tunnel.
+// Поддержку copy-schemas заранее проверяет streamCopyAvailable.
+func (s *MySQLShellService) copyTarget(target models.SyncTarget, observer models.ProgressObserver) (*models.SyncResult, *tableProgressTracker, error) {
+	startTime := time.Now()
+	databaseName := target.DatabaseName
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhasePlanning, DatabaseName: databaseName, Message: "Preparing streaming copy", Timestamp: startTime})
+	}
+
+	stats, err := s.collectTargetStats(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	tracker := newTableProgressTracker(databaseName, stats.tables)
+	mysqlshPath, err := s.findMySQLShell()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.prepareLocalDatabase(databaseName); err != nil {
+		return nil, nil, err
+	}
+
+	snapshot := s.planSnapshot(target, config.DumpEngineMySQLShell)
+	s.warnSnapshot(databaseName, snapshot)
+	fixes := s.newCompatFixes(config.DumpEngineMySQLShell)
+
+	conn, tunnel, cleanup, err := s.remoteDumpConn()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cleanup()
+
+	s.printStatusf("🚚 Copying %s (%d tables)...", databaseName, stats.tablesCount)
+	if observer != nil {
+		observer(models.ProgressSnapshot{
+			Phase:        models.SyncPhaseDump,
+			DatabaseName: databaseName,
+			Message:      "Streaming copy to local MySQL",
+			BytesTotal:   stats.logicalSize,
+			Traffic:      tunnel.Metrics(),
+			Timestamp:    time.Now(),
+		})
+	}
+
+	stopLiveProgress := make(chan struct{})
+	defer close(stopLiveProgress)
+	if observer != nil {
+		go emitTrafficSnapshots(stopLiveProgress, 250*time.Millisecond, databaseName, stats.logicalSize, tunnel.Metrics, observer)
+	}
+
+	err = s.runEngineCommand(engineCommand{
+		cmd:          s.mysqlshCommand(mysqlshPath, s.mysqlShellCopyArgs(conn, databaseName, stats.effectiveTables, s.effectiveDumpThreads(stats.logicalSize), snapshot.Consistent)...),
+		tool:         "mysqlsh",
+		operation:    "copy",
+		phase:        models.SyncPhaseDump,
+		databaseName: databaseName,
+		metricsFn:    tunnel.Metrics,
+		parse:        mysqlShellLineParser(models.SyncPhaseDump),
+		inspect:      fixes.inspectMySQLShell,
+	}, observer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	endTime := time.Now()
+	tracker.FinishDump(endTime)
+	tracker.FinishLoad(endTime)
+	s.printStatusf("\r✅ Copied %s (%d tables) in %v                    \n", databaseName, stats.tablesCount, endTime.Sub(startTime).Round(time.Second))
+
+	// Время копирования учитывается как restore phase: отдельной фазы дампа нет.
+	// GTID снимка copy-schemas не сохраняет: метаданных дампа на диске нет.
+	result := &models.SyncResult{
+		Success:            true,
+		DatabaseName:       databaseName,
+		LogicalSize:        stats.logicalSize,
+		IndexSize:          stats.indexSize,
+		TablesCount:        stats.tablesCount,
+		SelectedTables:     append([]string(nil), target.SelectedTables...),
+		AutoIncludedTables: append([]string(nil), target.AutoIncludedTables...),
+		TransportMode:      tunnel.TransportMode(),
+		Traffic:            tunnel.Metrics(),
+		Tables:             tracker.Snapshot(endTime),
+		StreamCopy:         true,
+		Snapshot:           snapshot,
+		Compatibility:      fixes.forSchema(databaseName),
+		StartTime:          startTime,
+		EndTime:            endTime,
+	}
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: databaseName, Message: "Copy complete", Percent: 100, BytesCompleted: stats.logicalSize, BytesTotal: stats.logicalSize, Traffic: result.Traffic, Tables: result.Tables, Timestamp: endTime})
+	}
+	return result, tracker, nil
+}
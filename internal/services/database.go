@@ -3,8 +3,10 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -12,7 +14,7 @@ import (
 	"db-sync-cli/internal/config"
 	"db-sync-cli/internal/models"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 // DatabaseService предоставляет функции для работы с MySQL
@@ -23,6 +25,8 @@ type DatabaseService struct {
 const (
 	exactRowCountConcurrency = 4
 	exactRowCountTimeout     = 1500 * time.Millisecond
+
+	mysqlErrNoSuchTable = 1146
 )
 
 // NewDatabaseService создает новый экземпляр DatabaseService
@@ -41,12 +45,16 @@ func (ds *DatabaseService) mysqlConfig(isRemote bool) config.MySQLConfig {
 }
 
 func (ds *DatabaseService) openConnection(isRemote bool, database string) (*sql.DB, func(), error) {
-	mysqlConfig := ds.mysqlConfig(isRemote)
+	return openMySQLConnection(ds.mysqlConfig(isRemote), database)
+}
+
+// openMySQLConnection открывает пул соединений; при заданном ProxyURL — через туннель, в том числе к приемнику.
+func openMySQLConnection(mysqlConfig config.MySQLConfig, database string) (*sql.DB, func(), error) {
 	host := mysqlConfig.Host
 	port := mysqlConfig.Port
 	cleanup := func() {}
 
-	if isRemote && mysqlConfig.HasProxy() {
+	if mysqlConfig.HasProxy() {
 		tunnel, err := newProxyTunnel(mysqlConfig)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to start proxy tunnel: %w", err)
@@ -217,7 +225,9 @@ func (ds *DatabaseService) ListTables(databaseName string, isRemote bool) ([]mod
 			COALESCE(TABLE_ROWS, 0) AS table_rows,
 			COALESCE(ENGINE, ''),
 			COALESCE(TABLE_COLLATION, ''),
-			COALESCE(DATA_FREE, 0)
+			COALESCE(DATA_FREE, 0),
+			TABLE_TYPE,
+			UPDATE_TIME
 		FROM information_schema.TABLES
 		WHERE TABLE_SCHEMA = ?
 		ORDER BY data_size DESC, TABLE_NAME ASC
@@ -232,6 +242,7 @@ func (ds *DatabaseService) ListTables(databaseName string, isRemote bool) ([]mod
 	tables := make([]models.Table, 0)
 	for rows.Next() {
 		var table models.Table
+		var updateTime sql.NullTime
 		if err := rows.Scan(
 			&table.DatabaseName,
 			&table.Name,
@@ -242,10 +253,15 @@ func (ds *DatabaseService) ListTables(databaseName string, isRemote bool) ([]mod
 			&table.Engine,
 			&table.Collation,
 			&table.DataFree,
+			&table.Type,
+			&updateTime,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan table row: %w", err)
 		}
 		table.RowsApprox = true
+		if updateTime.Valid {
+			table.UpdateTime = updateTime.Time
+		}
 		tables = append(tables, table)
 	}
 
@@ -305,6 +321,486 @@ func (ds *DatabaseService) exactTableRowCount(db *sql.DB, databaseName string, t
 	return count, true
 }
 
+// TableFingerprints возвращает точное число строк, DDL и (для checksumTables) CHECKSUM TABLE.
+// Отсутствующие на сервере таблицы не попадают в результат.
+func (ds *DatabaseService) TableFingerprints(databaseName string, tableNames []string, checksumTables []string, isRemote bool) (map[string]models.TableFingerprint, error) {
+	if len(tableNames) == 0 {
+		return map[string]models.TableFingerprint{}, nil
+	}
+
+	db, cleanup, err := ds.openConnection(isRemote, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer cleanup()
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping server: %w", err)
+	}
+
+	withChecksum := make(map[string]bool, len(checksumTables))
+	for _, tableName := range checksumTables {
+		withChecksum[tableName] = true
+	}
+
+	fingerprints := make(map[string]models.TableFingerprint, len(tableNames))
+	for _, tableName := range tableNames {
+		qualified := quoteIdentifier(databaseName) + "." + quoteIdentifier(tableName)
+
+		var fingerprint models.TableFingerprint
+		var createTable string
+		if err := db.QueryRow("SHOW CREATE TABLE "+qualified).Scan(new(string), &createTable); err != nil {
+			if isMissingTableError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to show create table %s: %w", tableName, err)
+		}
+		fingerprint.DDL = createTable
+
+		if err := db.QueryRow("SELECT COUNT(*) FROM " + qualified).Scan(&fingerprint.Rows); err != nil {
+			return nil, fmt.Errorf("failed to count rows of %s: %w", tableName, err)
+		}
+
+		if withChecksum[tableName] {
+			var checksum sql.NullString
+			if err := db.QueryRow("CHECKSUM TABLE "+qualified).Scan(new(string), &checksum); err != nil {
+				return nil, fmt.Errorf("failed to checksum %s: %w", tableName, err)
+			}
+			fingerprint.Checksum = checksum.String
+		}
+
+		fingerprints[tableName] = fingerprint
+	}
+
+	return fingerprints, nil
+}
+
+// DescribeSchema возвращает структуру базовых таблиц БД: колонки, индексы и число строк.
+func (ds *DatabaseService) DescribeSchema(databaseName string, isRemote bool) ([]models.TableSchema, error) {
+	db, cleanup, err := ds.openConnection(isRemote, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer cleanup()
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping server: %w", err)
+	}
+
+	tableRows, err := db.Query(`
+		SELECT
+			TABLE_NAME,
+			COALESCE(ENGINE, ''),
+			COALESCE(TABLE_COLLATION, ''),
+			COALESCE(TABLE_ROWS, 0)
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ?
+		  AND TABLE_TYPE = 'BASE TABLE'
+		ORDER BY TABLE_NAME
+	`, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tables: %w", err)
+	}
+	defer tableRows.Close()
+
+	tables := make([]models.Table, 0)
+	schemas := make([]models.TableSchema, 0)
+	for tableRows.Next() {
+		var schema models.TableSchema
+		if err := tableRows.Scan(&schema.Name, &schema.Engine, &schema.Collation, &schema.Rows); err != nil {
+			return nil, fmt.Errorf("failed to scan table row: %w", err)
+		}
+		schema.RowsApprox = true
+		schemas = append(schemas, schema)
+		tables = append(tables, models.Table{DatabaseName: databaseName, Name: schema.Name, Rows: schema.Rows, RowsApprox: true})
+	}
+	if err := tableRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table rows: %w", err)
+	}
+
+	ds.enrichExactTableRows(db, databaseName, tables)
+	index := make(map[string]int, len(schemas))
+	for i := range schemas {
+		schemas[i].Rows = tables[i].Rows
+		schemas[i].RowsApprox = tables[i].RowsApprox
+		index[schemas[i].Name] = i
+	}
+
+	columnRows, err := db.Query(`
+		SELECT
+			TABLE_NAME,
+			COLUMN_NAME,
+			ORDINAL_POSITION,
+			COLUMN_TYPE,
+			IS_NULLABLE,
+			COLUMN_DEFAULT,
+			COALESCE(EXTRA, ''),
+			COALESCE(COLLATION_NAME, '')
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, ORDINAL_POSITION
+	`, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query columns: %w", err)
+	}
+	defer columnRows.Close()
+
+	for columnRows.Next() {
+		var tableName, nullable string
+		var defaultValue sql.NullString
+		var column models.ColumnSchema
+		if err := columnRows.Scan(&tableName, &column.Name, &column.Position, &column.Type, &nullable, &defaultValue, &column.Extra, &column.Collation); err != nil {
+			return nil, fmt.Errorf("failed to scan column row: %w", err)
+		}
+		position, ok := index[tableName]
+		if !ok {
+			continue
+		}
+		column.Nullable = nullable == "YES"
+		if defaultValue.Valid {
+			value := defaultValue.String
+			column.Default = &value
+		}
+		schemas[position].Columns = append(schemas[position].Columns, column)
+	}
+	if err := columnRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating column rows: %w", err)
+	}
+
+	indexRows, err := db.Query(`
+		SELECT
+			TABLE_NAME,
+			INDEX_NAME,
+			NON_UNIQUE,
+			COALESCE(COLUMN_NAME, ''),
+			COALESCE(INDEX_TYPE, '')
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX
+	`, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indexes: %w", err)
+	}
+	defer indexRows.Close()
+
+	for indexRows.Next() {
+		var tableName, indexName, columnName, indexType string
+		var nonUnique int
+		if err := indexRows.Scan(&tableName, &indexName, &nonUnique, &columnName, &indexType); err != nil {
+			return nil, fmt.Errorf("failed to scan index row: %w", err)
+		}
+		position, ok := index[tableName]
+		if !ok {
+			continue
+		}
+		indexes := schemas[position].Indexes
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != indexName {
+			indexes = append(indexes, models.IndexSchema{Name: indexName, Unique: nonUnique == 0, Type: indexType})
+		}
+		last := &indexes[len(indexes)-1]
+		last.Columns = append(last.Columns, columnName)
+		schemas[position].Indexes = indexes
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating index rows: %w", err)
+	}
+
+	return schemas, nil
+}
+
+// incrementalColumnRow описывает колонку-кандидата для инкрементальной синхронизации.
+type incrementalColumnRow struct {
+	TableName  string
+	ColumnName string
+	DataType   string
+	ColumnKey  string
+	Extra      string
+}
+
+// IncrementalColumns возвращает таблицы, которые можно синхронизировать инкрементально:
+// с timestampColumn типа DATETIME/TIMESTAMP или с единственным AUTO_INCREMENT первичным ключом.
+func (ds *DatabaseService) IncrementalColumns(databaseName string, timestampColumn string, isRemote bool) (map[string]models.IncrementalColumn, error) {
+	db, cleanup, err := ds.openConnection(isRemote, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer cleanup()
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping server: %w", err)
+	}
+
+	rows, err := db.Query(`
+		SELECT
+			c.TABLE_NAME,
+			c.COLUMN_NAME,
+			c.DATA_TYPE,
+			c.COLUMN_KEY,
+			COALESCE(c.EXTRA, '')
+		FROM information_schema.COLUMNS c
+		JOIN information_schema.TABLES t
+		  ON t.TABLE_SCHEMA = c.TABLE_SCHEMA
+		 AND t.TABLE_NAME = c.TABLE_NAME
+		 AND t.TABLE_TYPE = 'BASE TABLE'
+		WHERE c.TABLE_SCHEMA = ?
+		  AND (c.COLUMN_KEY = 'PRI' OR c.COLUMN_NAME = ?)
+		ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION
+	`, databaseName, timestampColumn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query incremental columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []incrementalColumnRow
+	for rows.Next() {
+		var column incrementalColumnRow
+		if err := rows.Scan(&column.TableName, &column.ColumnName, &column.DataType, &column.ColumnKey, &column.Extra); err != nil {
+			return nil, fmt.Errorf("failed to scan column row: %w", err)
+		}
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating column rows: %w", err)
+	}
+
+	return selectIncrementalColumns(columns, timestampColumn), nil
+}
+
+// selectIncrementalColumns выбирает колонку high-water mark для каждой таблицы.
+// Timestamp-колонка предпочтительнее: она ловит и обновления, а не только вставки.
+// Таблицы без первичного ключа не подходят — upsert по ним дублировал бы строки.
+func selectIncrementalColumns(columns []incrementalColumnRow, timestampColumn string) map[string]models.IncrementalColumn {
+	type candidate struct {
+		primaryKeys int
+		primaryKey  incrementalColumnRow
+		timestamp   string
+	}
+	candidates := make(map[string]*candidate)
+	for _, column := range columns {
+		entry, ok := candidates[column.TableName]
+		if !ok {
+			entry = &candidate{}
+			candidates[column.TableName] = entry
+		}
+		if column.ColumnKey == "PRI" {
+			entry.primaryKeys++
+			entry.primaryKey = column
+		}
+		if strings.EqualFold(column.ColumnName, timestampColumn) {
+			switch strings.ToLower(column.DataType) {
+			case "datetime", "timestamp":
+				entry.timestamp = column.ColumnName
+			}
+		}
+	}
+
+	selected := make(map[string]models.IncrementalColumn, len(candidates))
+	for tableName, entry := range candidates {
+		if entry.primaryKeys == 0 {
+			continue
+		}
+		if entry.timestamp != "" {
+			selected[tableName] = models.IncrementalColumn{Column: entry.timestamp, Kind: models.WatermarkTimestamp}
+			continue
+		}
+		if entry.primaryKeys == 1 && isIntegerColumnType(entry.primaryKey.DataType) &&
+			strings.Contains(strings.ToLower(entry.primaryKey.Extra), "auto_increment") {
+			selected[tableName] = models.IncrementalColumn{Column: entry.primaryKey.ColumnName, Kind: models.WatermarkPrimaryKey}
+		}
+	}
+	return selected
+}
+
+func isIntegerColumnType(dataType string) bool {
+	switch strings.ToLower(dataType) {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		return true
+	default:
+		return false
+	}
+}
+
+// ColumnMaxValues возвращает текущий максимум колонки high-water mark по таблицам.
+// Пустые и отсутствующие таблицы в результат не попадают.
+func (ds *DatabaseService) ColumnMaxValues(databaseName string, columns map[string]models.IncrementalColumn, isRemote bool) (map[string]string, error) {
+	if len(columns) == 0 {
+		return map[string]string{}, nil
+	}
+
+	db, cleanup, err := ds.openConnection(isRemote, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer cleanup()
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping server: %w", err)
+	}
+
+	values := make(map[string]string, len(columns))
+	for tableName, column := range columns {
+		qualified := quoteIdentifier(databaseName) + "." + quoteIdentifier(tableName)
+		var value sql.NullString
+		query := fmt.Sprintf("SELECT CAST(MAX(%s) AS CHAR) FROM %s", quoteIdentifier(column.Column), qualified)
+		if err := db.QueryRow(query).Scan(&value); err != nil {
+			if isMissingTableError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read high-water mark of %s: %w", tableName, err)
+		}
+		if value.Valid {
+			values[tableName] = value.String
+		}
+	}
+
+	return values, nil
+}
+
+// BinlogStatus возвращает текущую позицию binlog и формат журнала сервера.
+func (ds *DatabaseService) BinlogStatus(isRemote bool) (*models.BinlogStatus, error) {
+	db, cleanup, err := ds.openConnection(isRemote, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer cleanup()
+	defer db.Close()
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping server: %w", err)
+	}
+
+	status := &models.BinlogStatus{}
+	var gtidMode string
+	if err := db.QueryRow("SELECT @@GLOBAL.binlog_format, @@GLOBAL.binlog_row_image, @@GLOBAL.gtid_mode").Scan(&status.Format, &status.RowImage, &gtidMode); err != nil {
+		return nil, fmt.Errorf("failed to read binlog settings: %w", err)
+	}
+
+	// MySQL 8.4 переименовал SHOW MASTER STATUS; старые серверы знают только прежнее имя.
+	rows, err := db.Query("SHOW BINARY LOG STATUS")
+	if err != nil {
+		rows, err = db.Query("SHOW MASTER STATUS")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binlog position: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binlog position: %w", err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read binlog position: %w", err)
+		}
+		return nil, fmt.Errorf("binary logging is disabled on the server")
+	}
+	values := make([]sql.NullString, len(columns))
+	targets := make([]any, len(columns))
+	for index := range values {
+		targets[index] = &values[index]
+	}
+	if err := rows.Scan(targets...); err != nil {
+		return nil, fmt.Errorf("failed to read binlog position: %w", err)
+	}
+	for index, column := range columns {
+		switch column {
+		case "File":
+			status.Position.File = values[index].String
+		case "Position":
+			position, err := strconv.ParseUint(values[index].String, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid binlog position %q: %w", values[index].String, err)
+			}
+			status.Position.Position = uint32(position)
+		case "Executed_Gtid_Set":
+			if strings.EqualFold(gtidMode, "ON") {
+				status.Position.GTIDSet = strings.ReplaceAll(values[index].String, "\n", "")
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// ServerIdentity возвращает @@hostname и server_uuid сервера, чтобы отличить один сервер
+// от другого, даже если они доступны по разным адресам.
+func (ds *DatabaseService) ServerIdentity(isRemote bool) (*models.ServerIdentity, error) {
+	db, cleanup, err := ds.openConnection(isRemote, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer cleanup()
+	defer db.Close()
+
+	identity := &models.ServerIdentity{}
+	var serverUUID sql.NullString
+	if err := db.QueryRow("SELECT @@GLOBAL.hostname, @@GLOBAL.server_uuid").Scan(&identity.Hostname, &serverUUID); err != nil {
+		return nil, fmt.Errorf("failed to read server identity: %w", err)
+	}
+	identity.ServerUUID = serverUUID.String
+	return identity, nil
+}
+
+// DataDir возвращает @@datadir сервера.
+func (ds *DatabaseService) DataDir(isRemote bool) (string, error) {
+	db, cleanup, err := ds.openConnection(isRemote, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer cleanup()
+	defer db.Close()
+
+	var dataDir string
+	if err := db.QueryRow("SELECT @@GLOBAL.datadir").Scan(&dataDir); err != nil {
+		return "", fmt.Errorf("failed to read datadir: %w", err)
+	}
+	return dataDir, nil
+}
+
+// ServerSettings читает версию сервера, SHOW GRANTS текущего пользователя, local_infile,
+// sql_mode и lower_case_table_names для dbsync doctor.
+func (ds *DatabaseService) ServerSettings(isRemote bool) (*models.ServerSettings, error) {
+	db, cleanup, err := ds.openConnection(isRemote, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer cleanup()
+	defer db.Close()
+
+	settings := &models.ServerSettings{}
+	if err := db.QueryRow("SELECT VERSION(), @@GLOBAL.local_infile, @@GLOBAL.sql_mode, @@GLOBAL.lower_case_table_names").
+		Scan(&settings.Version, &settings.LocalInfile, &settings.SQLMode, &settings.LowerCaseTableNames); err != nil {
+		return nil, fmt.Errorf("failed to read server settings: %w", err)
+	}
+
+	rows, err := db.Query("SHOW GRANTS")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grants: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return nil, fmt.Errorf("failed to scan grant: %w", err)
+		}
+		settings.Grants = append(settings.Grants, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read grants: %w", err)
+	}
+	return settings, nil
+}
+
+func isMissingTableError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrNoSuchTable
+}
+
 func quoteIdentifier(value string) string {
 	return "`" + strings.ReplaceAll(value, "`", "``") + "`"
 }
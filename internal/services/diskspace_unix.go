@@ -0,0 +1,24 @@
+//go:build !windows
+
+package services
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+// diskFree возвращает место, доступное непривилегированному пользователю на разделе path,
+// и идентификатор устройства раздела.
+func diskFree(path string) (int64, string, error) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(path, &fs); err != nil {
+		return 0, "", fmt.Errorf("failed to read free space of %s: %w", path, err)
+	}
+	var stat unix.Stat_t
+	if err := unix.Stat(path, &stat); err != nil {
+		return 0, "", fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	return int64(fs.Bavail) * int64(fs.Bsize), strconv.FormatUint(uint64(stat.Dev), 10), nil
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+// diskFree возвращает место, доступное пользователю на томе path, и имя тома.
+func diskFree(path string) (int64, string, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read free space of %s: %w", path, err)
+	}
+	var available, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &available, &total, &totalFree); err != nil {
+		return 0, "", fmt.Errorf("failed to read free space of %s: %w", path, err)
+	}
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		absolute = path
+	}
+	return int64(available), strings.ToUpper(filepath.VolumeName(absolute)), nil
+}
@@ -0,0 +1,520 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// mysqlShellMinVersion — минимальная поддерживаемая версия MySQL Shell (8.4 LTS).
+var mysqlShellMinVersion = [3]int{8, 4, 0}
+
+var (
+	serverVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)`)
+	grantPattern         = regexp.MustCompile(`(?is)^GRANT\s+(.+?)\s+ON\s+(?:(?:FUNCTION|PROCEDURE|TABLE)\s+)?(\S+)\s+TO\s+(\S+)`)
+	roleGrantPattern     = regexp.MustCompile(`(?is)^GRANT\s+\S+@\S+(\s*,\s*\S+@\S+)*\s+TO\s`)
+)
+
+// privilegeRequirement — привилегия, нужная синхронизации; достаточно любой из AnyOf.
+type privilegeRequirement struct {
+	AnyOf  []string
+	Reason string
+}
+
+var (
+	sourcePrivileges = []privilegeRequirement{
+		{AnyOf: []string{"SELECT"}},
+		{AnyOf: []string{"SHOW VIEW"}},
+		{AnyOf: []string{"TRIGGER"}},
+		{AnyOf: []string{"EVENT"}},
+	}
+	destinationPrivileges = []privilegeRequirement{
+		{AnyOf: []string{"CREATE"}},
+		{AnyOf: []string{"DROP"}},
+		{AnyOf: []string{"INSERT"}},
+		{AnyOf: []string{"ALTER"}},
+		{AnyOf: []string{"INDEX"}},
+		{AnyOf: []string{"CREATE VIEW"}},
+		{AnyOf: []string{"CREATE ROUTINE"}},
+		{AnyOf: []string{"TRIGGER"}},
+		{AnyOf: []string{"EVENT"}},
+	}
+	// variablesAdminPrivileges позволяют dbsync включить local_infile перед загрузкой.
+	variablesAdminPrivileges = []string{"SUPER", "SYSTEM_VARIABLES_ADMIN"}
+)
+
+// dumpLockPrivileges возвращает привилегии для блокировок, которыми движок делает дамп согласованным:
+// mydumper берет FLUSH TABLES WITH READ LOCK или LOCK INSTANCE FOR BACKUP, mysqldump — LOCK TABLES.
+func dumpLockPrivileges(engineName string) []privilegeRequirement {
+	switch engineName {
+	case config.DumpEngineMydumper:
+		return []privilegeRequirement{{AnyOf: []string{"RELOAD", "BACKUP_ADMIN"}, Reason: "mydumper locks the instance for a consistent dump"}}
+	case config.DumpEngineMysqldump:
+		return []privilegeRequirement{{AnyOf: []string{"LOCK TABLES"}, Reason: "mysqldump locks tables while dumping"}}
+	default:
+		return nil
+	}
+}
+
+// grantSet — привилегии из SHOW GRANTS: глобальные (ON *.*) и выданные только на отдельные БД или таблицы.
+type grantSet struct {
+	account string
+	global  map[string]bool
+	scoped  map[string][]string
+	roles   bool
+}
+
+func parseGrants(lines []string) grantSet {
+	grants := grantSet{global: map[string]bool{}, scoped: map[string][]string{}}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		match := grantPattern.FindStringSubmatch(line)
+		if match == nil {
+			if roleGrantPattern.MatchString(line) {
+				grants.roles = true
+			}
+			continue
+		}
+		if grants.account == "" {
+			grants.account = match[3]
+		}
+		scope := match[2]
+		for _, privilege := range splitPrivileges(match[1]) {
+			if scope == "*.*" && !strings.Contains(privilege, "(") {
+				grants.global[privilege] = true
+				continue
+			}
+			name := strings.TrimSpace(strings.SplitN(privilege, "(", 2)[0])
+			grants.scoped[name] = append(grants.scoped[name], scope)
+		}
+	}
+	return grants
+}
+
+// splitPrivileges делит список привилегий по запятым вне списков колонок.
+func splitPrivileges(list string) []string {
+	var privileges []string
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				privileges = append(privileges, strings.ToUpper(strings.TrimSpace(list[start:i])))
+				start = i + 1
+			}
+		}
+	}
+	return append(privileges, strings.ToUpper(strings.TrimSpace(list[start:])))
+}
+
+// has сообщает, выдана ли привилегия глобально, а если нет — на каких БД или таблицах.
+// ALL PRIVILEGES покрывает статические привилегии; динамические (BACKUP_ADMIN и т.п.,
+// всегда с подчеркиванием) SHOW GRANTS перечисляет отдельно.
+func (g grantSet) has(privilege string) (bool, []string) {
+	dynamic := strings.Contains(privilege, "_")
+	if g.global[privilege] || (!dynamic && g.global["ALL PRIVILEGES"]) {
+		return true, nil
+	}
+	scopes := slices.Clone(g.scoped[privilege])
+	if !dynamic {
+		scopes = append(scopes, g.scoped["ALL PRIVILEGES"]...)
+	}
+	return false, scopes
+}
+
+func (g grantSet) hasAnyGlobal(privileges []string) bool {
+	for _, privilege := range privileges {
+		if global, _ := g.has(privilege); global {
+			return true
+		}
+	}
+	return false
+}
+
+// Diagnose проверяет окружение маршрута from → to (пустые имена — remote и local): движок дампа
+// и версию mysqlsh, доступность proxy и серверов, совместимость версий, привилегии, local_infile,
+// sql_mode и lower_case_table_names. Ошибка возвращается только для неизвестного endpoint,
+// остальные проблемы попадают в отчет с подсказкой, как их исправить.
+func (s *MySQLShellService) Diagnose(from string, to string) (*models.DoctorReport, error) {
+	target := models.SyncTarget{Source: from, Destination: to}
+	sourceName, destinationName := targetRoute(target)
+	source, err := s.config.ResolveEndpoint(sourceName)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := s.config.ResolveEndpoint(destinationName)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.DoctorReport{Source: source.Name, Destination: destination.Name, CheckedAt: time.Now()}
+	engineName := s.diagnoseDumpTools(report)
+	for _, endpoint := range []struct {
+		label    string
+		endpoint config.Endpoint
+	}{{"source", source}, {"destination", destination}} {
+		if endpoint.endpoint.MySQL().HasProxy() {
+			report.Checks = append(report.Checks, diagnoseProxy(endpoint.label+" proxy", endpoint.endpoint))
+		}
+	}
+
+	service, cleanup, err := s.routeService(target)
+	if err != nil {
+		report.Checks = append(report.Checks, models.DoctorCheck{
+			Name:   "route",
+			Status: models.DoctorStatusFail,
+			Detail: err.Error(),
+			Hint:   fmt.Sprintf("mark endpoint %q as \"writable\" in the endpoints file or pick another destination with --to", destination.Name),
+		})
+		return report, nil
+	}
+	defer cleanup()
+
+	remote := service.diagnoseServer(report, "source", source, true)
+	local := service.diagnoseServer(report, "destination", destination, false)
+	if remote == nil || local == nil {
+		return report, nil
+	}
+	report.Checks = append(report.Checks,
+		diagnoseVersions(remote, local, engineName),
+		diagnosePrivileges("source privileges", remote, append(slices.Clone(sourcePrivileges), dumpLockPrivileges(engineName)...)),
+		diagnosePrivileges("destination privileges", local, destinationPrivileges),
+		diagnoseLocalInfile(local, engineName),
+		diagnoseSQLMode(remote, local),
+		diagnoseLowerCaseTableNames(remote, local),
+	)
+	if s.config.Dump.Consistent {
+		report.Checks = append(report.Checks, diagnoseSnapshot(remote, engineName))
+	}
+	return report, nil
+}
+
+// diagnoseDumpTools проверяет движок дампа и версию mysqlsh и возвращает имя движка.
+func (s *MySQLShellService) diagnoseDumpTools(report *models.DoctorReport) string {
+	engineName := s.config.Dump.Engine
+	engine, engineErr := s.dumpEngine()
+	if engineErr != nil {
+		report.Checks = append(report.Checks, models.DoctorCheck{
+			Name:   "dump engine",
+			Status: models.DoctorStatusFail,
+			Detail: engineErr.Error(),
+			Hint:   "install MySQL Shell 8.4 or newer (https://dev.mysql.com/downloads/shell/) or set DBSYNC_DUMP_ENGINE=native",
+		})
+	} else {
+		engineName = engine.Name()
+		report.Checks = append(report.Checks, models.DoctorCheck{Name: "dump engine", Status: models.DoctorStatusOK, Detail: engineName})
+	}
+
+	// Без mysqlsh синхронизация работает другим движком, но потоковое копирование недоступно.
+	status := models.DoctorStatusFail
+	if engineErr == nil && engineName != config.DumpEngineMySQLShell {
+		status = models.DoctorStatusWarn
+	}
+	minVersion := fmt.Sprintf("%d.%d.%d", mysqlShellMinVersion[0], mysqlShellMinVersion[1], mysqlShellMinVersion[2])
+	path, err := s.findMySQLShell()
+	if err != nil {
+		report.Checks = append(report.Checks, models.DoctorCheck{
+			Name:   "mysqlsh",
+			Status: status,
+			Detail: "MySQL Shell is not installed",
+			Hint:   "install MySQL Shell " + minVersion + " or newer: https://dev.mysql.com/downloads/shell/",
+		})
+		return engineName
+	}
+	version, err := s.mysqlShellVersion()
+	if err != nil {
+		report.Checks = append(report.Checks, models.DoctorCheck{Name: "mysqlsh", Status: status, Detail: err.Error(), Hint: "check that " + path + " runs, or reinstall MySQL Shell"})
+		return engineName
+	}
+	detail := fmt.Sprintf("%d.%d.%d at %s", version[0], version[1], version[2], path)
+	if compareVersions(version, mysqlShellMinVersion) < 0 {
+		report.Checks = append(report.Checks, models.DoctorCheck{
+			Name:   "mysqlsh",
+			Status: status,
+			Detail: detail + ", need " + minVersion + " or newer",
+			Hint:   "upgrade MySQL Shell to the 8.4 LTS release: https://dev.mysql.com/downloads/shell/",
+		})
+		return engineName
+	}
+	report.Checks = append(report.Checks, models.DoctorCheck{Name: "mysqlsh", Status: models.DoctorStatusOK, Detail: detail})
+	return engineName
+}
+
+func diagnoseProxy(name string, endpoint config.Endpoint) models.DoctorCheck {
+	mysqlConfig := endpoint.MySQL()
+	proxyErr, targetErr := checkProxyReachable(mysqlConfig)
+	switch {
+	case proxyErr != nil:
+		return models.DoctorCheck{Name: name, Status: models.DoctorStatusFail, Detail: proxyErr.Error(), Hint: "check that the proxy is running and the proxy URL of " + endpoint.Name + " is correct"}
+	case targetErr != nil:
+		return models.DoctorCheck{Name: name, Status: models.DoctorStatusFail, Detail: targetErr.Error(), Hint: "the proxy is up but cannot reach " + mysqlConfig.Address() + "; check its allow-list and credentials"}
+	default:
+		return models.DoctorCheck{Name: name, Status: models.DoctorStatusOK, Detail: "tunnel to " + mysqlConfig.Address() + " opened"}
+	}
+}
+
+// diagnoseServer проверяет подключение и читает настройки сервера; nil, если сервер недоступен.
+func (s *MySQLShellService) diagnoseServer(report *models.DoctorReport, label string, endpoint config.Endpoint, isRemote bool) *models.ServerSettings {
+	hint := fmt.Sprintf("check host, port, user and password of endpoint %q", endpoint.Name)
+	switch endpoint.Name {
+	case config.EndpointRemote:
+		hint = "check DBSYNC_REMOTE_HOST, DBSYNC_REMOTE_PORT, DBSYNC_REMOTE_USER and DBSYNC_REMOTE_PASSWORD"
+	case config.EndpointLocal:
+		hint = "check that local MySQL is running and DBSYNC_LOCAL_HOST, DBSYNC_LOCAL_PORT, DBSYNC_LOCAL_USER and DBSYNC_LOCAL_PASSWORD"
+	}
+	connection, err := s.dbService.TestConnection(isRemote)
+	if err != nil || connection == nil || !connection.Connected {
+		detail := "connection failed"
+		if connection != nil && connection.Error != "" {
+			detail = connection.Error
+		} else if err != nil {
+			detail = err.Error()
+		}
+		report.Checks = append(report.Checks, models.DoctorCheck{Name: label + " connection", Status: models.DoctorStatusFail, Detail: detail, Hint: hint})
+		return nil
+	}
+	report.Checks = append(report.Checks, models.DoctorCheck{
+		Name:   label + " connection",
+		Status: models.DoctorStatusOK,
+		Detail: fmt.Sprintf("%s@%s, MySQL %s", connection.User, endpoint.MySQL().Address(), connection.Version),
+	})
+
+	settings, err := s.dbService.ServerSettings(isRemote)
+	if err != nil {
+		report.Checks = append(report.Checks, models.DoctorCheck{
+			Name:   label + " settings",
+			Status: models.DoctorStatusFail,
+			Detail: err.Error(),
+			Hint:   "the account must be able to run SHOW GRANTS and read global variables",
+		})
+		return nil
+	}
+	return settings
+}
+
+func parseServerVersion(version string) ([3]int, bool) {
+	match := serverVersionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return [3]int{}, false
+	}
+	var parsed [3]int
+	for i := range parsed {
+		parsed[i], _ = strconv.Atoi(match[i+1])
+	}
+	return parsed, true
+}
+
+func isMariaDB(version string) bool {
+	return strings.Contains(strings.ToLower(version), "mariadb")
+}
+
+// diagnoseVersions проверяет, что приемник не старше источника: дамп новой версии использует
+// collation и синтаксис, которых нет в старой.
+func diagnoseVersions(remote *models.ServerSettings, local *models.ServerSettings, engineName string) models.DoctorCheck {
+	check := models.DoctorCheck{Name: "server versions", Status: models.DoctorStatusOK, Detail: fmt.Sprintf("source %s, destination %s", remote.Version, local.Version)}
+	remoteMariaDB, localMariaDB := isMariaDB(remote.Version), isMariaDB(local.Version)
+	switch {
+	case (remoteMariaDB || localMariaDB) && engineName == config.DumpEngineMySQLShell:
+		check.Status = models.DoctorStatusFail
+		check.Detail += "; MySQL Shell does not support MariaDB"
+		check.Hint = "set DBSYNC_DUMP_ENGINE=mydumper or DBSYNC_DUMP_ENGINE=native"
+		return check
+	case remoteMariaDB != localMariaDB:
+		check.Status = models.DoctorStatusWarn
+		check.Detail += "; MySQL and MariaDB dumps are not fully compatible"
+		check.Hint = "use the same server flavour on both sides or verify the result with dbsync diff"
+		return check
+	}
+
+	remoteVersion, remoteOK := parseServerVersion(remote.Version)
+	localVersion, localOK := parseServerVersion(local.Version)
+	if !remoteOK || !localOK {
+		check.Status = models.DoctorStatusWarn
+		check.Detail += "; cannot compare versions"
+		return check
+	}
+	switch {
+	case localVersion[0] < remoteVersion[0]:
+		check.Status = models.DoctorStatusFail
+	case localVersion[0] == remoteVersion[0] && localVersion[1] < remoteVersion[1]:
+		check.Status = models.DoctorStatusWarn
+	default:
+		return check
+	}
+	check.Detail += "; the destination is older than the source"
+	check.Hint = fmt.Sprintf("upgrade the destination server to MySQL %d.%d or newer", remoteVersion[0], remoteVersion[1])
+	return check
+}
+
+func diagnosePrivileges(name string, settings *models.ServerSettings, requirements []privilegeRequirement) models.DoctorCheck {
+	grants := parseGrants(settings.Grants)
+	var missing, scoped, granted, reasons []string
+	for _, requirement := range requirements {
+		label := strings.Join(requirement.AnyOf, " or ")
+		if grants.hasAnyGlobal(requirement.AnyOf) {
+			granted = append(granted, label)
+			continue
+		}
+		var scopes []string
+		for _, privilege := range requirement.AnyOf {
+			_, privilegeScopes := grants.has(privilege)
+			scopes = append(scopes, privilegeScopes...)
+		}
+		if len(scopes) > 0 {
+			scoped = append(scoped, fmt.Sprintf("%s only on %s", label, strings.Join(slices.Compact(slices.Sorted(slices.Values(scopes))), ", ")))
+			continue
+		}
+		missing = append(missing, requirement.AnyOf[0])
+		if requirement.Reason != "" {
+			reasons = append(reasons, requirement.Reason)
+		}
+	}
+
+	account := grants.account
+	if account == "" {
+		account = "<user>@<host>"
+	}
+	switch {
+	case len(missing) > 0:
+		check := models.DoctorCheck{Name: name, Status: models.DoctorStatusFail, Detail: "missing " + strings.Join(missing, ", ")}
+		if len(reasons) > 0 {
+			check.Detail += " (" + strings.Join(reasons, "; ") + ")"
+		}
+		if grants.roles {
+			// Привилегии неактивных ролей SHOW GRANTS не показывает.
+			check.Status = models.DoctorStatusWarn
+			check.Detail += "; the account has roles, make sure they are active by default"
+		}
+		check.Hint = fmt.Sprintf("GRANT %s ON *.* TO %s;", strings.Join(missing, ", "), account)
+		return check
+	case len(scoped) > 0:
+		return models.DoctorCheck{
+			Name:   name,
+			Status: models.DoctorStatusWarn,
+			Detail: strings.Join(scoped, "; "),
+			Hint:   "make sure the grants cover every synced database, or grant them ON *.* to " + account,
+		}
+	default:
+		return models.DoctorCheck{Name: name, Status: models.DoctorStatusOK, Detail: strings.Join(granted, ", ")}
+	}
+}
+
+// diagnoseLocalInfile проверяет local_infile приемника: load-dump mysqlsh без него не работает,
+// native загружает через INSERT медленнее, а mydumper и mysqldump его не используют.
+func diagnoseLocalInfile(local *models.ServerSettings, engineName string) models.DoctorCheck {
+	check := models.DoctorCheck{Name: "local_infile", Status: models.DoctorStatusOK, Detail: "ON"}
+	if engineName == config.DumpEngineMydumper || engineName == config.DumpEngineMysqldump {
+		check.Detail = "not used by " + engineName
+		return check
+	}
+	if local.LocalInfile {
+		return check
+	}
+	grants := parseGrants(local.Grants)
+	if grants.hasAnyGlobal(variablesAdminPrivileges) {
+		check.Detail = "OFF, dbsync enables it before loading"
+		return check
+	}
+	check.Status = models.DoctorStatusFail
+	check.Detail = "OFF, and the account cannot enable it (needs SUPER or SYSTEM_VARIABLES_ADMIN)"
+	if engineName == config.DumpEngineNative {
+		check.Status = models.DoctorStatusWarn
+		check.Detail += "; native loads fall back to slower INSERT statements"
+	}
+	account := grants.account
+	if account == "" {
+		account = "<user>@<host>"
+	}
+	check.Hint = fmt.Sprintf("run SET PERSIST local_infile = 1 as an administrator, or GRANT SYSTEM_VARIABLES_ADMIN ON *.* TO %s;", account)
+	return check
+}
+
+// diagnoseSnapshot проверяет, сможет ли движок снять согласованный дамп при dump.consistent.
+// Без привилегий синхронизация не падает, а снимает дамп без блокировки, поэтому это предупреждение.
+func diagnoseSnapshot(remote *models.ServerSettings, engineName string) models.DoctorCheck {
+	grants := parseGrants(remote.Grants)
+	lock, reason := snapshotLock(engineName, grants)
+	if lock != "" {
+		return models.DoctorCheck{Name: "consistent snapshot", Status: models.DoctorStatusOK, Detail: string(lock)}
+	}
+	check := models.DoctorCheck{Name: "consistent snapshot", Status: models.DoctorStatusWarn, Detail: reason + ", dumps will not be consistent"}
+	if engineName == config.DumpEngineNative {
+		check.Hint = "set DBSYNC_DUMP_ENGINE=mysqlsh or mysqldump for consistent dumps"
+		return check
+	}
+	account := grants.account
+	if account == "" {
+		account = "<user>@<host>"
+	}
+	check.Hint = fmt.Sprintf("GRANT RELOAD ON *.* TO %s;", account)
+	return check
+}
+
+func diagnoseSQLMode(remote *models.ServerSettings, local *models.ServerSettings) models.DoctorCheck {
+	remoteModes, localModes := sqlModes(remote.SQLMode), sqlModes(local.SQLMode)
+	if slices.Equal(remoteModes, localModes) {
+		return models.DoctorCheck{Name: "sql_mode", Status: models.DoctorStatusOK, Detail: "same on both servers"}
+	}
+	var onlyRemote, onlyLocal []string
+	for _, mode := range remoteModes {
+		if !slices.Contains(localModes, mode) {
+			onlyRemote = append(onlyRemote, mode)
+		}
+	}
+	for _, mode := range localModes {
+		if !slices.Contains(remoteModes, mode) {
+			onlyLocal = append(onlyLocal, mode)
+		}
+	}
+	var parts []string
+	if len(onlyRemote) > 0 {
+		parts = append(parts, "only on source: "+strings.Join(onlyRemote, ","))
+	}
+	if len(onlyLocal) > 0 {
+		parts = append(parts, "only on destination: "+strings.Join(onlyLocal, ","))
+	}
+	return models.DoctorCheck{
+		Name:   "sql_mode",
+		Status: models.DoctorStatusWarn,
+		Detail: strings.Join(parts, "; "),
+		Hint:   fmt.Sprintf("SET PERSIST sql_mode = '%s'; on the destination so it behaves like the source", remote.SQLMode),
+	}
+}
+
+func sqlModes(mode string) []string {
+	var modes []string
+	for _, part := range strings.Split(mode, ",") {
+		if part = strings.ToUpper(strings.TrimSpace(part)); part != "" {
+			modes = append(modes, part)
+		}
+	}
+	slices.Sort(modes)
+	return slices.Compact(modes)
+}
+
+func diagnoseLowerCaseTableNames(remote *models.ServerSettings, local *models.ServerSettings) models.DoctorCheck {
+	detail := fmt.Sprintf("source %d, destination %d", remote.LowerCaseTableNames, local.LowerCaseTableNames)
+	if remote.LowerCaseTableNames == local.LowerCaseTableNames {
+		return models.DoctorCheck{Name: "lower_case_table_names", Status: models.DoctorStatusOK, Detail: detail}
+	}
+	check := models.DoctorCheck{
+		Name:   "lower_case_table_names",
+		Status: models.DoctorStatusWarn,
+		Detail: detail,
+		Hint:   fmt.Sprintf("lower_case_table_names can only be set when the datadir is initialized: reinitialize the destination with --lower-case-table-names=%d", remote.LowerCaseTableNames),
+	}
+	if remote.LowerCaseTableNames == 0 {
+		check.Detail += "; tables whose names differ only in case will collide on the destination"
+	}
+	return check
+}
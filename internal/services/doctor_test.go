@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func TestParseGrants(t *testing.T) {
+	grants := parseGrants([]string{
+		"GRANT SELECT, SHOW VIEW, RELOAD ON *.* TO `dbsync`@`%`",
+		"GRANT BACKUP_ADMIN,SYSTEM_VARIABLES_ADMIN ON *.* TO `dbsync`@`%`",
+		"GRANT ALL PRIVILEGES ON `shop`.* TO `dbsync`@`%`",
+		"GRANT SELECT (`id`, `name`), INSERT ON `crm`.`users` TO `dbsync`@`%`",
+		"GRANT `readers`@`%` TO `dbsync`@`%`",
+	})
+
+	if grants.account != "`dbsync`@`%`" || !grants.roles {
+		t.Fatalf("unexpected account or roles: %+v", grants)
+	}
+	for _, privilege := range []string{"SELECT", "SHOW VIEW", "RELOAD", "BACKUP_ADMIN"} {
+		if global, _ := grants.has(privilege); !global {
+			t.Fatalf("%s must be granted globally", privilege)
+		}
+	}
+	if global, scopes := grants.has("EVENT"); global || len(scopes) != 1 || scopes[0] != "`shop`.*" {
+		t.Fatalf("EVENT must come from ALL PRIVILEGES on shop only, got %v %v", global, scopes)
+	}
+	if global, scopes := grants.has("INSERT"); global || len(scopes) != 2 {
+		t.Fatalf("INSERT must be granted on crm.users and shop, got %v %v", global, scopes)
+	}
+	// ALL PRIVILEGES не включает динамические привилегии.
+	if global, scopes := grants.has("REPLICATION_APPLIER"); global || len(scopes) != 0 {
+		t.Fatalf("dynamic privilege must not come from ALL PRIVILEGES, got %v %v", global, scopes)
+	}
+}
+
+func doctorCheck(t *testing.T, report *models.DoctorReport, name string) models.DoctorCheck {
+	t.Helper()
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check %q not found in %+v", name, report.Checks)
+	return models.DoctorCheck{}
+}
+
+func TestDiagnose(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{
+		RemoteSettings: &models.ServerSettings{
+			Version:             "8.4.3",
+			Grants:              []string{"GRANT SELECT, SHOW VIEW, TRIGGER ON *.* TO `reader`@`%`"},
+			SQLMode:             "STRICT_TRANS_TABLES,NO_ZERO_DATE",
+			LowerCaseTableNames: 0,
+		},
+		LocalSettings: &models.ServerSettings{
+			Version:             "8.0.36",
+			Grants:              []string{"GRANT CREATE, DROP, INSERT, ALTER, INDEX, CREATE VIEW, CREATE ROUTINE, TRIGGER, EVENT ON *.* TO `root`@`localhost`"},
+			SQLMode:             "STRICT_TRANS_TABLES",
+			LowerCaseTableNames: 1,
+		},
+	}
+	cfg := testConfig(t)
+	cfg.Dump.Engine = config.DumpEngineNative
+	report, err := newTestService(cfg, dbService).Diagnose("", "")
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	if report.Source != config.EndpointRemote || report.Destination != config.EndpointLocal {
+		t.Fatalf("unexpected route %s → %s", report.Source, report.Destination)
+	}
+
+	tests := []struct {
+		name   string
+		status models.DoctorStatus
+		want   string
+	}{
+		{"dump engine", models.DoctorStatusOK, "native"},
+		{"source connection", models.DoctorStatusOK, "prod.example.com:3306"},
+		{"server versions", models.DoctorStatusWarn, "destination is older"},
+		{"source privileges", models.DoctorStatusFail, "missing EVENT"},
+		{"destination privileges", models.DoctorStatusOK, "CREATE"},
+		{"local_infile", models.DoctorStatusWarn, "INSERT"},
+		{"sql_mode", models.DoctorStatusWarn, "only on source: NO_ZERO_DATE"},
+		{"lower_case_table_names", models.DoctorStatusWarn, "collide"},
+	}
+	for _, tt := range tests {
+		check := doctorCheck(t, report, tt.name)
+		if check.Status != tt.status || !strings.Contains(check.Detail, tt.want) {
+			t.Errorf("%s: got %s %q, want %s containing %q", tt.name, check.Status, check.Detail, tt.status, tt.want)
+		}
+		if check.Status != models.DoctorStatusOK && check.Hint == "" {
+			t.Errorf("%s: a problem must come with a hint", tt.name)
+		}
+	}
+	if hint := doctorCheck(t, report, "source privileges").Hint; hint != "GRANT EVENT ON *.* TO `reader`@`%`;" {
+		t.Errorf("unexpected grant hint %q", hint)
+	}
+	if report.Failures() == 0 {
+		t.Errorf("missing privileges must fail the report")
+	}
+}
+
+func TestDiagnoseDumpLockPrivileges(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{
+		RemoteSettings: &models.ServerSettings{Version: "8.4.3", Grants: []string{"GRANT SELECT, SHOW VIEW, TRIGGER, EVENT ON *.* TO `reader`@`%`"}},
+		LocalSettings:  &models.ServerSettings{Version: "8.4.3", Grants: []string{"GRANT CREATE, DROP ON *.* TO `root`@`localhost`"}},
+	}
+	cfg := testConfig(t)
+	cfg.Dump.Engine = config.DumpEngineMydumper
+	service := newTestService(cfg, dbService)
+	report, err := service.Diagnose("", "")
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	if check := doctorCheck(t, report, "source privileges"); check.Status != models.DoctorStatusFail || !strings.Contains(check.Detail, "missing RELOAD (mydumper locks") {
+		t.Fatalf("mydumper must require RELOAD or BACKUP_ADMIN, got %+v", check)
+	}
+	if check := doctorCheck(t, report, "local_infile"); check.Status != models.DoctorStatusOK {
+		t.Fatalf("mydumper does not use local_infile, got %+v", check)
+	}
+
+	dbService.RemoteSettings.Grants = append(dbService.RemoteSettings.Grants, "GRANT BACKUP_ADMIN ON *.* TO `reader`@`%`")
+	report, _ = service.Diagnose("", "")
+	if check := doctorCheck(t, report, "source privileges"); check.Status != models.DoctorStatusOK {
+		t.Fatalf("BACKUP_ADMIN must satisfy the lock requirement, got %+v", check)
+	}
+}
+
+func TestDiagnoseStopsWhenServerIsUnreachable(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{TestConnectionError: errors.New("connection refused")}
+	cfg := testConfig(t)
+	cfg.Dump.Engine = config.DumpEngineNative
+	report, err := newTestService(cfg, dbService).Diagnose("", "")
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	check := doctorCheck(t, report, "source connection")
+	if check.Status != models.DoctorStatusFail || !strings.Contains(check.Hint, "DBSYNC_REMOTE_HOST") {
+		t.Fatalf("unexpected connection check %+v", check)
+	}
+	for _, check := range report.Checks {
+		if check.Name == "source privileges" {
+			t.Fatalf("privileges must not be checked without a connection")
+		}
+	}
+}
+
+func TestDiagnoseConsistentSnapshot(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{
+		RemoteSettings: &models.ServerSettings{Version: "8.4.3", Grants: []string{"GRANT SELECT, SHOW VIEW, TRIGGER, EVENT ON *.* TO `reader`@`%`"}},
+		LocalSettings:  &models.ServerSettings{Version: "8.4.3", LocalInfile: true, Grants: []string{"GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost`"}},
+	}
+	cfg := testConfig(t)
+	cfg.Dump.Engine = config.DumpEngineMySQLShell
+	service := newTestService(cfg, dbService)
+	report, _ := service.Diagnose("", "")
+	for _, check := range report.Checks {
+		if check.Name == "consistent snapshot" {
+			t.Fatalf("snapshot must only be checked with dump.consistent, got %+v", check)
+		}
+	}
+
+	service.config.Dump.Consistent = true
+	report, _ = service.Diagnose("", "")
+	check := doctorCheck(t, report, "consistent snapshot")
+	if check.Status != models.DoctorStatusWarn || check.Hint != "GRANT RELOAD ON *.* TO `reader`@`%`;" {
+		t.Fatalf("missing RELOAD must warn with a grant hint, got %+v", check)
+	}
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// dumpEngineMarker хранит имя движка, которым снят дамп; загрузка всегда идет тем же движком.
+const dumpEngineMarker = ".dbsync-engine"
+
+// DumpRequest описывает снятие дампа одной схемы в директорию.
+type DumpRequest struct {
+	// Conn указывает на сервер-источник; для remote адрес уже заменен на локальный proxy tunnel.
+	Conn            config.MySQLConfig
+	DatabaseName    string
+	Tables          []string
+	Dir             string
+	Threads         int
+	Compress        bool
+	NetworkCompress bool
+	TableCount      int
+	// Incremental ограничивает строки таблиц условием high-water mark; поддерживается не всеми движками.
+	Incremental *models.IncrementalPlan
+	Phase       models.SyncPhase
+	MetricsFn   func() models.TrafficMetrics
+	Tracker     *tableProgressTracker
+	// Schemas — схемы пакетного дампа mysqlsh; DatabaseName тогда остается пустым.
+	Schemas []string
+	// Consistent снимает дамп согласованным снимком; учитывается mysqlsh, остальные движки
+	// ведут себя одинаково при любом значении. native всегда читает каждую таблицу одной транзакцией
+	// REPEATABLE READ, но общего снимка между таблицами у него нет: таблицы, которые воркеры читают
+	// в разное время, могут не сходиться по внешним ключам.
+	Consistent bool
+	// Compat — исправления совместимости и пропускаемые объекты дампа источника; nil у локальных
+	// бэкапов, которые снимаются как есть. Fixes собирает исправления, о которых сообщил движок.
+	Compat *config.CompatConfig
+	Fixes  *compatFixes
+}
+
+// LoadRequest описывает загрузку дампа схемы DatabaseName в локальную схему Schema.
+type LoadRequest struct {
+	DatabaseName string
+	Schema       string
+	Dir          string
+	Threads      int
+	Tracker      *tableProgressTracker
+	// Schemas ограничивает загрузку пакетного дампа mysqlsh этими схемами.
+	Schemas []string
+}
+
+// compat возвращает исправления запроса; без них дамп снимается со всеми объектами как есть.
+func (r DumpRequest) compat() config.CompatConfig {
+	if r.Compat == nil {
+		return config.CompatConfig{}
+	}
+	return *r.Compat
+}
+
+func (r DumpRequest) operation() string {
+	if r.Phase == models.SyncPhaseBackup {
+		return "local backup"
+	}
+	return "dump"
+}
+
+// dumpEngines возвращает движки в порядке предпочтения для dump.engine=auto.
+func (s *MySQLShellService) dumpEngines() []DumpEngine {
+	return []DumpEngine{
+		mysqlShellEngine{service: s},
+		mydumperEngine{service: s},
+		mysqldumpEngine{service: s},
+		nativeEngine{service: s},
+	}
+}
+
+func (s *MySQLShellService) dumpEngineByName(name string) DumpEngine {
+	for _, engine := range s.dumpEngines() {
+		if engine.Name() == name {
+			return engine
+		}
+	}
+	return nil
+}
+
+// dumpEngine возвращает движок из dump.engine; auto выбирает первый установленный движок,
+// поэтому без mysqlsh синхронизация продолжает работать через mydumper, mysqldump или native.
+func (s *MySQLShellService) dumpEngine() (DumpEngine, error) {
+	name := s.config.Dump.Engine
+	if name != "" && name != config.DumpEngineAuto {
+		engine := s.dumpEngineByName(name)
+		if engine == nil {
+			return nil, fmt.Errorf("unknown dump engine %q", name)
+		}
+		if err := engine.Available(); err != nil {
+			return nil, fmt.Errorf("dump engine %s is not available: %w", name, err)
+		}
+		return engine, nil
+	}
+
+	var unavailable []error
+	for _, engine := range s.dumpEngines() {
+		err := engine.Available()
+		if err == nil {
+			return engine, nil
+		}
+		unavailable = append(unavailable, err)
+	}
+	return nil, fmt.Errorf("no dump engine available: %w", errors.Join(unavailable...))
+}
+
+// nativeFallbackReason объясняет, почему auto выбрал native.
+const nativeFallbackReason = "mysqlsh, mydumper and mysqldump are not installed, no snapshot shared across tables and definers are removed"
+
+// nativeFallback сообщает, что native выбран в auto за неимением внешних утилит, а не задан явно.
+func (s *MySQLShellService) nativeFallback(engineName string) bool {
+	return engineName == config.DumpEngineNative && (s.config.Dump.Engine == "" || s.config.Dump.Engine == config.DumpEngineAuto)
+}
+
+// warnNativeFallback предупреждает о дампе через native, выбранный в auto.
+func (s *MySQLShellService) warnNativeFallback(engineName string) {
+	if s.nativeFallback(engineName) {
+		s.printStatusf("⚠️  Using the built-in native engine: %s (set DBSYNC_DUMP_ENGINE=native to silence this warning)\n", nativeFallbackReason)
+	}
+}
+
+// dumpEngineName возвращает имя движка для сообщений, даже если движок сейчас недоступен.
+func (s *MySQLShellService) dumpEngineName() string {
+	if engine, err := s.dumpEngine(); err == nil {
+		return engine.Name()
+	}
+	return s.config.Dump.Engine
+}
+
+// dumpEngineForDir возвращает движок, которым снят дамп в dir. Дампы без маркера сняты mysqlsh.
+func (s *MySQLShellService) dumpEngineForDir(dir string) (DumpEngine, error) {
+	name := config.DumpEngineMySQLShell
+	data, err := os.ReadFile(filepath.Join(dir, dumpEngineMarker))
+	switch {
+	case err == nil:
+		name = strings.TrimSpace(string(data))
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read dump engine marker: %w", err)
+	}
+
+	engine := s.dumpEngineByName(name)
+	if engine == nil {
+		return nil, fmt.Errorf("dump %s was created by unknown engine %q", dir, name)
+	}
+	if err := engine.Available(); err != nil {
+		return nil, fmt.Errorf("dump %s requires the %s engine: %w", dir, name, err)
+	}
+	return engine, nil
+}
+
+func writeDumpEngineMarker(dir string, engine DumpEngine) error {
+	if err := os.WriteFile(filepath.Join(dir, dumpEngineMarker), []byte(engine.Name()+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write dump engine marker: %w", err)
+	}
+	return nil
+}
+
+// isCompleteDump сообщает, что дамп в dir завершен: mysqlsh пишет свой маркер, остальные движки — наш.
+func isCompleteDump(dir string) bool {
+	for _, marker := range []string{backupDoneMarker, dumpEngineMarker} {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// lookPathTools проверяет, что все утилиты движка есть в PATH.
+func lookPathTools(tools ...string) error {
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool); err != nil {
+			return fmt.Errorf("%s not found in PATH", tool)
+		}
+	}
+	return nil
+}
+
+// engineLineParser превращает строку вывода утилиты в progress snapshot без фазы, БД и времени.
+type engineLineParser func(line string) (models.ProgressSnapshot, bool)
+
+// engineCommand описывает запуск утилиты движка и разбор ее вывода.
+type engineCommand struct {
+	cmd          *exec.Cmd
+	tool         string
+	operation    string
+	phase        models.SyncPhase
+	databaseName string
+	metricsFn    func() models.TrafficMetrics
+	parse        engineLineParser
+	// inspect получает каждую строку вывода, даже без observer.
+	inspect func(line string)
+}
+
+// engineToolCommand создает процесс утилиты движка, привязанный к контексту выполнения сервиса.
+func (s *MySQLShellService) engineToolCommand(tool string, args ...string) *exec.Cmd {
+	return exec.CommandContext(s.runContext(), tool, args...)
+}
+
+// runEngineCommand запускает утилиту, стримит ее вывод в observer и возвращает ошибку с выводом утилиты.
+// Если cmd.Stdout уже задан, stdout не перехватывается.
+func (s *MySQLShellService) runEngineCommand(command engineCommand, observer models.ProgressObserver) error {
+	cmd := command.cmd
+	stdoutCapture := &progressCaptureWriter{}
+	stderrCapture := &progressCaptureWriter{}
+	// Вывод бэкапа не дублируется в консоль: он идет посреди синхронизации.
+	if !s.quiet && command.phase != models.SyncPhaseBackup {
+		stdoutCapture.writer = os.Stdout
+		stderrCapture.writer = os.Stderr
+	}
+
+	var streams []io.Reader
+	if cmd.Stdout == nil {
+		stdoutPipe, err := cmd.StdoutPipe()
+		if err != nil {
+			return fmt.Errorf("failed to capture %s output: %w", command.tool, err)
+		}
+		streams = append(streams, io.TeeReader(stdoutPipe, stdoutCapture))
+	}
+	stderrPipe, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture %s output: %w", command.tool, err)
+	}
+	streams = append(streams, io.TeeReader(stderrPipe, stderrCapture))
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", command.tool, err)
+	}
+
+	// Парсеры движков хранят состояние, поэтому строки stdout и stderr разбираются по очереди.
+	var parseMu sync.Mutex
+	var streamWG sync.WaitGroup
+	for _, stream := range streams {
+		streamWG.Add(1)
+		go func() {
+			defer streamWG.Done()
+			filterEngineOutput(stream, command, &parseMu, observer)
+		}()
+	}
+	streamWG.Wait()
+	if err := cmd.Wait(); err != nil {
+		return formatEngineError(command.tool, command.operation, err, stdoutCapture.String(), stderrCapture.String())
+	}
+	return nil
+}
+
+// filterEngineOutput читает вывод утилиты построчно и отправляет распознанный прогресс в observer.
+func filterEngineOutput(r io.Reader, command engineCommand, parseMu *sync.Mutex, observer models.ProgressObserver) {
+	scanner := bufio.NewScanner(r)
+	// Увеличиваем буфер для длинных строк
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && command.inspect != nil {
+			parseMu.Lock()
+			command.inspect(line)
+			parseMu.Unlock()
+		}
+		if observer == nil || command.parse == nil || line == "" {
+			continue
+		}
+		parseMu.Lock()
+		snapshot, ok := command.parse(line)
+		parseMu.Unlock()
+		if !ok {
+			continue
+		}
+		speed := snapshot.Traffic.CurrentBytesPerSecond
+		if command.metricsFn != nil {
+			snapshot.Traffic = command.metricsFn()
+		}
+		if speed > 0 {
+			snapshot.Traffic.CurrentBytesPerSecond = speed
+		}
+		snapshot.Phase = command.phase
+		snapshot.DatabaseName = command.databaseName
+		snapshot.Timestamp = time.Now()
+		observer(snapshot)
+	}
+	// Дочитываем остаток, чтобы утилита не заблокировалась на записи после слишком длинной строки.
+	_, _ = io.Copy(io.Discard, r)
+}
+
+func formatEngineError(tool string, operation string, commandErr error, stdout string, stderr string) error {
+	parts := []string{fmt.Sprintf("%s %s failed: %v", tool, operation, commandErr)}
+	if stderr != "" {
+		parts = append(parts, "stderr: "+stderr)
+	}
+	if stdout != "" {
+		parts = append(parts, "stdout: "+stdout)
+	}
+	return errors.New(strings.Join(parts, "\n"))
+}
+
+// tableCountProgress заполняет счетчики таблиц; процент не достигает 100 до завершения утилиты.
+func tableCountProgress(snapshot *models.ProgressSnapshot, current int64, total int64) {
+	snapshot.Current = current
+	snapshot.Total = total
+	if total > 0 {
+		snapshot.Percent = min(float64(current)/float64(total)*100, 99)
+	}
+}
+
+// countingReader считает прочитанные байты для прогресса загрузки через stdin.
+type countingReader struct {
+	reader io.Reader
+	read   atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.read.Add(int64(n))
+	return n, err
+}
+
+// emitReadProgressSnapshots периодически публикует долю прочитанного файла дампа.
+func emitReadProgressSnapshots(stop <-chan struct{}, interval time.Duration, databaseName string, reader *countingReader, bytesTotal int64, observer models.ProgressObserver) {
+	if observer == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			completed := reader.read.Load()
+			percent := float64(0)
+			if bytesTotal > 0 {
+				percent = min(float64(completed)/float64(bytesTotal)*100, 99)
+			}
+			observer(models.ProgressSnapshot{
+				Phase:          models.SyncPhaseRestore,
+				DatabaseName:   databaseName,
+				Message:        "Loading SQL dump into local MySQL",
+				Percent:        percent,
+				BytesCompleted: completed,
+				BytesTotal:     bytesTotal,
+				Timestamp:      now,
+			})
+		}
+	}
+}
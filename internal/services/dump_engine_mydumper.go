@@ -0,0 +1,128 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+var (
+	mydumperTablePattern = regexp.MustCompile("(?i)(dumping|restoring|loading)[^`]*`([^`]+)`\\.`([^`]+)`")
+	mydumperCountPattern = regexp.MustCompile(`(?i)(?:\[\s*|tables?:?\s*)(\d+)\s*(?:of|/)\s*(\d+)`)
+)
+
+// mydumperEngine снимает многопоточный дамп через mydumper и загружает его через myloader.
+type mydumperEngine struct {
+	service *MySQLShellService
+}
+
+func (e mydumperEngine) Name() string {
+	return config.DumpEngineMydumper
+}
+
+func (e mydumperEngine) Available() error {
+	return lookPathTools("mydumper", "myloader")
+}
+
+// SupportsIncremental: mydumper не принимает отдельные условия WHERE для таблиц.
+func (e mydumperEngine) SupportsIncremental() bool {
+	return false
+}
+
+func mydumperArgs(request DumpRequest) []string {
+	args := append(request.Conn.GetMysqlArgs(""),
+		"--database="+request.DatabaseName,
+		"--outputdir="+request.Dir,
+		fmt.Sprintf("--threads=%d", max(request.Threads, 1)),
+		"--verbose=3",
+	)
+	compat := request.compat()
+	if !compat.SkipTriggers {
+		args = append(args, "--triggers")
+	}
+	if !compat.SkipRoutines {
+		args = append(args, "--routines")
+	}
+	if !compat.SkipEvents {
+		args = append(args, "--events")
+	}
+	if request.Compress {
+		args = append(args, "--compress")
+	}
+	if request.NetworkCompress {
+		args = append(args, "--compress-protocol")
+	}
+	if len(request.Tables) > 0 {
+		qualified := make([]string, 0, len(request.Tables))
+		for _, tableName := range request.Tables {
+			qualified = append(qualified, request.DatabaseName+"."+tableName)
+		}
+		args = append(args, "--tables-list="+strings.Join(qualified, ","))
+	}
+	return args
+}
+
+func myloaderArgs(local config.MySQLConfig, request LoadRequest) []string {
+	return append(local.GetMysqlArgs(""),
+		"--directory="+request.Dir,
+		"--source-db="+request.DatabaseName,
+		"--database="+request.Schema,
+		"--overwrite-tables",
+		fmt.Sprintf("--threads=%d", max(request.Threads, 1)),
+		"--verbose=3",
+	)
+}
+
+func (e mydumperEngine) Dump(request DumpRequest, observer models.ProgressObserver) error {
+	return e.service.runEngineCommand(engineCommand{
+		cmd:          e.service.engineToolCommand("mydumper", mydumperArgs(request)...),
+		tool:         "mydumper",
+		operation:    request.operation(),
+		phase:        request.Phase,
+		databaseName: request.DatabaseName,
+		metricsFn:    request.MetricsFn,
+		parse:        mydumperLineParser(request.TableCount),
+	}, observer)
+}
+
+func (e mydumperEngine) Load(request LoadRequest, observer models.ProgressObserver) error {
+	return e.service.runEngineCommand(engineCommand{
+		cmd:          e.service.engineToolCommand("myloader", myloaderArgs(e.service.config.Local, request)...),
+		tool:         "myloader",
+		operation:    "load",
+		phase:        models.SyncPhaseRestore,
+		databaseName: request.DatabaseName,
+		parse:        mydumperLineParser(0),
+	}, observer)
+}
+
+// mydumperLineParser разбирает verbose-вывод mydumper и myloader: имя таблицы из `db`.`table`
+// и счетчик "N of M", если версия утилиты его печатает; иначе считаются встреченные таблицы.
+func mydumperLineParser(tableCount int) engineLineParser {
+	seen := make(map[string]bool)
+	return func(line string) (models.ProgressSnapshot, bool) {
+		match := mydumperTablePattern.FindStringSubmatch(line)
+		if match == nil {
+			return models.ProgressSnapshot{}, false
+		}
+		tableName := match[3]
+		seen[tableName] = true
+
+		verb := "Dumping"
+		if !strings.EqualFold(match[1], "dumping") {
+			verb = "Restoring"
+		}
+		snapshot := models.ProgressSnapshot{TableName: tableName, Message: verb + " table " + tableName}
+		current, total := int64(len(seen)-1), int64(tableCount)
+		if counts := mydumperCountPattern.FindStringSubmatch(line); counts != nil {
+			current, _ = strconv.ParseInt(counts[1], 10, 64)
+			total, _ = strconv.ParseInt(counts[2], 10, 64)
+		}
+		tableCountProgress(&snapshot, current, total)
+		return snapshot, true
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+var mysqldumpTablePattern = regexp.MustCompile(`^-- Retrieving table structure for table (.+?)\.\.\.$`)
+
+// mysqldumpEngine снимает однопоточный SQL дамп через mysqldump и загружает его клиентом mysql.
+// Работает там, где установлены только стандартные клиентские утилиты MySQL.
+type mysqldumpEngine struct {
+	service *MySQLShellService
+}
+
+func (e mysqldumpEngine) Name() string {
+	return config.DumpEngineMysqldump
+}
+
+func (e mysqldumpEngine) Available() error {
+	return lookPathTools("mysqldump", "mysql")
+}
+
+// SupportsIncremental: у mysqldump одно условие --where на все таблицы, per-table watermarks не выразить.
+func (e mysqldumpEngine) SupportsIncremental() bool {
+	return false
+}
+
+func mysqldumpFilePath(dir string, databaseName string) string {
+	return filepath.Join(dir, databaseName+".sql")
+}
+
+// mysqldumpArgs дополняет MySQLConfig.GetMysqldumpArgs прогрессом, сжатием протокола и событиями
+// и убирает пропускаемые объекты; таблицы идут после имени БД.
+func mysqldumpArgs(request DumpRequest) []string {
+	compat := request.compat()
+	args := []string{
+		"--verbose",
+		"--no-tablespaces",
+		"--result-file=" + mysqldumpFilePath(request.Dir, request.DatabaseName),
+	}
+	if !compat.SkipEvents {
+		args = append(args, "--events")
+	}
+	if compat.SkipTriggers {
+		args = append(args, "--skip-triggers")
+	}
+	if request.NetworkCompress {
+		args = append(args, "--compress")
+	}
+	args = append(args, slices.DeleteFunc(request.Conn.GetMysqldumpArgs(request.DatabaseName), func(arg string) bool {
+		return (arg == "--routines" && compat.SkipRoutines) || (arg == "--triggers" && compat.SkipTriggers)
+	})...)
+	return append(args, request.Tables...)
+}
+
+func (e mysqldumpEngine) Dump(request DumpRequest, observer models.ProgressObserver) error {
+	if err := os.MkdirAll(request.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create dump directory: %w", err)
+	}
+	return e.service.runEngineCommand(engineCommand{
+		cmd:          e.service.engineToolCommand("mysqldump", mysqldumpArgs(request)...),
+		tool:         "mysqldump",
+		operation:    request.operation(),
+		phase:        request.Phase,
+		databaseName: request.DatabaseName,
+		metricsFn:    request.MetricsFn,
+		parse:        mysqldumpLineParser(request.TableCount),
+	}, observer)
+}
+
+func (e mysqldumpEngine) Load(request LoadRequest, observer models.ProgressObserver) error {
+	dumpFile := mysqldumpFilePath(request.Dir, request.DatabaseName)
+	file, err := os.Open(dumpFile)
+	if err != nil {
+		return fmt.Errorf("failed to open SQL dump: %w", err)
+	}
+	defer file.Close()
+	var bytesTotal int64
+	if info, err := file.Stat(); err == nil {
+		bytesTotal = info.Size()
+	}
+
+	reader := &countingReader{reader: file}
+	cmd := e.service.engineToolCommand("mysql", e.service.config.Local.GetMysqlArgs(request.Schema)...)
+	cmd.Stdin = reader
+
+	stopProgress := make(chan struct{})
+	defer close(stopProgress)
+	go emitReadProgressSnapshots(stopProgress, 250*time.Millisecond, request.DatabaseName, reader, bytesTotal, observer)
+
+	return e.service.runEngineCommand(engineCommand{
+		cmd:          cmd,
+		tool:         "mysql",
+		operation:    "load",
+		phase:        models.SyncPhaseRestore,
+		databaseName: request.DatabaseName,
+	}, observer)
+}
+
+// mysqldumpLineParser считает таблицы по строкам --verbose, которые mysqldump пишет в stderr.
+func mysqldumpLineParser(tableCount int) engineLineParser {
+	var started int64
+	return func(line string) (models.ProgressSnapshot, bool) {
+		match := mysqldumpTablePattern.FindStringSubmatch(line)
+		if match == nil {
+			return models.ProgressSnapshot{}, false
+		}
+		tableName := strings.Trim(match[1], "`")
+		snapshot := models.ProgressSnapshot{TableName: tableName, Message: "Dumping table " + tableName}
+		// Таблица только начата, поэтому завершенными считаются предыдущие.
+		tableCountProgress(&snapshot, started, int64(tableCount))
+		started++
+		return snapshot, true
+	}
+}
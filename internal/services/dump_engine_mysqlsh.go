@@ -0,0 +1,183 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// mysqlShellEngine снимает дамп через util dump-schemas и загружает через util load-dump.
+type mysqlShellEngine struct {
+	service *MySQLShellService
+}
+
+func (e mysqlShellEngine) Name() string {
+	return config.DumpEngineMySQLShell
+}
+
+func (e mysqlShellEngine) Available() error {
+	_, err := e.service.findMySQLShell()
+	return err
+}
+
+func (e mysqlShellEngine) SupportsIncremental() bool {
+	return true
+}
+
+func (e mysqlShellEngine) Dump(request DumpRequest, observer models.ProgressObserver) error {
+	mysqlshPath, err := e.service.findMySQLShell()
+	if err != nil {
+		return err
+	}
+
+	uri := e.service.buildURI(request.Conn, request.Conn.Host, request.Conn.Port)
+	args := e.service.mysqlShellDumpArgs(uri, request)
+	args = append(args, incrementalDumpArgs(request.DatabaseName, request.Incremental)...)
+
+	tracker := request.Tracker
+	stopTableProgress := make(chan struct{})
+	defer close(stopTableProgress)
+	if observer != nil && tracker != nil {
+		go emitTableProgressSnapshots(stopTableProgress, request.Phase, request.DatabaseName, tracker, func(now time.Time) bool {
+			return tracker.ObserveDumpDir(request.Dir, now)
+		}, observer)
+	}
+
+	command := engineCommand{
+		cmd:          e.service.mysqlshCommand(mysqlshPath, args...),
+		tool:         "mysqlsh",
+		operation:    request.operation(),
+		phase:        request.Phase,
+		databaseName: request.DatabaseName,
+		metricsFn:    request.MetricsFn,
+		parse:        mysqlShellLineParser(request.Phase),
+	}
+	if request.Fixes != nil {
+		command.inspect = request.Fixes.inspectMySQLShell
+	}
+	err = e.service.runEngineCommand(command, observer)
+	if err != nil {
+		return err
+	}
+	tracker.ObserveDumpDir(request.Dir, time.Now())
+	return nil
+}
+
+// Load загружает дамп через util load-dump; local_infile включает сессия тюнинга вокруг загрузки.
+func (e mysqlShellEngine) Load(request LoadRequest, observer models.ProgressObserver) error {
+	mysqlshPath, err := e.service.findMySQLShell()
+	if err != nil {
+		return err
+	}
+
+	progressFile := loadProgressFilePath(request.Dir)
+	args := []string{
+		"--uri", e.service.buildLocalURI(),
+		fmt.Sprintf("--password=%s", e.service.config.Local.Password),
+		"--", "util", "load-dump", request.Dir,
+		fmt.Sprintf("--threads=%d", request.Threads),
+	}
+	if request.Schema != request.DatabaseName {
+		args = append(args, "--schema="+request.Schema) // Грузим рядом, а не поверх локальной БД
+	}
+	if len(request.Schemas) > 0 {
+		args = append(args, "--includeSchemas="+strings.Join(request.Schemas, ","))
+	}
+	args = append(args,
+		"--deferTableIndexes=all",      // Создаём индексы после данных
+		"--resetProgress",              // Сбрасываем прогресс предыдущих попыток
+		"--progressFile="+progressFile, // Per-table прогресс для TUI
+		"--ignoreVersion",              // Игнорируем разницу версий MySQL
+		"--skipBinlog=true",            // Пропускаем запись в binlog
+	)
+
+	tracker := request.Tracker
+	stopTableProgress := make(chan struct{})
+	defer close(stopTableProgress)
+	if observer != nil && tracker != nil {
+		go emitTableProgressSnapshots(stopTableProgress, models.SyncPhaseRestore, request.DatabaseName, tracker, func(now time.Time) bool {
+			return tracker.ObserveLoadProgress(progressFile, now)
+		}, observer)
+	}
+
+	err = e.service.runEngineCommand(engineCommand{
+		cmd:          e.service.mysqlshCommand(mysqlshPath, args...),
+		tool:         "mysqlsh",
+		operation:    "load",
+		phase:        models.SyncPhaseRestore,
+		databaseName: request.DatabaseName,
+		parse:        mysqlShellLineParser(models.SyncPhaseRestore),
+	}, observer)
+	if err != nil {
+		return err
+	}
+	tracker.ObserveLoadProgress(progressFile, time.Now())
+	return nil
+}
+
+// mysqlShellDumpArgs строит аргументы util dump-schemas для запроса дампа.
+func (s *MySQLShellService) mysqlShellDumpArgs(uri string, request DumpRequest) []string {
+	args := []string{
+		"--uri", uri,
+		fmt.Sprintf("--password=%s", request.Conn.Password),
+	}
+	if request.NetworkCompress {
+		args = append(args, s.transportCompressionArgs()...)
+	}
+	compression := "--compression=none"
+	if request.Compress {
+		compression = "--compression=zstd"
+	}
+	schemas := request.DatabaseName
+	if len(request.Schemas) > 0 {
+		schemas = strings.Join(request.Schemas, ",")
+	}
+	args = append(args,
+		"--", "util", "dump-schemas", schemas,
+		fmt.Sprintf("--outputUrl=%s", request.Dir),
+		fmt.Sprintf("--threads=%d", max(request.Threads, 1)),
+	)
+	args = append(args, mysqlShellConsistencyArgs(request.Consistent)...)
+	args = append(args, mysqlShellCompatArgs(request.compat())...)
+	args = append(args, compression)
+	if len(request.Tables) > 0 {
+		qualified := make([]string, 0, len(request.Tables))
+		for _, tableName := range request.Tables {
+			qualified = append(qualified, fmt.Sprintf("%s.%s", request.DatabaseName, tableName))
+		}
+		args = append(args, "--includeTables="+strings.Join(qualified, ","))
+	}
+	return args
+}
+
+// mysqlShellLineParser извлекает из вывода mysqlsh процент, объем, скорость, ETA и статусные сообщения.
+func mysqlShellLineParser(phase models.SyncPhase) engineLineParser {
+	return func(line string) (models.ProgressSnapshot, bool) {
+		parsed, ok := parseMySQLShellProgressLine(line)
+		statusMessage, statusOK := classifyMySQLShellStatusLine(phase, line)
+		if !ok && !statusOK {
+			return models.ProgressSnapshot{}, false
+		}
+		snapshot := models.ProgressSnapshot{Message: statusMessage}
+		if ok {
+			snapshot.Percent = parsed.Percent
+			snapshot.BytesCompleted = parsed.BytesCompleted
+			snapshot.BytesTotal = parsed.BytesTotal
+			snapshot.ETA = parsed.ETA
+			snapshot.Traffic.CurrentBytesPerSecond = parsed.BytesPerSecond
+		}
+		return snapshot, true
+	}
+}
+
+// mysqlShellConsistencyArgs включает согласованный снимок mysqlsh: FTWRL или LOCK INSTANCE FOR BACKUP
+// на время открытия транзакций потоков. Без него проверки согласованности тоже отключаются.
+func mysqlShellConsistencyArgs(consistent bool) []string {
+	if consistent {
+		return []string{"--consistent=true"}
+	}
+	return []string{"--consistent=false", "--skipConsistencyChecks"}
+}
@@ -0,0 +1,1159 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	nativeManifestFile = "@.native.json"
+	// nativeChunkRows — строк в одном chunk-файле; для таблиц с PK это и размер keyset-выборки.
+	nativeChunkRows = 50000
+	// nativeInsertBatchBytes ограничивает размер одного multi-row INSERT при загрузке без LOAD DATA.
+	nativeInsertBatchBytes = 1 << 20
+)
+
+var (
+	nativeDefinerPattern = regexp.MustCompile("DEFINER=`(?:[^`]|``)*`@`(?:[^`]|``)*` ")
+	// nativeReaderSeq делает имена io.Reader обработчиков LOAD DATA уникальными в процессе.
+	nativeReaderSeq atomic.Int64
+)
+
+// nativeBinaryTypes — типы, значения которых пишутся в hex, чтобы не зависеть от кодировки соединения.
+var nativeBinaryTypes = []string{
+	"binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit",
+	"geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection",
+}
+
+// nativeDumpManifest описывает дамп native движка; пишется последним и одновременно служит маркером завершения.
+type nativeDumpManifest struct {
+	DatabaseName string             `json:"database_name"`
+	Tables       []nativeDumpTable  `json:"tables"`
+	Objects      []nativeDumpObject `json:"objects,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+type nativeDumpTable struct {
+	Name    string             `json:"name"`
+	View    bool               `json:"view,omitempty"`
+	DDLFile string             `json:"ddl_file"`
+	Columns []nativeDumpColumn `json:"columns,omitempty"`
+	Chunks  []nativeDumpChunk  `json:"chunks,omitempty"`
+}
+
+type nativeDumpColumn struct {
+	Name   string `json:"name"`
+	Binary bool   `json:"binary,omitempty"`
+}
+
+// nativeDumpObject — процедура, функция, триггер или событие схемы. Они создаются после загрузки
+// данных в sql_mode и time_zone источника, чтобы триггеры не срабатывали на строках дампа.
+type nativeDumpObject struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	SQLMode  string `json:"sql_mode"`
+	TimeZone string `json:"time_zone,omitempty"`
+	DDLFile  string `json:"ddl_file"`
+}
+
+// nativeDumpChunk — файл со строками в формате LOAD DATA (TSV, \N для NULL); Bytes — размер до сжатия.
+type nativeDumpChunk struct {
+	File  string `json:"file"`
+	Rows  int64  `json:"rows"`
+	Bytes int64  `json:"bytes"`
+}
+
+// nativeEngine снимает и загружает дамп через database/sql без внешних утилит.
+type nativeEngine struct {
+	service *MySQLShellService
+}
+
+func (e nativeEngine) Name() string {
+	return config.DumpEngineNative
+}
+
+func (e nativeEngine) Available() error {
+	return nil
+}
+
+func (e nativeEngine) SupportsIncremental() bool {
+	return true
+}
+
+// openNativeConnection открывает пул без parseTime: значения читаются как есть, в текстовом виде сервера.
+func openNativeConnection(conn config.MySQLConfig, databaseName string) (*sql.DB, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = conn.User
+	cfg.Passwd = conn.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(conn.Host, strconv.Itoa(conn.Port))
+	cfg.DBName = databaseName
+	cfg.Timeout = 10 * time.Second
+	cfg.InterpolateParams = true
+	if err := cfg.Apply(mysql.Charset("utf8mb4", "")); err != nil {
+		return nil, err
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// nativeSessionConn берет соединение из пула и готовит сессию; ошибки необязательных выражений игнорируются.
+func nativeSessionConn(ctx context.Context, db *sql.DB, required []string, optional ...string) (*sql.Conn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range required {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to prepare session: %w", err)
+		}
+	}
+	for _, statement := range optional {
+		_, _ = conn.ExecContext(ctx, statement)
+	}
+	return conn, nil
+}
+
+// runNativeWorkers выполняет work для индексов [0, count) в threads горутинах и останавливается на первой ошибке.
+func runNativeWorkers(ctx context.Context, threads int, count int, work func(ctx context.Context, worker *sql.Conn, index int) error, open func(ctx context.Context) (*sql.Conn, error)) error {
+	if count == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var errOnce sync.Once
+	var firstErr error
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	indices := make(chan int)
+	var waitGroup sync.WaitGroup
+	for range min(max(threads, 1), count) {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			conn, err := open(ctx)
+			if err != nil {
+				fail(err)
+				for range indices {
+				}
+				return
+			}
+			defer conn.Close()
+			for index := range indices {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := work(ctx, conn, index); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	for index := range count {
+		if ctx.Err() != nil {
+			break
+		}
+		indices <- index
+	}
+	close(indices)
+	waitGroup.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func (e nativeEngine) Dump(request DumpRequest, observer models.ProgressObserver) error {
+	ctx := e.service.runContext()
+	if err := os.MkdirAll(request.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create dump directory: %w", err)
+	}
+	db, err := openNativeConnection(request.Conn, request.DatabaseName)
+	if err != nil {
+		return fmt.Errorf("failed to open source connection: %w", err)
+	}
+	defer db.Close()
+
+	tables, err := nativeListTables(ctx, db, request.DatabaseName, request.Tables)
+	if err != nil {
+		return err
+	}
+	where := make(map[string]string)
+	if request.Incremental.Active() {
+		for _, table := range request.Incremental.Incremental {
+			where[table.Name] = incrementalWhereClause(table)
+		}
+	}
+
+	dumper := &nativeDumper{request: request, observer: observer, tableCount: len(tables)}
+	err = runNativeWorkers(ctx, request.Threads, len(tables), func(ctx context.Context, conn *sql.Conn, index int) error {
+		return dumper.dumpTable(ctx, conn, index, &tables[index], where[tables[index].Name])
+	}, func(ctx context.Context) (*sql.Conn, error) {
+		return nativeSessionConn(ctx, db, []string{"SET SESSION time_zone = '+00:00'", "SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ"})
+	})
+	if err != nil {
+		return formatEngineError("native", request.operation(), err, "", "")
+	}
+
+	objects, err := dumper.dumpObjects(ctx, db, tables)
+	if err != nil {
+		return formatEngineError("native", request.operation(), err, "", "")
+	}
+
+	manifest := nativeDumpManifest{DatabaseName: request.DatabaseName, Tables: tables, Objects: objects, CreatedAt: time.Now()}
+	if err := writeJSONFileAtomic(filepath.Join(request.Dir, nativeManifestFile), manifest); err != nil {
+		return fmt.Errorf("failed to write native dump manifest: %w", err)
+	}
+	return nil
+}
+
+// nativeListTables возвращает таблицы и представления схемы; selected ограничивает выборку.
+func nativeListTables(ctx context.Context, db *sql.DB, databaseName string, selected []string) ([]nativeDumpTable, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT TABLE_NAME, TABLE_TYPE
+		FROM information_schema.TABLES
+		WHERE TABLE_SCHEMA = ?
+		ORDER BY TABLE_NAME`, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []nativeDumpTable
+	for rows.Next() {
+		var name, tableType string
+		if err := rows.Scan(&name, &tableType); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		if len(selected) > 0 && !slices.Contains(selected, name) {
+			continue
+		}
+		tables = append(tables, nativeDumpTable{Name: name, View: strings.EqualFold(tableType, "VIEW")})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	return tables, nil
+}
+
+// nativeDumper пишет таблицы схемы в chunk-файлы и отчитывается о прогрессе каждого chunk.
+type nativeDumper struct {
+	request    DumpRequest
+	observer   models.ProgressObserver
+	tableCount int
+	tablesDone atomic.Int64
+}
+
+func (d *nativeDumper) dumpTable(ctx context.Context, conn *sql.Conn, index int, table *nativeDumpTable, where string) error {
+	databaseName := d.request.DatabaseName
+	d.request.Tracker.ObserveDumpChunk(table.Name, 0, false, time.Now())
+	d.publish(table.Name)
+
+	table.DDLFile = fmt.Sprintf("t%04d.sql", index)
+	ddl, err := nativeShowCreate(ctx, conn, databaseName, *table)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(d.request.Dir, table.DDLFile), []byte(ddl+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write DDL of %s: %w", table.Name, err)
+	}
+
+	if table.View {
+		d.noteDefinerRemoved("view", table.Name)
+	} else {
+		if err := d.dumpTableData(ctx, conn, index, table, where); err != nil {
+			return fmt.Errorf("failed to dump table %s: %w", table.Name, err)
+		}
+	}
+
+	d.tablesDone.Add(1)
+	d.request.Tracker.ObserveDumpChunk(table.Name, 0, true, time.Now())
+	d.publish(table.Name)
+	return nil
+}
+
+// noteDefinerRemoved записывает в отчет совместимости, что объект создается без DEFINER.
+func (d *nativeDumper) noteDefinerRemoved(kind string, name string) {
+	if d.request.Fixes == nil {
+		return
+	}
+	d.request.Fixes.add(models.CompatibilityFix{
+		Schema: d.request.DatabaseName,
+		Object: fmt.Sprintf("%s %s.%s", kind, quoteIdentifier(d.request.DatabaseName), quoteIdentifier(name)),
+		Fix:    "definer clause removed",
+	})
+}
+
+// dumpObjects выгружает процедуры, функции, триггеры и события схемы в порядке создания при загрузке.
+// При выборке отдельных таблиц выгружаются только их триггеры. DEFINER убирается, как у представлений.
+func (d *nativeDumper) dumpObjects(ctx context.Context, db *sql.DB, tables []nativeDumpTable) ([]nativeDumpObject, error) {
+	objects, err := nativeListObjects(ctx, db, d.request.DatabaseName, d.request.Tables, tables, d.request.compat())
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	for index := range objects {
+		object := &objects[index]
+		ddl, err := nativeShowCreateObject(ctx, conn, d.request.DatabaseName, object)
+		if err != nil {
+			return nil, err
+		}
+		ddl = nativeDefinerPattern.ReplaceAllString(ddl, "")
+		object.DDLFile = fmt.Sprintf("o%04d.sql", index)
+		if err := os.WriteFile(filepath.Join(d.request.Dir, object.DDLFile), []byte(ddl+"\n"), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write DDL of %s %s: %w", strings.ToLower(object.Kind), object.Name, err)
+		}
+		d.noteDefinerRemoved(strings.ToLower(object.Kind), object.Name)
+	}
+	return objects, nil
+}
+
+// nativeListObjects перечисляет объекты схемы: сначала процедуры и функции, на которые ссылаются
+// триггеры и события, затем триггеры в порядке ACTION_ORDER и события. Триггеры остаются только
+// у таблиц из tables; при tables == nil — у всех таблиц схемы.
+func nativeListObjects(ctx context.Context, db *sql.DB, databaseName string, selected []string, tables []nativeDumpTable, compat config.CompatConfig) ([]nativeDumpObject, error) {
+	queries := []struct {
+		skip  bool
+		query string
+	}{
+		{compat.SkipRoutines || len(selected) > 0, "SELECT ROUTINE_TYPE, ROUTINE_NAME, '' FROM information_schema.ROUTINES WHERE ROUTINE_SCHEMA = ? ORDER BY ROUTINE_TYPE, ROUTINE_NAME"},
+		{compat.SkipTriggers, "SELECT 'TRIGGER', TRIGGER_NAME, EVENT_OBJECT_TABLE FROM information_schema.TRIGGERS WHERE TRIGGER_SCHEMA = ? ORDER BY EVENT_OBJECT_TABLE, ACTION_TIMING, EVENT_MANIPULATION, ACTION_ORDER"},
+		{compat.SkipEvents || len(selected) > 0, "SELECT 'EVENT', EVENT_NAME, '' FROM information_schema.EVENTS WHERE EVENT_SCHEMA = ? ORDER BY EVENT_NAME"},
+	}
+	var objects []nativeDumpObject
+	for _, list := range queries {
+		if list.skip {
+			continue
+		}
+		rows, err := db.QueryContext(ctx, list.query, databaseName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schema objects: %w", err)
+		}
+		for rows.Next() {
+			var kind, name, tableName string
+			if err := rows.Scan(&kind, &name, &tableName); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to list schema objects: %w", err)
+			}
+			if tableName != "" && tables != nil && !slices.ContainsFunc(tables, func(table nativeDumpTable) bool { return table.Name == tableName }) {
+				continue
+			}
+			objects = append(objects, nativeDumpObject{Kind: strings.ToUpper(kind), Name: name})
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schema objects: %w", err)
+		}
+	}
+	return objects, nil
+}
+
+// nativeShowCreateObject читает DDL объекта через SHOW CREATE и запоминает его sql_mode и time_zone.
+// Тело процедуры без прав на нее сервер возвращает как NULL: такой дамп был бы неполным.
+func nativeShowCreateObject(ctx context.Context, conn *sql.Conn, databaseName string, object *nativeDumpObject) (string, error) {
+	label := strings.ToLower(object.Kind) + " " + object.Name
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SHOW CREATE %s %s.%s", object.Kind, quoteIdentifier(databaseName), quoteIdentifier(object.Name)))
+	if err != nil {
+		return "", fmt.Errorf("failed to read DDL of %s: %w", label, err)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", fmt.Errorf("failed to read DDL of %s: %w", label, err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", fmt.Errorf("failed to read DDL of %s: %w", label, err)
+		}
+		return "", fmt.Errorf("failed to read DDL of %s: not found", label)
+	}
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]any, len(values))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+	if err := rows.Scan(scanArgs...); err != nil {
+		return "", fmt.Errorf("failed to read DDL of %s: %w", label, err)
+	}
+
+	var ddl string
+	for i, column := range columns {
+		switch {
+		case column == "sql_mode":
+			object.SQLMode = values[i].String
+		case column == "time_zone":
+			object.TimeZone = values[i].String
+		case column == "SQL Original Statement" || strings.HasPrefix(column, "Create "):
+			ddl = values[i].String
+		}
+	}
+	if ddl == "" {
+		return "", fmt.Errorf("failed to read DDL of %s: the source user lacks privileges to see its definition", label)
+	}
+	return ddl, nil
+}
+
+func nativeShowCreate(ctx context.Context, conn *sql.Conn, databaseName string, table nativeDumpTable) (string, error) {
+	qualified := quoteIdentifier(databaseName) + "." + quoteIdentifier(table.Name)
+	var name, ddl string
+	var err error
+	if table.View {
+		var charset, collation string
+		err = conn.QueryRowContext(ctx, "SHOW CREATE VIEW "+qualified).Scan(&name, &ddl, &charset, &collation)
+		// Представление создается от имени пользователя загрузки, как mysqldump --skip-definer.
+		ddl = nativeDefinerPattern.ReplaceAllString(ddl, "")
+	} else {
+		err = conn.QueryRowContext(ctx, "SHOW CREATE TABLE "+qualified).Scan(&name, &ddl)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read DDL of %s: %w", table.Name, err)
+	}
+	return ddl, nil
+}
+
+// nativeTableColumns возвращает хранимые колонки таблицы и колонки первичного ключа.
+// Генерируемые колонки не дампятся: сервер вычислит их при загрузке.
+func nativeTableColumns(ctx context.Context, conn *sql.Conn, databaseName string, tableName string) ([]nativeDumpColumn, []string, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT COLUMN_NAME, DATA_TYPE, COLUMN_KEY, EXTRA
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, databaseName, tableName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []nativeDumpColumn
+	for rows.Next() {
+		var name, dataType, columnKey, extra string
+		if err := rows.Scan(&name, &dataType, &columnKey, &extra); err != nil {
+			return nil, nil, fmt.Errorf("failed to read columns: %w", err)
+		}
+		extra = strings.ToUpper(extra)
+		if strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") {
+			continue
+		}
+		columns = append(columns, nativeDumpColumn{Name: name, Binary: slices.Contains(nativeBinaryTypes, strings.ToLower(dataType))})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	keyRows, err := conn.QueryContext(ctx, `
+		SELECT COLUMN_NAME
+		FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = 'PRIMARY'
+		ORDER BY SEQ_IN_INDEX`, databaseName, tableName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read primary key: %w", err)
+	}
+	defer keyRows.Close()
+	var primaryKey []string
+	for keyRows.Next() {
+		var name string
+		if err := keyRows.Scan(&name); err != nil {
+			return nil, nil, fmt.Errorf("failed to read primary key: %w", err)
+		}
+		primaryKey = append(primaryKey, name)
+	}
+	return columns, primaryKey, keyRows.Err()
+}
+
+// nativeChunkQuery строит keyset-выборку следующего chunk: строки после cursor в порядке первичного ключа.
+// Без первичного ключа таблица читается одним запросом, а chunk-файлы режутся по числу строк.
+func nativeChunkQuery(databaseName string, tableName string, columns []nativeDumpColumn, primaryKey []string, where string, cursor []any) string {
+	selected := make([]string, 0, len(columns))
+	for _, column := range columns {
+		selected = append(selected, quoteIdentifier(column.Name))
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s.%s", strings.Join(selected, ", "), quoteIdentifier(databaseName), quoteIdentifier(tableName))
+
+	var conditions []string
+	if where != "" {
+		conditions = append(conditions, "("+where+")")
+	}
+	quotedKey := make([]string, 0, len(primaryKey))
+	for _, column := range primaryKey {
+		quotedKey = append(quotedKey, quoteIdentifier(column))
+	}
+	if len(cursor) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(cursor)), ", ")
+		conditions = append(conditions, fmt.Sprintf("(%s) > (%s)", strings.Join(quotedKey, ", "), placeholders))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	if len(primaryKey) > 0 {
+		query += fmt.Sprintf(" ORDER BY %s LIMIT %d", strings.Join(quotedKey, ", "), nativeChunkRows)
+	}
+	return query
+}
+
+func (d *nativeDumper) dumpTableData(ctx context.Context, conn *sql.Conn, index int, table *nativeDumpTable, where string) error {
+	columns, primaryKey, err := nativeTableColumns(ctx, conn, d.request.DatabaseName, table.Name)
+	if err != nil {
+		return err
+	}
+	table.Columns = columns
+	keyIndexes := make([]int, 0, len(primaryKey))
+	for _, keyColumn := range primaryKey {
+		position := slices.IndexFunc(columns, func(column nativeDumpColumn) bool { return column.Name == keyColumn })
+		if position < 0 {
+			// Генерируемая колонка в ключе: keyset по ней невозможен, читаем таблицу целиком.
+			primaryKey, keyIndexes = nil, nil
+			break
+		}
+		keyIndexes = append(keyIndexes, position)
+	}
+
+	// Все chunk таблицы читаются одним снимком REPEATABLE READ, иначе каждый keyset-запрос видел бы
+	// свою версию данных. Снимок общий только внутри таблицы: воркеры открывают свои транзакции.
+	if _, err := conn.ExecContext(ctx, "START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY"); err != nil {
+		return fmt.Errorf("failed to start snapshot transaction: %w", err)
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.WithoutCancel(ctx), "ROLLBACK")
+	}()
+
+	var cursor []any
+	for {
+		query := nativeChunkQuery(d.request.DatabaseName, table.Name, columns, primaryKey, where, cursor)
+		rows, err := conn.QueryContext(ctx, query, cursor...)
+		if err != nil {
+			return err
+		}
+		read, last, err := d.writeRows(index, table, rows, keyIndexes)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		if len(primaryKey) == 0 || read < nativeChunkRows {
+			return nil
+		}
+		cursor = last
+	}
+}
+
+// writeRows пишет результат запроса в chunk-файлы и возвращает число строк и ключ последней строки.
+func (d *nativeDumper) writeRows(index int, table *nativeDumpTable, rows *sql.Rows, keyIndexes []int) (int64, []any, error) {
+	values := make([]sql.RawBytes, len(table.Columns))
+	scanArgs := make([]any, len(values))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	var writer *nativeChunkWriter
+	closeChunk := func() error {
+		if writer == nil {
+			return nil
+		}
+		chunk, size, err := writer.Close()
+		writer = nil
+		if err != nil {
+			return err
+		}
+		table.Chunks = append(table.Chunks, chunk)
+		d.request.Tracker.ObserveDumpChunk(table.Name, size, false, time.Now())
+		d.publish(table.Name)
+		return nil
+	}
+
+	var read int64
+	var line []byte
+	// RawBytes действительны только до следующего Next, поэтому ключ копируется на каждой строке.
+	key := make([][]byte, len(keyIndexes))
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return 0, nil, err
+		}
+		for i, position := range keyIndexes {
+			key[i] = append(key[i][:0], values[position]...)
+		}
+		if writer != nil && writer.rows >= nativeChunkRows {
+			if err := closeChunk(); err != nil {
+				return 0, nil, err
+			}
+		}
+		if writer == nil {
+			name := fmt.Sprintf("t%04d@%05d.tsv", index, len(table.Chunks))
+			if d.request.Compress {
+				name += ".zst"
+			}
+			var err error
+			if writer, err = newNativeChunkWriter(filepath.Join(d.request.Dir, name)); err != nil {
+				return 0, nil, err
+			}
+		}
+		line = appendNativeRow(line[:0], table.Columns, values)
+		if err := writer.WriteRow(line); err != nil {
+			writer.Close()
+			return 0, nil, err
+		}
+		read++
+	}
+	if err := rows.Err(); err != nil {
+		if writer != nil {
+			writer.Close()
+		}
+		return 0, nil, err
+	}
+
+	var last []any
+	if read > 0 {
+		last = make([]any, 0, len(keyIndexes))
+		for i, position := range keyIndexes {
+			if table.Columns[position].Binary {
+				last = append(last, key[i])
+			} else {
+				last = append(last, string(key[i]))
+			}
+		}
+	}
+	return read, last, closeChunk()
+}
+
+func (d *nativeDumper) publish(tableName string) {
+	if d.observer == nil {
+		return
+	}
+	now := time.Now()
+	snapshot := models.ProgressSnapshot{
+		Phase:        d.request.Phase,
+		DatabaseName: d.request.DatabaseName,
+		TableName:    tableName,
+		Message:      "Dumping table " + tableName,
+		Tables:       d.request.Tracker.Snapshot(now),
+		Timestamp:    now,
+	}
+	if d.request.MetricsFn != nil {
+		// Общий процент remote дампа считается по трафику туннеля.
+		snapshot.Traffic = d.request.MetricsFn()
+		snapshot.Current, snapshot.Total = d.tablesDone.Load(), int64(d.tableCount)
+	} else {
+		tableCountProgress(&snapshot, d.tablesDone.Load(), int64(d.tableCount))
+	}
+	d.observer(snapshot)
+}
+
+// appendNativeRow кодирует строку в формате LOAD DATA по умолчанию: TAB между полями, \N для NULL,
+// экранирование обратным слешем; бинарные колонки пишутся в hex.
+func appendNativeRow(dst []byte, columns []nativeDumpColumn, values []sql.RawBytes) []byte {
+	for i, value := range values {
+		if i > 0 {
+			dst = append(dst, '\t')
+		}
+		switch {
+		case value == nil:
+			dst = append(dst, `\N`...)
+		case columns[i].Binary:
+			dst = hex.AppendEncode(dst, value)
+		default:
+			for _, b := range value {
+				switch b {
+				case '\\':
+					dst = append(dst, `\\`...)
+				case '\t':
+					dst = append(dst, `\t`...)
+				case '\n':
+					dst = append(dst, `\n`...)
+				case '\r':
+					dst = append(dst, `\r`...)
+				case 0:
+					dst = append(dst, `\0`...)
+				default:
+					dst = append(dst, b)
+				}
+			}
+		}
+	}
+	return append(dst, '\n')
+}
+
+// decodeNativeRow разбирает строку chunk-файла в аргументы INSERT: nil для NULL, []byte для бинарных колонок.
+func decodeNativeRow(line []byte, columns []nativeDumpColumn) ([]any, error) {
+	fields := strings.Split(strings.TrimSuffix(string(line), "\n"), "\t")
+	if len(fields) != len(columns) {
+		return nil, fmt.Errorf("row has %d fields, want %d", len(fields), len(columns))
+	}
+	values := make([]any, len(fields))
+	for i, field := range fields {
+		switch {
+		case field == `\N`:
+			values[i] = nil
+		case columns[i].Binary:
+			decoded, err := hex.DecodeString(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid binary value in column %s: %w", columns[i].Name, err)
+			}
+			values[i] = decoded
+		default:
+			values[i] = unescapeNativeField(field)
+		}
+	}
+	return values, nil
+}
+
+func unescapeNativeField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var builder strings.Builder
+	builder.Grow(len(field))
+	for i := 0; i < len(field); i++ {
+		if field[i] != '\\' || i+1 == len(field) {
+			builder.WriteByte(field[i])
+			continue
+		}
+		i++
+		switch field[i] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case '0':
+			builder.WriteByte(0)
+		case 'Z':
+			builder.WriteByte(0x1a)
+		default:
+			builder.WriteByte(field[i])
+		}
+	}
+	return builder.String()
+}
+
+// nativeChunkWriter пишет chunk-файл, при расширении .zst — со сжатием zstd.
+type nativeChunkWriter struct {
+	path    string
+	file    *os.File
+	encoder *zstd.Encoder
+	buffer  *bufio.Writer
+	rows    int64
+	bytes   int64
+}
+
+func newNativeChunkWriter(path string) (*nativeChunkWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chunk file: %w", err)
+	}
+	writer := &nativeChunkWriter{path: path, file: file}
+	var target io.Writer = file
+	if strings.HasSuffix(path, ".zst") {
+		if writer.encoder, err = zstd.NewWriter(file); err != nil {
+			file.Close()
+			return nil, err
+		}
+		target = writer.encoder
+	}
+	writer.buffer = bufio.NewWriterSize(target, 256*1024)
+	return writer, nil
+}
+
+func (w *nativeChunkWriter) WriteRow(line []byte) error {
+	if _, err := w.buffer.Write(line); err != nil {
+		return err
+	}
+	w.rows++
+	w.bytes += int64(len(line))
+	return nil
+}
+
+// Close дописывает chunk и возвращает его описание вместе с размером файла на диске.
+func (w *nativeChunkWriter) Close() (nativeDumpChunk, int64, error) {
+	err := w.buffer.Flush()
+	if w.encoder != nil {
+		err = errors.Join(err, w.encoder.Close())
+	}
+	var size int64
+	if info, statErr := w.file.Stat(); statErr == nil {
+		size = info.Size()
+	}
+	err = errors.Join(err, w.file.Close())
+	if err != nil {
+		return nativeDumpChunk{}, 0, fmt.Errorf("failed to write chunk file: %w", err)
+	}
+	return nativeDumpChunk{File: filepath.Base(w.path), Rows: w.rows, Bytes: w.bytes}, size, nil
+}
+
+// nativeChunkReader читает chunk-файл, распаковывая .zst; Close закрывает и декодер, и файл.
+type nativeChunkReader struct {
+	io.Reader
+	file    *os.File
+	decoder *zstd.Decoder
+}
+
+func openNativeChunk(path string) (*nativeChunkReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open chunk file: %w", err)
+	}
+	reader := &nativeChunkReader{Reader: file, file: file}
+	if strings.HasSuffix(path, ".zst") {
+		if reader.decoder, err = zstd.NewReader(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to open chunk file: %w", err)
+		}
+		reader.Reader = reader.decoder
+	}
+	return reader, nil
+}
+
+func (r *nativeChunkReader) Close() error {
+	if r.decoder != nil {
+		r.decoder.Close()
+	}
+	return r.file.Close()
+}
+
+func readNativeManifest(dir string) (*nativeDumpManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, nativeManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read native dump manifest: %w", err)
+	}
+	var manifest nativeDumpManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse native dump manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// nativeLoadSession — выражения сессии загрузки, как в заголовке дампа mysqldump.
+var nativeLoadSession = []string{
+	"SET SESSION foreign_key_checks = 0",
+	"SET SESSION unique_checks = 0",
+	"SET SESSION sql_mode = 'NO_AUTO_VALUE_ON_ZERO'",
+	"SET SESSION time_zone = '+00:00'",
+}
+
+func (e nativeEngine) Load(request LoadRequest, observer models.ProgressObserver) error {
+	ctx := e.service.runContext()
+	manifest, err := readNativeManifest(request.Dir)
+	if err != nil {
+		return err
+	}
+	db, err := openNativeConnection(e.service.config.Local, request.Schema)
+	if err != nil {
+		return fmt.Errorf("failed to open local connection: %w", err)
+	}
+	defer db.Close()
+	openSession := func(ctx context.Context) (*sql.Conn, error) {
+		// Запись в binlog отключается, если хватает прав, как skipBinlog у mysqlsh.
+		return nativeSessionConn(ctx, db, nativeLoadSession, "SET SESSION sql_log_bin = 0")
+	}
+
+	var tables, views []nativeDumpTable
+	var bytesTotal int64
+	for _, table := range manifest.Tables {
+		if table.View {
+			views = append(views, table)
+			continue
+		}
+		tables = append(tables, table)
+		for _, chunk := range table.Chunks {
+			bytesTotal += chunk.Bytes
+		}
+	}
+
+	loader := &nativeLoader{request: request, observer: observer, bytesTotal: bytesTotal, useLoadData: nativeLocalInfileEnabled(ctx, db)}
+	ddlConn, err := openSession(ctx)
+	if err != nil {
+		return err
+	}
+	defer ddlConn.Close()
+	for _, table := range tables {
+		if err := loader.createTable(ctx, ddlConn, manifest.DatabaseName, table); err != nil {
+			return err
+		}
+	}
+
+	err = runNativeWorkers(ctx, request.Threads, len(tables), func(ctx context.Context, conn *sql.Conn, index int) error {
+		return loader.loadTable(ctx, conn, tables[index])
+	}, openSession)
+	if err != nil {
+		return formatEngineError("native", "load", err, "", "")
+	}
+	if err := loader.createViews(ctx, ddlConn, manifest.DatabaseName, views); err != nil {
+		return err
+	}
+	return loader.createObjects(ctx, ddlConn, manifest.DatabaseName, manifest.Objects)
+}
+
+// nativeLocalInfileEnabled сообщает, можно ли грузить через LOAD DATA LOCAL. local_infile включает
+// сессия тюнинга вокруг загрузки; если у пользователя нет прав, загрузка идет через INSERT.
+func nativeLocalInfileEnabled(ctx context.Context, db *sql.DB) bool {
+	var enabled bool
+	err := db.QueryRowContext(ctx, "SELECT @@GLOBAL.local_infile").Scan(&enabled)
+	return err == nil && enabled
+}
+
+// nativeLoader загружает таблицы native дампа и считает загруженные байты для общего процента.
+type nativeLoader struct {
+	request     LoadRequest
+	observer    models.ProgressObserver
+	bytesTotal  int64
+	bytesLoaded atomic.Int64
+	useLoadData bool
+}
+
+// retargetDDL заменяет ссылки на исходную схему, если дамп грузится в схему с другим именем.
+func (l *nativeLoader) retargetDDL(sourceSchema string, ddl string) string {
+	return retargetSchemaDDL(ddl, sourceSchema, l.request.Schema)
+}
+
+// retargetSchemaDDL заменяет в DDL квалифицированные ссылки на схему from ссылками на схему to.
+func retargetSchemaDDL(ddl string, from string, to string) string {
+	if from == to {
+		return ddl
+	}
+	return strings.ReplaceAll(ddl, quoteIdentifier(from)+".", quoteIdentifier(to)+".")
+}
+
+func (l *nativeLoader) readDDL(sourceSchema string, table nativeDumpTable) (string, error) {
+	data, err := os.ReadFile(filepath.Join(l.request.Dir, table.DDLFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to read DDL of %s: %w", table.Name, err)
+	}
+	return l.retargetDDL(sourceSchema, strings.TrimSpace(string(data))), nil
+}
+
+func (l *nativeLoader) createTable(ctx context.Context, conn *sql.Conn, sourceSchema string, table nativeDumpTable) error {
+	ddl, err := l.readDDL(sourceSchema, table)
+	if err != nil {
+		return err
+	}
+	for _, statement := range []string{"DROP TABLE IF EXISTS " + quoteIdentifier(table.Name), ddl} {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", table.Name, err)
+		}
+	}
+	return nil
+}
+
+// createViews создает представления после таблиц; представления над представлениями создаются за несколько проходов.
+func (l *nativeLoader) createViews(ctx context.Context, conn *sql.Conn, sourceSchema string, views []nativeDumpTable) error {
+	pending := views
+	for len(pending) > 0 {
+		var failed []nativeDumpTable
+		var lastErr error
+		for _, view := range pending {
+			ddl, err := l.readDDL(sourceSchema, view)
+			if err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, "DROP VIEW IF EXISTS "+quoteIdentifier(view.Name)); err != nil {
+				return fmt.Errorf("failed to create view %s: %w", view.Name, err)
+			}
+			if _, err := conn.ExecContext(ctx, ddl); err != nil {
+				failed = append(failed, view)
+				lastErr = fmt.Errorf("failed to create view %s: %w", view.Name, err)
+			}
+		}
+		if len(failed) == len(pending) {
+			return lastErr
+		}
+		pending = failed
+	}
+	return nil
+}
+
+// createObjects создает процедуры, функции, триггеры и события после загрузки данных.
+func (l *nativeLoader) createObjects(ctx context.Context, conn *sql.Conn, sourceSchema string, objects []nativeDumpObject) error {
+	for _, object := range objects {
+		label := strings.ToLower(object.Kind) + " " + object.Name
+		data, err := os.ReadFile(filepath.Join(l.request.Dir, object.DDLFile))
+		if err != nil {
+			return fmt.Errorf("failed to read DDL of %s: %w", label, err)
+		}
+		if _, err := conn.ExecContext(ctx, "SET SESSION sql_mode = ?", object.SQLMode); err != nil {
+			return fmt.Errorf("failed to create %s: %w", label, err)
+		}
+		if object.TimeZone != "" {
+			if _, err := conn.ExecContext(ctx, "SET SESSION time_zone = ?", object.TimeZone); err != nil {
+				return fmt.Errorf("failed to create %s: %w", label, err)
+			}
+		}
+		ddl := l.retargetDDL(sourceSchema, strings.TrimSpace(string(data)))
+		for _, statement := range []string{fmt.Sprintf("DROP %s IF EXISTS %s", object.Kind, quoteIdentifier(object.Name)), ddl} {
+			if _, err := conn.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("failed to create %s: %w", label, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (l *nativeLoader) loadTable(ctx context.Context, conn *sql.Conn, table nativeDumpTable) error {
+	l.request.Tracker.ObserveLoadChunk(table.Name, 0, 0, false, time.Now())
+	for _, chunk := range table.Chunks {
+		reader, err := openNativeChunk(filepath.Join(l.request.Dir, chunk.File))
+		if err != nil {
+			return err
+		}
+		if l.useLoadData {
+			err = l.loadDataChunk(ctx, conn, table, reader)
+		} else {
+			err = l.insertChunk(ctx, conn, table, reader)
+			reader.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load table %s: %w", table.Name, err)
+		}
+		l.bytesLoaded.Add(chunk.Bytes)
+		l.request.Tracker.ObserveLoadChunk(table.Name, chunk.Bytes, chunk.Rows, false, time.Now())
+		l.publish(table.Name)
+	}
+	l.request.Tracker.ObserveLoadChunk(table.Name, 0, 0, true, time.Now())
+	l.publish(table.Name)
+	return nil
+}
+
+// loadDataChunk отдает chunk серверу через LOAD DATA LOCAL INFILE и io.Reader обработчик драйвера,
+// который сам закрывает reader после отправки.
+func (l *nativeLoader) loadDataChunk(ctx context.Context, conn *sql.Conn, table nativeDumpTable, reader *nativeChunkReader) error {
+	handler := fmt.Sprintf("dbsync-native-%d", nativeReaderSeq.Add(1))
+	mysql.RegisterReaderHandler(handler, func() io.Reader { return reader })
+	defer mysql.DeregisterReaderHandler(handler)
+	_, err := conn.ExecContext(ctx, nativeLoadDataStatement(handler, table))
+	return err
+}
+
+func nativeLoadDataStatement(handler string, table nativeDumpTable) string {
+	targets := make([]string, 0, len(table.Columns))
+	var assignments []string
+	for i, column := range table.Columns {
+		if !column.Binary {
+			targets = append(targets, quoteIdentifier(column.Name))
+			continue
+		}
+		variable := fmt.Sprintf("@c%d", i)
+		targets = append(targets, variable)
+		assignments = append(assignments, fmt.Sprintf("%s = UNHEX(%s)", quoteIdentifier(column.Name), variable))
+	}
+	statement := fmt.Sprintf("LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 (%s)",
+		handler, quoteIdentifier(table.Name), strings.Join(targets, ", "))
+	if len(assignments) > 0 {
+		statement += " SET " + strings.Join(assignments, ", ")
+	}
+	return statement
+}
+
+// insertChunk загружает chunk multi-row INSERT-ами, когда local_infile на сервере выключен.
+func (l *nativeLoader) insertChunk(ctx context.Context, conn *sql.Conn, table nativeDumpTable, reader io.Reader) error {
+	columns := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, quoteIdentifier(column.Name))
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", quoteIdentifier(table.Name), strings.Join(columns, ", "))
+	rowPlaceholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	var statement strings.Builder
+	var args []any
+	var batchBytes int
+	flush := func() error {
+		if len(args) == 0 {
+			return nil
+		}
+		_, err := conn.ExecContext(ctx, statement.String(), args...)
+		statement.Reset()
+		args = args[:0]
+		batchBytes = 0
+		return err
+	}
+
+	buffered := bufio.NewReaderSize(reader, 256*1024)
+	for {
+		line, readErr := buffered.ReadBytes('\n')
+		if len(line) > 0 {
+			values, err := decodeNativeRow(line, table.Columns)
+			if err != nil {
+				return err
+			}
+			if statement.Len() == 0 {
+				statement.WriteString(prefix)
+			} else {
+				statement.WriteString(", ")
+			}
+			statement.WriteString(rowPlaceholders)
+			args = append(args, values...)
+			batchBytes += len(line)
+			if batchBytes >= nativeInsertBatchBytes {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if errors.Is(readErr, io.EOF) {
+			return flush()
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+func (l *nativeLoader) publish(tableName string) {
+	if l.observer == nil {
+		return
+	}
+	now := time.Now()
+	completed := l.bytesLoaded.Load()
+	percent := float64(0)
+	if l.bytesTotal > 0 {
+		percent = min(float64(completed)/float64(l.bytesTotal)*100, 99)
+	}
+	l.observer(models.ProgressSnapshot{
+		Phase:          models.SyncPhaseRestore,
+		DatabaseName:   l.request.DatabaseName,
+		TableName:      tableName,
+		Message:        "Loading table " + tableName,
+		Percent:        percent,
+		BytesCompleted: completed,
+		BytesTotal:     l.bytesTotal,
+		Tables:         l.request.Tracker.Snapshot(now),
+		Timestamp:      now,
+	})
+}
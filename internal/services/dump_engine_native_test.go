@@ -0,0 +1,319 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+func TestNativeRowRoundTrip(t *testing.T) {
+	columns := []nativeDumpColumn{{Name: "id"}, {Name: "note"}, {Name: "payload", Binary: true}, {Name: "deleted_at"}}
+	values := []sql.RawBytes{
+		sql.RawBytes("7"),
+		sql.RawBytes("tab\there\nnew\\line\r\x00end"),
+		sql.RawBytes{0x00, '\t', 0xff},
+		nil,
+	}
+
+	line := appendNativeRow(nil, columns, values)
+	if got, want := string(line), "7\ttab\\there\\nnew\\\\line\\r\\0end\t0009ff\t\\N\n"; got != want {
+		t.Fatalf("appendNativeRow() = %q, want %q", got, want)
+	}
+
+	decoded, err := decodeNativeRow(line, columns)
+	if err != nil {
+		t.Fatalf("decodeNativeRow() error = %v", err)
+	}
+	if decoded[0] != "7" || decoded[1] != string(values[1]) || !bytes.Equal(decoded[2].([]byte), values[2]) || decoded[3] != nil {
+		t.Fatalf("unexpected decoded row: %#v", decoded)
+	}
+	if _, err := decodeNativeRow([]byte("1\t2\n"), columns); err == nil {
+		t.Fatal("row with wrong field count must be rejected")
+	}
+}
+
+func TestNativeChunkQuery(t *testing.T) {
+	columns := []nativeDumpColumn{{Name: "tenant_id"}, {Name: "id"}, {Name: "title"}}
+
+	query := nativeChunkQuery("shop", "orders", columns, []string{"tenant_id", "id"}, "", nil)
+	if want := "SELECT `tenant_id`, `id`, `title` FROM `shop`.`orders` ORDER BY `tenant_id`, `id` LIMIT 50000"; query != want {
+		t.Fatalf("first chunk query = %q, want %q", query, want)
+	}
+	query = nativeChunkQuery("shop", "orders", columns, []string{"tenant_id", "id"}, "`updated_at` >= '2026-01-01'", []any{"3", "120"})
+	if want := "SELECT `tenant_id`, `id`, `title` FROM `shop`.`orders` WHERE (`updated_at` >= '2026-01-01') AND (`tenant_id`, `id`) > (?, ?) ORDER BY `tenant_id`, `id` LIMIT 50000"; query != want {
+		t.Fatalf("next chunk query = %q, want %q", query, want)
+	}
+	query = nativeChunkQuery("shop", "log", columns[2:], nil, "", nil)
+	if want := "SELECT `title` FROM `shop`.`log`"; query != want {
+		t.Fatalf("table without primary key must be read in one query, got %q", query)
+	}
+}
+
+func TestNativeChunkWriterRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "t0000@00000.tsv.zst")
+	writer, err := newNativeChunkWriter(path)
+	if err != nil {
+		t.Fatalf("newNativeChunkWriter() error = %v", err)
+	}
+	rows := []string{"1\talpha\n", "2\t" + strings.Repeat("b", 4096) + "\n"}
+	for _, row := range rows {
+		if err := writer.WriteRow([]byte(row)); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	chunk, size, err := writer.Close()
+	if err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if chunk.File != "t0000@00000.tsv.zst" || chunk.Rows != 2 || chunk.Bytes != int64(len(rows[0])+len(rows[1])) || size <= 0 || size >= chunk.Bytes {
+		t.Fatalf("unexpected chunk: %+v size=%d", chunk, size)
+	}
+
+	reader, err := openNativeChunk(path)
+	if err != nil {
+		t.Fatalf("openNativeChunk() error = %v", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != rows[0]+rows[1] {
+		t.Fatalf("compressed chunk must round-trip, got %d bytes err=%v", len(data), err)
+	}
+}
+
+func TestNativeLoadDataStatement(t *testing.T) {
+	table := nativeDumpTable{Name: "files", Columns: []nativeDumpColumn{{Name: "id"}, {Name: "body", Binary: true}, {Name: "name"}}}
+	statement := nativeLoadDataStatement("dbsync-native-1", table)
+	want := "LOAD DATA LOCAL INFILE 'Reader::dbsync-native-1' INTO TABLE `files` CHARACTER SET utf8mb4 (`id`, @c1, `name`) SET `body` = UNHEX(@c1)"
+	if statement != want {
+		t.Fatalf("nativeLoadDataStatement() = %q, want %q", statement, want)
+	}
+}
+
+func TestTableProgressTrackerObservesNativeChunks(t *testing.T) {
+	tracker := newTableProgressTracker("shop", []models.Table{{Name: "orders"}})
+	start := time.Unix(100, 0)
+
+	tracker.ObserveDumpChunk("orders", 0, false, start)
+	tracker.ObserveDumpChunk("orders", 512, false, start.Add(time.Second))
+	tracker.ObserveDumpChunk("orders", 0, true, start.Add(2*time.Second))
+	tracker.ObserveLoadChunk("orders", 2048, 10, false, start.Add(3*time.Second))
+	tables := tracker.Snapshot(start.Add(4 * time.Second))
+	if len(tables) != 1 || tables[0].State != models.TableStateLoading || tables[0].DumpBytes != 512 || tables[0].LoadedBytes != 2048 || tables[0].Rows != 10 || tables[0].DumpDuration != 2*time.Second {
+		t.Fatalf("unexpected progress: %+v", tables)
+	}
+
+	tracker.ObserveLoadChunk("orders", 0, 0, true, start.Add(5*time.Second))
+	tables = tracker.Snapshot(start.Add(6 * time.Second))
+	if tables[0].State != models.TableStateDone || tables[0].LoadDuration != 2*time.Second {
+		t.Fatalf("finished table must be done, got %+v", tables[0])
+	}
+}
+
+// nativeFakeConnector отдает таблицу shop.orders (id PK, note) keyset-выборками и объекты схемы. Как и
+// go-sql-driver/mysql, строки пишутся в один буфер, который портится после последней строки.
+type nativeFakeConnector struct {
+	rows    int
+	queries *int
+	// statements собирает выполненные Exec и keyset-запросы в порядке вызова.
+	statements *[]string
+}
+
+func (c nativeFakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return nativeFakeConn(c), nil
+}
+
+func (c nativeFakeConnector) Driver() driver.Driver { return nil }
+
+type nativeFakeConn nativeFakeConnector
+
+func (c nativeFakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c nativeFakeConn) Close() error { return nil }
+func (c nativeFakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c nativeFakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if c.statements != nil {
+		*c.statements = append(*c.statements, query)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c nativeFakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	switch {
+	case strings.Contains(query, "information_schema.COLUMNS"):
+		return &nativeFakeRows{columns: 4, values: [][]string{{"id", "int", "PRI", ""}, {"note", "varchar", "", ""}}}, nil
+	case strings.Contains(query, "information_schema.STATISTICS"):
+		return &nativeFakeRows{columns: 1, values: [][]string{{"id"}}}, nil
+	case strings.Contains(query, "information_schema.ROUTINES"):
+		return &nativeFakeRows{columns: 3, values: [][]string{{"PROCEDURE", "refresh", ""}}}, nil
+	case strings.Contains(query, "information_schema.TRIGGERS"):
+		return &nativeFakeRows{columns: 3, values: [][]string{{"TRIGGER", "orders_bi", "orders"}, {"TRIGGER", "archive_bi", "archive"}}}, nil
+	case strings.Contains(query, "information_schema.EVENTS"):
+		return nil, errors.New("events must be skipped")
+	case strings.HasPrefix(query, "SHOW CREATE PROCEDURE"):
+		return &nativeFakeRows{columns: 6, names: []string{"Procedure", "sql_mode", "Create Procedure", "character_set_client", "collation_connection", "Database Collation"},
+			values: [][]string{{"refresh", "STRICT_TRANS_TABLES", "CREATE DEFINER=`prod_app`@`10.%` PROCEDURE `refresh`()\nBEGIN\n  DELETE FROM orders WHERE id < 0;\nEND", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci"}}}, nil
+	case strings.HasPrefix(query, "SHOW CREATE TRIGGER"):
+		return &nativeFakeRows{columns: 7, names: []string{"Trigger", "sql_mode", "SQL Original Statement", "character_set_client", "collation_connection", "Database Collation", "Created"},
+			values: [][]string{{"orders_bi", "", "CREATE DEFINER=`prod_app`@`10.%` TRIGGER `orders_bi` BEFORE INSERT ON `orders` FOR EACH ROW SET NEW.note = TRIM(NEW.note)", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci", "2026-01-01 00:00:00.00"}}}, nil
+	}
+	*c.queries++
+	if c.statements != nil {
+		*c.statements = append(*c.statements, "SELECT")
+	}
+	after := 0
+	if len(args) > 0 {
+		cursor, _ := args[0].Value.(string)
+		var err error
+		if after, err = strconv.Atoi(cursor); err != nil {
+			return nil, fmt.Errorf("invalid keyset cursor %q", cursor)
+		}
+	}
+	rows := &nativeFakeRows{columns: 2}
+	for id := after + 1; id <= c.rows && len(rows.values) < nativeChunkRows; id++ {
+		rows.values = append(rows.values, []string{strconv.Itoa(id), "order " + strconv.Itoa(id)})
+	}
+	return rows, nil
+}
+
+type nativeFakeRows struct {
+	columns int
+	names   []string
+	values  [][]string
+	buffers [][]byte
+}
+
+func (r *nativeFakeRows) Columns() []string {
+	if r.names != nil {
+		return r.names
+	}
+	return make([]string, r.columns)
+}
+
+func (r *nativeFakeRows) Close() error { return nil }
+
+func (r *nativeFakeRows) Next(dest []driver.Value) error {
+	if r.buffers == nil {
+		r.buffers = make([][]byte, r.columns)
+	}
+	if len(r.values) == 0 {
+		for _, buffer := range r.buffers {
+			for i := range buffer {
+				buffer[i] = 'x'
+			}
+		}
+		return io.EOF
+	}
+	for i, value := range r.values[0] {
+		r.buffers[i] = append(r.buffers[i][:0], value...)
+		dest[i] = r.buffers[i]
+	}
+	r.values = r.values[1:]
+	return nil
+}
+
+func TestNativeDumpTableDataKeysetChunks(t *testing.T) {
+	total := 2*nativeChunkRows + 7
+	queries := 0
+	var statements []string
+	db := sql.OpenDB(nativeFakeConnector{rows: total, queries: &queries, statements: &statements})
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	dumper := &nativeDumper{request: DumpRequest{DatabaseName: "shop", Dir: t.TempDir()}}
+	table := &nativeDumpTable{Name: "orders"}
+	if err := dumper.dumpTableData(context.Background(), conn, 0, table, ""); err != nil {
+		t.Fatalf("dumpTableData() error = %v", err)
+	}
+	if queries != 3 {
+		t.Fatalf("expected 3 keyset queries, got %d", queries)
+	}
+	if want := []string{"START TRANSACTION WITH CONSISTENT SNAPSHOT, READ ONLY", "SELECT", "SELECT", "SELECT", "ROLLBACK"}; !reflect.DeepEqual(statements, want) {
+		t.Fatalf("keyset chunks must be read in one snapshot transaction, got %q", statements)
+	}
+
+	seen := make(map[string]int, total)
+	for _, chunk := range table.Chunks {
+		reader, err := openNativeChunk(filepath.Join(dumper.request.Dir, chunk.File))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.SplitAfter(string(data), "\n") {
+			if line == "" {
+				continue
+			}
+			row, err := decodeNativeRow([]byte(line), table.Columns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			seen[row[0].(string)]++
+		}
+	}
+	if len(seen) != total {
+		t.Fatalf("expected %d distinct rows, got %d", total, len(seen))
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Fatalf("row %s dumped %d times", id, count)
+		}
+	}
+}
+
+func TestNativeDumpObjects(t *testing.T) {
+	queries := 0
+	db := sql.OpenDB(nativeFakeConnector{queries: &queries})
+	defer db.Close()
+
+	fixes := &compatFixes{}
+	dumper := &nativeDumper{request: DumpRequest{DatabaseName: "shop", Dir: t.TempDir(), Compat: &config.CompatConfig{SkipEvents: true}, Fixes: fixes}}
+	objects, err := dumper.dumpObjects(context.Background(), db, []nativeDumpTable{{Name: "orders"}})
+	if err != nil {
+		t.Fatalf("dumpObjects() error = %v", err)
+	}
+	want := []nativeDumpObject{
+		{Kind: "PROCEDURE", Name: "refresh", SQLMode: "STRICT_TRANS_TABLES", DDLFile: "o0000.sql"},
+		{Kind: "TRIGGER", Name: "orders_bi", DDLFile: "o0001.sql"},
+	}
+	if !reflect.DeepEqual(objects, want) {
+		t.Fatalf("objects = %+v, want %+v", objects, want)
+	}
+	ddl, err := os.ReadFile(filepath.Join(dumper.request.Dir, "o0001.sql"))
+	if err != nil || !strings.HasPrefix(string(ddl), "CREATE TRIGGER `orders_bi` BEFORE INSERT ON `orders`") {
+		t.Fatalf("trigger DDL must be dumped without DEFINER, got %q, %v", ddl, err)
+	}
+	if got := fixes.forSchema("shop"); len(got) != 2 || got[0].Object != "procedure `shop`.`refresh`" || got[0].Fix != "definer clause removed" {
+		t.Fatalf("removed definers must be reported, got %+v", got)
+	}
+
+	dumper.request.Tables = []string{"orders"}
+	dumper.request.Compat = nil
+	if objects, err := dumper.dumpObjects(context.Background(), db, []nativeDumpTable{{Name: "orders"}}); err != nil || len(objects) != 1 || objects[0].Kind != "TRIGGER" {
+		t.Fatalf("selected tables must keep only their triggers, got %+v, %v", objects, err)
+	}
+}
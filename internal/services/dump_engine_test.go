@@ -0,0 +1,191 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// installFakeTools подменяет PATH директорией с заглушками утилит движков.
+func installFakeTools(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+			t.Fatalf("write fake %s: %v", name, err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestDumpEngineSelection(t *testing.T) {
+	service := NewMySQLShellService(&config.Config{Dump: config.DumpConfig{Engine: config.DumpEngineMysqldump}}, nil)
+
+	installFakeTools(t, map[string]string{"mysqldump": ""})
+	if _, err := service.dumpEngine(); err == nil || !strings.Contains(err.Error(), "mysql not found in PATH") {
+		t.Fatalf("mysqldump engine without mysql client must be unavailable, got %v", err)
+	}
+
+	installFakeTools(t, map[string]string{"mysqldump": "", "mysql": "", "mydumper": "", "myloader": ""})
+	engine, err := service.dumpEngine()
+	if err != nil || engine.Name() != config.DumpEngineMysqldump {
+		t.Fatalf("explicit engine must be used even when others are installed, got %v err=%v", engine, err)
+	}
+
+	if _, err := service.findMySQLShell(); err == nil {
+		t.Skip("mysqlsh is installed, auto always selects it")
+	}
+	service.config.Dump.Engine = config.DumpEngineAuto
+	if engine, err := service.dumpEngine(); err != nil || engine.Name() != config.DumpEngineMydumper {
+		t.Fatalf("auto must fall back to mydumper first, got %v err=%v", engine, err)
+	}
+	installFakeTools(t, map[string]string{"mysqldump": "", "mysql": ""})
+	if engine, err := service.dumpEngine(); err != nil || engine.Name() != config.DumpEngineMysqldump {
+		t.Fatalf("auto must fall back to mysqldump, got %v err=%v", engine, err)
+	}
+	installFakeTools(t, nil)
+	if engine, err := service.dumpEngine(); err != nil || engine.Name() != config.DumpEngineNative {
+		t.Fatalf("auto without external tools must fall back to native, got %v err=%v", engine, err)
+	}
+}
+
+func TestDumpEngineForDirUsesMarker(t *testing.T) {
+	installFakeTools(t, map[string]string{"mysqldump": "", "mysql": ""})
+	service := NewMySQLShellService(&config.Config{}, nil)
+	dir := t.TempDir()
+
+	if isCompleteDump(dir) {
+		t.Fatal("dump without markers must be incomplete")
+	}
+	if err := writeDumpEngineMarker(dir, mysqldumpEngine{service: service}); err != nil {
+		t.Fatalf("writeDumpEngineMarker() error = %v", err)
+	}
+	if !isCompleteDump(dir) {
+		t.Fatal("engine marker must mark the dump as complete")
+	}
+	engine, err := service.dumpEngineForDir(dir)
+	if err != nil || engine.Name() != config.DumpEngineMysqldump {
+		t.Fatalf("dump must be loaded by the engine that created it, got %v err=%v", engine, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, dumpEngineMarker), []byte("pg_dump\n"), 0o600); err != nil {
+		t.Fatalf("write marker: %v", err)
+	}
+	if _, err := service.dumpEngineForDir(dir); err == nil || !strings.Contains(err.Error(), `unknown engine "pg_dump"`) {
+		t.Fatalf("unknown marker must be rejected, got %v", err)
+	}
+}
+
+func TestMysqldumpEngineDumpsWithProgress(t *testing.T) {
+	installFakeTools(t, map[string]string{"mysqldump": `for arg in "$@"; do
+	case "$arg" in --result-file=*) out="${arg#--result-file=}";; esac
+done
+echo "-- Connecting to 127.0.0.1..." >&2
+echo "-- Retrieving table structure for table orders..." >&2
+echo "-- Retrieving table structure for table users..." >&2
+echo "$@" > "$out"
+`})
+	service := NewMySQLShellService(&config.Config{}, nil)
+	service.SetQuiet(true)
+	dir := filepath.Join(t.TempDir(), "dump")
+
+	var mu sync.Mutex
+	var snapshots []models.ProgressSnapshot
+	err := mysqldumpEngine{service: service}.Dump(DumpRequest{
+		Conn:            config.MySQLConfig{Host: "127.0.0.1", Port: 3307, User: "reader", Password: "secret"},
+		DatabaseName:    "shop",
+		Tables:          []string{"orders", "users"},
+		Dir:             dir,
+		NetworkCompress: true,
+		TableCount:      2,
+		Phase:           models.SyncPhaseDump,
+	}, func(snapshot models.ProgressSnapshot) {
+		mu.Lock()
+		defer mu.Unlock()
+		snapshots = append(snapshots, snapshot)
+	})
+	if err != nil {
+		t.Fatalf("Dump() error = %v", err)
+	}
+
+	args, err := os.ReadFile(mysqldumpFilePath(dir, "shop"))
+	if err != nil {
+		t.Fatalf("dump file must be written: %v", err)
+	}
+	if !strings.Contains(string(args), "--compress --single-transaction") || !strings.HasSuffix(strings.TrimSpace(string(args)), "--password=secret shop orders users") {
+		t.Fatalf("unexpected mysqldump args: %s", args)
+	}
+	if len(snapshots) != 2 || snapshots[1].TableName != "users" || snapshots[1].Current != 1 || snapshots[1].Percent != 50 || snapshots[1].Phase != models.SyncPhaseDump || snapshots[1].DatabaseName != "shop" {
+		t.Fatalf("unexpected progress snapshots: %+v", snapshots)
+	}
+
+	installFakeTools(t, map[string]string{"mysqldump": `echo "Access denied for user 'reader'" >&2; exit 2`})
+	err = mysqldumpEngine{service: service}.Dump(DumpRequest{DatabaseName: "shop", Dir: dir, Phase: models.SyncPhaseBackup}, nil)
+	if err == nil || !strings.Contains(err.Error(), "mysqldump local backup failed: exit status 2") || !strings.Contains(err.Error(), "stderr: Access denied") {
+		t.Fatalf("failed dump must include tool output, got %v", err)
+	}
+}
+
+func TestMydumperArgsAndProgress(t *testing.T) {
+	request := DumpRequest{
+		Conn:         config.MySQLConfig{Host: "127.0.0.1", Port: 3307, User: "reader"},
+		DatabaseName: "shop",
+		Tables:       []string{"orders", "users"},
+		Dir:          "/tmp/dump",
+		Threads:      6,
+		Compress:     true,
+	}
+	joined := strings.Join(mydumperArgs(request), " ")
+	if !strings.Contains(joined, "--database=shop --outputdir=/tmp/dump --threads=6") || !strings.Contains(joined, "--tables-list=shop.orders,shop.users") || strings.Contains(joined, "--password") {
+		t.Fatalf("unexpected mydumper args: %s", joined)
+	}
+	joined = strings.Join(myloaderArgs(config.MySQLConfig{Host: "localhost", Port: 3306, User: "root"}, LoadRequest{DatabaseName: "shop", Schema: "shop__dbsync_incr", Dir: "/tmp/dump"}), " ")
+	if !strings.Contains(joined, "--source-db=shop --database=shop__dbsync_incr --overwrite-tables --threads=1") {
+		t.Fatalf("unexpected myloader args: %s", joined)
+	}
+
+	parse := mydumperLineParser(4)
+	if _, ok := parse("** Message: 10:00:00.000: Connected to a MySQL server"); ok {
+		t.Fatal("lines without a table must be ignored")
+	}
+	snapshot, ok := parse("** Message: 10:00:01.000: Thread 2: dumping data for `shop`.`orders`")
+	if !ok || snapshot.TableName != "orders" || snapshot.Message != "Dumping table orders" || snapshot.Current != 0 || snapshot.Total != 4 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+	snapshot, _ = parse("** Message: 10:00:02.000: Thread 1: dumping data from `shop`.`users` | Tables: 3/4")
+	if snapshot.Current != 3 || snapshot.Percent != 75 {
+		t.Fatalf("explicit table counter must win, got %+v", snapshot)
+	}
+	snapshot, _ = mydumperLineParser(0)("** Message: Thread 3: restoring `shop`.`users` part 1 of 2")
+	if snapshot.Message != "Restoring table users" {
+		t.Fatalf("unexpected myloader snapshot: %+v", snapshot)
+	}
+}
+
+func TestMySQLShellLineParser(t *testing.T) {
+	parse := mysqlShellLineParser(models.SyncPhaseDump)
+	snapshot, ok := parse("1 thds dumping - 45% (1.20 MB / 2.67 MB), 512.00 KB/s")
+	if !ok || snapshot.Percent != 45 || snapshot.Traffic.CurrentBytesPerSecond != 512*1024 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+	if _, ok := parse("Some unrelated line"); ok {
+		t.Fatal("unrelated output must be ignored")
+	}
+}
+
+func TestFullIncrementalPlan(t *testing.T) {
+	plan := &models.IncrementalPlan{
+		DatabaseName: "shop",
+		Incremental:  []models.IncrementalTable{{Name: "orders", Column: "updated_at"}},
+		Full:         []string{"users"},
+	}
+	full := fullIncrementalPlan(plan, incrementalReasonEngine)
+	if full.Active() || strings.Join(full.Full, ",") != "orders,users" || full.Reason != incrementalReasonEngine {
+		t.Fatalf("unexpected downgraded plan: %+v", full)
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// targetRoute возвращает имена source и destination цели с учетом встроенных endpoints.
+func targetRoute(target models.SyncTarget) (string, string) {
+	source, destination := target.Source, target.Destination
+	if source == "" {
+		source = config.EndpointRemote
+	}
+	if destination == "" {
+		destination = config.EndpointLocal
+	}
+	return source, destination
+}
+
+// sameRoute сообщает, что цели синхронизируются между одними и теми же endpoints.
+func sameRoute(a models.SyncTarget, b models.SyncTarget) bool {
+	aSource, aDestination := targetRoute(a)
+	bSource, bDestination := targetRoute(b)
+	return aSource == bSource && aDestination == bDestination
+}
+
+// routeService возвращает сервис, у которого Remote и Local указывают на source и destination цели.
+// Для приемника за proxy открывается туннель: Local переключается на его адрес до вызова cleanup,
+// поэтому mysqlsh, myloader, mysql и native загрузка пишут через тот же транспорт, что и DatabaseService.
+func (s *MySQLShellService) routeService(target models.SyncTarget) (*MySQLShellService, func(), error) {
+	sourceName, destinationName := targetRoute(target)
+	defaultRoute := sourceName == config.EndpointRemote && destinationName == config.EndpointLocal
+	if defaultRoute && !s.config.Local.HasProxy() {
+		return s, func() {}, nil
+	}
+
+	source, err := s.config.ResolveEndpoint(sourceName)
+	if err != nil {
+		return nil, nil, err
+	}
+	destination, err := s.config.ResolveEndpoint(destinationName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ValidateRoute(source, destination); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := *s.config
+	cfg.Remote = source.MySQL()
+	cfg.Local = destination.MySQL()
+	service := *s
+	service.config = &cfg
+	service.destination = &destination
+	if !defaultRoute {
+		service.dbService = s.newDatabaseService(&cfg)
+	}
+
+	cleanup := func() {}
+	if cfg.Local.HasProxy() {
+		tunnel, err := newProxyTunnel(cfg.Local)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to start destination proxy tunnel: %w", err)
+		}
+		cfg.Local.Host = tunnel.Host()
+		cfg.Local.Port = tunnel.Port()
+		cfg.Local.ProxyURL = ""
+		cleanup = func() {
+			_ = tunnel.Close()
+		}
+	}
+	return &service, cleanup, nil
+}
+
+// ValidateRoute отклоняет маршрут, который перезапишет сам источник или БД на сервере,
+// не являющемся localhost, без явного "writable": true в файле endpoints.
+func ValidateRoute(source config.Endpoint, destination config.Endpoint) error {
+	if !destination.CanWrite() {
+		if destination.Name == config.EndpointRemote {
+			return fmt.Errorf("endpoint %q is read-only and cannot be a destination", destination.Name)
+		}
+		return fmt.Errorf("destination endpoint %q (%s) is not localhost; set \"writable\": true for it in the endpoints file to allow replacing its databases", destination.Name, destination.MySQL().Address())
+	}
+	if sameServer(source.MySQL(), destination.MySQL()) {
+		return fmt.Errorf("source %q and destination %q point to the same server %s", source.Name, destination.Name, destination.MySQL().Address())
+	}
+	return nil
+}
+
+func sameServer(a config.MySQLConfig, b config.MySQLConfig) bool {
+	return strings.EqualFold(a.Host, b.Host) && a.Port == b.Port && strings.TrimSpace(a.ProxyURL) == strings.TrimSpace(b.ProxyURL)
+}
+
+// destinationConfig возвращает настройки приемника без подмены адреса туннелем.
+func (s *MySQLShellService) destinationConfig() config.MySQLConfig {
+	if s.destination != nil {
+		return s.destination.MySQL()
+	}
+	return s.config.Local
+}
+
+// backupDatabaseDir возвращает каталог бэкапов БД. Бэкапы приемников, отличных от local, лежат
+// в @<endpoint>, чтобы restore-backup и откат из TUI не загрузили их в локальный сервер.
+func (s *MySQLShellService) backupDatabaseDir(databaseName string) string {
+	if s.destination != nil && s.destination.Name != config.EndpointLocal {
+		return filepath.Join(s.config.Backup.ResolvedDir(), "@"+s.destination.Name, databaseName)
+	}
+	return filepath.Join(s.config.Backup.ResolvedDir(), databaseName)
+}
+
+// executeRoutedBatch выполняет пакет целей сервисом, направленным на их source и destination.
+func (s *MySQLShellService) executeRoutedBatch(targets []models.SyncTarget, observer models.ProgressObserver) ([]models.SyncResult, error) {
+	service, cleanup, err := s.routeService(targets[0])
+	if err != nil {
+		now := time.Now()
+		target := targets[0]
+		if observer != nil {
+			observer(models.ProgressSnapshot{Phase: models.SyncPhaseFailed, DatabaseName: target.DatabaseName, Message: err.Error(), Timestamp: now})
+		}
+		return []models.SyncResult{{DatabaseName: target.DatabaseName, Error: err.Error(), FailureType: models.FailureTypeError, Source: target.Source, Destination: target.Destination, StartTime: now, EndTime: now}}, err
+	}
+	defer cleanup()
+	return service.executeBatch(targets, observer)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+// writeEndpointsFile записывает файл именованных endpoints: реплику, staging за proxy и qa без права записи.
+func writeEndpointsFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "endpoints.json")
+	content := `{"endpoints": [
+		{"name": "prod-replica", "host": "replica.internal", "user": "reader"},
+		{"name": "staging", "host": "staging.internal", "user": "dbsync", "proxy_url": "socks5://bastion.internal:1080", "writable": true},
+		{"name": "qa", "host": "qa.internal", "user": "dbsync"}
+	]}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRouteServiceSwitchesEndpoints(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Endpoints.File = writeEndpointsFile(t)
+	defaultDB := &mocks.MockDatabaseService{}
+	service := NewMySQLShellService(cfg, defaultDB)
+	var routedConfig *config.Config
+	service.newDatabaseService = func(cfg *config.Config) DatabaseServiceInterface {
+		routedConfig = cfg
+		return &mocks.MockDatabaseService{}
+	}
+
+	same, cleanup, err := service.routeService(models.SyncTarget{DatabaseName: "shop"})
+	if err != nil || same != service {
+		t.Fatalf("default route must reuse the service, got %p, %v", same, err)
+	}
+	cleanup()
+
+	routed, cleanup, err := service.routeService(models.SyncTarget{DatabaseName: "shop", Source: "prod-replica", Destination: "staging"})
+	if err != nil {
+		t.Fatalf("routeService() error = %v", err)
+	}
+	defer cleanup()
+	if routed.config.Remote.Host != "replica.internal" || routed.dbService == DatabaseServiceInterface(defaultDB) || routedConfig != routed.config {
+		t.Fatalf("source endpoint was not applied: %+v", routed.config.Remote)
+	}
+	// Приемник за proxy пишется через локальный туннель, а профиль и бэкапы привязаны к исходному адресу.
+	if routed.config.Local.Host != "127.0.0.1" || routed.config.Local.HasProxy() || routed.config.Local.Port == 3306 {
+		t.Fatalf("destination must be reached through the tunnel, got %+v", routed.config.Local)
+	}
+	if got := routed.destinationConfig().Host; got != "staging.internal" || !strings.Contains(routed.syncProfile(), "staging.internal") {
+		t.Fatalf("unexpected destination config %q, profile %q", got, routed.syncProfile())
+	}
+	if got := routed.backupDatabaseDir("shop"); got != filepath.Join(cfg.Backup.Dir, "@staging", "shop") {
+		t.Fatalf("backups of remote destinations must be kept apart, got %s", got)
+	}
+	if service.config.Local.Host != "127.0.0.1" || service.config.Remote.Host != "prod.example.com" {
+		t.Fatal("routing must not modify the shared config")
+	}
+}
+
+func TestRouteServiceGuardsDestination(t *testing.T) {
+	tests := []struct {
+		name   string
+		target models.SyncTarget
+		want   string
+	}{
+		{name: "not writable", target: models.SyncTarget{DatabaseName: "shop", Destination: "qa"}, want: `destination endpoint "qa" (qa.internal:3306) is not localhost`},
+		{name: "remote is read-only", target: models.SyncTarget{DatabaseName: "shop", Source: "prod-replica", Destination: "remote"}, want: `endpoint "remote" is read-only`},
+		{name: "same server", target: models.SyncTarget{DatabaseName: "shop", Source: "staging", Destination: "staging"}, want: "point to the same server staging.internal:3306"},
+		{name: "unknown", target: models.SyncTarget{DatabaseName: "shop", Destination: "prod"}, want: `unknown endpoint "prod"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig(t)
+			cfg.Endpoints.File = writeEndpointsFile(t)
+			service := newTestService(cfg, &mocks.MockDatabaseService{})
+			results, err := service.ExecutePlan(&models.SyncPlan{Targets: []models.SyncTarget{tt.target}}, models.RuntimeOptions{}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if len(results) != 1 || results[0].Success || results[0].Destination != tt.target.Destination {
+				t.Fatalf("unexpected results %+v", results)
+			}
+		})
+	}
+}
@@ -0,0 +1,868 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+
+	gomysql "github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+const (
+	followHeartbeatPeriod   = 2 * time.Second
+	followStateSaveInterval = time.Second
+	followStatusInterval    = time.Second
+
+	// Автоматический server_id берется из диапазона, который обычно не занят реальными репликами.
+	followServerIDBase  = 1_000_000_000
+	followServerIDRange = 1_000_000_000
+)
+
+// followState хранит позицию binlog, до которой локальная копия базы уже догнала remote.
+type followState struct {
+	DatabaseName string                `json:"database_name"`
+	Profile      string                `json:"profile"`
+	Position     models.BinlogPosition `json:"position"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+func (s *MySQLShellService) followStatePath(databaseName string) string {
+	return filepath.Join(s.config.Follow.ResolvedDir(), s.syncProfile(), databaseName+".json")
+}
+
+// LoadFollowPosition возвращает сохраненную позицию follow режима или nil, если база еще не отслеживалась.
+func (s *MySQLShellService) LoadFollowPosition(databaseName string) (*models.BinlogPosition, error) {
+	data, err := os.ReadFile(s.followStatePath(databaseName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read follow position: %w", err)
+	}
+	var state followState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse follow position: %w", err)
+	}
+	return &state.Position, nil
+}
+
+func (s *MySQLShellService) saveFollowPosition(databaseName string, position models.BinlogPosition) error {
+	state := followState{DatabaseName: databaseName, Profile: s.syncProfile(), Position: position, UpdatedAt: time.Now()}
+	if err := writeJSONFileAtomic(s.followStatePath(databaseName), state); err != nil {
+		return fmt.Errorf("failed to write follow position: %w", err)
+	}
+	return nil
+}
+
+// followServerID возвращает server_id реплики: явный из конфига или стабильный для профиля и базы.
+func (s *MySQLShellService) followServerID(databaseName string) uint32 {
+	if s.config.Follow.ServerID > 0 {
+		return uint32(s.config.Follow.ServerID)
+	}
+	hostname, _ := os.Hostname()
+	hash := crc32.ChecksumIEEE([]byte(hostname + "/" + s.syncProfile() + "/" + databaseName))
+	return followServerIDBase + hash%followServerIDRange
+}
+
+// validateBinlogStatus проверяет, что из binlog remote можно восстановить полные строки.
+func validateBinlogStatus(status *models.BinlogStatus) error {
+	if !strings.EqualFold(status.Format, "ROW") {
+		return fmt.Errorf("follow requires binlog_format=ROW on the remote server, got %s", status.Format)
+	}
+	if !strings.EqualFold(status.RowImage, "FULL") {
+		return fmt.Errorf("follow requires binlog_row_image=FULL on the remote server, got %s", status.RowImage)
+	}
+	return nil
+}
+
+// Follow выполняет начальную синхронизацию и затем применяет row events remote базы к локальной копии,
+// пока не будет отменен ctx. С resume начальная синхронизация пропускается и поток продолжается
+// с сохраненной позиции. Follow пишет в приемник как синхронизация, поэтому проверяется той же политикой.
+func (s *MySQLShellService) Follow(ctx context.Context, target models.SyncTarget, resume bool, observer models.FollowObserver) error {
+	if _, err := s.enforcePolicy(&models.SyncPlan{Targets: []models.SyncTarget{target}, ConfirmedDatabases: s.confirmed}, nil); err != nil {
+		return err
+	}
+	service, cleanup, err := s.routeService(target)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	return service.follow(ctx, target, resume, observer)
+}
+
+func (s *MySQLShellService) follow(ctx context.Context, target models.SyncTarget, resume bool, observer models.FollowObserver) error {
+	databaseName := target.DatabaseName
+	emit := func(status models.FollowStatus) {
+		if observer != nil {
+			status.DatabaseName = databaseName
+			status.Timestamp = time.Now()
+			observer(status)
+		}
+	}
+
+	status, err := s.dbService.BinlogStatus(true)
+	if err != nil {
+		return fmt.Errorf("failed to read remote binlog status: %w", err)
+	}
+	if err := validateBinlogStatus(status); err != nil {
+		return err
+	}
+
+	var start models.BinlogPosition
+	if resume {
+		saved, err := s.LoadFollowPosition(databaseName)
+		if err != nil {
+			return err
+		}
+		if saved == nil {
+			return fmt.Errorf("no saved follow position for database '%s'; run follow without --resume first", databaseName)
+		}
+		start = *saved
+	} else {
+		// Позиция берется до дампа: события, попавшие и в дамп, и в binlog, применяются
+		// идемпотентно (REPLACE/DELETE по ключу), поэтому повтор не портит данные.
+		start = status.Position
+		emit(models.FollowStatus{Phase: models.FollowPhaseInitialSync, Position: start, Message: "Running initial sync"})
+		target.ForceFull = true
+		var mu sync.Mutex
+		lastMessage := ""
+		_, err := s.executeTarget(target, func(snapshot models.ProgressSnapshot) {
+			// Прогресс дампа приходит несколько раз в секунду из разных горутин; follow интересна только смена этапа.
+			mu.Lock()
+			defer mu.Unlock()
+			if snapshot.Message != lastMessage {
+				lastMessage = snapshot.Message
+				emit(models.FollowStatus{Phase: models.FollowPhaseInitialSync, Position: start, Message: snapshot.Message})
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("initial sync failed: %w", err)
+		}
+		if err := s.saveFollowPosition(databaseName, start); err != nil {
+			return err
+		}
+	}
+
+	err = s.streamBinlog(ctx, databaseName, start, emit)
+	if err != nil {
+		emit(models.FollowStatus{Phase: models.FollowPhaseFailed, Position: start, Message: err.Error()})
+	}
+	return err
+}
+
+// streamBinlog подключается к remote как реплика и применяет события до отмены ctx.
+func (s *MySQLShellService) streamBinlog(ctx context.Context, databaseName string, start models.BinlogPosition, emit models.FollowObserver) error {
+	tunnel, err := newProxyTunnel(s.config.Remote)
+	if err != nil {
+		return fmt.Errorf("failed to start proxy tunnel: %w", err)
+	}
+	defer tunnel.Close()
+
+	syncer := replication.NewBinlogSyncer(replication.BinlogSyncerConfig{
+		ServerID:                s.followServerID(databaseName),
+		Flavor:                  gomysql.MySQLFlavor,
+		Host:                    tunnel.Host(),
+		Port:                    uint16(tunnel.Port()),
+		User:                    s.config.Remote.User,
+		Password:                s.config.Remote.Password,
+		HeartbeatPeriod:         followHeartbeatPeriod,
+		TimestampStringLocation: time.UTC,
+		Logger:                  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	defer syncer.Close()
+
+	var streamer *replication.BinlogStreamer
+	if start.GTIDSet != "" {
+		gtidSet, err := gomysql.ParseMysqlGTIDSet(start.GTIDSet)
+		if err != nil {
+			return fmt.Errorf("invalid GTID set %q: %w", start.GTIDSet, err)
+		}
+		streamer, err = syncer.StartSyncGTID(gtidSet)
+		if err != nil {
+			return fmt.Errorf("failed to start binlog stream: %w", err)
+		}
+	} else {
+		streamer, err = syncer.StartSync(gomysql.Position{Name: start.File, Pos: start.Position})
+		if err != nil {
+			return fmt.Errorf("failed to start binlog stream: %w", err)
+		}
+	}
+
+	applier, err := newSQLFollowApplier(s.config.Local, databaseName)
+	if err != nil {
+		return err
+	}
+	defer applier.Close()
+
+	follower := newBinlogFollower(databaseName, start, applier)
+	emit(follower.status(models.FollowPhaseStreaming, "Streaming binlog"))
+	lastSaved := time.Now()
+	lastEmitted := time.Time{}
+	for {
+		event, err := streamer.GetEvent(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				if err := s.saveFollowPosition(databaseName, follower.position); err != nil {
+					return err
+				}
+				emit(follower.status(models.FollowPhaseStopped, "Follow stopped"))
+				return nil
+			}
+			return fmt.Errorf("binlog stream failed: %w", err)
+		}
+
+		committed, err := follower.handle(event, time.Now())
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if committed && now.Sub(lastSaved) >= followStateSaveInterval {
+			if err := s.saveFollowPosition(databaseName, follower.position); err != nil {
+				return err
+			}
+			lastSaved = now
+		}
+		if now.Sub(lastEmitted) >= followStatusInterval {
+			emit(follower.status(models.FollowPhaseStreaming, ""))
+			lastEmitted = now
+		}
+	}
+}
+
+// followColumn описывает колонку локальной таблицы в порядке ORDINAL_POSITION.
+type followColumn struct {
+	Name      string
+	DataType  string
+	Key       bool
+	Unsigned  bool
+	Generated bool
+}
+
+// followStatement — параметризованное выражение для локальной БД.
+type followStatement struct {
+	Query string
+	Args  []any
+}
+
+// followApplier применяет изменения к локальной копии базы.
+type followApplier interface {
+	Columns(tableName string) ([]followColumn, error)
+	Apply(statements []followStatement) error
+	Exec(query string) error
+}
+
+// binlogFollower превращает события binlog в транзакции локальной БД и отслеживает позицию.
+type binlogFollower struct {
+	databaseName string
+	applier      followApplier
+	position     models.BinlogPosition
+	pending      []followStatement
+	pendingRows  int64
+	transactions int64
+	rowsApplied  int64
+	lag          time.Duration
+	lastEventAt  time.Time
+}
+
+func newBinlogFollower(databaseName string, start models.BinlogPosition, applier followApplier) *binlogFollower {
+	return &binlogFollower{databaseName: databaseName, applier: applier, position: start}
+}
+
+func (f *binlogFollower) status(phase models.FollowPhase, message string) models.FollowStatus {
+	return models.FollowStatus{
+		Phase:        phase,
+		Position:     f.position,
+		Lag:          f.lag,
+		Transactions: f.transactions,
+		RowsApplied:  f.rowsApplied,
+		LastEventAt:  f.lastEventAt,
+		Message:      message,
+	}
+}
+
+// handle обрабатывает одно событие; committed сообщает, что позиция продвинулась на границу транзакции.
+func (f *binlogFollower) handle(event *replication.BinlogEvent, now time.Time) (bool, error) {
+	header := event.Header
+	switch e := event.Event.(type) {
+	case *replication.RotateEvent:
+		f.position.File = string(e.NextLogName)
+		f.position.Position = uint32(e.Position)
+		return false, nil
+	case *replication.RowsEvent:
+		if string(e.Table.Schema) != f.databaseName {
+			return false, nil
+		}
+		tableName := string(e.Table.Table)
+		columns, err := f.applier.Columns(tableName)
+		if err != nil {
+			return false, err
+		}
+		statements, rows, err := rowsEventStatements(f.databaseName, tableName, columns, e.Type(), e.Rows)
+		if err != nil {
+			return false, err
+		}
+		f.pending = append(f.pending, statements...)
+		f.pendingRows += rows
+		return false, nil
+	case *replication.QueryEvent:
+		query := strings.TrimSpace(string(e.Query))
+		if strings.EqualFold(query, "BEGIN") {
+			f.pending = nil
+			f.pendingRows = 0
+			return false, nil
+		}
+		// Нетранзакционные движки завершают транзакцию выражением COMMIT вместо XID.
+		if strings.EqualFold(query, "COMMIT") {
+			return true, f.commit(header, e.GSet, now)
+		}
+		// В ROW формате через QueryEvent приходят только DDL и служебные выражения. Схема события — лишь
+		// база по умолчанию сессии, поэтому цель DDL определяется по именам объектов в самом выражении.
+		applies, err := ddlAppliesTo(query, string(e.Schema), f.databaseName)
+		if err != nil {
+			return false, err
+		}
+		if applies {
+			if err := f.applier.Exec(query); err != nil {
+				return false, fmt.Errorf("failed to apply DDL %q: %w", query, err)
+			}
+			f.transactions++
+		}
+		return true, f.commit(header, e.GSet, now)
+	case *replication.XIDEvent:
+		return true, f.commit(header, e.GSet, now)
+	}
+	if header.EventType == replication.HEARTBEAT_EVENT || header.EventType == replication.HEARTBEAT_LOG_EVENT_V2 {
+		// Heartbeat приходит, только когда remote нечего отправить: реплика догнала источник.
+		f.lag = 0
+	}
+	return false, nil
+}
+
+// commit применяет накопленные строки транзакции одной локальной транзакцией и продвигает позицию.
+func (f *binlogFollower) commit(header *replication.EventHeader, gtidSet gomysql.GTIDSet, now time.Time) error {
+	if len(f.pending) > 0 {
+		if err := f.applier.Apply(f.pending); err != nil {
+			return fmt.Errorf("failed to apply transaction at %s:%d: %w", f.position.File, header.LogPos, err)
+		}
+		f.transactions++
+		f.rowsApplied += f.pendingRows
+	}
+	f.pending = nil
+	f.pendingRows = 0
+	if header.LogPos > 0 {
+		f.position.Position = header.LogPos
+	}
+	if gtidSet != nil {
+		f.position.GTIDSet = gtidSet.String()
+	}
+	if header.Timestamp > 0 {
+		f.lastEventAt = time.Unix(int64(header.Timestamp), 0)
+		f.lag = max(now.Sub(f.lastEventAt), 0)
+	}
+	return nil
+}
+
+// rowsEventStatements строит идемпотентные выражения для row event: вставки и обновления
+// становятся REPLACE, удаления — DELETE по первичному ключу (или по всем колонкам без ключа).
+func rowsEventStatements(databaseName string, tableName string, columns []followColumn, eventType replication.EnumRowsEventType, rows [][]any) ([]followStatement, int64, error) {
+	for _, row := range rows {
+		if len(row) != len(columns) {
+			return nil, 0, fmt.Errorf("table %s has %d columns locally but binlog row has %d; run a full sync", tableName, len(columns), len(row))
+		}
+	}
+	qualified := quoteIdentifier(databaseName) + "." + quoteIdentifier(tableName)
+
+	switch eventType {
+	case replication.EnumRowsEventTypeInsert:
+		if len(rows) == 0 {
+			return nil, 0, nil
+		}
+		return []followStatement{replaceStatement(qualified, columns, rows)}, int64(len(rows)), nil
+	case replication.EnumRowsEventTypeDelete:
+		statements := make([]followStatement, 0, len(rows))
+		for _, row := range rows {
+			statements = append(statements, deleteStatement(qualified, columns, row))
+		}
+		return statements, int64(len(rows)), nil
+	case replication.EnumRowsEventTypeUpdate:
+		if len(rows)%2 != 0 {
+			return nil, 0, fmt.Errorf("table %s: update event has unpaired row images", tableName)
+		}
+		statements := make([]followStatement, 0, len(rows))
+		for index := 0; index < len(rows); index += 2 {
+			before, after := rows[index], rows[index+1]
+			if !sameRowKey(columns, before, after) {
+				statements = append(statements, deleteStatement(qualified, columns, before))
+			}
+			statements = append(statements, replaceStatement(qualified, columns, [][]any{after}))
+		}
+		return statements, int64(len(rows) / 2), nil
+	}
+	return nil, 0, fmt.Errorf("table %s: unsupported rows event %s", tableName, eventType)
+}
+
+func replaceStatement(qualified string, columns []followColumn, rows [][]any) followStatement {
+	names := make([]string, 0, len(columns))
+	placeholders := make([]string, 0, len(columns))
+	for _, column := range columns {
+		// Значения generated колонок MySQL вычисляет сам и не принимает во вставке.
+		if column.Generated {
+			continue
+		}
+		names = append(names, quoteIdentifier(column.Name))
+		placeholders = append(placeholders, "?")
+	}
+	tuple := "(" + strings.Join(placeholders, ", ") + ")"
+	tuples := make([]string, 0, len(rows))
+	args := make([]any, 0, len(rows)*len(names))
+	for _, row := range rows {
+		tuples = append(tuples, tuple)
+		for index, column := range columns {
+			if !column.Generated {
+				args = append(args, followValue(row[index], column))
+			}
+		}
+	}
+	query := fmt.Sprintf("REPLACE INTO %s (%s) VALUES %s", qualified, strings.Join(names, ", "), strings.Join(tuples, ", "))
+	return followStatement{Query: query, Args: args}
+}
+
+func deleteStatement(qualified string, columns []followColumn, row []any) followStatement {
+	keyed := hasKeyColumns(columns)
+	conditions := make([]string, 0, len(columns))
+	args := make([]any, 0, len(columns))
+	for index, column := range columns {
+		if (keyed && !column.Key) || column.Generated {
+			continue
+		}
+		conditions = append(conditions, quoteIdentifier(column.Name)+" <=> ?")
+		args = append(args, followValue(row[index], column))
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT 1", qualified, strings.Join(conditions, " AND "))
+	return followStatement{Query: query, Args: args}
+}
+
+func hasKeyColumns(columns []followColumn) bool {
+	for _, column := range columns {
+		if column.Key {
+			return true
+		}
+	}
+	return false
+}
+
+func sameRowKey(columns []followColumn, before []any, after []any) bool {
+	if !hasKeyColumns(columns) {
+		return false
+	}
+	for index, column := range columns {
+		if column.Key && fmt.Sprint(before[index]) != fmt.Sprint(after[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// followValue приводит значение из binlog к виду для локальной вставки: binlog не хранит
+// знаковость, поэтому unsigned колонки приходят как отрицательные числа.
+func followValue(value any, column followColumn) any {
+	if !column.Unsigned {
+		return value
+	}
+	switch v := value.(type) {
+	case int8:
+		return uint8(v)
+	case int16:
+		return uint16(v)
+	case int32:
+		if column.DataType == "mediumint" {
+			return uint32(v) & 0xFFFFFF
+		}
+		return uint32(v)
+	case int64:
+		return uint64(v)
+	}
+	return value
+}
+
+// sqlFollowApplier применяет изменения через одно соединение с локальной базой.
+type sqlFollowApplier struct {
+	db           *sql.DB
+	conn         *sql.Conn
+	cleanup      func()
+	databaseName string
+	columns      map[string][]followColumn
+}
+
+func newSQLFollowApplier(mysqlConfig config.MySQLConfig, databaseName string) (*sqlFollowApplier, error) {
+	db, cleanup, err := openMySQLConnection(mysqlConfig, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local connection: %w", err)
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		db.Close()
+		cleanup()
+		return nil, fmt.Errorf("failed to open local connection: %w", err)
+	}
+	applier := &sqlFollowApplier{db: db, conn: conn, cleanup: cleanup, databaseName: databaseName, columns: make(map[string][]followColumn)}
+	// Как и реплика, применяем строки без проверок режима и FK: порядок уже гарантирован источником.
+	for _, statement := range []string{"SET SESSION sql_mode = ''", "SET SESSION time_zone = '+00:00'", "SET SESSION foreign_key_checks = 0"} {
+		if err := applier.Exec(statement); err != nil {
+			applier.Close()
+			return nil, fmt.Errorf("failed to prepare local session: %w", err)
+		}
+	}
+	return applier, nil
+}
+
+func (a *sqlFollowApplier) Columns(tableName string) ([]followColumn, error) {
+	if columns, ok := a.columns[tableName]; ok {
+		return columns, nil
+	}
+	rows, err := a.conn.QueryContext(context.Background(), `
+		SELECT COLUMN_NAME, DATA_TYPE, COLUMN_KEY, COLUMN_TYPE, EXTRA
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, a.databaseName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	var columns []followColumn
+	for rows.Next() {
+		var column followColumn
+		var columnKey, columnType, extra string
+		if err := rows.Scan(&column.Name, &column.DataType, &columnKey, &columnType, &extra); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", tableName, err)
+		}
+		column.Key = columnKey == "PRI"
+		column.Unsigned = strings.Contains(strings.ToLower(columnType), "unsigned")
+		column.Generated = strings.Contains(strings.ToUpper(extra), "GENERATED")
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", tableName, err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s.%s does not exist locally; run a full sync", a.databaseName, tableName)
+	}
+	a.columns[tableName] = columns
+	return columns, nil
+}
+
+func (a *sqlFollowApplier) Apply(statements []followStatement) error {
+	ctx := context.Background()
+	tx, err := a.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement.Query, statement.Args...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Exec выполняет выражение без параметров; после DDL кэш колонок сбрасывается.
+func (a *sqlFollowApplier) Exec(query string) error {
+	a.columns = make(map[string][]followColumn)
+	_, err := a.conn.ExecContext(context.Background(), query)
+	return err
+}
+
+func (a *sqlFollowApplier) Close() {
+	a.conn.Close()
+	a.db.Close()
+	a.cleanup()
+}
+
+// ddlToken — слово, идентификатор, литерал или знак выражения; Quoted отличает `идентификатор`
+// и строку от ключевого слова.
+type ddlToken struct {
+	Text   string
+	Quoted bool
+}
+
+// tokenizeDDL разбивает выражение на токены без комментариев. Содержимое исполняемых комментариев
+// /*!50001 ... */, которыми mysqldump оборачивает DEFINER и заголовки представлений, сохраняется.
+func tokenizeDDL(query string) []ddlToken {
+	var tokens []ddlToken
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || strings.HasPrefix(query[i:], "-- "):
+			line, _, _ := strings.Cut(query[i:], "\n")
+			i += len(line)
+		case strings.HasPrefix(query[i:], "/*!"):
+			i += 3
+			for i < len(query) && query[i] >= '0' && query[i] <= '9' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case strings.HasPrefix(query[i:], "*/"):
+			i += 2
+		case c == '`':
+			var name strings.Builder
+			for i++; i < len(query); i++ {
+				if query[i] == '`' {
+					if i+1 < len(query) && query[i+1] == '`' {
+						name.WriteByte('`')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				name.WriteByte(query[i])
+			}
+			tokens = append(tokens, ddlToken{Text: name.String(), Quoted: true})
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(query) && query[end] != c {
+				if query[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(query))
+			tokens = append(tokens, ddlToken{Text: query[i:end], Quoted: true})
+			i = end
+		case isDDLWordByte(c):
+			start := i
+			for i < len(query) && isDDLWordByte(query[i]) {
+				i++
+			}
+			tokens = append(tokens, ddlToken{Text: query[start:i]})
+		default:
+			tokens = append(tokens, ddlToken{Text: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+func isDDLWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// ddlParser разбирает заголовок DDL до имен объектов, которые выражение создает, меняет или удаляет.
+type ddlParser struct {
+	tokens        []ddlToken
+	pos           int
+	defaultSchema string
+	schemas       []string
+}
+
+// ddlTargetSchemas возвращает схемы объектов, которые меняет DDL; неквалифицированные имена относятся
+// к defaultSchema. Пустой список означает выражение уровня сервера (пользователи, GRANT, FLUSH),
+// false — что выражение не разобрано.
+func ddlTargetSchemas(query string, defaultSchema string) ([]string, bool) {
+	p := &ddlParser{tokens: tokenizeDDL(query), defaultSchema: defaultSchema}
+	return p.parse()
+}
+
+func (p *ddlParser) parse() ([]string, bool) {
+	verb := p.word()
+	p.pos++
+	switch verb {
+	case "CREATE", "ALTER", "DROP":
+		// Модификаторы (OR REPLACE, TEMPORARY, UNIQUE, ALGORITHM=, DEFINER=, SQL SECURITY) пропускаются до вида объекта.
+		for ; p.pos < len(p.tokens); p.pos++ {
+			switch kind := p.word(); kind {
+			case "TABLE", "VIEW", "INDEX", "TRIGGER", "PROCEDURE", "FUNCTION", "EVENT", "DATABASE", "SCHEMA":
+				p.pos++
+				return p.object(verb, kind)
+			case "USER", "ROLE", "TABLESPACE", "SERVER", "LOGFILE", "UNDO", "INSTANCE", "REFERENCE":
+				return nil, true
+			}
+		}
+		return nil, false
+	case "RENAME":
+		if !p.accept("TABLE") && !p.accept("TABLES") {
+			return nil, true
+		}
+		for {
+			if !p.name() || !p.accept("TO") || !p.name() {
+				return nil, false
+			}
+			if !p.acceptSymbol(",") {
+				return p.schemas, true
+			}
+		}
+	case "TRUNCATE":
+		p.accept("TABLE")
+		return p.schemas, p.name()
+	case "GRANT", "REVOKE", "FLUSH", "ANALYZE", "OPTIMIZE", "REPAIR", "SET", "INSTALL", "UNINSTALL":
+		return nil, true
+	}
+	return nil, false
+}
+
+func (p *ddlParser) object(verb string, kind string) ([]string, bool) {
+	switch kind {
+	case "INDEX":
+		for ; p.pos < len(p.tokens); p.pos++ {
+			if p.word() == "ON" {
+				p.pos++
+				return p.schemas, p.name()
+			}
+		}
+		return nil, false
+	case "DATABASE", "SCHEMA":
+		p.skipIfExists()
+		switch word := p.word(); {
+		case p.pos >= len(p.tokens), word == "DEFAULT", word == "CHARACTER", word == "CHARSET", word == "COLLATE", word == "ENCRYPTION", word == "READ":
+			return []string{p.defaultSchema}, true
+		}
+		return []string{p.tokens[p.pos].Text}, true
+	}
+
+	p.skipIfExists()
+	if verb == "DROP" && (kind == "TABLE" || kind == "VIEW") {
+		return p.schemas, p.nameList()
+	}
+	if !p.name() {
+		return nil, false
+	}
+	// ALTER TABLE ... RENAME TO переносит таблицу и может увести ее в другую схему.
+	if verb == "ALTER" && kind == "TABLE" {
+		for ; p.pos < len(p.tokens); p.pos++ {
+			if p.word() != "RENAME" {
+				continue
+			}
+			p.pos++
+			if word := p.word(); word == "COLUMN" || word == "INDEX" || word == "KEY" {
+				continue
+			}
+			if !p.accept("TO") {
+				p.accept("AS")
+			}
+			if !p.name() {
+				return nil, false
+			}
+		}
+	}
+	return p.schemas, true
+}
+
+// word возвращает текущее ключевое слово в верхнем регистре или пустую строку для идентификатора в кавычках.
+func (p *ddlParser) word() string {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].Quoted {
+		return ""
+	}
+	return strings.ToUpper(p.tokens[p.pos].Text)
+}
+
+func (p *ddlParser) accept(word string) bool {
+	if p.word() == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) acceptSymbol(symbol string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].Quoted && p.tokens[p.pos].Text == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) skipIfExists() {
+	if p.accept("IF") {
+		p.accept("NOT")
+		p.accept("EXISTS")
+	}
+}
+
+func (p *ddlParser) isName(index int) bool {
+	if index >= len(p.tokens) {
+		return false
+	}
+	token := p.tokens[index]
+	return (token.Quoted && strings.IndexByte("'\"", token.Text[0]) < 0) || (!token.Quoted && isDDLWordByte(token.Text[0]))
+}
+
+// name читает имя [схема.]объект и запоминает его схему.
+func (p *ddlParser) name() bool {
+	if !p.isName(p.pos) {
+		return false
+	}
+	schema := p.defaultSchema
+	first := p.tokens[p.pos].Text
+	p.pos++
+	if p.acceptSymbol(".") {
+		if !p.isName(p.pos) {
+			return false
+		}
+		schema = first
+		p.pos++
+	}
+	p.schemas = append(p.schemas, schema)
+	return true
+}
+
+func (p *ddlParser) nameList() bool {
+	for {
+		if !p.name() {
+			return false
+		}
+		if !p.acceptSymbol(",") {
+			return true
+		}
+	}
+}
+
+// ddlAppliesTo сообщает, меняет ли выражение followed базу databaseName. DDL, которое меняет ее вместе
+// с другими схемами, и неразобранное выражение другой схемы, которое упоминает базу, останавливают follow:
+// применить их частично или пропустить молча нельзя.
+func ddlAppliesTo(query string, defaultSchema string, databaseName string) (bool, error) {
+	schemas, ok := ddlTargetSchemas(query, defaultSchema)
+	if !ok {
+		if defaultSchema == databaseName {
+			return true, nil
+		}
+		for _, token := range tokenizeDDL(query) {
+			if token.Text == databaseName {
+				return false, fmt.Errorf("cannot determine which schema statement %q changes; resync the database and restart follow", query)
+			}
+		}
+		return false, nil
+	}
+	matched := 0
+	for _, schema := range schemas {
+		if schema == databaseName {
+			matched++
+		}
+	}
+	if matched > 0 && matched < len(schemas) {
+		return false, fmt.Errorf("DDL %q changes %s together with other schemas and cannot be applied partially", query, databaseName)
+	}
+	return matched > 0, nil
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+type fakeFollowApplier struct {
+	columns map[string][]followColumn
+	applied [][]followStatement
+	execs   []string
+}
+
+func (a *fakeFollowApplier) Columns(tableName string) ([]followColumn, error) {
+	return a.columns[tableName], nil
+}
+
+func (a *fakeFollowApplier) Apply(statements []followStatement) error {
+	a.applied = append(a.applied, statements)
+	return nil
+}
+
+func (a *fakeFollowApplier) Exec(query string) error {
+	a.execs = append(a.execs, query)
+	return nil
+}
+
+func TestRowsEventStatements(t *testing.T) {
+	columns := []followColumn{
+		{Name: "id", DataType: "int", Key: true, Unsigned: true},
+		{Name: "name", DataType: "varchar"},
+		{Name: "name_upper", DataType: "varchar", Generated: true},
+	}
+
+	statements, rows, err := rowsEventStatements("shop", "users", columns, replication.EnumRowsEventTypeInsert, [][]any{{int32(-1), "ann", "ANN"}, {int32(2), "bob", "BOB"}})
+	if err != nil || rows != 2 || len(statements) != 1 {
+		t.Fatalf("unexpected insert statements: %+v rows=%d err=%v", statements, rows, err)
+	}
+	if statements[0].Query != "REPLACE INTO `shop`.`users` (`id`, `name`) VALUES (?, ?), (?, ?)" {
+		t.Fatalf("unexpected insert query: %s", statements[0].Query)
+	}
+	if statements[0].Args[0] != uint32(4294967295) || statements[0].Args[3] != "bob" {
+		t.Fatalf("unexpected insert args: %v", statements[0].Args)
+	}
+
+	statements, rows, err = rowsEventStatements("shop", "users", columns, replication.EnumRowsEventTypeUpdate, [][]any{
+		{int32(1), "ann", "ANN"}, {int32(1), "anna", "ANNA"},
+		{int32(2), "bob", "BOB"}, {int32(3), "bob", "BOB"},
+	})
+	if err != nil || rows != 2 {
+		t.Fatalf("unexpected update result: rows=%d err=%v", rows, err)
+	}
+	queries := make([]string, 0, len(statements))
+	for _, statement := range statements {
+		queries = append(queries, statement.Query)
+	}
+	want := []string{
+		"REPLACE INTO `shop`.`users` (`id`, `name`) VALUES (?, ?)",
+		"DELETE FROM `shop`.`users` WHERE `id` <=> ? LIMIT 1",
+		"REPLACE INTO `shop`.`users` (`id`, `name`) VALUES (?, ?)",
+	}
+	if strings.Join(queries, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("key change must delete the old row:\n%s", strings.Join(queries, "\n"))
+	}
+
+	keyless := []followColumn{{Name: "a", DataType: "int"}, {Name: "b", DataType: "varchar"}}
+	statements, _, err = rowsEventStatements("shop", "log", keyless, replication.EnumRowsEventTypeDelete, [][]any{{int32(1), nil}})
+	if err != nil || statements[0].Query != "DELETE FROM `shop`.`log` WHERE `a` <=> ? AND `b` <=> ? LIMIT 1" {
+		t.Fatalf("unexpected keyless delete: %+v err=%v", statements, err)
+	}
+
+	if _, _, err := rowsEventStatements("shop", "log", keyless, replication.EnumRowsEventTypeInsert, [][]any{{int32(1)}}); err == nil {
+		t.Fatal("column count mismatch must fail")
+	}
+}
+
+func TestBinlogFollowerHandle(t *testing.T) {
+	applier := &fakeFollowApplier{}
+	follower := newBinlogFollower("shop", models.BinlogPosition{File: "binlog.000001", Position: 4}, applier)
+	now := time.Unix(1_800_000_100, 0)
+
+	committed, err := follower.handle(&replication.BinlogEvent{Header: &replication.EventHeader{EventType: replication.ROTATE_EVENT}, Event: &replication.RotateEvent{Position: 4, NextLogName: []byte("binlog.000002")}}, now)
+	if err != nil || committed || follower.position.File != "binlog.000002" {
+		t.Fatalf("rotate must switch binlog file, got %+v err=%v", follower.position, err)
+	}
+
+	_, _ = follower.handle(&replication.BinlogEvent{Header: &replication.EventHeader{EventType: replication.QUERY_EVENT}, Event: &replication.QueryEvent{Schema: []byte("shop"), Query: []byte("BEGIN")}}, now)
+	follower.pending = []followStatement{{Query: "REPLACE INTO `shop`.`users` (`id`) VALUES (?)", Args: []any{int32(1)}}}
+	follower.pendingRows = 1
+	committed, err = follower.handle(&replication.BinlogEvent{Header: &replication.EventHeader{EventType: replication.XID_EVENT, Timestamp: 1_800_000_090, LogPos: 512}, Event: &replication.XIDEvent{}}, now)
+	if err != nil || !committed || len(applier.applied) != 1 {
+		t.Fatalf("XID must apply the pending transaction, applied=%d err=%v", len(applier.applied), err)
+	}
+	status := follower.status(models.FollowPhaseStreaming, "")
+	if status.Position.Position != 512 || status.Lag != 10*time.Second || status.Transactions != 1 || status.RowsApplied != 1 {
+		t.Fatalf("unexpected follow status: %+v", status)
+	}
+
+	_, err = follower.handle(&replication.BinlogEvent{Header: &replication.EventHeader{EventType: replication.QUERY_EVENT, LogPos: 700}, Event: &replication.QueryEvent{Schema: []byte("other"), Query: []byte("ALTER TABLE t ADD c INT")}}, now)
+	if err != nil || len(applier.execs) != 0 || follower.position.Position != 700 {
+		t.Fatalf("DDL of another schema must only move the position, execs=%v pos=%+v", applier.execs, follower.position)
+	}
+	_, err = follower.handle(&replication.BinlogEvent{Header: &replication.EventHeader{EventType: replication.QUERY_EVENT, LogPos: 800}, Event: &replication.QueryEvent{Schema: []byte("shop"), Query: []byte("ALTER TABLE users ADD c INT")}}, now)
+	if err != nil || len(applier.execs) != 1 {
+		t.Fatalf("DDL of the followed schema must be applied, execs=%v err=%v", applier.execs, err)
+	}
+
+	_, err = follower.handle(&replication.BinlogEvent{Header: &replication.EventHeader{EventType: replication.QUERY_EVENT, LogPos: 900}, Event: &replication.QueryEvent{Schema: []byte("other"), Query: []byte("ALTER TABLE `shop`.`users` ADD d INT")}}, now)
+	if err != nil || len(applier.execs) != 2 {
+		t.Fatalf("qualified DDL of the followed schema must be applied from any default schema, execs=%v err=%v", applier.execs, err)
+	}
+	_, err = follower.handle(&replication.BinlogEvent{Header: &replication.EventHeader{EventType: replication.QUERY_EVENT, LogPos: 1000}, Event: &replication.QueryEvent{Schema: []byte("other"), Query: []byte("RENAME TABLE shop.users TO archive.users")}}, now)
+	if err == nil || len(applier.execs) != 2 || follower.position.Position != 900 {
+		t.Fatalf("DDL moving a table out of the followed schema must stop follow, execs=%v pos=%+v err=%v", applier.execs, follower.position, err)
+	}
+
+	_, _ = follower.handle(&replication.BinlogEvent{Header: &replication.EventHeader{EventType: replication.HEARTBEAT_EVENT}, Event: &replication.GenericEvent{}}, now)
+	if follower.lag != 0 {
+		t.Fatalf("heartbeat must reset lag, got %v", follower.lag)
+	}
+}
+
+func TestDDLAppliesTo(t *testing.T) {
+	tests := []struct {
+		query         string
+		defaultSchema string
+		applies       bool
+		fails         bool
+	}{
+		{query: "ALTER TABLE users ADD c INT", defaultSchema: "shop", applies: true},
+		{query: "ALTER TABLE users ADD c INT", defaultSchema: "other"},
+		{query: "/* app */ ALTER TABLE `shop`.`users` ADD c INT", defaultSchema: "other", applies: true},
+		{query: "ALTER TABLE shop.users RENAME COLUMN a TO b", defaultSchema: "", applies: true},
+		{query: "ALTER TABLE shop.users RENAME TO archive.users", defaultSchema: "shop", fails: true},
+		{query: "CREATE TABLE IF NOT EXISTS `shop`.`t` LIKE `other`.`t`", defaultSchema: "other", applies: true},
+		{query: "DROP TABLE IF EXISTS `shop`.`a`, `shop`.`b`", defaultSchema: "mysql", applies: true},
+		{query: "DROP TABLE shop.a, other.b", defaultSchema: "other", fails: true},
+		{query: "CREATE INDEX idx ON `shop`.`users` (email)", defaultSchema: "other", applies: true},
+		{query: "CREATE DEFINER=`root`@`%` TRIGGER shop.users_bi BEFORE INSERT ON users FOR EACH ROW SET NEW.a = 1", defaultSchema: "other", applies: true},
+		{query: "CREATE /*!50017 DEFINER=`root`@`%`*/ TRIGGER users_bi BEFORE INSERT ON users FOR EACH ROW SET NEW.a = 1", defaultSchema: "other"},
+		{query: "CREATE OR REPLACE ALGORITHM=MERGE SQL SECURITY DEFINER VIEW v AS SELECT o.id FROM shop.orders o", defaultSchema: "other"},
+		{query: "TRUNCATE shop.sessions", defaultSchema: "other", applies: true},
+		{query: "ALTER DATABASE CHARACTER SET utf8mb4", defaultSchema: "shop", applies: true},
+		{query: "DROP DATABASE `shop`", defaultSchema: "other", applies: true},
+		{query: "CREATE USER 'shop'@'%' IDENTIFIED BY 'x'", defaultSchema: "shop"},
+		{query: "GRANT SELECT ON shop.* TO 'app'@'%'", defaultSchema: "shop"},
+		{query: "LOCK INSTANCE FOR BACKUP", defaultSchema: "other"},
+		{query: "UPDATE shop.users SET a = 1", defaultSchema: "other", fails: true},
+	}
+	for _, test := range tests {
+		applies, err := ddlAppliesTo(test.query, test.defaultSchema, "shop")
+		if (err != nil) != test.fails || applies != test.applies {
+			t.Errorf("ddlAppliesTo(%q, %q) = %v, %v; want applies=%v fails=%v", test.query, test.defaultSchema, applies, err, test.applies, test.fails)
+		}
+	}
+}
+
+func TestFollowChecksBinlogAndSavedPosition(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{BinlogStatusResult: &models.BinlogStatus{Format: "MIXED", RowImage: "FULL"}}
+	cfg := &config.Config{
+		Remote: config.MySQLConfig{Host: "db.example.com", Port: 3306, User: "sync"},
+		Local:  config.MySQLConfig{Host: "localhost", Port: 3306},
+		Follow: config.FollowConfig{Dir: t.TempDir()},
+	}
+	service := NewMySQLShellService(cfg, dbService)
+	service.SetQuiet(true)
+	target := models.SyncTarget{DatabaseName: "shop", ReplaceEntireDatabase: true}
+
+	err := service.Follow(context.Background(), target, true, nil)
+	if err == nil || !strings.Contains(err.Error(), "binlog_format=ROW") {
+		t.Fatalf("statement binlog must be rejected, got %v", err)
+	}
+
+	dbService.BinlogStatusResult = nil
+	err = service.Follow(context.Background(), target, true, nil)
+	if err == nil || !strings.Contains(err.Error(), "no saved follow position") {
+		t.Fatalf("resume without saved position must fail, got %v", err)
+	}
+
+	position := models.BinlogPosition{File: "binlog.000007", Position: 1337}
+	if err := service.saveFollowPosition("shop", position); err != nil {
+		t.Fatalf("saveFollowPosition() error = %v", err)
+	}
+	saved, err := service.LoadFollowPosition("shop")
+	if err != nil || saved == nil || *saved != position {
+		t.Fatalf("unexpected saved position: %+v err=%v", saved, err)
+	}
+	if id := service.followServerID("shop"); id < followServerIDBase {
+		t.Fatalf("auto server id must stay in the reserved range, got %d", id)
+	}
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+const (
+	defaultHookTimeout = 10 * time.Minute
+	maxHookOutputBytes = 16 * 1024
+	hookDatabaseAny    = "*"
+	// hookWaitDelay ограничивает ожидание вывода после отмены hook, если его держит потомок команды.
+	hookWaitDelay = 5 * time.Second
+)
+
+// HookSpec описывает один hook: SQL-файл для локальной БД или shell-команду.
+type HookSpec struct {
+	Name    string `json:"name,omitempty"`
+	SQL     string `json:"sql,omitempty"`
+	Command string `json:"command,omitempty"`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// HookSet хранит hooks по базам данных; ключ "*" применяется ко всем базам.
+type HookSet struct {
+	Databases map[string]map[models.HookPoint][]HookSpec `json:"databases"`
+
+	baseDir string
+}
+
+// LoadHookSet читает JSON-файл hooks; отсутствие файла означает пустой набор.
+func LoadHookSet(path string) (*HookSet, error) {
+	if path == "" {
+		return &HookSet{}, nil
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &HookSet{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks file: %w", err)
+	}
+
+	var hooks HookSet
+	if err := json.Unmarshal(content, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks file %s: %w", path, err)
+	}
+	hooks.baseDir = filepath.Dir(path)
+	if err := hooks.validate(); err != nil {
+		return nil, fmt.Errorf("invalid hooks file %s: %w", path, err)
+	}
+	return &hooks, nil
+}
+
+func (h *HookSet) validate() error {
+	known := make(map[models.HookPoint]bool)
+	for _, point := range models.HookPoints() {
+		known[point] = true
+	}
+	for databaseName, points := range h.Databases {
+		for point, specs := range points {
+			if !known[point] {
+				return fmt.Errorf("database %q: unknown hook point %q", databaseName, point)
+			}
+			for index, spec := range specs {
+				if (spec.SQL == "") == (spec.Command == "") {
+					return fmt.Errorf("database %q %s hook #%d: exactly one of sql or command is required", databaseName, point, index+1)
+				}
+				if spec.Timeout != "" {
+					if _, err := time.ParseDuration(spec.Timeout); err != nil {
+						return fmt.Errorf("database %q %s hook #%d: invalid timeout: %w", databaseName, point, index+1, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// HooksFor возвращает hooks для базы и точки: сначала общие ("*"), затем специфичные.
+func (h *HookSet) HooksFor(databaseName string, point models.HookPoint) []HookSpec {
+	if h == nil {
+		return nil
+	}
+	var specs []HookSpec
+	specs = append(specs, h.Databases[hookDatabaseAny][point]...)
+	if databaseName != hookDatabaseAny {
+		specs = append(specs, h.Databases[databaseName][point]...)
+	}
+	return specs
+}
+
+// Empty сообщает, что ни одного hook не настроено.
+func (h *HookSet) Empty() bool {
+	if h == nil {
+		return true
+	}
+	for _, points := range h.Databases {
+		for _, specs := range points {
+			if len(specs) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Kind возвращает тип hook для отчета.
+func (spec HookSpec) Kind() string {
+	if spec.SQL != "" {
+		return "sql"
+	}
+	return "command"
+}
+
+// DisplayName возвращает имя hook для TUI и отчета.
+func (spec HookSpec) DisplayName() string {
+	if spec.Name != "" {
+		return spec.Name
+	}
+	if spec.SQL != "" {
+		return filepath.Base(spec.SQL)
+	}
+	return spec.Command
+}
+
+func (spec HookSpec) timeout() time.Duration {
+	if spec.Timeout == "" {
+		return defaultHookTimeout
+	}
+	timeout, err := time.ParseDuration(spec.Timeout)
+	if err != nil || timeout <= 0 {
+		return defaultHookTimeout
+	}
+	return timeout
+}
+
+func (h *HookSet) resolvePath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+		}
+	}
+	if filepath.IsAbs(path) || h == nil || h.baseDir == "" {
+		return path
+	}
+	return filepath.Join(h.baseDir, path)
+}
+
+// hookEnv описывает контекст, который передается hook через переменные окружения.
+type hookEnv struct {
+	Point        models.HookPoint
+	DatabaseName string
+	LocalDB      string
+	DumpDir      string
+	Failure      error
+}
+
+func (e hookEnv) environ() []string {
+	env := append(os.Environ(),
+		"DBSYNC_HOOK_POINT="+string(e.Point),
+		"DBSYNC_DATABASE="+e.DatabaseName,
+		"DBSYNC_LOCAL_DB="+e.LocalDB,
+		"DBSYNC_DUMP_DIR="+e.DumpDir,
+	)
+	if e.Failure != nil {
+		env = append(env, "DBSYNC_ERROR="+e.Failure.Error())
+	}
+	return env
+}
+
+func (s *MySQLShellService) loadHooks() (*HookSet, error) {
+	if s.config == nil {
+		return &HookSet{}, nil
+	}
+	return LoadHookSet(s.config.Hooks.ResolvedFile())
+}
+
+// runHooks выполняет hooks точки по порядку и останавливается на первой ошибке; отмена ctx прерывает текущий hook.
+func (s *MySQLShellService) runHooks(ctx context.Context, hooks *HookSet, env hookEnv, observer models.ProgressObserver) ([]models.HookResult, error) {
+	specs := hooks.HooksFor(env.DatabaseName, env.Point)
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	results := make([]models.HookResult, 0, len(specs))
+	for _, spec := range specs {
+		name := spec.DisplayName()
+		if observer != nil {
+			observer(models.ProgressSnapshot{Phase: models.SyncPhaseHooks, DatabaseName: env.DatabaseName, Message: fmt.Sprintf("%s: %s", env.Point, name), Timestamp: time.Now()})
+		}
+		s.printStatusf("🪝 Running %s hook %s...\n", env.Point, name)
+
+		startedAt := time.Now()
+		output, err := s.runHook(ctx, hooks, spec, env)
+		result := models.HookResult{
+			Point:    env.Point,
+			Name:     name,
+			Kind:     spec.Kind(),
+			Success:  err == nil,
+			Output:   truncateHookOutput(output),
+			Duration: time.Since(startedAt),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+		if err != nil {
+			return results, fmt.Errorf("%s hook %q failed: %w", env.Point, name, err)
+		}
+	}
+	return results, nil
+}
+
+func (s *MySQLShellService) runHook(ctx context.Context, hooks *HookSet, spec HookSpec, env hookEnv) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, spec.timeout())
+	defer cancel()
+
+	var output string
+	var err error
+	if spec.SQL != "" {
+		output, err = s.runSQLHook(ctx, hooks.resolvePath(spec.SQL), env.LocalDB)
+	} else {
+		cmd := hookShellCommand(ctx, spec.Command)
+		if hooks != nil && hooks.baseDir != "" {
+			cmd.Dir = hooks.baseDir
+		}
+		cmd.Env = env.environ()
+		cmd.WaitDelay = hookWaitDelay
+		var combined []byte
+		combined, err = cmd.CombinedOutput()
+		output = string(combined)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return output, fmt.Errorf("timed out after %s", spec.timeout())
+	}
+	return output, err
+}
+
+// runSQLHook выполняет SQL-файл в локальной базе через database/sql: пароль не попадает в argv клиента mysql.
+func (s *MySQLShellService) runSQLHook(ctx context.Context, path string, databaseName string) (string, error) {
+	script, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open sql file: %w", err)
+	}
+	statements := splitSQLScript(string(script))
+	if err := s.execLocalSQLContext(ctx, databaseName, statements...); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d statements executed", len(statements)), nil
+}
+
+// splitSQLScript разбивает SQL-файл на запросы так же, как клиент mysql: по разделителю вне строк,
+// идентификаторов и комментариев, с директивой DELIMITER для тел процедур и триггеров.
+// Комментарии, кроме исполняемых /*! ... */, отбрасываются.
+func splitSQLScript(script string) []string {
+	var statements []string
+	var current strings.Builder
+	delimiter := ";"
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); {
+		if strings.TrimSpace(current.String()) == "" {
+			rest := strings.TrimLeft(script[i:], " \t\r\n")
+			line, _, _ := strings.Cut(rest, "\n")
+			if fields := strings.Fields(line); len(fields) == 2 && strings.EqualFold(fields[0], "DELIMITER") {
+				delimiter = fields[1]
+				current.Reset()
+				i = len(script) - len(rest) + len(line)
+				continue
+			}
+		}
+
+		c := script[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(script) && script[end] != c {
+				if script[end] == '\\' && c != '`' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(script))
+			current.WriteString(script[i:end])
+			i = end
+		case c == '#' || strings.HasPrefix(script[i:], "-- ") || strings.HasPrefix(script[i:], "--\n") || strings.HasPrefix(script[i:], "--\t"):
+			line, _, _ := strings.Cut(script[i:], "\n")
+			i += len(line)
+		case strings.HasPrefix(script[i:], "/*") && !strings.HasPrefix(script[i:], "/*!"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 4
+			}
+			current.WriteByte(' ')
+		case strings.HasPrefix(script[i:], delimiter):
+			flush()
+			i += len(delimiter)
+		default:
+			current.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+func truncateHookOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) <= maxHookOutputBytes {
+		return output
+	}
+	return "…" + output[len(output)-maxHookOutputBytes:]
+}
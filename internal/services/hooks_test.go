@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func writeHooksFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hooks.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write hooks file: %v", err)
+	}
+	return path
+}
+
+func TestLoadHookSet(t *testing.T) {
+	hooks, err := LoadHookSet(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil || !hooks.Empty() {
+		t.Fatalf("missing hooks file should produce empty set, got %+v err=%v", hooks, err)
+	}
+
+	path := writeHooksFile(t, `{"databases": {
+		"*": {"after_restore": [{"name": "common", "command": "true"}]},
+		"shop": {"after_restore": [{"sql": "reset.sql"}], "before_dump": [{"command": "echo hi", "timeout": "5s"}]}
+	}}`)
+	hooks, err = LoadHookSet(path)
+	if err != nil {
+		t.Fatalf("LoadHookSet() error = %v", err)
+	}
+	afterRestore := hooks.HooksFor("shop", models.HookAfterRestore)
+	if len(afterRestore) != 2 || afterRestore[0].DisplayName() != "common" || afterRestore[1].DisplayName() != "reset.sql" {
+		t.Fatalf("unexpected after_restore hooks: %+v", afterRestore)
+	}
+	if got := hooks.resolvePath("reset.sql"); got != filepath.Join(filepath.Dir(path), "reset.sql") {
+		t.Fatalf("relative sql path should resolve next to hooks file, got %s", got)
+	}
+	if len(hooks.HooksFor("crm", models.HookBeforeDump)) != 0 {
+		t.Fatal("hooks of another database must not leak")
+	}
+}
+
+func TestLoadHookSetRejectsInvalidHooks(t *testing.T) {
+	tests := map[string]string{
+		"unknown point":   `{"databases": {"shop": {"after_everything": [{"command": "true"}]}}}`,
+		"sql and command": `{"databases": {"shop": {"after_restore": [{"sql": "a.sql", "command": "true"}]}}}`,
+		"empty hook":      `{"databases": {"shop": {"after_restore": [{"name": "noop"}]}}}`,
+		"invalid timeout": `{"databases": {"shop": {"after_restore": [{"command": "true", "timeout": "soon"}]}}}`,
+		"malformed json":  `{"databases": `,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadHookSet(writeHooksFile(t, content)); err == nil {
+				t.Fatal("expected LoadHookSet() to fail")
+			}
+		})
+	}
+}
+
+func TestRunHooksPassesEnvironmentAndStopsOnFailure(t *testing.T) {
+	path := writeHooksFile(t, `{"databases": {"shop": {"after_restore": [
+		{"name": "env", "command": "echo \"$DBSYNC_HOOK_POINT $DBSYNC_DATABASE $DBSYNC_LOCAL_DB $DBSYNC_DUMP_DIR\""},
+		{"name": "broken", "command": "echo oops >&2; exit 3"},
+		{"name": "skipped", "command": "true"}
+	]}}}`)
+	hooks, err := LoadHookSet(path)
+	if err != nil {
+		t.Fatalf("LoadHookSet() error = %v", err)
+	}
+
+	var snapshots []models.ProgressSnapshot
+	service := NewMySQLShellService(&config.Config{}, nil)
+	service.SetQuiet(true)
+	results, err := service.runHooks(context.Background(), hooks, hookEnv{Point: models.HookAfterRestore, DatabaseName: "shop", LocalDB: "shop", DumpDir: "/tmp/dump"}, func(snapshot models.ProgressSnapshot) {
+		snapshots = append(snapshots, snapshot)
+	})
+	if err == nil || !strings.Contains(err.Error(), `after_restore hook "broken" failed`) {
+		t.Fatalf("expected broken hook error, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected execution to stop after failing hook, got %d results", len(results))
+	}
+	if !results[0].Success || results[0].Output != "after_restore shop shop /tmp/dump" || results[0].Kind != "command" {
+		t.Fatalf("unexpected first hook result: %+v", results[0])
+	}
+	if results[1].Success || results[1].Output != "oops" || results[1].Error == "" {
+		t.Fatalf("unexpected failed hook result: %+v", results[1])
+	}
+	if len(snapshots) != 2 || snapshots[0].Phase != models.SyncPhaseHooks || snapshots[0].Message != "after_restore: env" {
+		t.Fatalf("unexpected hook snapshots: %+v", snapshots)
+	}
+}
+
+func TestRunHooksStopsOnContextCancel(t *testing.T) {
+	hooks, err := LoadHookSet(writeHooksFile(t, `{"databases": {"shop": {"before_dump": [{"name": "slow", "command": "sleep 5"}]}}}`))
+	if err != nil {
+		t.Fatalf("LoadHookSet() error = %v", err)
+	}
+	service := NewMySQLShellService(&config.Config{}, nil)
+	service.SetQuiet(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	startedAt := time.Now()
+	results, err := service.runHooks(ctx, hooks, hookEnv{Point: models.HookBeforeDump, DatabaseName: "shop"}, nil)
+	if err == nil || len(results) != 1 || results[0].Success {
+		t.Fatalf("cancelled plan must stop the running hook, got %+v, %v", results, err)
+	}
+	if elapsed := time.Since(startedAt); elapsed > 3*time.Second {
+		t.Fatalf("hook kept running after cancel for %v", elapsed)
+	}
+}
+
+func TestSplitSQLScript(t *testing.T) {
+	script := `-- reset caches
+UPDATE settings SET value = 'a;b' WHERE name = "x;y"; # trailing comment
+/* maintenance */ DELETE FROM ` + "`odd;table`" + `;
+DELIMITER $$
+CREATE TRIGGER t_bi BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.a = 'it''s'; SET NEW.b = 1; END$$
+DELIMITER ;
+/*!40101 SET NAMES utf8mb4 */;
+SELECT 1`
+	want := []string{
+		`UPDATE settings SET value = 'a;b' WHERE name = "x;y"`,
+		"DELETE FROM `odd;table`",
+		"CREATE TRIGGER t_bi BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.a = 'it''s'; SET NEW.b = 1; END",
+		"/*!40101 SET NAMES utf8mb4 */",
+		"SELECT 1",
+	}
+	if got := splitSQLScript(script); !reflect.DeepEqual(got, want) {
+		t.Fatalf("splitSQLScript() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestExecutePlanRunsFailureHooks(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "failure.txt")
+	path := writeHooksFile(t, `{"databases": {"shop": {"on_failure": [{"name": "report", "command": "echo \"$DBSYNC_ERROR\" > `+outputFile+`"}]}}}`)
+
+	dbService := &mocks.MockDatabaseService{ValidateNameError: errors.New("bad name")}
+	service := NewMySQLShellService(&config.Config{Hooks: config.HooksConfig{File: path}}, dbService)
+	service.SetQuiet(true)
+
+	plan := &models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "shop"}}}
+	results, err := service.ExecutePlan(plan, models.RuntimeOptions{}, nil)
+	if err == nil {
+		t.Fatal("expected plan to fail")
+	}
+	if len(results) != 1 || results[0].Success || len(results[0].Hooks) != 1 || results[0].Hooks[0].Point != models.HookOnFailure {
+		t.Fatalf("unexpected failed result: %+v", results)
+	}
+	content, readErr := os.ReadFile(outputFile)
+	if readErr != nil {
+		t.Fatalf("failure hook did not run: %v", readErr)
+	}
+	if !strings.Contains(string(content), "bad name") {
+		t.Fatalf("failure hook did not receive error, got %q", string(content))
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package services
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// hookShellCommand запускает команду hook через sh -c в отдельной группе процессов: при отмене
+// завершаются и запущенные shell дочерние процессы.
+func hookShellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd
+}
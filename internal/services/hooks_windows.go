@@ -0,0 +1,17 @@
+//go:build windows
+
+package services
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// hookShellCommand запускает команду hook через cmd /C. Командная строка передается как есть:
+// cmd.exe не понимает экранирование аргументов, которое применяет os/exec.
+func hookShellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "cmd.exe")
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd.exe /S /C "` + command + `"`}
+	return cmd
+}
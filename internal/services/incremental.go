@@ -0,0 +1,565 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+const (
+	incrementalStagingSuffix = "__dbsync_incr"
+	maxSchemaNameLength      = 64
+
+	incrementalReasonDisabled    = "incremental sync disabled"
+	incrementalReasonForced      = "full resync forced"
+	incrementalReasonNoLocal     = "local database does not exist"
+	incrementalReasonNoWatermark = "no tables with recorded watermarks"
+	incrementalReasonEngine      = "incremental sync requires the mysqlsh dump engine"
+)
+
+var syncProfileUnsafe = regexp.MustCompile(`[^A-Za-z0-9._@-]+`)
+
+// watermarkFile хранит high-water marks таблиц одной базы для одного профиля подключений.
+type watermarkFile struct {
+	DatabaseName string                           `json:"database_name"`
+	Profile      string                           `json:"profile"`
+	Tables       map[string]models.TableWatermark `json:"tables"`
+	UpdatedAt    time.Time                        `json:"updated_at"`
+}
+
+// syncProfile возвращает имя профиля: пара source и destination серверов, между которыми идет синхронизация.
+func (s *MySQLShellService) syncProfile() string {
+	destination := s.destinationConfig()
+	profile := fmt.Sprintf("%s@%s_%d__%s_%d", s.config.Remote.User, s.config.Remote.Host, s.config.Remote.Port, destination.Host, destination.Port)
+	return syncProfileUnsafe.ReplaceAllString(profile, "_")
+}
+
+func (s *MySQLShellService) watermarkPath(databaseName string) string {
+	return filepath.Join(s.config.Incremental.ResolvedDir(), s.syncProfile(), databaseName+".json")
+}
+
+// LoadWatermarks возвращает сохраненные high-water marks таблиц базы.
+func (s *MySQLShellService) LoadWatermarks(databaseName string) (map[string]models.TableWatermark, error) {
+	return loadWatermarks(s.watermarkPath(databaseName))
+}
+
+func loadWatermarks(path string) (map[string]models.TableWatermark, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]models.TableWatermark{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermarks: %w", err)
+	}
+	var file watermarkFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse watermarks %s: %w", path, err)
+	}
+	if file.Tables == nil {
+		file.Tables = map[string]models.TableWatermark{}
+	}
+	return file.Tables, nil
+}
+
+// saveWatermarks атомарно перезаписывает файл high-water marks.
+func saveWatermarks(path string, databaseName string, profile string, marks map[string]models.TableWatermark) error {
+	if err := writeJSONFileAtomic(path, watermarkFile{DatabaseName: databaseName, Profile: profile, Tables: marks, UpdatedAt: time.Now()}); err != nil {
+		return fmt.Errorf("failed to write watermarks: %w", err)
+	}
+	return nil
+}
+
+// writeJSONFileAtomic записывает JSON во временный файл и переименовывает его поверх path.
+func writeJSONFileAtomic(path string, value any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// PlanIncremental определяет, какие таблицы цели будут догружены инкрементально, а какие перезаписаны полностью.
+func (s *MySQLShellService) PlanIncremental(target models.SyncTarget) (*models.IncrementalPlan, error) {
+	service, cleanup, err := s.routeService(target)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	plan, _, err := service.planIncremental(target)
+	return plan, err
+}
+
+// planIncremental возвращает план и колонки high-water mark всех подходящих таблиц цели.
+func (s *MySQLShellService) planIncremental(target models.SyncTarget) (*models.IncrementalPlan, map[string]models.IncrementalColumn, error) {
+	databaseName := target.DatabaseName
+	plan := &models.IncrementalPlan{DatabaseName: databaseName}
+	if !s.config.Incremental.Enabled {
+		plan.Reason = incrementalReasonDisabled
+		return plan, nil, nil
+	}
+
+	tableNames := target.EffectiveTables()
+	if len(tableNames) == 0 {
+		remoteTables, err := s.dbService.ListTables(databaseName, true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list remote tables: %w", err)
+		}
+		for _, table := range remoteTables {
+			// Представления пересоздаются вместе с объектами staging-схемы, а не через RENAME TABLE.
+			if isViewTable(table) {
+				continue
+			}
+			tableNames = append(tableNames, table.Name)
+		}
+	}
+
+	columns, err := s.dbService.IncrementalColumns(databaseName, s.config.Incremental.Column, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	targetColumns := make(map[string]models.IncrementalColumn, len(columns))
+	for _, tableName := range tableNames {
+		if column, ok := columns[tableName]; ok {
+			targetColumns[tableName] = column
+		}
+	}
+
+	if target.ForceFull {
+		plan.Reason = incrementalReasonForced
+		plan.Full = tableNames
+		return plan, targetColumns, nil
+	}
+
+	localExists, err := s.dbService.DatabaseExists(databaseName, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check if local database exists: %w", err)
+	}
+	if !localExists {
+		plan.Reason = incrementalReasonNoLocal
+		plan.Full = tableNames
+		return plan, targetColumns, nil
+	}
+
+	localTables, err := s.dbService.ListTables(databaseName, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list local tables: %w", err)
+	}
+	localSet := make(map[string]bool, len(localTables))
+	for _, table := range localTables {
+		localSet[table.Name] = true
+	}
+
+	marks, err := s.LoadWatermarks(databaseName)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan.Incremental, plan.Full = splitIncrementalTables(tableNames, targetColumns, marks, localSet)
+	if len(plan.Incremental) == 0 {
+		plan.Reason = incrementalReasonNoWatermark
+	}
+	return plan, targetColumns, nil
+}
+
+// isViewTable сообщает, что запись information_schema.TABLES описывает представление, а не таблицу.
+func isViewTable(table models.Table) bool {
+	return strings.EqualFold(table.Type, "VIEW")
+}
+
+// splitIncrementalTables относит таблицу к incremental, только если она есть локально и ее
+// сохраненный watermark снят по той же колонке, что подходит сейчас на remote.
+func splitIncrementalTables(tableNames []string, columns map[string]models.IncrementalColumn, marks map[string]models.TableWatermark, localTables map[string]bool) ([]models.IncrementalTable, []string) {
+	var incremental []models.IncrementalTable
+	var full []string
+	for _, tableName := range tableNames {
+		column, ok := columns[tableName]
+		mark, hasMark := marks[tableName]
+		if !ok || !hasMark || !localTables[tableName] || mark.Column != column.Column || mark.Kind != column.Kind || mark.Value == "" {
+			full = append(full, tableName)
+			continue
+		}
+		incremental = append(incremental, models.IncrementalTable{Name: tableName, Column: column.Column, Kind: column.Kind, Since: mark.Value})
+	}
+	return incremental, full
+}
+
+// captureHighWater снимает текущие максимумы колонок на remote до начала дампа:
+// строки, появившиеся во время дампа, попадут и в следующий запуск, а upsert сделает повтор безопасным.
+func (s *MySQLShellService) captureHighWater(databaseName string, columns map[string]models.IncrementalColumn) (map[string]models.TableWatermark, error) {
+	values, err := s.dbService.ColumnMaxValues(databaseName, columns, true)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	marks := make(map[string]models.TableWatermark, len(values))
+	for tableName, value := range values {
+		column := columns[tableName]
+		marks[tableName] = models.TableWatermark{Column: column.Column, Kind: column.Kind, Value: value, SyncedAt: now}
+	}
+	return marks, nil
+}
+
+// storeWatermarks сохраняет новые high-water marks для синхронизированных таблиц.
+// Watermarks таблиц вне цели не трогаются; таблицы цели без новой отметки теряют старую.
+func (s *MySQLShellService) storeWatermarks(databaseName string, tableNames []string, marks map[string]models.TableWatermark) error {
+	path := s.watermarkPath(databaseName)
+	stored, err := loadWatermarks(path)
+	if err != nil {
+		return err
+	}
+	if len(tableNames) == 0 {
+		stored = map[string]models.TableWatermark{}
+	}
+	for _, tableName := range tableNames {
+		delete(stored, tableName)
+	}
+	for tableName, mark := range marks {
+		stored[tableName] = mark
+	}
+	return saveWatermarks(path, databaseName, s.syncProfile(), stored)
+}
+
+// incrementalWhereClause возвращает условие mysqlsh dump для строк после high-water mark.
+// Для timestamp используется >=, чтобы не потерять строки, обновленные в ту же секунду.
+func incrementalWhereClause(table models.IncrementalTable) string {
+	column := quoteIdentifier(table.Column)
+	if table.Kind == models.WatermarkPrimaryKey {
+		if value, err := strconv.ParseInt(table.Since, 10, 64); err == nil {
+			return fmt.Sprintf("%s > %d", column, value)
+		}
+		return fmt.Sprintf("%s > %s", column, quoteSQLString(table.Since))
+	}
+	return fmt.Sprintf("%s >= %s", column, quoteSQLString(table.Since))
+}
+
+func quoteSQLString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// incrementalDumpArgs возвращает опцию --where для таблиц, которые дампятся после high-water mark.
+func incrementalDumpArgs(databaseName string, plan *models.IncrementalPlan) []string {
+	if !plan.Active() {
+		return nil
+	}
+	where := make(map[string]string, len(plan.Incremental))
+	for _, table := range plan.Incremental {
+		where[databaseName+"."+table.Name] = incrementalWhereClause(table)
+	}
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(where)
+	return []string{"--where=" + strings.TrimSpace(encoded.String())}
+}
+
+// fullIncrementalPlan переводит все таблицы плана в полную перезагрузку с указанной причиной.
+func fullIncrementalPlan(plan *models.IncrementalPlan, reason string) *models.IncrementalPlan {
+	full := &models.IncrementalPlan{DatabaseName: plan.DatabaseName, Reason: reason}
+	for _, table := range plan.Incremental {
+		full.Full = append(full.Full, table.Name)
+	}
+	full.Full = append(full.Full, plan.Full...)
+	return full
+}
+
+// incrementalStagingSchema возвращает имя временной схемы для загрузки инкрементального дампа.
+func incrementalStagingSchema(databaseName string) string {
+	name := databaseName + incrementalStagingSuffix
+	if len(name) <= maxSchemaNameLength {
+		return name
+	}
+	sum := sha1.Sum([]byte(databaseName))
+	return "dbsync_incr_" + hex.EncodeToString(sum[:8])
+}
+
+// incrementalObject — представление, процедура, функция, триггер или событие staging-схемы с DDL,
+// прочитанным до переноса таблиц.
+type incrementalObject struct {
+	nativeDumpObject
+	DDL string
+}
+
+// incrementalMergeStatements переносит таблицы из staging-схемы: full таблицы заменяются целиком,
+// incremental — upsert через REPLACE по первичному ключу с явным списком колонок staging-таблицы.
+// Триггеры staging удаляются заранее: MySQL не переносит RENAME TABLE таблицу с триггерами в другую
+// схему. Проверка FK отключена на время переноса и включается после пересоздания объектов.
+func incrementalMergeStatements(databaseName string, stagingSchema string, plan *models.IncrementalPlan, columns map[string][]string, objects []incrementalObject) []string {
+	target := quoteIdentifier(databaseName)
+	staging := quoteIdentifier(stagingSchema)
+	statements := []string{"SET SESSION FOREIGN_KEY_CHECKS = 0"}
+	for _, object := range objects {
+		if object.Kind == "TRIGGER" {
+			statements = append(statements, fmt.Sprintf("DROP TRIGGER %s.%s", staging, quoteIdentifier(object.Name)))
+		}
+	}
+	full := append([]string(nil), plan.Full...)
+	sort.Strings(full)
+	for _, tableName := range full {
+		table := quoteIdentifier(tableName)
+		statements = append(statements,
+			fmt.Sprintf("DROP TABLE IF EXISTS %s.%s", target, table),
+			fmt.Sprintf("RENAME TABLE %s.%s TO %s.%s", staging, table, target, table),
+		)
+	}
+	for _, incremental := range plan.Incremental {
+		table := quoteIdentifier(incremental.Name)
+		quoted := make([]string, len(columns[incremental.Name]))
+		for i, column := range columns[incremental.Name] {
+			quoted[i] = quoteIdentifier(column)
+		}
+		list := strings.Join(quoted, ", ")
+		statements = append(statements, fmt.Sprintf("REPLACE INTO %s.%s (%s) SELECT %s FROM %s.%s", target, table, list, list, staging, table))
+	}
+	return append(statements, "USE "+target)
+}
+
+// incrementalObjectStatements пересоздает объект staging-схемы в локальной БД в sql_mode и time_zone источника.
+func incrementalObjectStatements(databaseName string, stagingSchema string, object incrementalObject) []string {
+	var statements []string
+	if object.Kind != "VIEW" {
+		statements = append(statements, "SET SESSION sql_mode = "+quoteSQLString(object.SQLMode))
+		if object.TimeZone != "" {
+			statements = append(statements, "SET SESSION time_zone = "+quoteSQLString(object.TimeZone))
+		}
+	}
+	return append(statements,
+		fmt.Sprintf("DROP %s IF EXISTS %s", object.Kind, quoteIdentifier(object.Name)),
+		retargetSchemaDDL(object.DDL, stagingSchema, databaseName),
+	)
+}
+
+// incrementalStagingObjects читает DDL представлений, процедур, функций, триггеров и событий staging-схемы.
+// Представления идут первыми: триггеры и события могут на них ссылаться.
+func incrementalStagingObjects(ctx context.Context, db *sql.DB, conn *sql.Conn, stagingSchema string) ([]incrementalObject, error) {
+	rows, err := db.QueryContext(ctx, "SELECT TABLE_NAME FROM information_schema.VIEWS WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME", stagingSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staging views: %w", err)
+	}
+	var listed []nativeDumpObject
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to list staging views: %w", err)
+		}
+		listed = append(listed, nativeDumpObject{Kind: "VIEW", Name: name})
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staging views: %w", err)
+	}
+
+	others, err := nativeListObjects(ctx, db, stagingSchema, nil, nil, config.CompatConfig{})
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]incrementalObject, 0, len(listed)+len(others))
+	for _, object := range append(listed, others...) {
+		ddl, err := nativeShowCreateObject(ctx, conn, stagingSchema, &object)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, incrementalObject{nativeDumpObject: object, DDL: ddl})
+	}
+	return objects, nil
+}
+
+// incrementalColumnLists возвращает записываемые колонки таблиц staging-схемы по ORDINAL_POSITION;
+// generated колонки MySQL вычисляет сам и не принимает во вставке.
+func incrementalColumnLists(ctx context.Context, db *sql.DB, stagingSchema string) (map[string][]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT TABLE_NAME, COLUMN_NAME, EXTRA FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME, ORDINAL_POSITION", stagingSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staging columns: %w", err)
+	}
+	defer rows.Close()
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var tableName, columnName, extra string
+		if err := rows.Scan(&tableName, &columnName, &extra); err != nil {
+			return nil, fmt.Errorf("failed to list staging columns: %w", err)
+		}
+		extra = strings.ToUpper(extra)
+		if strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED") {
+			continue
+		}
+		columns[tableName] = append(columns[tableName], columnName)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list staging columns: %w", err)
+	}
+	return columns, nil
+}
+
+// mergeIncremental переносит staging-схему в локальную БД в одной сессии: таблицы, затем
+// представления (за несколько проходов, если они ссылаются друг на друга) и остальные объекты.
+func (s *MySQLShellService) mergeIncremental(databaseName string, stagingSchema string, plan *models.IncrementalPlan) error {
+	db, cleanup, err := openMySQLConnection(s.config.Local, "")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	defer db.Close()
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	objects, err := incrementalStagingObjects(ctx, db, conn, stagingSchema)
+	if err != nil {
+		return err
+	}
+	columns, err := incrementalColumnLists(ctx, db, stagingSchema)
+	if err != nil {
+		return err
+	}
+	exec := func(statements []string) error {
+		for _, statement := range statements {
+			if _, err := conn.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := exec(incrementalMergeStatements(databaseName, stagingSchema, plan, columns, objects)); err != nil {
+		return err
+	}
+
+	var views, others []incrementalObject
+	for _, object := range objects {
+		if object.Kind == "VIEW" {
+			views = append(views, object)
+		} else {
+			others = append(others, object)
+		}
+	}
+	for len(views) > 0 {
+		var failed []incrementalObject
+		var lastErr error
+		for _, view := range views {
+			if err := exec(incrementalObjectStatements(databaseName, stagingSchema, view)); err != nil {
+				failed = append(failed, view)
+				lastErr = fmt.Errorf("failed to create view %s: %w", view.Name, err)
+			}
+		}
+		if len(failed) == len(views) {
+			return lastErr
+		}
+		views = failed
+	}
+	for _, object := range others {
+		if err := exec(incrementalObjectStatements(databaseName, stagingSchema, object)); err != nil {
+			return fmt.Errorf("failed to create %s %s: %w", strings.ToLower(object.Kind), object.Name, err)
+		}
+	}
+	return exec([]string{fmt.Sprintf("DROP DATABASE IF EXISTS %s", quoteIdentifier(stagingSchema)), "SET SESSION FOREIGN_KEY_CHECKS = 1"})
+}
+
+// execLocalSQL выполняет SQL в одной сессии локального MySQL через database/sql, без клиента mysql.
+func (s *MySQLShellService) execLocalSQL(statements ...string) error {
+	return s.execLocalSQLContext(context.Background(), "", statements...)
+}
+
+// execLocalSQLContext выполняет SQL в одной сессии локального MySQL с базой по умолчанию databaseName;
+// отмена ctx прерывает текущий запрос.
+func (s *MySQLShellService) execLocalSQLContext(ctx context.Context, databaseName string, statements ...string) error {
+	db, cleanup, err := openMySQLConnection(s.config.Local, databaseName)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	for _, statement := range statements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreIncremental загружает дамп в staging-схему и переносит строки в локальную БД без DROP DATABASE.
+func (s *MySQLShellService) restoreIncremental(dumpDir string, databaseName string, plan *models.IncrementalPlan, observer models.ProgressObserver, tracker *tableProgressTracker) error {
+	if _, err := os.Stat(dumpDir); os.IsNotExist(err) {
+		return fmt.Errorf("dump directory does not exist: %s", dumpDir)
+	}
+	engine, err := s.dumpEngineForDir(dumpDir)
+	if err != nil {
+		return err
+	}
+
+	stagingSchema := incrementalStagingSchema(databaseName)
+	if err := s.execLocalSQL(
+		fmt.Sprintf("DROP DATABASE IF EXISTS %s", quoteIdentifier(stagingSchema)),
+		fmt.Sprintf("CREATE DATABASE %s", quoteIdentifier(stagingSchema)),
+	); err != nil {
+		return fmt.Errorf("failed to create staging schema: %w", err)
+	}
+	dropStaging := func() {
+		_ = s.execLocalSQL(fmt.Sprintf("DROP DATABASE IF EXISTS %s", quoteIdentifier(stagingSchema)))
+	}
+
+	s.printStatusf("🔄 Restoring %s incrementally (%d incremental, %d full tables)...", databaseName, len(plan.Incremental), len(plan.Full))
+	startTime := time.Now()
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: databaseName, Message: "Loading incremental dump into staging schema", Timestamp: startTime})
+	}
+
+	tracker.SetLoadSchema(stagingSchema)
+	// Грузим рядом, а не поверх локальной БД
+	if err := engine.Load(LoadRequest{DatabaseName: databaseName, Schema: stagingSchema, Dir: dumpDir, Threads: s.config.Dump.Threads, Tracker: tracker}, observer); err != nil {
+		dropStaging()
+		return err
+	}
+
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: databaseName, Message: "Merging incremental rows into local database", Timestamp: time.Now()})
+	}
+	if err := s.mergeIncremental(databaseName, stagingSchema, plan); err != nil {
+		dropStaging()
+		return fmt.Errorf("failed to merge incremental tables (a full resync may be required): %w", err)
+	}
+
+	finishedAt := time.Now()
+	tracker.FinishLoad(finishedAt)
+
+	s.printStatusf("\r✅ Restored %s incrementally in %v                    \n", databaseName, time.Since(startTime).Round(time.Second))
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: databaseName, Message: "Restore complete", Percent: 100, Tables: tracker.Snapshot(finishedAt), Timestamp: finishedAt})
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func TestSelectIncrementalColumns(t *testing.T) {
+	columns := selectIncrementalColumns([]incrementalColumnRow{
+		{TableName: "orders", ColumnName: "id", DataType: "bigint", ColumnKey: "PRI", Extra: "auto_increment"},
+		{TableName: "orders", ColumnName: "updated_at", DataType: "timestamp"},
+		{TableName: "events", ColumnName: "id", DataType: "int", ColumnKey: "PRI", Extra: "auto_increment"},
+		{TableName: "settings", ColumnName: "key", DataType: "varchar", ColumnKey: "PRI"},
+		{TableName: "pairs", ColumnName: "a", DataType: "int", ColumnKey: "PRI", Extra: "auto_increment"},
+		{TableName: "pairs", ColumnName: "b", DataType: "int", ColumnKey: "PRI"},
+		{TableName: "logs", ColumnName: "updated_at", DataType: "datetime"},
+		{TableName: "notes", ColumnName: "id", DataType: "int", ColumnKey: "PRI", Extra: "auto_increment"},
+		{TableName: "notes", ColumnName: "updated_at", DataType: "varchar"},
+	}, "updated_at")
+
+	want := map[string]models.IncrementalColumn{
+		"orders": {Column: "updated_at", Kind: models.WatermarkTimestamp},
+		"events": {Column: "id", Kind: models.WatermarkPrimaryKey},
+		"notes":  {Column: "id", Kind: models.WatermarkPrimaryKey},
+	}
+	if len(columns) != len(want) {
+		t.Fatalf("unexpected incremental columns: %+v", columns)
+	}
+	for tableName, column := range want {
+		if columns[tableName] != column {
+			t.Fatalf("table %s: got %+v, want %+v", tableName, columns[tableName], column)
+		}
+	}
+}
+
+func TestSplitIncrementalTables(t *testing.T) {
+	columns := map[string]models.IncrementalColumn{
+		"orders":  {Column: "updated_at", Kind: models.WatermarkTimestamp},
+		"events":  {Column: "id", Kind: models.WatermarkPrimaryKey},
+		"renamed": {Column: "id", Kind: models.WatermarkPrimaryKey},
+		"fresh":   {Column: "id", Kind: models.WatermarkPrimaryKey},
+	}
+	marks := map[string]models.TableWatermark{
+		"orders":  {Column: "updated_at", Kind: models.WatermarkTimestamp, Value: "2026-01-02 03:04:05"},
+		"events":  {Column: "id", Kind: models.WatermarkPrimaryKey, Value: "100"},
+		"renamed": {Column: "updated_at", Kind: models.WatermarkTimestamp, Value: "2026-01-01 00:00:00"},
+		"fresh":   {Column: "id", Kind: models.WatermarkPrimaryKey, Value: "7"},
+	}
+	local := map[string]bool{"orders": true, "events": true, "renamed": true, "users": true}
+
+	incremental, full := splitIncrementalTables([]string{"orders", "events", "renamed", "fresh", "users"}, columns, marks, local)
+	if len(incremental) != 2 || incremental[0].Name != "orders" || incremental[1].Name != "events" || incremental[1].Since != "100" {
+		t.Fatalf("unexpected incremental tables: %+v", incremental)
+	}
+	if strings.Join(full, ",") != "renamed,fresh,users" {
+		t.Fatalf("unexpected full tables: %v", full)
+	}
+}
+
+func TestIncrementalDumpArgsAndWhereClause(t *testing.T) {
+	if args := incrementalDumpArgs("shop", &models.IncrementalPlan{Full: []string{"users"}}); args != nil {
+		t.Fatalf("inactive plan must not add dump args, got %v", args)
+	}
+
+	plan := &models.IncrementalPlan{Incremental: []models.IncrementalTable{
+		{Name: "events", Column: "id", Kind: models.WatermarkPrimaryKey, Since: "100"},
+		{Name: "orders", Column: "updated_at", Kind: models.WatermarkTimestamp, Since: "2026-01-02 03:04:05"},
+	}}
+	args := incrementalDumpArgs("shop", plan)
+	want := "--where={\"shop.events\":\"`id` > 100\",\"shop.orders\":\"`updated_at` >= '2026-01-02 03:04:05'\"}"
+	if len(args) != 1 || args[0] != want {
+		t.Fatalf("unexpected dump args:\n got %v\nwant %s", args, want)
+	}
+
+	clause := incrementalWhereClause(models.IncrementalTable{Column: "code", Kind: models.WatermarkPrimaryKey, Since: "a'b"})
+	if clause != "`code` > 'a''b'" {
+		t.Fatalf("non-numeric watermark must be quoted, got %s", clause)
+	}
+}
+
+func TestIncrementalMergeStatements(t *testing.T) {
+	plan := &models.IncrementalPlan{
+		Incremental: []models.IncrementalTable{{Name: "orders", Column: "updated_at", Kind: models.WatermarkTimestamp, Since: "2026-01-01 00:00:00"}},
+		Full:        []string{"users"},
+	}
+	columns := map[string][]string{"orders": {"id", "total", "updated_at"}}
+	objects := []incrementalObject{
+		{nativeDumpObject: nativeDumpObject{Kind: "VIEW", Name: "order_totals"}, DDL: "CREATE VIEW `shop__dbsync_incr`.`order_totals` AS select `shop__dbsync_incr`.`orders`.`total` AS `total` from `shop__dbsync_incr`.`orders`"},
+		{nativeDumpObject: nativeDumpObject{Kind: "TRIGGER", Name: "users_bi", SQLMode: "STRICT_TRANS_TABLES"}, DDL: "CREATE TRIGGER `users_bi` BEFORE INSERT ON `users` FOR EACH ROW SET NEW.email = LOWER(NEW.email)"},
+	}
+	statements := incrementalMergeStatements("shop", "shop__dbsync_incr", plan, columns, objects)
+	want := []string{
+		"SET SESSION FOREIGN_KEY_CHECKS = 0",
+		"DROP TRIGGER `shop__dbsync_incr`.`users_bi`",
+		"DROP TABLE IF EXISTS `shop`.`users`",
+		"RENAME TABLE `shop__dbsync_incr`.`users` TO `shop`.`users`",
+		"REPLACE INTO `shop`.`orders` (`id`, `total`, `updated_at`) SELECT `id`, `total`, `updated_at` FROM `shop__dbsync_incr`.`orders`",
+		"USE `shop`",
+	}
+	if strings.Join(statements, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected merge statements:\n%s", strings.Join(statements, "\n"))
+	}
+
+	statements = incrementalObjectStatements("shop", "shop__dbsync_incr", objects[0])
+	want = []string{
+		"DROP VIEW IF EXISTS `order_totals`",
+		"CREATE VIEW `shop`.`order_totals` AS select `shop`.`orders`.`total` AS `total` from `shop`.`orders`",
+	}
+	if strings.Join(statements, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("view must be recreated against the local schema:\n%s", strings.Join(statements, "\n"))
+	}
+	statements = incrementalObjectStatements("shop", "shop__dbsync_incr", objects[1])
+	want = []string{
+		"SET SESSION sql_mode = 'STRICT_TRANS_TABLES'",
+		"DROP TRIGGER IF EXISTS `users_bi`",
+		objects[1].DDL,
+	}
+	if strings.Join(statements, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("trigger must be recreated in its source sql_mode:\n%s", strings.Join(statements, "\n"))
+	}
+
+	long := strings.Repeat("x", 60)
+	if name := incrementalStagingSchema(long); len(name) > maxSchemaNameLength || !strings.HasPrefix(name, "dbsync_incr_") {
+		t.Fatalf("staging schema must fit MySQL identifier limit, got %s", name)
+	}
+}
+
+func TestPlanIncrementalUsesStoredWatermarks(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{
+		DatabaseExistsResult: true,
+		TableList:            []models.Table{{Name: "orders", Engine: "InnoDB"}, {Name: "users", Engine: "InnoDB"}, {Name: "events", Engine: "InnoDB"}, {Name: "order_totals", Type: "VIEW"}},
+		IncrementalColumnMap: map[string]models.IncrementalColumn{
+			"orders": {Column: "updated_at", Kind: models.WatermarkTimestamp},
+			"events": {Column: "id", Kind: models.WatermarkPrimaryKey},
+		},
+		RemoteMaxValues: map[string]string{"orders": "2026-02-01 10:00:00", "events": "250"},
+	}
+	cfg := &config.Config{
+		Remote:      config.MySQLConfig{Host: "db.example.com", Port: 3306, User: "sync"},
+		Local:       config.MySQLConfig{Host: "localhost", Port: 3306},
+		Incremental: config.IncrementalConfig{Enabled: true, Column: "updated_at", Dir: t.TempDir()},
+	}
+	service := NewMySQLShellService(cfg, dbService)
+	service.SetQuiet(true)
+	target := models.SyncTarget{DatabaseName: "shop", ReplaceEntireDatabase: true}
+
+	plan, columns, err := service.planIncremental(target)
+	if err != nil {
+		t.Fatalf("planIncremental() error = %v", err)
+	}
+	if plan.Active() || plan.Reason != incrementalReasonNoWatermark || len(plan.Full) != 3 {
+		t.Fatalf("first sync must go full, got %+v", plan)
+	}
+
+	marks, err := service.captureHighWater("shop", columns)
+	if err != nil {
+		t.Fatalf("captureHighWater() error = %v", err)
+	}
+	if err := service.storeWatermarks("shop", nil, marks); err != nil {
+		t.Fatalf("storeWatermarks() error = %v", err)
+	}
+	stored, err := service.LoadWatermarks("shop")
+	if err != nil || stored["events"].Value != "250" || stored["orders"].SyncedAt.IsZero() {
+		t.Fatalf("unexpected stored watermarks: %+v err=%v", stored, err)
+	}
+
+	plan, err = service.PlanIncremental(target)
+	if err != nil {
+		t.Fatalf("PlanIncremental() error = %v", err)
+	}
+	if strings.Join(plan.IncrementalTableNames(), ",") != "orders,events" || strings.Join(plan.Full, ",") != "users" {
+		t.Fatalf("unexpected incremental plan: %+v", plan)
+	}
+
+	target.ForceFull = true
+	plan, err = service.PlanIncremental(target)
+	if err != nil || plan.Active() || plan.Reason != incrementalReasonForced {
+		t.Fatalf("forced target must go full, got %+v err=%v", plan, err)
+	}
+
+	// Партиальная синхронизация обновляет только watermarks своих таблиц.
+	if err := service.storeWatermarks("shop", []string{"events"}, map[string]models.TableWatermark{
+		"events": {Column: "id", Kind: models.WatermarkPrimaryKey, Value: "300", SyncedAt: time.Now()},
+	}); err != nil {
+		t.Fatalf("storeWatermarks() error = %v", err)
+	}
+	stored, _ = service.LoadWatermarks("shop")
+	if stored["events"].Value != "300" || stored["orders"].Value != "2026-02-01 10:00:00" {
+		t.Fatalf("partial sync must keep other watermarks, got %+v", stored)
+	}
+}
@@ -13,6 +13,14 @@ type DatabaseServiceInterface interface {
 	ValidateDatabaseName(name string) error
 	DatabaseExists(name string, isRemote bool) (bool, error)
 	GetDatabaseInfo(name string, isRemote bool) (*models.Database, error)
+	TableFingerprints(databaseName string, tableNames []string, checksumTables []string, isRemote bool) (map[string]models.TableFingerprint, error)
+	DescribeSchema(databaseName string, isRemote bool) ([]models.TableSchema, error)
+	IncrementalColumns(databaseName string, timestampColumn string, isRemote bool) (map[string]models.IncrementalColumn, error)
+	ColumnMaxValues(databaseName string, columns map[string]models.IncrementalColumn, isRemote bool) (map[string]string, error)
+	BinlogStatus(isRemote bool) (*models.BinlogStatus, error)
+	ServerIdentity(isRemote bool) (*models.ServerIdentity, error)
+	DataDir(isRemote bool) (string, error)
+	ServerSettings(isRemote bool) (*models.ServerSettings, error)
 }
 
 // DumpServiceInterface определяет интерфейс для работы с дампами
@@ -26,3 +34,15 @@ type DumpServiceInterface interface {
 type SyncServiceInterface interface {
 	ExecutePlan(plan *models.SyncPlan, runtime models.RuntimeOptions, observer models.ProgressObserver) ([]models.SyncResult, error)
 }
+
+// DumpEngine снимает логический дамп схемы в директорию и загружает его в локальный сервер.
+// Реализации DumpServiceInterface выбирают движок по dump.engine.
+type DumpEngine interface {
+	Name() string
+	// Available возвращает ошибку, если утилиты движка не установлены.
+	Available() error
+	// SupportsIncremental сообщает, умеет ли движок дампить таблицы с условием high-water mark.
+	SupportsIncremental() bool
+	Dump(request DumpRequest, observer models.ProgressObserver) error
+	Load(request LoadRequest, observer models.ProgressObserver) error
+}
@@ -1,9 +1,8 @@
 package services
 
 import (
-	"bufio"
 	"bytes"
-	"errors"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -13,7 +12,6 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"db-sync-cli/internal/config"
@@ -26,6 +24,15 @@ type MySQLShellService struct {
 	dbService   DatabaseServiceInterface
 	mysqlshPath string
 	quiet       bool
+	notifier    *Notifier
+	// ctx прерывает запущенные процессы mysqlsh; nil означает выполнение без отмены.
+	ctx context.Context
+	// destination — endpoint приемника цели до подмены адреса туннелем; nil означает встроенный local.
+	destination *config.Endpoint
+	// newDatabaseService создает сервис БД для маршрута цели с другими source и destination.
+	newDatabaseService func(*config.Config) DatabaseServiceInterface
+	// confirmed — защищенные БД, подтвержденные вводом имени; без подтверждения их не удаляет prepareLocalDatabase.
+	confirmed []string
 }
 
 type mysqlShellParsedProgress struct {
@@ -46,10 +53,38 @@ var (
 
 // NewMySQLShellService создает новый экземпляр MySQLShellService
 func NewMySQLShellService(cfg *config.Config, dbService DatabaseServiceInterface) *MySQLShellService {
-	return &MySQLShellService{
+	service := &MySQLShellService{
 		config:    cfg,
 		dbService: dbService,
+		newDatabaseService: func(cfg *config.Config) DatabaseServiceInterface {
+			return NewDatabaseService(cfg)
+		},
 	}
+	if cfg != nil {
+		service.notifier = NewNotifier(cfg.Notify)
+	}
+	return service
+}
+
+// withContext возвращает копию сервиса, процессы mysqlsh которой завершаются при отмене ctx.
+func (s *MySQLShellService) withContext(ctx context.Context) *MySQLShellService {
+	service := *s
+	service.ctx = ctx
+	return &service
+}
+
+func (s *MySQLShellService) runContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// mysqlshCommand создает процесс mysqlsh, привязанный к контексту выполнения сервиса.
+func (s *MySQLShellService) mysqlshCommand(mysqlshPath string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(s.runContext(), mysqlshPath, args...)
+	cmd.Env = append(os.Environ(), "MYSQLSH_TERM_COLOR_MODE=nocolor")
+	return cmd
 }
 
 // SetQuiet отключает прямой вывод статусов в stdout/stderr для TUI режима.
@@ -81,51 +116,6 @@ func (w *progressCaptureWriter) String() string {
 	return strings.TrimSpace(w.buffer.String())
 }
 
-// filterMySQLShellOutput фильтрует вывод mysqlsh и по возможности извлекает progress snapshots.
-func filterMySQLShellOutput(r io.Reader, phase models.SyncPhase, databaseName string, metricsFn func() models.TrafficMetrics, observer models.ProgressObserver, _ io.Writer) {
-	scanner := bufio.NewScanner(r)
-	// Увеличиваем буфер для длинных строк
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if observer == nil || line == "" {
-			continue
-		}
-		parsed, ok := parseMySQLShellProgressLine(line)
-		snapshot := models.ProgressSnapshot{
-			Phase:        phase,
-			DatabaseName: databaseName,
-			Timestamp:    time.Now(),
-		}
-		if metricsFn != nil {
-			snapshot.Traffic = metricsFn()
-		}
-		if ok {
-			if statusMessage, statusOK := classifyMySQLShellStatusLine(phase, line); statusOK {
-				snapshot.Message = statusMessage
-			}
-			snapshot.Percent = parsed.Percent
-			snapshot.BytesCompleted = parsed.BytesCompleted
-			snapshot.BytesTotal = parsed.BytesTotal
-			snapshot.ETA = parsed.ETA
-			if parsed.BytesPerSecond > 0 {
-				snapshot.Traffic.CurrentBytesPerSecond = parsed.BytesPerSecond
-			}
-			observer(snapshot)
-			continue
-		}
-
-		statusMessage, statusOK := classifyMySQLShellStatusLine(phase, line)
-		if !statusOK {
-			continue
-		}
-		snapshot.Message = statusMessage
-		observer(snapshot)
-	}
-}
-
 func parseMySQLShellProgressLine(line string) (mysqlShellParsedProgress, bool) {
 	parsed := mysqlShellParsedProgress{Message: strings.TrimSpace(line)}
 	matched := false
@@ -304,8 +294,17 @@ func (s *MySQLShellService) findMySQLShell() (string, error) {
 	return "", fmt.Errorf("mysqlsh not found. Please install MySQL Shell: https://dev.mysql.com/downloads/shell/")
 }
 
-// ValidateDumpOperation проверяет возможность выполнения операции дампа
+// ValidateDumpOperation проверяет возможность выполнения операции дампа и свободное место
+// под дамп и загрузку всей БД.
 func (s *MySQLShellService) ValidateDumpOperation(databaseName string) error {
+	if err := s.validateDumpPrerequisites(databaseName); err != nil {
+		return err
+	}
+	return s.checkTargetsDiskSpace([]models.SyncTarget{{DatabaseName: databaseName, ReplaceEntireDatabase: true}}, false)
+}
+
+// validateDumpPrerequisites проверяет имя БД, подключения и наличие движка дампа.
+func (s *MySQLShellService) validateDumpPrerequisites(databaseName string) error {
 	// Валидация имени базы данных
 	if err := s.dbService.ValidateDatabaseName(databaseName); err != nil {
 		return fmt.Errorf("invalid database name: %w", err)
@@ -332,9 +331,8 @@ func (s *MySQLShellService) ValidateDumpOperation(databaseName string) error {
 		return fmt.Errorf("cannot connect to local server: %s", localConn.Error)
 	}
 
-	// Проверяем что MySQL Shell доступен
-	_, err = s.findMySQLShell()
-	if err != nil {
+	// Проверяем что движок дампа доступен
+	if _, err := s.dumpEngine(); err != nil {
 		return err
 	}
 
@@ -362,17 +360,21 @@ func (s *MySQLShellService) transportMode() models.TransportMode {
 	return models.TransportModeDirect
 }
 
-func (s *MySQLShellService) remoteDumpURI() (string, *proxyTunnel, func(), error) {
+// remoteDumpConn возвращает параметры remote подключения, направленные через proxy tunnel.
+func (s *MySQLShellService) remoteDumpConn() (config.MySQLConfig, *proxyTunnel, func(), error) {
 	tunnel, err := newProxyTunnel(s.config.Remote)
 	if err != nil {
-		return "", nil, nil, fmt.Errorf("failed to start proxy tunnel: %w", err)
+		return config.MySQLConfig{}, nil, nil, fmt.Errorf("failed to start proxy tunnel: %w", err)
 	}
 
 	cleanup := func() {
 		_ = tunnel.Close()
 	}
 
-	return s.buildURI(s.config.Remote, tunnel.Host(), tunnel.Port()), tunnel, cleanup, nil
+	conn := s.config.Remote
+	conn.Host = tunnel.Host()
+	conn.Port = tunnel.Port()
+	return conn, tunnel, cleanup, nil
 }
 
 func (s *MySQLShellService) effectiveDumpThreads(logicalSize int64) int {
@@ -392,13 +394,6 @@ func (s *MySQLShellService) effectiveDumpThreads(logicalSize int64) int {
 	return threads
 }
 
-func (s *MySQLShellService) dumpCompressionArg() string {
-	if !s.config.Dump.Compress {
-		return "--compression=none"
-	}
-	return "--compression=zstd"
-}
-
 func (s *MySQLShellService) transportCompressionArgs() []string {
 	if !s.config.Dump.NetworkCompress {
 		return nil
@@ -417,26 +412,15 @@ func (s *MySQLShellService) transportCompressionArgs() []string {
 }
 
 func (s *MySQLShellService) buildDumpArgs(remoteURI string, databaseName string, dumpDir string, logicalSize int64, effectiveTables []string) []string {
-	args := append([]string{
-		"--uri", remoteURI,
-		fmt.Sprintf("--password=%s", s.config.Remote.Password),
-	}, s.transportCompressionArgs()...)
-	args = append(args,
-		"--", "util", "dump-schemas", databaseName,
-		fmt.Sprintf("--outputUrl=%s", dumpDir),
-		fmt.Sprintf("--threads=%d", s.effectiveDumpThreads(logicalSize)),
-		"--consistent=false",
-		"--skipConsistencyChecks",
-		s.dumpCompressionArg(),
-	)
-	if len(effectiveTables) > 0 {
-		qualified := make([]string, 0, len(effectiveTables))
-		for _, tableName := range effectiveTables {
-			qualified = append(qualified, fmt.Sprintf("%s.%s", databaseName, tableName))
-		}
-		args = append(args, "--includeTables="+strings.Join(qualified, ","))
-	}
-	return args
+	return s.mysqlShellDumpArgs(remoteURI, DumpRequest{
+		Conn:            s.config.Remote,
+		DatabaseName:    databaseName,
+		Tables:          effectiveTables,
+		Dir:             dumpDir,
+		Threads:         s.effectiveDumpThreads(logicalSize),
+		Compress:        s.config.Dump.Compress,
+		NetworkCompress: s.config.Dump.NetworkCompress,
+	})
 }
 
 // CreateDump создает дамп удаленной базы данных через MySQL Shell
@@ -451,28 +435,26 @@ func (s *MySQLShellService) CreateDumpTarget(target models.SyncTarget, dryRun bo
 
 // CreateDumpTargetWithObserver создает дамп и отправляет progress snapshots в observer.
 func (s *MySQLShellService) CreateDumpTargetWithObserver(target models.SyncTarget, dryRun bool, observer models.ProgressObserver) (*models.SyncResult, string, error) {
+	result, dumpDir, _, err := s.createDumpTarget(target, nil, dryRun, observer)
+	return result, dumpDir, err
+}
+
+// createDumpTarget дампит цель; таблицы из incremental плана дампятся только после их high-water mark.
+func (s *MySQLShellService) createDumpTarget(target models.SyncTarget, incremental *models.IncrementalPlan, dryRun bool, observer models.ProgressObserver) (*models.SyncResult, string, *tableProgressTracker, error) {
 	startTime := time.Now()
 	databaseName := target.DatabaseName
-	effectiveTables := target.EffectiveTables()
 	if observer != nil {
 		observer(models.ProgressSnapshot{Phase: models.SyncPhasePlanning, DatabaseName: databaseName, Message: "Preparing remote dump", Timestamp: startTime})
 	}
 
-	// Получаем информацию о базе данных
-	dbInfo, err := s.dbService.GetDatabaseInfo(databaseName, true)
+	stats, err := s.collectTargetStats(target)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to get database info: %w", err)
-	}
-
-	logicalSize := dbInfo.DataSize
-	indexSize := dbInfo.IndexSize
-	tablesCount := dbInfo.Tables
-	if len(effectiveTables) > 0 {
-		logicalSize, indexSize, tablesCount, err = s.selectedTableStats(databaseName, effectiveTables)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to calculate selected table stats: %w", err)
-		}
+		return nil, "", nil, err
 	}
+	effectiveTables := stats.effectiveTables
+	remoteTables := stats.tables
+	logicalSize, indexSize, tablesCount := stats.logicalSize, stats.indexSize, stats.tablesCount
+	tracker := newTableProgressTracker(databaseName, remoteTables)
 
 	if dryRun {
 		result := &models.SyncResult{
@@ -490,47 +472,38 @@ func (s *MySQLShellService) CreateDumpTargetWithObserver(target models.SyncTarge
 			EndTime:            time.Now(),
 			Duration:           0,
 		}
-		result.Error = fmt.Sprintf("DRY RUN: Would dump database '%s' using MySQL Shell with %d threads",
-			databaseName, s.config.Dump.Threads)
-		return result, "", nil
+		result.Error = fmt.Sprintf("DRY RUN: Would dump database '%s' using %s with %d threads",
+			databaseName, s.dumpEngineName(), s.config.Dump.Threads)
+		result.Tables = tracker.Snapshot(result.EndTime)
+		return result, "", tracker, nil
 	}
 
-	// Создаём директорию для дампа
-	tempDir := os.TempDir()
-	dumpDir := filepath.Join(tempDir, fmt.Sprintf("mysqlsh_%s_%d", databaseName, time.Now().Unix()))
-	if err := os.MkdirAll(dumpDir, 0755); err != nil {
-		return nil, "", fmt.Errorf("failed to create dump directory: %w", err)
+	engine, err := s.dumpEngine()
+	if err != nil {
+		return nil, "", nil, err
 	}
+	snapshot := s.planSnapshot(target, engine.Name())
+	s.warnSnapshot(databaseName, snapshot)
+	s.warnNativeFallback(engine.Name())
+	s.warnIgnoredCompat(engine.Name())
+	fixes := s.newCompatFixes(engine.Name())
 
-	mysqlshPath, err := s.findMySQLShell()
+	// Создаём директорию для дампа
+	dumpDir, err := s.createWorkDir(fmt.Sprintf("%s_%s_%d", engine.Name(), databaseName, time.Now().Unix()))
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, fmt.Errorf("failed to create dump directory: %w", err)
 	}
 
-	remoteURI, tunnel, cleanup, err := s.remoteDumpURI()
+	conn, tunnel, cleanup, err := s.remoteDumpConn()
 	if err != nil {
-		os.RemoveAll(dumpDir)
-		return nil, "", err
+		removeWorkDir(dumpDir)
+		return nil, "", nil, err
 	}
 	defer cleanup()
 
-	// Строим команду mysqlsh для дампа
-	args := s.buildDumpArgs(remoteURI, databaseName, dumpDir, logicalSize, effectiveTables)
-
-	cmd := exec.Command(mysqlshPath, args...)
-	cmd.Env = append(os.Environ(), "MYSQLSH_TERM_COLOR_MODE=nocolor")
-
 	// Показываем статус в одной строке (будет перезаписана)
 	s.printStatusf("📦 Dumping %s (%d tables)...", databaseName, tablesCount)
 
-	// Создаём pipe для фильтрации вывода
-	stdoutPipe, _ := cmd.StdoutPipe()
-	stderrPipe, _ := cmd.StderrPipe()
-
-	if err := cmd.Start(); err != nil {
-		os.RemoveAll(dumpDir)
-		return nil, "", fmt.Errorf("failed to start mysqlsh: %w", err)
-	}
 	if observer != nil {
 		observer(models.ProgressSnapshot{
 			Phase:          models.SyncPhaseDump,
@@ -543,35 +516,38 @@ func (s *MySQLShellService) CreateDumpTargetWithObserver(target models.SyncTarge
 		})
 	}
 
-	stdoutCapture := &progressCaptureWriter{}
-	stderrCapture := &progressCaptureWriter{}
-	if !s.quiet {
-		stdoutCapture.writer = os.Stdout
-		stderrCapture.writer = os.Stderr
-	}
-
 	stopLiveProgress := make(chan struct{})
 	defer close(stopLiveProgress)
 	if observer != nil {
 		go emitTrafficSnapshots(stopLiveProgress, 250*time.Millisecond, databaseName, logicalSize, tunnel.Metrics, observer)
 	}
 
-	var streamWG sync.WaitGroup
-	streamWG.Add(2)
-	go func() {
-		defer streamWG.Done()
-		filterMySQLShellOutput(io.TeeReader(stdoutPipe, stdoutCapture), models.SyncPhaseDump, databaseName, tunnel.Metrics, observer, nil)
-	}()
-	go func() {
-		defer streamWG.Done()
-		filterMySQLShellOutput(io.TeeReader(stderrPipe, stderrCapture), models.SyncPhaseDump, databaseName, tunnel.Metrics, observer, nil)
-	}()
-
-	err = cmd.Wait()
-	streamWG.Wait()
+	err = engine.Dump(DumpRequest{
+		Conn:            conn,
+		DatabaseName:    databaseName,
+		Tables:          effectiveTables,
+		Dir:             dumpDir,
+		Threads:         s.effectiveDumpThreads(logicalSize),
+		Compress:        s.config.Dump.Compress,
+		NetworkCompress: s.config.Dump.NetworkCompress,
+		TableCount:      tablesCount,
+		Incremental:     incremental,
+		Phase:           models.SyncPhaseDump,
+		MetricsFn:       tunnel.Metrics,
+		Tracker:         tracker,
+		Consistent:      snapshot.Consistent,
+		Compat:          &s.config.Compat,
+		Fixes:           fixes,
+	}, observer)
+	if err == nil && engine.Name() == config.DumpEngineMySQLShell && s.config.Compat.Definers == config.DefinersRewrite {
+		err = rewriteDumpDefiners(dumpDir, s.config.Compat.DefinerClause(), fixes)
+	}
+	if err == nil {
+		err = writeDumpEngineMarker(dumpDir, engine)
+	}
 	if err != nil {
-		os.RemoveAll(dumpDir)
-		return nil, "", formatMySQLShellError("dump", err, stdoutCapture.String(), stderrCapture.String())
+		removeWorkDir(dumpDir)
+		return nil, "", nil, err
 	}
 
 	// Подсчитываем размер дампа
@@ -586,10 +562,12 @@ func (s *MySQLShellService) CreateDumpTargetWithObserver(target models.SyncTarge
 		return nil
 	})
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to calculate dump size: %w", err)
+		return nil, "", nil, fmt.Errorf("failed to calculate dump size: %w", err)
 	}
 
 	endTime := time.Now()
+	tracker.FinishDump(endTime)
+	readDumpSnapshot(engine.Name(), dumpDir, databaseName, snapshot)
 
 	// Перезаписываем строку с результатом
 	s.printStatusf("\r✅ Dumped %s (%d tables) → %s in %v\n", databaseName, tablesCount, FormatSize(totalSize), endTime.Sub(startTime).Round(time.Second))
@@ -607,6 +585,9 @@ func (s *MySQLShellService) CreateDumpTargetWithObserver(target models.SyncTarge
 		AutoIncludedTables: append([]string(nil), target.AutoIncludedTables...),
 		TransportMode:      tunnel.TransportMode(),
 		Traffic:            tunnel.Metrics(),
+		Tables:             tracker.Snapshot(endTime),
+		Snapshot:           snapshot,
+		Compatibility:      fixes.forSchema(databaseName),
 		StartTime:          startTime,
 		EndTime:            endTime,
 	}
@@ -614,42 +595,116 @@ func (s *MySQLShellService) CreateDumpTargetWithObserver(target models.SyncTarge
 		result.CompressionRatio = float64(totalSize) / float64(logicalSize)
 	}
 	if observer != nil {
-		observer(models.ProgressSnapshot{Phase: models.SyncPhaseDump, DatabaseName: databaseName, Message: "Dump complete", Percent: 100, BytesCompleted: totalSize, BytesTotal: totalSize, Traffic: result.Traffic, Timestamp: endTime})
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseDump, DatabaseName: databaseName, Message: "Dump complete", Percent: 100, BytesCompleted: totalSize, BytesTotal: totalSize, Traffic: result.Traffic, Tables: result.Tables, Timestamp: endTime})
 	}
 
-	return result, dumpDir, nil
+	return result, dumpDir, tracker, nil
+}
+
+// targetStats — объем цели синхронизации на remote для прогресса и итогового результата.
+type targetStats struct {
+	tables          []models.Table
+	effectiveTables []string
+	logicalSize     int64
+	indexSize       int64
+	tablesCount     int
 }
 
-func (s *MySQLShellService) selectedTableStats(databaseName string, selectedTables []string) (int64, int64, int, error) {
-	tables, err := s.dbService.ListTables(databaseName, true)
+// collectTargetStats считает объем цели; для выбранных таблиц — только по ним.
+func (s *MySQLShellService) collectTargetStats(target models.SyncTarget) (targetStats, error) {
+	databaseName := target.DatabaseName
+	stats := targetStats{effectiveTables: target.EffectiveTables()}
+
+	// Получаем информацию о базе данных
+	dbInfo, err := s.dbService.GetDatabaseInfo(databaseName, true)
 	if err != nil {
-		return 0, 0, 0, err
+		return targetStats{}, fmt.Errorf("failed to get database info: %w", err)
+	}
+
+	stats.logicalSize = dbInfo.DataSize
+	stats.indexSize = dbInfo.IndexSize
+	stats.tablesCount = dbInfo.Tables
+	// Список таблиц нужен для per-table прогресса; для полной БД его отсутствие не критично.
+	remoteTables, listErr := s.dbService.ListTables(databaseName, true)
+	stats.tables = remoteTables
+	if len(target.SkipTables) > 0 {
+		if listErr != nil {
+			return targetStats{}, fmt.Errorf("failed to list remote tables: %w", listErr)
+		}
+		// Неизменившиеся таблицы остаются в локальной БД и в дамп не попадают.
+		stats.effectiveTables = dumpTableNames(target, remoteTables)
 	}
-	selected := make(map[string]struct{}, len(selectedTables))
-	for _, tableName := range selectedTables {
+	if len(stats.effectiveTables) > 0 {
+		if listErr != nil {
+			return targetStats{}, fmt.Errorf("failed to calculate selected table stats: %w", listErr)
+		}
+		stats.tables = filterTablesByName(remoteTables, stats.effectiveTables)
+		stats.logicalSize, stats.indexSize, stats.tablesCount = summarizeTableStats(stats.tables)
+	}
+	return stats, nil
+}
+
+func filterTablesByName(tables []models.Table, tableNames []string) []models.Table {
+	selected := make(map[string]struct{}, len(tableNames))
+	for _, tableName := range tableNames {
 		selected[tableName] = struct{}{}
 	}
+	filtered := make([]models.Table, 0, len(tableNames))
+	for _, table := range tables {
+		if _, ok := selected[table.Name]; ok {
+			filtered = append(filtered, table)
+		}
+	}
+	return filtered
+}
+
+func summarizeTableStats(tables []models.Table) (int64, int64, int) {
 	var logicalSize int64
 	var indexSize int64
-	var count int
 	for _, table := range tables {
-		if _, ok := selected[table.Name]; !ok {
-			continue
-		}
 		logicalSize += table.DataSize
 		indexSize += table.IndexSize
-		count++
 	}
-	return logicalSize, indexSize, count, nil
+	return logicalSize, indexSize, len(tables)
 }
 
-// RestoreDump восстанавливает дамп в локальную БД через MySQL Shell
+// RestoreDump восстанавливает дамп в локальную БД движком, которым дамп был снят
 func (s *MySQLShellService) RestoreDump(dumpDir string, databaseName string, dryRun bool) error {
 	return s.RestoreDumpWithObserver(dumpDir, databaseName, dryRun, nil)
 }
 
 // RestoreDumpWithObserver восстанавливает дамп и отправляет progress snapshots в observer.
+// Глобальные переменные локального MySQL, измененные на время загрузки, возвращаются после нее.
 func (s *MySQLShellService) RestoreDumpWithObserver(dumpDir string, databaseName string, dryRun bool, observer models.ProgressObserver) error {
+	if dryRun {
+		return s.restoreDump(dumpDir, databaseName, dryRun, observer, newTableProgressTracker(databaseName, nil))
+	}
+	return s.restoreDumpTuned(dumpDir, databaseName, databaseName, observer, newTableProgressTracker(databaseName, nil))
+}
+
+// restoreDumpTuned выполняет restoreDumpAs в сессии тюнинга для загрузок вне синхронизации:
+// бэкапов, архивов и RestoreDump.
+func (s *MySQLShellService) restoreDumpTuned(dumpDir string, sourceName string, databaseName string, observer models.ProgressObserver, tracker *tableProgressTracker) error {
+	// Тюнинг меняет переменные приемника, поэтому политика проверяется до него, а не только перед DROP.
+	if err := s.checkDestinationPolicy(databaseName); err != nil {
+		return err
+	}
+	engineName := ""
+	if engine, err := s.dumpEngineForDir(dumpDir); err == nil {
+		engineName = engine.Name()
+	}
+	_, err := s.withLoadTuning(engineName, func() error {
+		return s.restoreDumpAs(dumpDir, sourceName, databaseName, false, observer, tracker)
+	})
+	return err
+}
+
+func (s *MySQLShellService) restoreDump(dumpDir string, databaseName string, dryRun bool, observer models.ProgressObserver, tracker *tableProgressTracker) error {
+	return s.restoreDumpAs(dumpDir, databaseName, databaseName, dryRun, observer, tracker)
+}
+
+// restoreDumpAs пересоздает локальную БД databaseName и загружает в нее дамп схемы sourceName.
+func (s *MySQLShellService) restoreDumpAs(dumpDir string, sourceName string, databaseName string, dryRun bool, observer models.ProgressObserver, tracker *tableProgressTracker) error {
 	if dryRun {
 		if _, err := os.Stat(dumpDir); os.IsNotExist(err) {
 			return fmt.Errorf("dump directory does not exist: %s", dumpDir)
@@ -662,148 +717,38 @@ func (s *MySQLShellService) RestoreDumpWithObserver(dumpDir string, databaseName
 		return fmt.Errorf("dump directory does not exist: %s", dumpDir)
 	}
 
-	// Включаем local_infile на локальном сервере (требуется для MySQL Shell)
-	enableLocalInfile := exec.Command(
-		"mysql",
-		"--host="+s.config.Local.Host,
-		"--port="+fmt.Sprintf("%d", s.config.Local.Port),
-		"--user="+s.config.Local.User,
-		"--password="+s.config.Local.Password,
-		"-e", "SET GLOBAL local_infile = 1",
-	)
-	if output, err := enableLocalInfile.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to enable local_infile: %w\nOutput: %s", err, string(output))
-	}
-
-	// Проверяем существует ли локальная база данных
-	localExists, err := s.dbService.DatabaseExists(databaseName, false)
+	// Движок определяется до удаления локальной БД, чтобы не остаться без данных и без загрузчика
+	engine, err := s.dumpEngineForDir(dumpDir)
 	if err != nil {
-		return fmt.Errorf("failed to check if local database exists: %w", err)
-	}
-
-	if localExists {
-		// Убиваем все сессии, подключённые к этой БД
-		killQuery := fmt.Sprintf(`SELECT GROUP_CONCAT(id) FROM information_schema.processlist WHERE db = '%s' AND id != CONNECTION_ID()`, databaseName)
-		killCmd := exec.Command(
-			"mysql",
-			"--host="+s.config.Local.Host,
-			"--port="+fmt.Sprintf("%d", s.config.Local.Port),
-			"--user="+s.config.Local.User,
-			"--password="+s.config.Local.Password,
-			"-N", "-s",
-			"-e", killQuery,
-		)
-		if output, err := killCmd.Output(); err == nil {
-			ids := strings.Split(strings.TrimSpace(string(output)), ",")
-			for _, id := range ids {
-				if id != "" && id != "NULL" {
-					exec.Command(
-						"mysql",
-						"--host="+s.config.Local.Host,
-						"--port="+fmt.Sprintf("%d", s.config.Local.Port),
-						"--user="+s.config.Local.User,
-						"--password="+s.config.Local.Password,
-						"-e", fmt.Sprintf("KILL %s", id),
-					).Run()
-				}
-			}
-		}
-
-		dropCmd := exec.Command(
-			"mysql",
-			"--host="+s.config.Local.Host,
-			"--port="+fmt.Sprintf("%d", s.config.Local.Port),
-			"--user="+s.config.Local.User,
-			"--password="+s.config.Local.Password,
-			"-e", fmt.Sprintf("DROP DATABASE IF EXISTS `%s`", databaseName),
-		)
-
-		if output, err := dropCmd.CombinedOutput(); err != nil {
-			return fmt.Errorf("failed to drop existing database: %w\nOutput: %s", err, string(output))
-		}
-	}
-
-	// Создаём новую БД
-	createCmd := exec.Command(
-		"mysql",
-		"--host="+s.config.Local.Host,
-		"--port="+fmt.Sprintf("%d", s.config.Local.Port),
-		"--user="+s.config.Local.User,
-		"--password="+s.config.Local.Password,
-		"-e", fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", databaseName),
-	)
-
-	if output, err := createCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("failed to create database: %w\nOutput: %s", err, string(output))
+		return err
 	}
 
-	mysqlshPath, err := s.findMySQLShell()
-	if err != nil {
+	if err := s.prepareLocalDatabase(databaseName); err != nil {
 		return err
 	}
 
-	// Строим команду mysqlsh для загрузки
-	threads := s.config.Dump.Threads
-	args := []string{
-		"--uri", s.buildLocalURI(),
-		fmt.Sprintf("--password=%s", s.config.Local.Password),
-		"--", "util", "load-dump", dumpDir,
-		fmt.Sprintf("--threads=%d", threads),
-		"--deferTableIndexes=all", // Создаём индексы после данных
-		"--resetProgress",         // Сбрасываем прогресс предыдущих попыток
-		"--ignoreVersion",         // Игнорируем разницу версий MySQL
-		"--skipBinlog=true",       // Пропускаем запись в binlog
-	}
-
-	cmd := exec.Command(mysqlshPath, args...)
-	cmd.Env = append(os.Environ(), "MYSQLSH_TERM_COLOR_MODE=nocolor")
-
 	// Показываем статус в одной строке (будет перезаписана)
 	s.printStatusf("🔄 Restoring %s...", databaseName)
 
-	// Создаём pipe для фильтрации вывода
-	stdoutPipe, _ := cmd.StdoutPipe()
-	stderrPipe, _ := cmd.StderrPipe()
-
 	startTime := time.Now()
 	if observer != nil {
 		observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: databaseName, Message: "Preparing local restore", Timestamp: startTime})
-	}
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start mysqlsh: %w", err)
-	}
-	if observer != nil {
 		observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: databaseName, Message: "Loading dump into local MySQL", Timestamp: time.Now()})
 	}
-
-	stdoutCapture := &progressCaptureWriter{}
-	stderrCapture := &progressCaptureWriter{}
-	if !s.quiet {
-		stdoutCapture.writer = os.Stdout
-		stderrCapture.writer = os.Stderr
+	if sourceName != databaseName {
+		tracker.SetLoadSchema(databaseName)
 	}
-
-	var streamWG sync.WaitGroup
-	streamWG.Add(2)
-	go func() {
-		defer streamWG.Done()
-		filterMySQLShellOutput(io.TeeReader(stdoutPipe, stdoutCapture), models.SyncPhaseRestore, databaseName, nil, observer, nil)
-	}()
-	go func() {
-		defer streamWG.Done()
-		filterMySQLShellOutput(io.TeeReader(stderrPipe, stderrCapture), models.SyncPhaseRestore, databaseName, nil, observer, nil)
-	}()
-
-	err = cmd.Wait()
-	streamWG.Wait()
-	if err != nil {
-		return formatMySQLShellError("load", err, stdoutCapture.String(), stderrCapture.String())
+	if err := engine.Load(LoadRequest{DatabaseName: sourceName, Schema: databaseName, Dir: dumpDir, Threads: s.config.Dump.Threads, Tracker: tracker}, observer); err != nil {
+		return err
 	}
 
+	finishedAt := time.Now()
+	tracker.FinishLoad(finishedAt)
+
 	// Перезаписываем строку с результатом
 	s.printStatusf("\r✅ Restored %s in %v                    \n", databaseName, time.Since(startTime).Round(time.Second))
 	if observer != nil {
-		observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: databaseName, Message: "Restore complete", Percent: 100, Timestamp: time.Now()})
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: databaseName, Message: "Restore complete", Percent: 100, Tables: tracker.Snapshot(finishedAt), Timestamp: finishedAt})
 	}
 
 	return nil
@@ -811,7 +756,12 @@ func (s *MySQLShellService) RestoreDumpWithObserver(dumpDir string, databaseName
 
 // ExecuteSync выполняет полную синхронизацию базы данных через MySQL Shell
 func (s *MySQLShellService) ExecuteSync(databaseName string) (*models.SyncResult, error) {
-	return s.ExecuteTargetWithObserver(models.SyncTarget{DatabaseName: databaseName, ReplaceEntireDatabase: true}, nil)
+	plan := &models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: databaseName, ReplaceEntireDatabase: true}}, CreatedAt: time.Now()}
+	results, err := s.ExecutePlan(plan, models.RuntimeOptions{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &results[0], nil
 }
 
 // ExecuteTarget выполняет полную синхронизацию указанной цели, включая partial table sync.
@@ -821,54 +771,353 @@ func (s *MySQLShellService) ExecuteTarget(target models.SyncTarget) (*models.Syn
 
 // ExecuteTargetWithObserver выполняет синхронизацию одной цели с progress observer.
 func (s *MySQLShellService) ExecuteTargetWithObserver(target models.SyncTarget, observer models.ProgressObserver) (*models.SyncResult, error) {
-	startTime := time.Now()
+	service, cleanup, err := s.routeService(target)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	result, err := service.executeTarget(target, observer)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// targetRun хранит состояние синхронизации одной цели между фазами, чтобы фазы дампа и загрузки
+// можно было выполнять как для одной цели, так и пакетом для нескольких.
+type targetRun struct {
+	s            *MySQLShellService
+	target       models.SyncTarget
+	observer     models.ProgressObserver
+	startTime    time.Time
+	hooks        *HookSet
+	hookResults  []models.HookResult
+	backup       *models.LocalBackup
+	verification *models.VerificationResult
+	incremental  *models.IncrementalPlan
+	smart        *models.SmartSyncPlan
+	highWater    map[string]models.TableWatermark
+	snapshots    map[string]models.TableSnapshot
+	env          hookEnv
+	// localReplaced: локальная БД уже удалена или перезаписана, при ошибке нужен откат из бэкапа.
+	localReplaced bool
+	upToDate      bool
+	streamCopy    bool
+	// batched: цель дампится общим пакетом, место на диске проверяется для пакета целиком.
+	batched         bool
+	dumpResult      *models.SyncResult
+	dumpDir         string
+	tracker         *tableProgressTracker
+	restoreDuration time.Duration
+	// tuning: переменные локального MySQL, измененные и восстановленные вокруг загрузки.
+	tuning []models.ServerVariable
+}
+
+func (s *MySQLShellService) newTargetRun(target models.SyncTarget, observer models.ProgressObserver) *targetRun {
+	return &targetRun{
+		s:          s,
+		target:     target,
+		observer:   observer,
+		startTime:  time.Now(),
+		env:        hookEnv{DatabaseName: target.DatabaseName, LocalDB: target.DatabaseName},
+		dumpResult: &models.SyncResult{SelectedTables: append([]string(nil), target.SelectedTables...), AutoIncludedTables: append([]string(nil), target.AutoIncludedTables...), TransportMode: s.transportMode()},
+		tracker:    newTableProgressTracker(target.DatabaseName, nil),
+	}
+}
+
+// fail возвращает частичный результат, откатывает локальную БД из бэкапа и выполняет on_failure hooks.
+func (r *targetRun) fail(err error) (*models.SyncResult, error) {
+	s := r.s
+	databaseName := r.target.DatabaseName
+	result := &models.SyncResult{
+		Success:            false,
+		DatabaseName:       databaseName,
+		Error:              err.Error(),
+		FailureType:        models.FailureTypeError,
+		SelectedTables:     append([]string(nil), r.target.SelectedTables...),
+		AutoIncludedTables: append([]string(nil), r.target.AutoIncludedTables...),
+		Backup:             r.backup,
+		Verification:       r.verification,
+		Incremental:        r.incremental,
+		SmartSync:          r.smart,
+		Batch:              append([]string(nil), r.dumpResult.Batch...),
+		ServerTuning:       r.tuning,
+		Snapshot:           r.dumpResult.Snapshot,
+		Compatibility:      r.dumpResult.Compatibility,
+		Source:             r.target.Source,
+		Destination:        r.target.Destination,
+		StartTime:          r.startTime,
+	}
+	// Локальная БД уже удалена или перезаписана — возвращаем ее из бэкапа.
+	if r.localReplaced {
+		s.rollbackFromBackup(result, r.observer)
+	}
+	if r.hooks != nil {
+		failureEnv := r.env
+		failureEnv.Point = models.HookOnFailure
+		failureEnv.Failure = err
+		// on_failure должны сработать и после отмены плана, поэтому отмена ctx на них не распространяется.
+		failureHooks, _ := s.runHooks(context.WithoutCancel(s.runContext()), r.hooks, failureEnv, r.observer)
+		r.hookResults = append(r.hookResults, failureHooks...)
+	}
+	result.Hooks = r.hookResults
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(r.startTime)
+	if r.observer != nil {
+		r.observer(models.ProgressSnapshot{Phase: models.SyncPhaseFailed, DatabaseName: databaseName, Message: err.Error(), Timestamp: result.EndTime})
+	}
+	return result, err
+}
+
+func (r *targetRun) runHooks(point models.HookPoint) error {
+	pointEnv := r.env
+	pointEnv.Point = point
+	results, err := r.s.runHooks(r.s.runContext(), r.hooks, pointEnv, r.observer)
+	r.hookResults = append(r.hookResults, results...)
+	return err
+}
+
+// engineName возвращает движок, которым цель снята и загружена. Потоковое копирование выполняет
+// mysqlsh и грузит данные через LOAD DATA LOCAL, как load-dump.
+func (r *targetRun) engineName() string {
+	if r.streamCopy {
+		return config.DumpEngineMySQLShell
+	}
+	return r.s.dumpEngineName()
+}
+
+// cleanup удаляет директорию дампа цели.
+func (r *targetRun) cleanup() {
+	if r.dumpDir != "" {
+		removeWorkDir(r.dumpDir)
+	}
+}
+
+// prepare проверяет цель, строит incremental и smart планы и выполняет before_dump hooks.
+func (r *targetRun) prepare() error {
+	s := r.s
+	target := r.target
 	databaseName := target.DatabaseName
-	if observer != nil {
-		observer(models.ProgressSnapshot{Phase: models.SyncPhaseValidation, DatabaseName: databaseName, Message: "Validating connections and prerequisites", Timestamp: startTime})
+	if r.observer != nil {
+		r.observer(models.ProgressSnapshot{Phase: models.SyncPhaseValidation, DatabaseName: databaseName, Message: "Validating connections and prerequisites", Timestamp: r.startTime})
+	}
+
+	hooks, err := s.loadHooks()
+	r.hooks = hooks
+	if err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Валидация операции; место на диске проверяется ниже, когда известно, что цель будет дампить.
+	if err := s.validateDumpPrerequisites(databaseName); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if s.config.Incremental.Enabled {
+		var columns map[string]models.IncrementalColumn
+		r.incremental, columns, err = s.planIncremental(target)
+		if err != nil {
+			return fmt.Errorf("incremental planning failed: %w", err)
+		}
+		r.highWater, err = s.captureHighWater(databaseName, columns)
+		if err != nil {
+			return fmt.Errorf("incremental planning failed: %w", err)
+		}
+		if engine, err := s.dumpEngine(); err == nil && !engine.SupportsIncremental() && r.incremental.Active() {
+			r.incremental = fullIncrementalPlan(r.incremental, incrementalReasonEngine)
+		}
 	}
 
-	// Валидация операции
-	if err := s.ValidateDumpOperation(databaseName); err != nil {
-		if observer != nil {
-			observer(models.ProgressSnapshot{Phase: models.SyncPhaseFailed, DatabaseName: databaseName, Message: err.Error(), Timestamp: time.Now()})
+	if s.config.Smart.Enabled {
+		r.smart, r.snapshots, err = s.planSmartSync(target)
+		if err != nil {
+			return fmt.Errorf("smart sync planning failed: %w", err)
 		}
-		return nil, fmt.Errorf("validation failed: %w", err)
+		r.target.SkipTables = r.smart.SkippedTables()
+		r.incremental = r.incremental.Without(r.target.SkipTables)
 	}
+	r.upToDate = r.smart != nil && len(r.smart.Tables) > 0 && len(r.smart.RefreshedTables()) == 0
 
-	// Создаем дамп
-	dumpResult, dumpDir, err := s.CreateDumpTargetWithObserver(target, false, observer)
+	// Потоковое копирование заменяет дамп и загрузку, когда локальная БД пересоздается целиком.
+	if s.config.Dump.Copy && !r.upToDate {
+		if err := s.streamCopyAvailable(r.target, r.incremental); err != nil {
+			s.printStatusf("⚠️  %v, using dump and load\n", err)
+		} else {
+			r.streamCopy = true
+		}
+	}
+
+	if !r.batched {
+		if err := r.checkDiskSpace(); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+	}
+
+	return r.runHooks(models.HookBeforeDump)
+}
+
+// diskTarget возвращает цель в том объеме, который реально будет сдамплен и загружен:
+// при incremental синхронизации полностью переносятся только таблицы из Full.
+func (r *targetRun) diskTarget() (models.SyncTarget, bool) {
+	if r.upToDate {
+		return models.SyncTarget{}, false
+	}
+	target := r.target
+	if r.incremental.Active() {
+		if len(r.incremental.Full) == 0 {
+			return models.SyncTarget{}, false
+		}
+		target.SelectedTables = append([]string(nil), r.incremental.Full...)
+		target.AutoIncludedTables = nil
+	}
+	return target, true
+}
+
+// checkDiskSpace проверяет свободное место под дамп и загрузку цели.
+func (r *targetRun) checkDiskSpace() error {
+	target, ok := r.diskTarget()
+	if !ok {
+		return nil
+	}
+	return r.s.checkTargetsDiskSpace([]models.SyncTarget{target}, r.streamCopy)
+}
+
+// dump создает дамп цели; если ни одна таблица не изменилась, дамп и восстановление не нужны.
+func (r *targetRun) dump() error {
+	if r.upToDate || r.streamCopy {
+		return nil
+	}
+	// При ошибке dumpResult остается прежним: fail берет из него пакет цели.
+	dumpResult, dumpDir, tracker, err := r.s.createDumpTarget(r.target, r.incremental, false, r.observer)
 	if err != nil {
-		if observer != nil {
-			observer(models.ProgressSnapshot{Phase: models.SyncPhaseFailed, DatabaseName: databaseName, Message: err.Error(), Timestamp: time.Now()})
+		return fmt.Errorf("dump creation failed: %w", err)
+	}
+	r.dumpResult, r.dumpDir, r.tracker = dumpResult, dumpDir, tracker
+	r.env.DumpDir = r.dumpDir
+	return nil
+}
+
+// afterDump выполняет after_dump hooks и бэкапит локальную БД перед ее заменой.
+func (r *targetRun) afterDump() error {
+	s := r.s
+	databaseName := r.target.DatabaseName
+	if !r.streamCopy {
+		if err := r.runHooks(models.HookAfterDump); err != nil {
+			return err
 		}
-		return nil, fmt.Errorf("dump creation failed: %w", err)
 	}
 
-	// Очищаем директорию дампа после завершения
-	defer func() {
-		if dumpDir != "" {
-			os.RemoveAll(dumpDir)
+	if s.config.Backup.Enabled && !r.upToDate {
+		localExists, err := s.dbService.DatabaseExists(databaseName, false)
+		if err != nil {
+			return fmt.Errorf("failed to check if local database exists: %w", err)
 		}
-	}()
+		if localExists {
+			r.backup, err = s.BackupLocalDatabase(databaseName, r.observer)
+			if err != nil {
+				return fmt.Errorf("local backup failed: %w", err)
+			}
+		}
+	}
+	return nil
+}
 
-	// Восстанавливаем дамп
+// restore загружает дамп или копирует цель напрямую в локальную БД в сессии тюнинга локального MySQL.
+func (r *targetRun) restore() error {
+	s := r.s
 	restoreStart := time.Now()
-	if err := s.RestoreDumpWithObserver(dumpDir, databaseName, false, observer); err != nil {
-		if observer != nil {
-			observer(models.ProgressSnapshot{Phase: models.SyncPhaseFailed, DatabaseName: databaseName, Message: err.Error(), Timestamp: time.Now()})
+	if r.upToDate {
+		s.printStatusf("⏭️  %s: all %d tables unchanged since last sync\n", r.target.DatabaseName, len(r.smart.Tables))
+		r.restoreDuration = time.Since(restoreStart)
+		return nil
+	}
+	var err error
+	r.tuning, err = s.withLoadTuning(r.engineName(), r.load)
+	if err != nil {
+		return err
+	}
+	r.restoreDuration = time.Since(restoreStart)
+	return nil
+}
+
+// load выполняет загрузку цели: потоковое копирование, staging-загрузку или полную замену БД.
+func (r *targetRun) load() error {
+	s := r.s
+	databaseName := r.target.DatabaseName
+	var err error
+	switch {
+	case r.streamCopy:
+		r.localReplaced = true
+		var copyResult *models.SyncResult
+		copyResult, r.tracker, err = s.copyTarget(r.target, r.observer)
+		if err != nil {
+			// Поддержка copy-schemas проверена в prepare: ошибка копирования — обычный сбой цели с откатом.
+			return fmt.Errorf("stream copy failed: %w", err)
+		}
+		r.dumpResult = copyResult
+		if err := r.runHooks(models.HookAfterDump); err != nil {
+			return err
 		}
-		return nil, fmt.Errorf("restore failed: %w", err)
+	case r.incremental.Active() || len(r.target.SkipTables) > 0:
+		// Пропущенные таблицы остаются на месте, поэтому загрузка идет через staging-схему.
+		r.localReplaced = true
+		err = s.restoreIncremental(r.dumpDir, databaseName, stagingPlan(databaseName, r.incremental, r.smart), r.observer, r.tracker)
+	default:
+		r.localReplaced = true
+		err = s.restoreDump(r.dumpDir, databaseName, false, r.observer, r.tracker)
 	}
-	restoreDuration := time.Since(restoreStart)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	return nil
+}
 
-	endTime := time.Now()
+// finish проверяет результат, выполняет after_restore hooks, сохраняет состояние для следующих запусков
+// и собирает итоговый результат цели.
+func (r *targetRun) finish() (*models.SyncResult, error) {
+	s := r.s
+	target := r.target
+	databaseName := target.DatabaseName
+	if s.config.Verify.Enabled {
+		r.verification = s.verifyTarget(databaseName, r.engineName(), r.tracker.Snapshot(time.Now()), r.observer)
+		if err := verificationError(r.verification); err != nil {
+			if s.config.Verify.FailOnMismatch {
+				return r.fail(err)
+			}
+			s.printStatusf("⚠️  %v\n", err)
+		}
+	}
+
+	if err := r.runHooks(models.HookAfterRestore); err != nil {
+		return r.fail(err)
+	}
+
+	if s.config.Incremental.Enabled {
+		// Ошибка записи watermarks не ломает синхронизацию: следующий запуск просто пойдет полностью.
+		if err := s.storeWatermarks(databaseName, target.EffectiveTables(), r.highWater); err != nil {
+			s.printStatusf("⚠️  Failed to store incremental watermarks: %v\n", err)
+		}
+	}
+	if s.config.Smart.Enabled {
+		if err := s.storeTableSnapshots(databaseName, target.EffectiveTables(), r.snapshots, time.Now()); err != nil {
+			s.printStatusf("⚠️  Failed to store table snapshots: %v\n", err)
+		}
+	}
+	// Дамп с дельтой incremental не отражает сжатие данных и в историю не попадает.
+	if ratio := r.dumpResult.CompressionRatio; ratio > 0 && !r.streamCopy && !r.incremental.Active() && s.config.Preflight.Disk != config.PreflightDiskOff {
+		if err := s.storeCompressionRatio(databaseName, ratio); err != nil {
+			s.printStatusf("⚠️  Failed to store dump history: %v\n", err)
+		}
+	}
 
+	endTime := time.Now()
+	dumpResult := r.dumpResult
 	result := &models.SyncResult{
 		Success:            true,
 		DatabaseName:       databaseName,
-		Duration:           endTime.Sub(startTime),
+		Duration:           endTime.Sub(r.startTime),
 		DumpDuration:       dumpResult.Duration,
-		RestoreDuration:    restoreDuration,
+		RestoreDuration:    r.restoreDuration,
 		DumpSize:           dumpResult.DumpSize,
 		DumpSizeOnDisk:     dumpResult.DumpSizeOnDisk,
 		LogicalSize:        dumpResult.LogicalSize,
@@ -879,52 +1128,103 @@ func (s *MySQLShellService) ExecuteTargetWithObserver(target models.SyncTarget,
 		TransportMode:      dumpResult.TransportMode,
 		CompressionRatio:   dumpResult.CompressionRatio,
 		Traffic:            dumpResult.Traffic,
-		StartTime:          startTime,
+		Tables:             r.tracker.Snapshot(endTime),
+		Hooks:              r.hookResults,
+		Backup:             r.backup,
+		Verification:       r.verification,
+		Incremental:        r.incremental,
+		SmartSync:          r.smart,
+		StreamCopy:         dumpResult.StreamCopy,
+		Batch:              append([]string(nil), dumpResult.Batch...),
+		ServerTuning:       r.tuning,
+		Snapshot:           dumpResult.Snapshot,
+		Compatibility:      dumpResult.Compatibility,
+		Source:             r.target.Source,
+		Destination:        r.target.Destination,
+		StartTime:          r.startTime,
 		EndTime:            endTime,
 	}
-	if observer != nil {
-		observer(models.ProgressSnapshot{Phase: models.SyncPhaseDone, DatabaseName: databaseName, Message: "Sync complete", Percent: 100, BytesCompleted: result.Traffic.TotalBytes(), BytesTotal: result.Traffic.TotalBytes(), Traffic: result.Traffic, Timestamp: endTime})
+	if r.observer != nil {
+		r.observer(models.ProgressSnapshot{Phase: models.SyncPhaseDone, DatabaseName: databaseName, Message: "Sync complete", Percent: 100, BytesCompleted: result.Traffic.TotalBytes(), BytesTotal: result.Traffic.TotalBytes(), Traffic: result.Traffic, Tables: result.Tables, Timestamp: endTime})
 	}
-
 	return result, nil
 }
 
+// executeTarget выполняет цель и при ошибке возвращает частичный результат с выполненными hooks.
+func (s *MySQLShellService) executeTarget(target models.SyncTarget, observer models.ProgressObserver) (*models.SyncResult, error) {
+	run := s.newTargetRun(target, observer)
+	// Очищаем директорию дампа после завершения
+	defer run.cleanup()
+
+	for _, phase := range []func() error{run.prepare, run.dump, run.afterDump, run.restore} {
+		if err := phase(); err != nil {
+			return run.fail(err)
+		}
+	}
+	return run.finish()
+}
+
 // ExecutePlan выполняет план синхронизации последовательно и стримит progress snapshots.
 func (s *MySQLShellService) ExecutePlan(plan *models.SyncPlan, runtime models.RuntimeOptions, observer models.ProgressObserver) ([]models.SyncResult, error) {
 	if plan == nil {
 		return nil, fmt.Errorf("sync plan is nil")
 	}
+	if policyResults, err := s.enforcePolicy(plan, observer); err != nil {
+		s.notifyPlan(plan, policyResults, err)
+		return policyResults, err
+	}
+	return s.withConfirmedDatabases(plan.ConfirmedDatabases).executePlan(plan, observer)
+}
+
+func (s *MySQLShellService) executePlan(plan *models.SyncPlan, observer models.ProgressObserver) ([]models.SyncResult, error) {
 	results := make([]models.SyncResult, 0, len(plan.Targets))
-	for _, target := range plan.Targets {
-		result, err := s.ExecuteTargetWithObserver(target, observer)
+	// Подряд идущие целые БД при dump.batch_size > 1 дампятся и загружаются одним вызовом mysqlsh.
+	for _, batch := range s.planBatches(plan.Targets) {
+		if err := s.runContext().Err(); err != nil {
+			err = fmt.Errorf("sync cancelled: %w", err)
+			s.notifyPlan(plan, results, err)
+			return results, err
+		}
+		batchResults, err := s.executeRoutedBatch(batch, observer)
+		results = append(results, batchResults...)
 		if err != nil {
-			failed := models.SyncResult{DatabaseName: target.DatabaseName, Success: false, Error: err.Error(), StartTime: time.Now(), EndTime: time.Now()}
-			results = append(results, failed)
+			if ctxErr := s.runContext().Err(); ctxErr != nil {
+				err = fmt.Errorf("sync cancelled: %w", ctxErr)
+			}
+			s.notifyPlan(plan, results, err)
 			return results, err
 		}
-		results = append(results, *result)
 	}
-	_ = runtime
+	s.notifyPlan(plan, results, nil)
 	return results, nil
 }
 
+// ExecutePlanContext выполняет план как ExecutePlan; отмена ctx останавливает текущий mysqlsh и пропускает
+// оставшиеся цели. Откат из бэкапа после отмены выполняется до конца.
+func (s *MySQLShellService) ExecutePlanContext(ctx context.Context, plan *models.SyncPlan, runtime models.RuntimeOptions, observer models.ProgressObserver) ([]models.SyncResult, error) {
+	return s.withContext(ctx).ExecutePlan(plan, runtime, observer)
+}
+
+// notifyPlan отправляет webhook-уведомления; ошибки доставки не влияют на результат плана.
+func (s *MySQLShellService) notifyPlan(plan *models.SyncPlan, results []models.SyncResult, runErr error) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.NotifyPlan(plan, results, runErr); err != nil {
+		s.printStatusf("⚠️  Notification delivery failed: %v\n", err)
+	}
+}
+
 // Cleanup удаляет временные файлы
 func (s *MySQLShellService) Cleanup(dumpDir string) error {
 	if dumpDir != "" {
-		return os.RemoveAll(dumpDir)
+		return removeWorkDir(dumpDir)
 	}
 	return nil
 }
 
 func formatMySQLShellError(operation string, commandErr error, stdout string, stderr string) error {
-	parts := []string{fmt.Sprintf("mysqlsh %s failed: %v", operation, commandErr)}
-	if stderr != "" {
-		parts = append(parts, "stderr: "+stderr)
-	}
-	if stdout != "" {
-		parts = append(parts, "stdout: "+stdout)
-	}
-	return errors.New(strings.Join(parts, "\n"))
+	return formatEngineError("mysqlsh", operation, commandErr, stdout, stderr)
 }
 
 func emitTrafficSnapshots(stop <-chan struct{}, interval time.Duration, databaseName string, bytesTotal int64, metricsFn func() models.TrafficMetrics, observer models.ProgressObserver) {
@@ -978,3 +1278,57 @@ func emitTrafficSnapshots(stop <-chan struct{}, interval time.Duration, database
 		}
 	}
 }
+
+// killLocalSessions завершает локальные сессии, подключённые к БД, чтобы DROP DATABASE не ждал блокировок.
+func (s *MySQLShellService) killLocalSessions(databaseName string) {
+	db, cleanup, err := openMySQLConnection(s.config.Local, "")
+	if err != nil {
+		return
+	}
+	defer cleanup()
+	defer db.Close()
+
+	rows, err := db.Query("SELECT id FROM information_schema.processlist WHERE db = ? AND id != CONNECTION_ID()", databaseName)
+	if err != nil {
+		return
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if rows.Scan(&id) == nil {
+			ids = append(ids, id)
+		}
+	}
+	rows.Close()
+	for _, id := range ids {
+		_, _ = db.Exec(fmt.Sprintf("KILL %d", id))
+	}
+}
+
+// prepareLocalDatabase пересоздает пустую локальную БД перед полной загрузкой.
+func (s *MySQLShellService) prepareLocalDatabase(databaseName string) error {
+	if err := s.checkDestinationPolicy(databaseName); err != nil {
+		return err
+	}
+
+	// Проверяем существует ли локальная база данных
+	localExists, err := s.dbService.DatabaseExists(databaseName, false)
+	if err != nil {
+		return fmt.Errorf("failed to check if local database exists: %w", err)
+	}
+
+	if localExists {
+		// Убиваем все сессии, подключённые к этой БД
+		s.killLocalSessions(databaseName)
+
+		if err := s.execLocalSQL(fmt.Sprintf("DROP DATABASE IF EXISTS %s", quoteIdentifier(databaseName))); err != nil {
+			return fmt.Errorf("failed to drop existing database: %w", err)
+		}
+	}
+
+	// Создаём новую БД
+	if err := s.execLocalSQL(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", quoteIdentifier(databaseName))); err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+	return nil
+}
@@ -9,6 +9,24 @@ import (
 	"db-sync-cli/internal/models"
 )
 
+// testConfig возвращает конфигурацию remote prod.example.com → локальный 127.0.0.1 с бэкапами во временной
+// директории. Тесты меняют нужные поля на месте.
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		Remote: config.MySQLConfig{Host: "prod.example.com", Port: 3306, User: "reader"},
+		Local:  config.MySQLConfig{Host: "127.0.0.1", Port: 3306, User: "root"},
+		Backup: config.BackupConfig{Dir: t.TempDir()},
+	}
+}
+
+// newTestService возвращает сервис над cfg без вывода статуса.
+func newTestService(cfg *config.Config, dbService DatabaseServiceInterface) *MySQLShellService {
+	service := NewMySQLShellService(cfg, dbService)
+	service.SetQuiet(true)
+	return service
+}
+
 func TestParseMySQLShellProgressLine(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// WebhookKind описывает формат payload для webhook-уведомления.
+type WebhookKind string
+
+const (
+	WebhookKindJSON     WebhookKind = "json"
+	WebhookKindSlack    WebhookKind = "slack"
+	WebhookKindTelegram WebhookKind = "telegram"
+)
+
+const (
+	notifyEventCompleted = "plan.completed"
+	notifyEventFailed    = "plan.failed"
+
+	defaultNotifyBackoff = time.Second
+)
+
+// WebhookTarget описывает один адрес доставки уведомлений.
+type WebhookTarget struct {
+	Kind WebhookKind
+	URL  string
+}
+
+// PlanNotification содержит итог выполнения плана для уведомлений. Длительности передаются
+// в секундах, чтобы получатели не зависели от наносекунд time.Duration.
+type PlanNotification struct {
+	Event           string                  `json:"event"`
+	Success         bool                    `json:"success"`
+	Error           string                  `json:"error,omitempty"`
+	Targets         int                     `json:"targets"`
+	Completed       int                     `json:"completed"`
+	DurationSeconds float64                 `json:"duration_seconds"`
+	FinishedAt      time.Time               `json:"finished_at"`
+	Results         []NotificationResultRow `json:"results"`
+}
+
+// NotificationResultRow содержит краткую сводку SyncResult одной цели.
+type NotificationResultRow struct {
+	DatabaseName           string  `json:"database_name"`
+	Success                bool    `json:"success"`
+	Error                  string  `json:"error,omitempty"`
+	DurationSeconds        float64 `json:"duration_seconds"`
+	DumpDurationSeconds    float64 `json:"dump_duration_seconds"`
+	RestoreDurationSeconds float64 `json:"restore_duration_seconds"`
+	LogicalSize            int64   `json:"logical_size_bytes,omitempty"`
+	DumpSizeOnDisk         int64   `json:"dump_size_on_disk_bytes,omitempty"`
+	BytesIn                int64   `json:"bytes_in,omitempty"`
+	BytesOut               int64   `json:"bytes_out,omitempty"`
+}
+
+// Notifier доставляет уведомления о завершении плана в webhooks.
+// Ошибки доставки возвращаются вызывающему коду и никогда не влияют на результат синхронизации.
+type Notifier struct {
+	targets []WebhookTarget
+	client  *http.Client
+	retries int
+	backoff time.Duration
+	sleep   func(time.Duration)
+}
+
+// NewNotifier создает Notifier по настройкам notify.*; без webhooks возвращает nil.
+func NewNotifier(cfg config.NotifyConfig) *Notifier {
+	targets := ParseWebhookTargets(cfg.WebhookURLs())
+	if len(targets) == 0 {
+		return nil
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &Notifier{
+		targets: targets,
+		client:  &http.Client{Timeout: timeout},
+		retries: cfg.Retries,
+		backoff: defaultNotifyBackoff,
+		sleep:   time.Sleep,
+	}
+}
+
+// ParseWebhookTargets определяет формат каждого webhook по префиксу или хосту.
+func ParseWebhookTargets(rawURLs []string) []WebhookTarget {
+	targets := make([]WebhookTarget, 0, len(rawURLs))
+	for _, raw := range rawURLs {
+		kind, target := config.SplitWebhookKind(strings.TrimSpace(raw))
+		if target == "" {
+			continue
+		}
+		webhookKind := WebhookKind(kind)
+		if webhookKind == "" {
+			webhookKind = detectWebhookKind(target)
+		}
+		targets = append(targets, WebhookTarget{Kind: webhookKind, URL: target})
+	}
+	return targets
+}
+
+func detectWebhookKind(rawURL string) WebhookKind {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return WebhookKindJSON
+	}
+	host := strings.ToLower(parsed.Hostname())
+	switch {
+	case host == "hooks.slack.com" || strings.HasSuffix(host, ".slack.com"):
+		return WebhookKindSlack
+	case host == "api.telegram.org":
+		return WebhookKindTelegram
+	default:
+		return WebhookKindJSON
+	}
+}
+
+// BuildPlanNotification собирает сводку плана из результатов ExecutePlan.
+func BuildPlanNotification(plan *models.SyncPlan, results []models.SyncResult, runErr error, finishedAt time.Time) PlanNotification {
+	notification := PlanNotification{
+		Event:      notifyEventCompleted,
+		Success:    runErr == nil,
+		FinishedAt: finishedAt,
+		Results:    make([]NotificationResultRow, 0, len(results)),
+	}
+	if plan != nil {
+		notification.Targets = len(plan.Targets)
+	}
+	for _, result := range results {
+		if result.Success {
+			notification.Completed++
+		} else {
+			notification.Success = false
+		}
+		notification.DurationSeconds += result.Duration.Seconds()
+		notification.Results = append(notification.Results, NotificationResultRow{
+			DatabaseName:           result.DatabaseName,
+			Success:                result.Success,
+			Error:                  result.Error,
+			DurationSeconds:        result.Duration.Seconds(),
+			DumpDurationSeconds:    result.DumpDuration.Seconds(),
+			RestoreDurationSeconds: result.RestoreDuration.Seconds(),
+			LogicalSize:            result.LogicalSize,
+			DumpSizeOnDisk:         result.DumpSizeOnDisk,
+			BytesIn:                result.Traffic.BytesIn,
+			BytesOut:               result.Traffic.BytesOut,
+		})
+	}
+	if runErr != nil {
+		notification.Error = runErr.Error()
+	}
+	if !notification.Success {
+		notification.Event = notifyEventFailed
+	}
+	return notification
+}
+
+// NotifyPlan отправляет сводку плана во все webhooks и возвращает ошибки доставки.
+func (n *Notifier) NotifyPlan(plan *models.SyncPlan, results []models.SyncResult, runErr error) error {
+	if n == nil || len(n.targets) == 0 {
+		return nil
+	}
+	notification := BuildPlanNotification(plan, results, runErr, time.Now())
+	var deliveryErrors []error
+	for _, target := range n.targets {
+		if err := n.deliver(target, notification); err != nil {
+			deliveryErrors = append(deliveryErrors, fmt.Errorf("%s webhook %s: %w", target.Kind, redactWebhookURL(target.URL), err))
+		}
+	}
+	return errors.Join(deliveryErrors...)
+}
+
+func (n *Notifier) deliver(target WebhookTarget, notification PlanNotification) error {
+	payload, err := buildWebhookPayload(target, notification)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	backoff := n.backoff
+	for attempt := 0; attempt <= n.retries; attempt++ {
+		if attempt > 0 {
+			n.sleep(backoff)
+			backoff *= 2
+		}
+		retryable, err := n.post(target.URL, payload)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (n *Notifier) post(targetURL string, payload []byte) (bool, error) {
+	response, err := n.client.Post(targetURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 64*1024))
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+	return retryable, fmt.Errorf("unexpected status %s", response.Status)
+}
+
+func buildWebhookPayload(target WebhookTarget, notification PlanNotification) ([]byte, error) {
+	switch target.Kind {
+	case WebhookKindSlack:
+		return json.Marshal(map[string]string{"text": formatNotificationText(notification)})
+	case WebhookKindTelegram:
+		body := map[string]string{"text": formatNotificationText(notification)}
+		// Telegram ожидает chat_id; берем его из query-параметра webhook URL.
+		if parsed, err := url.Parse(target.URL); err == nil {
+			if chatID := parsed.Query().Get("chat_id"); chatID != "" {
+				body["chat_id"] = chatID
+			}
+		}
+		return json.Marshal(body)
+	default:
+		return json.Marshal(notification)
+	}
+}
+
+// secondsDuration переводит секунды уведомления в длительность для текста, с округлением до секунды.
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second)
+}
+
+func formatNotificationText(notification PlanNotification) string {
+	var builder strings.Builder
+	if notification.Success {
+		fmt.Fprintf(&builder, "dbsync: plan completed (%d/%d targets) in %s", notification.Completed, notification.Targets, secondsDuration(notification.DurationSeconds))
+	} else {
+		fmt.Fprintf(&builder, "dbsync: plan FAILED (%d/%d targets completed)", notification.Completed, notification.Targets)
+	}
+	for _, result := range notification.Results {
+		status := "OK"
+		if !result.Success {
+			status = "FAILED"
+		}
+		fmt.Fprintf(&builder, "\n%s %s: %s (dump %s, restore %s), source %s, dump %s, traffic %s",
+			status,
+			result.DatabaseName,
+			secondsDuration(result.DurationSeconds),
+			secondsDuration(result.DumpDurationSeconds),
+			secondsDuration(result.RestoreDurationSeconds),
+			FormatSize(result.LogicalSize),
+			FormatSize(result.DumpSizeOnDisk),
+			FormatSize(result.BytesIn+result.BytesOut),
+		)
+		if result.Error != "" {
+			fmt.Fprintf(&builder, "\n  error: %s", result.Error)
+		}
+	}
+	if notification.Error != "" && (len(notification.Results) == 0 || notification.Results[len(notification.Results)-1].Error != notification.Error) {
+		fmt.Fprintf(&builder, "\nerror: %s", notification.Error)
+	}
+	return builder.String()
+}
+
+// redactWebhookURL скрывает path и query, где обычно лежат токены webhook.
+func redactWebhookURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "<invalid url>"
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/…"
+}
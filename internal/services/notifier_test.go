@@ -0,0 +1,199 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+type webhookRecorder struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	statuses []int
+}
+
+func (r *webhookRecorder) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Errorf("read webhook body: %v", err)
+		}
+		if contentType := req.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("unexpected content type %q", contentType)
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.bodies = append(r.bodies, body)
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status = r.statuses[0]
+			r.statuses = r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}
+}
+
+func (r *webhookRecorder) calls() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func newTestNotifier(rawURLs string, retries int) (*Notifier, *[]time.Duration) {
+	notifier := NewNotifier(config.NotifyConfig{Webhooks: rawURLs, Retries: retries, Timeout: time.Second})
+	sleeps := &[]time.Duration{}
+	if notifier != nil {
+		notifier.sleep = func(delay time.Duration) { *sleeps = append(*sleeps, delay) }
+	}
+	return notifier, sleeps
+}
+
+func testPlanResults() (*models.SyncPlan, []models.SyncResult) {
+	plan := &models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "shop"}, {DatabaseName: "crm"}}}
+	results := []models.SyncResult{{
+		DatabaseName:    "shop",
+		Success:         true,
+		Duration:        90 * time.Second,
+		DumpDuration:    60 * time.Second,
+		RestoreDuration: 30 * time.Second,
+		LogicalSize:     10 * 1024 * 1024,
+		DumpSizeOnDisk:  2 * 1024 * 1024,
+		Traffic:         models.TrafficMetrics{BytesIn: 3 * 1024 * 1024, BytesOut: 1024},
+	}}
+	return plan, results
+}
+
+func TestNewNotifierWithoutWebhooksIsNil(t *testing.T) {
+	if notifier := NewNotifier(config.NotifyConfig{}); notifier != nil {
+		t.Fatalf("expected nil notifier, got %+v", notifier)
+	}
+	var notifier *Notifier
+	if err := notifier.NotifyPlan(nil, nil, nil); err != nil {
+		t.Fatalf("nil notifier should be a no-op, got %v", err)
+	}
+}
+
+func TestParseWebhookTargets(t *testing.T) {
+	targets := ParseWebhookTargets([]string{
+		"https://hooks.slack.com/services/T/B/X",
+		"https://api.telegram.org/bot123:abc/sendMessage?chat_id=42",
+		"https://example.com/hook",
+		"slack+https://chat.example.com/hook",
+	})
+	want := []WebhookKind{WebhookKindSlack, WebhookKindTelegram, WebhookKindJSON, WebhookKindSlack}
+	if len(targets) != len(want) {
+		t.Fatalf("expected %d targets, got %d", len(want), len(targets))
+	}
+	for index, target := range targets {
+		if target.Kind != want[index] {
+			t.Fatalf("target %d kind = %s, want %s", index, target.Kind, want[index])
+		}
+	}
+	if targets[3].URL != "https://chat.example.com/hook" {
+		t.Fatalf("expected kind prefix to be stripped, got %s", targets[3].URL)
+	}
+}
+
+func TestNotifierSendsGenericJSONPayload(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder.handler(t))
+	defer server.Close()
+
+	notifier, _ := newTestNotifier(server.URL, 0)
+	plan, results := testPlanResults()
+	if err := notifier.NotifyPlan(plan, results, nil); err != nil {
+		t.Fatalf("NotifyPlan() error = %v", err)
+	}
+	if recorder.calls() != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", recorder.calls())
+	}
+
+	var payload PlanNotification
+	if err := json.Unmarshal(recorder.bodies[0], &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.Event != notifyEventCompleted || !payload.Success || payload.Targets != 2 || payload.Completed != 1 || payload.DurationSeconds != 90 {
+		t.Fatalf("unexpected payload summary: %+v", payload)
+	}
+	if len(payload.Results) != 1 || payload.Results[0].DumpSizeOnDisk != 2*1024*1024 || payload.Results[0].BytesIn != 3*1024*1024 || payload.Results[0].DumpDurationSeconds != 60 {
+		t.Fatalf("unexpected payload results: %+v", payload.Results)
+	}
+}
+
+func TestNotifierSendsSlackAndTelegramText(t *testing.T) {
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder.handler(t))
+	defer server.Close()
+
+	notifier, _ := newTestNotifier("slack+"+server.URL+"/slack,telegram+"+server.URL+"/bot1/sendMessage?chat_id=42", 0)
+	plan, results := testPlanResults()
+	results = append(results, models.SyncResult{DatabaseName: "crm", Error: "restore failed: boom"})
+	if err := notifier.NotifyPlan(plan, results, errors.New("restore failed: boom")); err != nil {
+		t.Fatalf("NotifyPlan() error = %v", err)
+	}
+	if recorder.calls() != 2 {
+		t.Fatalf("expected 2 webhook calls, got %d", recorder.calls())
+	}
+
+	var slack map[string]string
+	if err := json.Unmarshal(recorder.bodies[0], &slack); err != nil {
+		t.Fatalf("decode slack payload: %v", err)
+	}
+	if !strings.Contains(slack["text"], "plan FAILED (1/2 targets completed)") || !strings.Contains(slack["text"], "FAILED crm") || !strings.Contains(slack["text"], "error: restore failed: boom") {
+		t.Fatalf("unexpected slack text: %q", slack["text"])
+	}
+
+	var telegram map[string]string
+	if err := json.Unmarshal(recorder.bodies[1], &telegram); err != nil {
+		t.Fatalf("decode telegram payload: %v", err)
+	}
+	if telegram["chat_id"] != "42" || !strings.Contains(telegram["text"], "OK shop: 1m30s") {
+		t.Fatalf("unexpected telegram payload: %+v", telegram)
+	}
+}
+
+func TestNotifierRetriesWithBackoff(t *testing.T) {
+	recorder := &webhookRecorder{statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK}}
+	server := httptest.NewServer(recorder.handler(t))
+	defer server.Close()
+
+	notifier, sleeps := newTestNotifier(server.URL, 3)
+	plan, results := testPlanResults()
+	if err := notifier.NotifyPlan(plan, results, nil); err != nil {
+		t.Fatalf("NotifyPlan() error = %v", err)
+	}
+	if recorder.calls() != 3 {
+		t.Fatalf("expected 3 webhook calls, got %d", recorder.calls())
+	}
+	if len(*sleeps) != 2 || (*sleeps)[0] != time.Second || (*sleeps)[1] != 2*time.Second {
+		t.Fatalf("unexpected backoff delays: %v", *sleeps)
+	}
+}
+
+func TestNotifierReportsDeliveryFailure(t *testing.T) {
+	recorder := &webhookRecorder{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(recorder.handler(t))
+	defer server.Close()
+
+	notifier, sleeps := newTestNotifier(server.URL+"/secret-token", 3)
+	plan, results := testPlanResults()
+	err := notifier.NotifyPlan(plan, results, nil)
+	if err == nil {
+		t.Fatal("expected delivery error")
+	}
+	if recorder.calls() != 1 || len(*sleeps) != 0 {
+		t.Fatalf("client errors must not be retried: calls=%d sleeps=%v", recorder.calls(), *sleeps)
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Fatalf("delivery error leaks webhook path: %v", err)
+	}
+}
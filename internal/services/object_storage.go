@@ -0,0 +1,350 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+const (
+	// storageMinPartSize — размер части multipart upload; архивы меньше уходят одним PUT.
+	storageMinPartSize = 64 << 20
+	storageMaxParts    = 10000
+	unsignedPayload    = "UNSIGNED-PAYLOAD"
+)
+
+// objectStorage — минимальный клиент S3 API с подписью SigV4: PUT/GET объектов, multipart upload
+// больших архивов и ListObjectsV2. Подходит для AWS S3, MinIO и других S3-совместимых хранилищ.
+type objectStorage struct {
+	config   config.StorageConfig
+	client   *http.Client
+	now      func() time.Time
+	partSize int64
+}
+
+func newObjectStorage(storage config.StorageConfig) (*objectStorage, error) {
+	if !storage.Enabled() {
+		return nil, fmt.Errorf("object storage is not configured: set DBSYNC_STORAGE_BUCKET")
+	}
+	return &objectStorage{config: storage, client: &http.Client{}, now: time.Now, partSize: storageMinPartSize}, nil
+}
+
+// objectKey собирает ключ объекта с учетом storage.prefix.
+func (o *objectStorage) objectKey(parts ...string) string {
+	if o.config.Prefix != "" {
+		parts = append([]string{o.config.Prefix}, parts...)
+	}
+	return strings.Join(parts, "/")
+}
+
+// Put загружает size байт из body в объект key; большие объекты идут через multipart upload.
+func (o *objectStorage) Put(ctx context.Context, key string, body io.ReaderAt, size int64, progress func(done int64, total int64)) error {
+	partSize := max(o.partSize, (size+storageMaxParts-1)/storageMaxParts)
+	if size <= partSize {
+		reader := &progressReader{reader: io.NewSectionReader(body, 0, size), total: size, progress: progress}
+		response, err := o.do(ctx, http.MethodPut, key, nil, reader, size)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		return nil
+	}
+	return o.putMultipart(ctx, key, body, size, partSize, progress)
+}
+
+func (o *objectStorage) putMultipart(ctx context.Context, key string, body io.ReaderAt, size int64, partSize int64, progress func(done int64, total int64)) error {
+	response, err := o.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, 0)
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = decodeStorageXML(response, &initiated)
+	if err != nil {
+		return fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	type completedPart struct {
+		PartNumber int    `xml:"PartNumber"`
+		ETag       string `xml:"ETag"`
+	}
+	var parts []completedPart
+	uploadErr := func() error {
+		var done int64
+		for offset, number := int64(0), 1; offset < size; offset, number = offset+partSize, number+1 {
+			length := min(partSize, size-offset)
+			partProgress := func(partDone int64, _ int64) {
+				if progress != nil {
+					progress(done+partDone, size)
+				}
+			}
+			reader := &progressReader{reader: io.NewSectionReader(body, offset, length), total: length, progress: partProgress}
+			query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {initiated.UploadID}}
+			response, err := o.do(ctx, http.MethodPut, key, query, reader, length)
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d: %w", number, err)
+			}
+			response.Body.Close()
+			parts = append(parts, completedPart{PartNumber: number, ETag: response.Header.Get("ETag")})
+			done += length
+		}
+
+		payload, err := xml.Marshal(struct {
+			XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+			Parts   []completedPart `xml:"Part"`
+		}{Parts: parts})
+		if err != nil {
+			return err
+		}
+		response, err := o.do(ctx, http.MethodPost, key, url.Values{"uploadId": {initiated.UploadID}}, bytes.NewReader(payload), int64(len(payload)))
+		if err != nil {
+			return fmt.Errorf("failed to complete multipart upload: %w", err)
+		}
+		// S3 может вернуть ошибку в теле ответа 200 на CompleteMultipartUpload.
+		var completed struct {
+			XMLName xml.Name
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if err := decodeStorageXML(response, &completed); err != nil {
+			return fmt.Errorf("failed to complete multipart upload: %w", err)
+		}
+		if completed.XMLName.Local == "Error" {
+			return fmt.Errorf("failed to complete multipart upload: %s: %s", completed.Code, completed.Message)
+		}
+		return nil
+	}()
+	if uploadErr != nil {
+		// Незавершенные части занимают место в бакете, поэтому upload явно отменяется.
+		if response, err := o.do(context.Background(), http.MethodDelete, key, url.Values{"uploadId": {initiated.UploadID}}, nil, 0); err == nil {
+			response.Body.Close()
+		}
+		return uploadErr
+	}
+	return nil
+}
+
+// Get скачивает объект key в writer.
+func (o *objectStorage) Get(ctx context.Context, key string, writer io.Writer, progress func(done int64, total int64)) (int64, error) {
+	response, err := o.do(ctx, http.MethodGet, key, nil, nil, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	reader := &progressReader{reader: response.Body, total: response.ContentLength, progress: progress}
+	written, err := io.Copy(writer, reader)
+	if err != nil {
+		return written, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	if response.ContentLength >= 0 && written != response.ContentLength {
+		return written, fmt.Errorf("failed to download %s: got %d of %d bytes", key, written, response.ContentLength)
+	}
+	return written, nil
+}
+
+// List возвращает все объекты с префиксом prefix.
+func (o *objectStorage) List(ctx context.Context, prefix string) ([]models.StorageObject, error) {
+	var objects []models.StorageObject
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		response, err := o.do(ctx, http.MethodGet, "", query, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+			Contents              []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+		}
+		if err := decodeStorageXML(response, &page); err != nil {
+			return nil, fmt.Errorf("failed to list bucket: %w", err)
+		}
+		for _, content := range page.Contents {
+			objects = append(objects, models.StorageObject{Key: content.Key, Size: content.Size, LastModified: content.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// do выполняет подписанный запрос к объекту key (пустой key — к самому бакету) и возвращает ошибку
+// с кодом S3 для ответов не 2xx.
+func (o *objectStorage) do(ctx context.Context, method string, key string, query url.Values, body io.Reader, size int64) (*http.Response, error) {
+	target, err := o.objectURL(key, query)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build storage request: %w", err)
+	}
+	if body != nil {
+		request.ContentLength = size
+	}
+	o.sign(request, target)
+
+	response, err := o.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("storage request failed: %w", err)
+	}
+	if response.StatusCode/100 != 2 {
+		defer response.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(response.Body, 64<<10))
+		var failure struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if xml.Unmarshal(data, &failure) == nil && failure.Code != "" {
+			return nil, fmt.Errorf("%s %s: %s: %s", method, o.describe(key), failure.Code, failure.Message)
+		}
+		return nil, fmt.Errorf("%s %s: %s", method, o.describe(key), response.Status)
+	}
+	return response, nil
+}
+
+func (o *objectStorage) describe(key string) string {
+	return "s3://" + o.config.Bucket + "/" + key
+}
+
+// objectURL строит адрес объекта в path-style (endpoint/bucket/key) или virtual-hosted (bucket.endpoint/key) виде.
+func (o *objectStorage) objectURL(key string, query url.Values) (*url.URL, error) {
+	endpoint, err := url.Parse(o.config.ResolvedEndpoint())
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage endpoint: %w", err)
+	}
+	objectPath := "/" + key
+	if o.config.PathStyle {
+		objectPath = "/" + o.config.Bucket + objectPath
+	} else {
+		endpoint.Host = o.config.Bucket + "." + endpoint.Host
+	}
+	endpoint.Path = strings.TrimRight(endpoint.Path, "/") + objectPath
+	endpoint.RawPath = escapeStoragePath(endpoint.Path)
+	endpoint.RawQuery = canonicalStorageQuery(query)
+	return endpoint, nil
+}
+
+// sign добавляет к запросу подпись AWS Signature Version 4; без ключей запрос уходит анонимно.
+func (o *objectStorage) sign(request *http.Request, target *url.URL) {
+	now := o.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	if o.config.AccessKey == "" {
+		return
+	}
+
+	date := now.Format("20060102")
+	scope := date + "/" + o.config.Region + "/s3/aws4_request"
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		target.EscapedPath(),
+		target.RawQuery,
+		"host:" + target.Host + "\nx-amz-content-sha256:" + unsignedPayload + "\nx-amz-date:" + amzDate + "\n",
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+o.config.SecretKey), date)
+	for _, part := range []string{o.config.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", o.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// escapeStoragePath кодирует путь по правилам SigV4: все, кроме unreserved символов и '/'.
+func escapeStoragePath(path string) string {
+	var builder strings.Builder
+	for _, b := range []byte(path) {
+		if isUnreservedByte(b) || b == '/' {
+			builder.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&builder, "%%%02X", b)
+	}
+	return builder.String()
+}
+
+// canonicalStorageQuery кодирует параметры запроса в отсортированном виде, который требует SigV4.
+func canonicalStorageQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, escapeStorageQuery(key)+"="+escapeStorageQuery(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+func escapeStorageQuery(value string) string {
+	return strings.ReplaceAll(escapeStoragePath(value), "/", "%2F")
+}
+
+func isUnreservedByte(b byte) bool {
+	return (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~'
+}
+
+func decodeStorageXML(response *http.Response, value any) error {
+	defer response.Body.Close()
+	return xml.NewDecoder(response.Body).Decode(value)
+}
+
+// progressReader сообщает в progress, сколько байт прочитано из reader.
+type progressReader struct {
+	reader   io.Reader
+	done     int64
+	total    int64
+	progress func(done int64, total int64)
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.done += int64(n)
+	if n > 0 && r.progress != nil {
+		r.progress(r.done, r.total)
+	}
+	return n, err
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// fakeS3 — in-process S3 API для тестов: объекты, multipart upload и постраничный ListObjectsV2.
+type fakeS3 struct {
+	t        *testing.T
+	mu       sync.Mutex
+	bucket   string
+	objects  map[string][]byte
+	modified map[string]time.Time
+	uploads  map[string]map[int][]byte
+	pageSize int
+	paths    []string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, config.StorageConfig) {
+	t.Helper()
+	fake := &fakeS3{t: t, bucket: "dumps", objects: make(map[string][]byte), modified: make(map[string]time.Time), uploads: make(map[string]map[int][]byte), pageSize: 2}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, config.StorageConfig{Endpoint: server.URL, Region: "us-east-1", Bucket: "dumps", Prefix: "nightly", AccessKey: "AKID", SecretKey: "secret", PathStyle: true}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, r.URL.EscapedPath())
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") || r.Header.Get("X-Amz-Date") == "" {
+		writeFakeS3Error(w, http.StatusForbidden, "AccessDenied", "missing signature")
+		return
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		writeFakeS3Error(w, http.StatusNotFound, "NoSuchBucket", "unknown bucket")
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		f.list(w, query)
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		uploadID := fmt.Sprintf("upload-%d", len(f.uploads)+1)
+		f.uploads[uploadID] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := io.ReadAll(r.Body)
+		f.uploads[query.Get("uploadId")][number] = data
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var complete struct {
+			Parts []struct {
+				PartNumber int    `xml:"PartNumber"`
+				ETag       string `xml:"ETag"`
+			} `xml:"Part"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&complete); err != nil {
+			writeFakeS3Error(w, http.StatusBadRequest, "MalformedXML", err.Error())
+			return
+		}
+		var object []byte
+		for _, part := range complete.Parts {
+			if part.ETag != fmt.Sprintf(`"etag-%d"`, part.PartNumber) {
+				writeFakeS3Error(w, http.StatusBadRequest, "InvalidPart", part.ETag)
+				return
+			}
+			object = append(object, f.uploads[query.Get("uploadId")][part.PartNumber]...)
+		}
+		delete(f.uploads, query.Get("uploadId"))
+		f.store(key, object)
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.store(key, data)
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			writeFakeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data)
+	default:
+		writeFakeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+func (f *fakeS3) store(key string, data []byte) {
+	f.objects[key] = data
+	// Разные секунды LastModified делают порядок архивов в тестах детерминированным.
+	f.modified[key] = time.Date(2024, 5, 1, 10, 0, len(f.modified), 0, time.UTC)
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, query map[string][]string) {
+	prefix := ""
+	if values := query["prefix"]; len(values) > 0 {
+		prefix = values[0]
+	}
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	start := 0
+	if values := query["continuation-token"]; len(values) > 0 {
+		start, _ = strconv.Atoi(values[0])
+	}
+	end := min(start+f.pageSize, len(keys))
+	var body strings.Builder
+	body.WriteString("<ListBucketResult>")
+	for _, key := range keys[start:end] {
+		fmt.Fprintf(&body, "<Contents><Key>%s</Key><Size>%d</Size><LastModified>%s</LastModified></Contents>", key, len(f.objects[key]), f.modified[key].Format(time.RFC3339))
+	}
+	if end < len(keys) {
+		fmt.Fprintf(&body, "<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>", end)
+	}
+	body.WriteString("</ListBucketResult>")
+	fmt.Fprint(w, body.String())
+}
+
+func writeFakeS3Error(w http.ResponseWriter, status int, code string, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
+
+func TestObjectStoragePutGetList(t *testing.T) {
+	fake, storageConfig := newFakeS3(t)
+	storage, err := newObjectStorage(storageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{"nightly/shop/a b.dbsync", "nightly/shop/b.dbsync", "nightly/shop/c.dbsync", "nightly/blog/a.dbsync"} {
+		if err := storage.Put(ctx, key, strings.NewReader(key), int64(len(key)), nil); err != nil {
+			t.Fatalf("Put(%s) failed: %v", key, err)
+		}
+	}
+	if got := fake.paths[0]; got != "/dumps/nightly/shop/a%20b.dbsync" {
+		t.Fatalf("unexpected escaped object path %q", got)
+	}
+
+	objects, err := storage.List(ctx, "nightly/shop/")
+	if err != nil || len(objects) != 3 {
+		t.Fatalf("List across pages returned %+v, %v", objects, err)
+	}
+
+	var buffer bytes.Buffer
+	if _, err := storage.Get(ctx, "nightly/shop/b.dbsync", &buffer, nil); err != nil || buffer.String() != "nightly/shop/b.dbsync" {
+		t.Fatalf("Get returned %q, %v", buffer.String(), err)
+	}
+	if _, err := storage.Get(ctx, "nightly/shop/missing.dbsync", io.Discard, nil); err == nil || !strings.Contains(err.Error(), "NoSuchKey") {
+		t.Fatalf("expected NoSuchKey, got %v", err)
+	}
+}
+
+func TestObjectStorageMultipartUpload(t *testing.T) {
+	fake, storageConfig := newFakeS3(t)
+	storage, err := newObjectStorage(storageConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storage.partSize = 5
+	payload := []byte("0123456789abcdefghijklm")
+
+	var lastDone int64
+	if err := storage.Put(context.Background(), "nightly/big.dbsync", bytes.NewReader(payload), int64(len(payload)), func(done int64, _ int64) { lastDone = done }); err != nil {
+		t.Fatalf("multipart Put failed: %v", err)
+	}
+	if !bytes.Equal(fake.objects["nightly/big.dbsync"], payload) || lastDone != int64(len(payload)) {
+		t.Fatalf("multipart object %q, progress %d", fake.objects["nightly/big.dbsync"], lastDone)
+	}
+	if len(fake.uploads) != 0 {
+		t.Fatalf("multipart upload was not completed: %v", fake.uploads)
+	}
+}
+
+func TestUploadAndListBucketArchives(t *testing.T) {
+	_, storageConfig := newFakeS3(t)
+	service := NewMySQLShellService(&config.Config{Storage: storageConfig}, nil)
+	service.SetQuiet(true)
+
+	archivePath := filepath.Join(t.TempDir(), "shop.dbsync")
+	if err := os.WriteFile(archivePath, []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, createdAt := range []time.Time{time.Date(2024, 5, 1, 3, 0, 0, 0, time.UTC), time.Date(2024, 5, 2, 3, 0, 0, 0, time.UTC)} {
+		if _, err := service.UploadArchive(archivePath, &models.ArchiveManifest{DatabaseName: "shop", CreatedAt: createdAt}, nil); err != nil {
+			t.Fatalf("UploadArchive failed: %v", err)
+		}
+	}
+	if _, err := service.UploadArchive(archivePath, &models.ArchiveManifest{DatabaseName: "shop_logs", CreatedAt: time.Now()}, nil); err != nil {
+		t.Fatalf("UploadArchive failed: %v", err)
+	}
+
+	archives, err := service.ListBucketArchives("shop")
+	if err != nil {
+		t.Fatalf("ListBucketArchives failed: %v", err)
+	}
+	if len(archives) != 2 || archives[0].Key != "nightly/shop/shop_20240502T030000.dbsync" {
+		t.Fatalf("unexpected archives %+v", archives)
+	}
+
+	if _, err := NewMySQLShellService(&config.Config{}, nil).ListBucketArchives("shop"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Fatalf("expected not configured error, got %v", err)
+	}
+}
+
+func TestCanonicalStorageQuery(t *testing.T) {
+	got := canonicalStorageQuery(map[string][]string{"prefix": {"nightly/shop db/"}, "list-type": {"2"}, "uploads": {""}})
+	if want := "list-type=2&prefix=nightly%2Fshop%20db%2F&uploads="; got != want {
+		t.Fatalf("canonicalStorageQuery() = %q, want %q", got, want)
+	}
+}
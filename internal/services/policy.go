@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+// ErrPolicyViolation возвращается, когда синхронизация, import, pull, restore-backup или follow
+// нарушают правила safety и не были запущены.
+var ErrPolicyViolation = errors.New("sync plan violates safety policy")
+
+// SetConfirmedDatabases задает защищенные БД, имя которых пользователь ввел для import, pull,
+// restore-backup и follow. ExecutePlan берет подтверждения из плана.
+func (s *MySQLShellService) SetConfirmedDatabases(names []string) {
+	s.confirmed = append([]string(nil), names...)
+}
+
+// withConfirmedDatabases возвращает копию сервиса с подтверждениями плана.
+func (s *MySQLShellService) withConfirmedDatabases(names []string) *MySQLShellService {
+	service := *s
+	service.confirmed = append([]string(nil), names...)
+	return &service
+}
+
+// checkDestinationPolicy вызывается перед каждым DROP DATABASE в приемнике, через который проходят
+// синхронизация, import, pull, restore-backup и follow: защищенная БД должна быть подтверждена,
+// а приемник — loopback или хостом из safety.allowed_hosts.
+func (s *MySQLShellService) checkDestinationPolicy(databaseName string) error {
+	if s.config.Safety.IsProtected(databaseName) && !slices.Contains(s.confirmed, databaseName) {
+		return fmt.Errorf("%w: database %q is protected; confirm it by typing its name", ErrPolicyViolation, databaseName)
+	}
+	if destination := s.destinationConfig(); !s.config.Safety.DestinationAllowed(destination) {
+		return fmt.Errorf("%w: destination %s is neither loopback nor listed in DBSYNC_SAFETY_ALLOWED_HOSTS", ErrPolicyViolation, destination.Address())
+	}
+	return nil
+}
+
+// enforcePolicy проверяет все цели плана до первого DROP в приемнике: защищенные БД должны быть
+// подтверждены вводом имени, приемник — loopback или хост из safety.allowed_hosts, а source и
+// destination — разными серверами по @@hostname и server_uuid. При любом нарушении план не
+// выполняется целиком, и каждая цель получает результат с FailureTypePolicy.
+func (s *MySQLShellService) enforcePolicy(plan *models.SyncPlan, observer models.ProgressObserver) ([]models.SyncResult, error) {
+	confirmed := make(map[string]bool, len(plan.ConfirmedDatabases))
+	for _, name := range plan.ConfirmedDatabases {
+		confirmed[name] = true
+	}
+
+	routeChecks := make(map[string][]models.PolicyViolation)
+	violations := make([][]models.PolicyViolation, len(plan.Targets))
+	var messages []string
+	for index, target := range plan.Targets {
+		if s.config.Safety.IsProtected(target.DatabaseName) && !confirmed[target.DatabaseName] {
+			violations[index] = append(violations[index], models.PolicyViolation{
+				Rule:    models.PolicyRuleProtectedDatabase,
+				Message: fmt.Sprintf("database %q is protected; confirm it by typing its name", target.DatabaseName),
+			})
+		}
+
+		source, destination := targetRoute(target)
+		key := source + "\x00" + destination
+		checks, ok := routeChecks[key]
+		if !ok {
+			checks = s.routeViolations(target)
+			routeChecks[key] = checks
+		}
+		violations[index] = append(violations[index], checks...)
+		for _, violation := range violations[index] {
+			messages = append(messages, target.DatabaseName+": "+violation.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	results := make([]models.SyncResult, 0, len(plan.Targets))
+	for index, target := range plan.Targets {
+		message := "not started: another target of the plan violates safety policy"
+		if len(violations[index]) > 0 {
+			parts := make([]string, 0, len(violations[index]))
+			for _, violation := range violations[index] {
+				parts = append(parts, violation.Message)
+			}
+			message = strings.Join(parts, "; ")
+			if observer != nil {
+				observer(models.ProgressSnapshot{Phase: models.SyncPhaseFailed, DatabaseName: target.DatabaseName, Message: message, Timestamp: now})
+			}
+		}
+		results = append(results, models.SyncResult{
+			DatabaseName:     target.DatabaseName,
+			Error:            message,
+			FailureType:      models.FailureTypePolicy,
+			PolicyViolations: violations[index],
+			SelectedTables:   append([]string(nil), target.SelectedTables...),
+			Source:           target.Source,
+			Destination:      target.Destination,
+			StartTime:        now,
+			EndTime:          now,
+		})
+	}
+	return results, fmt.Errorf("%w: %s", ErrPolicyViolation, strings.Join(messages, "; "))
+}
+
+// routeViolations проверяет приемник и идентичность серверов маршрута цели. Ошибки разрешения
+// маршрута не считаются нарушением: их сообщит выполнение пакета этой цели.
+func (s *MySQLShellService) routeViolations(target models.SyncTarget) []models.PolicyViolation {
+	service, cleanup, err := s.routeService(target)
+	if err != nil {
+		return nil
+	}
+	defer cleanup()
+
+	var violations []models.PolicyViolation
+	destination := service.destinationConfig()
+	if !s.config.Safety.DestinationAllowed(destination) {
+		violations = append(violations, models.PolicyViolation{
+			Rule:    models.PolicyRuleDestinationHost,
+			Message: fmt.Sprintf("destination %s is neither loopback nor listed in DBSYNC_SAFETY_ALLOWED_HOSTS", destination.Address()),
+		})
+	}
+
+	// Один сервер может быть доступен по разным адресам (DNS, proxy, проброс портов),
+	// поэтому сравниваем то, что сообщает сам MySQL.
+	sourceIdentity, err := service.dbService.ServerIdentity(true)
+	if err == nil {
+		var destinationIdentity *models.ServerIdentity
+		destinationIdentity, err = service.dbService.ServerIdentity(false)
+		if err == nil && sameIdentity(sourceIdentity, destinationIdentity) {
+			violations = append(violations, models.PolicyViolation{
+				Rule:    models.PolicyRuleSameServer,
+				Message: fmt.Sprintf("source and destination are the same server (hostname %q, server_uuid %q)", destinationIdentity.Hostname, destinationIdentity.ServerUUID),
+			})
+		}
+	}
+	if err != nil {
+		violations = append(violations, models.PolicyViolation{
+			Rule:    models.PolicyRuleSameServer,
+			Message: fmt.Sprintf("cannot verify that source and destination are different servers: %v", err),
+		})
+	}
+	return violations
+}
+
+func sameIdentity(a *models.ServerIdentity, b *models.ServerIdentity) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a.ServerUUID != "" && strings.EqualFold(a.ServerUUID, b.ServerUUID) {
+		return true
+	}
+	return a.Hostname != "" && strings.EqualFold(a.Hostname, b.Hostname)
+}
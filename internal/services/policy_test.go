@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func TestEnforcePolicyRefusesWholePlan(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Safety.ProtectedDatabases = "billing"
+	service := newTestService(cfg, &mocks.MockDatabaseService{})
+	plan := &models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "shop"}, {DatabaseName: "billing"}}}
+
+	var failed []string
+	results, err := service.ExecutePlan(plan, models.RuntimeOptions{}, func(snapshot models.ProgressSnapshot) {
+		if snapshot.Phase == models.SyncPhaseFailed {
+			failed = append(failed, snapshot.DatabaseName)
+		}
+	})
+	if !errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), `database "billing" is protected`) {
+		t.Fatalf("expected protected database violation, got %v", err)
+	}
+	if len(results) != 2 || results[0].FailureType != models.FailureTypePolicy || results[1].FailureType != models.FailureTypePolicy {
+		t.Fatalf("every target must be refused by policy, got %+v", results)
+	}
+	if len(results[0].PolicyViolations) != 0 || !strings.Contains(results[0].Error, "not started") {
+		t.Fatalf("unexpected result for an unprotected target: %+v", results[0])
+	}
+	if len(results[1].PolicyViolations) != 1 || results[1].PolicyViolations[0].Rule != models.PolicyRuleProtectedDatabase {
+		t.Fatalf("unexpected violations %+v", results[1].PolicyViolations)
+	}
+	if len(failed) != 1 || failed[0] != "billing" {
+		t.Fatalf("expected a failed snapshot for billing only, got %v", failed)
+	}
+
+	plan.ConfirmedDatabases = []string{"billing"}
+	if results, err := service.enforcePolicy(plan, nil); err != nil || results != nil {
+		t.Fatalf("confirmed plan must pass the policy, got %+v, %v", results, err)
+	}
+}
+
+func TestEnforcePolicyChecksDestination(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(service *MySQLShellService, dbService *mocks.MockDatabaseService)
+		rule      models.PolicyRule
+		want      string
+	}{
+		{
+			name: "host not allowed",
+			configure: func(service *MySQLShellService, _ *mocks.MockDatabaseService) {
+				service.config.Local.Host = "10.0.0.5"
+			},
+			rule: models.PolicyRuleDestinationHost,
+			want: "destination 10.0.0.5:3306 is neither loopback nor listed in DBSYNC_SAFETY_ALLOWED_HOSTS",
+		},
+		{
+			name: "same server uuid",
+			configure: func(_ *MySQLShellService, dbService *mocks.MockDatabaseService) {
+				dbService.RemoteIdentity = &models.ServerIdentity{Hostname: "db-1", ServerUUID: "3e11fa47-71ca-11e1-9e33-c80aa9429562"}
+				dbService.LocalIdentity = &models.ServerIdentity{Hostname: "db-1.local", ServerUUID: "3E11FA47-71CA-11E1-9E33-C80AA9429562"}
+			},
+			rule: models.PolicyRuleSameServer,
+			want: "source and destination are the same server",
+		},
+		{
+			name: "same hostname",
+			configure: func(_ *MySQLShellService, dbService *mocks.MockDatabaseService) {
+				dbService.RemoteIdentity = &models.ServerIdentity{Hostname: "db-1"}
+				dbService.LocalIdentity = &models.ServerIdentity{Hostname: "db-1"}
+			},
+			rule: models.PolicyRuleSameServer,
+			want: `hostname "db-1"`,
+		},
+		{
+			name: "identity unavailable",
+			configure: func(_ *MySQLShellService, dbService *mocks.MockDatabaseService) {
+				dbService.ServerIdentityError = errors.New("access denied")
+			},
+			rule: models.PolicyRuleSameServer,
+			want: "cannot verify that source and destination are different servers: access denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbService := &mocks.MockDatabaseService{}
+			cfg := testConfig(t)
+			cfg.Safety.ProtectedDatabases = "billing"
+			service := newTestService(cfg, dbService)
+			tt.configure(service, dbService)
+
+			results, err := service.enforcePolicy(&models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "shop"}}}, nil)
+			if !errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if len(results) != 1 || len(results[0].PolicyViolations) != 1 || results[0].PolicyViolations[0].Rule != tt.rule {
+				t.Fatalf("unexpected results %+v", results)
+			}
+		})
+	}
+
+	cfg := testConfig(t)
+	cfg.Local.Host = "10.0.0.5"
+	cfg.Safety.AllowedHosts = "10.0.0.0/8"
+	service := newTestService(cfg, &mocks.MockDatabaseService{})
+	if results, err := service.enforcePolicy(&models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "shop"}}}, nil); err != nil || results != nil {
+		t.Fatalf("allow-listed destination must pass, got %+v, %v", results, err)
+	}
+}
+
+func TestRestorePathsRefuseProtectedDatabase(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{}
+	cfg := testConfig(t)
+	cfg.Safety.ProtectedDatabases = "billing"
+	service := newTestService(cfg, dbService)
+
+	dumpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dumpDir, dumpEngineMarker), []byte(config.DumpEngineNative+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(t.TempDir(), "shop.dbsync")
+	if err := writeDumpArchive(dumpDir, archivePath, &models.ArchiveManifest{FormatVersion: archiveFormatVersion, DatabaseName: "shop", DumpEngine: config.DumpEngineNative}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ImportArchive(archivePath, "billing", nil); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("import into a protected database must be refused, got %v", err)
+	}
+	if _, err := service.ImportArchive(archivePath, "mysql", nil); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("import into a built-in protected database must be refused, got %v", err)
+	}
+	if dbService.DatabaseExistsCalled {
+		t.Fatal("refused import must not touch the local database")
+	}
+
+	service.config.Storage = config.StorageConfig{Bucket: "dumps", Endpoint: "http://127.0.0.1:1"}
+	if _, err := service.PullFromBucket("shop", "dumps/shop.dbsync", "billing", nil); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("pull into a protected database must be refused before download, got %v", err)
+	}
+
+	target := models.SyncTarget{DatabaseName: "billing", ReplaceEntireDatabase: true}
+	service.config.Follow = config.FollowConfig{Dir: t.TempDir()}
+	if err := service.Follow(context.Background(), target, true, nil); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("follow of a protected database must be refused, got %v", err)
+	}
+	service.SetConfirmedDatabases([]string{"billing"})
+	if err := service.Follow(context.Background(), target, true, nil); errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), "no saved follow position") {
+		t.Fatalf("confirmed follow must pass the policy, got %v", err)
+	}
+
+	service.config.Local.Host = "10.0.0.5"
+	if err := service.prepareLocalDatabase("shop"); !errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), "DBSYNC_SAFETY_ALLOWED_HOSTS") {
+		t.Fatalf("drop on a destination that is not allowed must be refused, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// Коэффициенты сжатия дампа до первой синхронизации БД: сжатые дампы mysqlsh, mydumper и native
+// обычно в 3-5 раз меньше данных, поэтому 0.5 оставляет запас; mysqldump пишет SQL без сжатия.
+const (
+	defaultCompressedDumpRatio = 0.5
+	defaultPlainDumpRatio      = 1.0
+)
+
+// dumpHistoryFile хранит коэффициенты сжатия прошлых дампов одной базы по движкам.
+type dumpHistoryFile struct {
+	DatabaseName      string             `json:"database_name"`
+	Profile           string             `json:"profile"`
+	CompressionRatios map[string]float64 `json:"compression_ratios"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+func (s *MySQLShellService) dumpHistoryPath(databaseName string) string {
+	return filepath.Join(s.config.Preflight.ResolvedDir(), s.syncProfile(), databaseName+".json")
+}
+
+func loadDumpHistory(path string) (dumpHistoryFile, error) {
+	history := dumpHistoryFile{CompressionRatios: map[string]float64{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return history, fmt.Errorf("failed to read dump history: %w", err)
+	}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return history, fmt.Errorf("failed to parse dump history %s: %w", path, err)
+	}
+	if history.CompressionRatios == nil {
+		history.CompressionRatios = map[string]float64{}
+	}
+	return history, nil
+}
+
+// compressionRatio возвращает отношение размера дампа к объему данных по прошлому дампу БД
+// текущим движком, а без истории — консервативную оценку.
+func (s *MySQLShellService) compressionRatio(databaseName string) float64 {
+	engine := s.dumpEngineName()
+	if history, err := loadDumpHistory(s.dumpHistoryPath(databaseName)); err == nil {
+		if ratio := history.CompressionRatios[engine]; ratio > 0 {
+			return ratio
+		}
+	}
+	if s.config.Dump.Compress && engine != config.DumpEngineMysqldump {
+		return defaultCompressedDumpRatio
+	}
+	return defaultPlainDumpRatio
+}
+
+// storeCompressionRatio запоминает коэффициент сжатия дампа для оценки следующих синхронизаций.
+func (s *MySQLShellService) storeCompressionRatio(databaseName string, ratio float64) error {
+	path := s.dumpHistoryPath(databaseName)
+	history, err := loadDumpHistory(path)
+	if err != nil {
+		return err
+	}
+	history.DatabaseName = databaseName
+	history.Profile = s.syncProfile()
+	history.CompressionRatios[s.dumpEngineName()] = ratio
+	history.UpdatedAt = time.Now()
+	if err := writeJSONFileAtomic(path, history); err != nil {
+		return fmt.Errorf("failed to write dump history: %w", err)
+	}
+	return nil
+}
+
+// estimateDiskUsage оценивает размер дампа цели на диске и объем, который займет загрузка в datadir.
+// Если БД пересоздается целиком, место под ее текущую локальную копию освобождается перед загрузкой.
+func (s *MySQLShellService) estimateDiskUsage(target models.SyncTarget, streamCopy bool) (int64, int64, error) {
+	stats, err := s.collectTargetStats(target)
+	if err != nil {
+		return 0, 0, err
+	}
+	var dumpBytes int64
+	if !streamCopy {
+		dumpBytes = int64(float64(stats.logicalSize) * s.compressionRatio(target.DatabaseName))
+	}
+	restoreBytes := stats.logicalSize + stats.indexSize
+	if len(target.EffectiveTables()) == 0 && len(target.SkipTables) == 0 {
+		if exists, err := s.dbService.DatabaseExists(target.DatabaseName, false); err == nil && exists {
+			if local, err := s.dbService.GetDatabaseInfo(target.DatabaseName, false); err == nil {
+				restoreBytes = max(restoreBytes-local.DataSize-local.IndexSize, 0)
+			}
+		}
+	}
+	return dumpBytes, restoreBytes, nil
+}
+
+// localDataDir возвращает @@datadir приемника, если он на этой машине и каталог доступен.
+func (s *MySQLShellService) localDataDir() string {
+	if !s.destinationConfig().IsLocalhost() {
+		return ""
+	}
+	dataDir, err := s.dbService.DataDir(false)
+	if err != nil || dataDir == "" {
+		return ""
+	}
+	if _, err := os.Stat(dataDir); err != nil {
+		return ""
+	}
+	return dataDir
+}
+
+// diskDemand — сколько места одновременно нужно под дампы и сколько добавится в каждый datadir.
+type diskDemand struct {
+	dumpBytes    int64
+	restoreBytes map[string]int64
+	totalRestore int64
+}
+
+// addTargets учитывает цели, дампы которых лежат на диске одновременно (одна цель или пакет).
+func (s *MySQLShellService) addTargets(demand *diskDemand, targets []models.SyncTarget, streamCopy bool) error {
+	var dumpBytes int64
+	dataDir := s.localDataDir()
+	for _, target := range targets {
+		dump, restore, err := s.estimateDiskUsage(target, streamCopy)
+		if err != nil {
+			return fmt.Errorf("%s: %w", target.DatabaseName, err)
+		}
+		dumpBytes += dump
+		demand.totalRestore += restore
+		if dataDir != "" {
+			demand.restoreBytes[dataDir] += restore
+		}
+	}
+	demand.dumpBytes = max(demand.dumpBytes, dumpBytes)
+	return nil
+}
+
+// evaluateDisk сравнивает потребность со свободным местом. Если datadir на одном разделе
+// с директорией дампов, дамп занимает место до конца загрузки и учитывается в обеих проверках.
+func (s *MySQLShellService) evaluateDisk(demand diskDemand) *models.DiskPreflight {
+	preflight := &models.DiskPreflight{DumpBytes: demand.dumpBytes, RestoreBytes: demand.totalRestore}
+	dumpDir := existingParent(s.config.Dump.ResolvedWorkDir())
+	dumpFree, dumpDevice, dumpErr := diskFree(dumpDir)
+	if dumpErr == nil && demand.dumpBytes > 0 {
+		preflight.Checks = append(preflight.Checks, s.diskCheck("dump", dumpDir, demand.dumpBytes, dumpFree))
+	}
+	for dataDir, restoreBytes := range demand.restoreBytes {
+		free, device, err := diskFree(dataDir)
+		if err != nil || restoreBytes == 0 {
+			continue
+		}
+		if dumpErr == nil && device == dumpDevice {
+			restoreBytes += demand.dumpBytes
+		}
+		preflight.Checks = append(preflight.Checks, s.diskCheck("datadir", dataDir, restoreBytes, free))
+	}
+	for _, check := range preflight.Checks {
+		if check.Status == models.DiskSpaceInsufficient && s.config.Preflight.Disk == config.PreflightDiskBlock {
+			preflight.Blocking = true
+		}
+	}
+	return preflight
+}
+
+// existingParent возвращает ближайшую существующую директорию пути: рабочая директория
+// дампов создается только перед первым дампом.
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+func (s *MySQLShellService) diskCheck(purpose string, path string, required int64, free int64) models.DiskSpaceCheck {
+	check := models.DiskSpaceCheck{Purpose: purpose, Path: path, RequiredBytes: required, FreeBytes: free, Status: models.DiskSpaceOK}
+	switch {
+	case free < required:
+		check.Status = models.DiskSpaceInsufficient
+	case free < required+required*int64(s.config.Preflight.HeadroomPercent)/100:
+		check.Status = models.DiskSpaceLow
+	}
+	return check
+}
+
+// CheckDiskSpace оценивает место под дампы и загрузку всего плана: цели выполняются по очереди
+// и дамп удаляется после загрузки, поэтому для директории дампов берется самый большой дамп
+// (или пакет), а datadir должен вместить все загружаемые БД. При preflight.disk=off возвращает nil.
+func (s *MySQLShellService) CheckDiskSpace(plan *models.SyncPlan) (*models.DiskPreflight, error) {
+	if plan == nil || s.config.Preflight.Disk == config.PreflightDiskOff {
+		return nil, nil
+	}
+	demand := diskDemand{restoreBytes: map[string]int64{}}
+	for _, batch := range s.planBatches(plan.Targets) {
+		service, cleanup, err := s.routeService(batch[0])
+		if err != nil {
+			return nil, err
+		}
+		err = service.addTargets(&demand, batch, s.config.Dump.Copy)
+		cleanup()
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate disk usage: %w", err)
+		}
+	}
+	return s.evaluateDisk(demand), nil
+}
+
+// checkTargetsDiskSpace проверяет место перед дампом целей, которые будут на диске одновременно:
+// при нехватке возвращает ошибку в режиме block и печатает предупреждение в режиме warn.
+// Ошибка оценки не останавливает синхронизацию.
+func (s *MySQLShellService) checkTargetsDiskSpace(targets []models.SyncTarget, streamCopy bool) error {
+	if s.config.Preflight.Disk == config.PreflightDiskOff {
+		return nil
+	}
+	demand := diskDemand{restoreBytes: map[string]int64{}}
+	if err := s.addTargets(&demand, targets, streamCopy); err != nil {
+		s.printStatusf("⚠️  Disk space preflight skipped: %v\n", err)
+		return nil
+	}
+	preflight := s.evaluateDisk(demand)
+	problems := diskSpaceProblems(preflight)
+	if len(problems) == 0 {
+		return nil
+	}
+	if preflight.Blocking {
+		return fmt.Errorf("not enough disk space (set DBSYNC_PREFLIGHT_DISK=warn to continue anyway): %s", strings.Join(problems, "; "))
+	}
+	s.printStatusf("⚠️  Low disk space: %s\n", strings.Join(problems, "; "))
+	return nil
+}
+
+// diskSpaceProblems описывает проверки, где места не хватает или запас меньше preflight.headroom_percent.
+func diskSpaceProblems(preflight *models.DiskPreflight) []string {
+	if preflight == nil {
+		return nil
+	}
+	var problems []string
+	for _, check := range preflight.Checks {
+		if check.Status == models.DiskSpaceOK {
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("%s %s needs ~%s, %s free", check.Purpose, check.Path, FormatSize(check.RequiredBytes), FormatSize(check.FreeBytes)))
+	}
+	return problems
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func TestCompressionRatioHistory(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Dump = config.DumpConfig{Engine: config.DumpEngineMySQLShell, Compress: true}
+	cfg.Preflight = config.PreflightConfig{Disk: config.PreflightDiskBlock, HeadroomPercent: 10, Dir: t.TempDir()}
+	service := newTestService(cfg, &mocks.MockDatabaseService{})
+	if ratio := service.compressionRatio("shop"); ratio != defaultCompressedDumpRatio {
+		t.Fatalf("expected default compressed ratio, got %v", ratio)
+	}
+	if err := service.storeCompressionRatio("shop", 0.2); err != nil {
+		t.Fatalf("storeCompressionRatio() error = %v", err)
+	}
+	if ratio := service.compressionRatio("shop"); ratio != 0.2 {
+		t.Fatalf("expected ratio from history, got %v", ratio)
+	}
+
+	// Коэффициент хранится отдельно для каждого движка.
+	service.config.Dump.Engine = config.DumpEngineMysqldump
+	if ratio := service.compressionRatio("shop"); ratio != defaultPlainDumpRatio {
+		t.Fatalf("expected plain ratio for mysqldump without history, got %v", ratio)
+	}
+}
+
+func TestCheckTargetsDiskSpace(t *testing.T) {
+	huge := &models.Database{Name: "shop", DataSize: 1 << 60, IndexSize: 1 << 58, Tables: 3}
+	targets := []models.SyncTarget{{DatabaseName: "shop", ReplaceEntireDatabase: true}}
+
+	dbService := &mocks.MockDatabaseService{DatabaseInfo: huge}
+	cfg := testConfig(t)
+	cfg.Dump = config.DumpConfig{Engine: config.DumpEngineMySQLShell, Compress: true}
+	cfg.Preflight = config.PreflightConfig{Disk: config.PreflightDiskBlock, HeadroomPercent: 10, Dir: t.TempDir()}
+	service := newTestService(cfg, dbService)
+	err := service.checkTargetsDiskSpace(targets, false)
+	if err == nil || !strings.Contains(err.Error(), "not enough disk space") || !strings.Contains(err.Error(), "dump ") {
+		t.Fatalf("expected blocking dump directory error, got %v", err)
+	}
+
+	cfg.Preflight.Disk = config.PreflightDiskWarn
+	if err := service.checkTargetsDiskSpace(targets, false); err != nil {
+		t.Fatalf("warn mode must not block, got %v", err)
+	}
+
+	cfg.Preflight.Disk = config.PreflightDiskBlock
+	dbService.DatabaseInfo = &models.Database{Name: "shop", DataSize: 1024, IndexSize: 1024}
+	if err := service.checkTargetsDiskSpace(targets, false); err != nil {
+		t.Fatalf("small dump must fit, got %v", err)
+	}
+}
+
+func TestCheckDiskSpaceIncludesLocalDataDir(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{
+		DatabaseInfo:  &models.Database{Name: "shop", DataSize: 1 << 60, IndexSize: 1 << 58},
+		DataDirResult: t.TempDir(),
+	}
+	cfg := testConfig(t)
+	cfg.Dump = config.DumpConfig{Engine: config.DumpEngineMySQLShell, Compress: true}
+	cfg.Preflight = config.PreflightConfig{Disk: config.PreflightDiskBlock, HeadroomPercent: 10, Dir: t.TempDir()}
+	service := newTestService(cfg, dbService)
+
+	// При потоковом копировании дамп не пишется на диск, но загрузка все равно не поместится в datadir.
+	service.config.Dump.Copy = true
+	preflight, err := service.CheckDiskSpace(&models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "shop", ReplaceEntireDatabase: true}}})
+	if err != nil {
+		t.Fatalf("CheckDiskSpace() error = %v", err)
+	}
+	if preflight.DumpBytes != 0 || preflight.RestoreBytes != 1<<60+1<<58 {
+		t.Fatalf("unexpected estimate %+v", preflight)
+	}
+	if len(preflight.Checks) != 1 || preflight.Checks[0].Purpose != "datadir" || preflight.Checks[0].Status != models.DiskSpaceInsufficient || !preflight.Blocking {
+		t.Fatalf("expected blocking datadir check, got %+v", preflight)
+	}
+
+	service.config.Preflight.Disk = config.PreflightDiskOff
+	if preflight, err := service.CheckDiskSpace(&models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "shop"}}}); err != nil || preflight != nil {
+		t.Fatalf("disabled preflight must return nil, got %+v, %v", preflight, err)
+	}
+}
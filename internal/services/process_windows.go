@@ -0,0 +1,30 @@
+//go:build windows
+
+package services
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive — код завершения, который Windows возвращает для работающего процесса.
+const stillActive = 259
+
+// processAlive сообщает, существует ли процесс pid. Отказ в доступе означает, что процесс есть,
+// но принадлежит другому пользователю.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(handle)
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
@@ -71,6 +71,29 @@ func newProxyTunnel(mysqlConfig config.MySQLConfig) (*proxyTunnel, error) {
 	return tunnel, nil
 }
 
+// checkProxyReachable проверяет, что proxy из mysqlConfig принимает подключения и открывает
+// туннель до MySQL. Ошибка первого шага означает недоступный proxy, второго — что proxy не
+// может достучаться до сервера или отказал в туннеле.
+func checkProxyReachable(mysqlConfig config.MySQLConfig) (proxyErr error, targetErr error) {
+	proxyURL, err := url.Parse(mysqlConfig.ProxyURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse proxy URL: %w", err), nil
+	}
+	conn, err := net.DialTimeout("tcp", proxyURL.Host, 10*time.Second)
+	if err != nil {
+		return fmt.Errorf("proxy %s is unreachable: %w", proxyURL.Host, err), nil
+	}
+	_ = conn.Close()
+
+	tunnel := &proxyTunnel{proxyURL: proxyURL, target: net.JoinHostPort(mysqlConfig.Host, strconv.Itoa(mysqlConfig.Port))}
+	conn, err = tunnel.dialTarget()
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.Close()
+	return nil, nil
+}
+
 func (t *proxyTunnel) Host() string {
 	host, _, err := net.SplitHostPort(t.listener.Addr().String())
 	if err != nil {
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	scheduleFileName     = "schedules.json"
+	scheduleRunsFileName = "runs.jsonl"
+
+	// scheduleReloadInterval ограничивает ожидание daemon, чтобы изменения расписаний подхватывались без перезапуска.
+	scheduleReloadInterval = 30 * time.Second
+
+	scheduleSkippedReason = "previous run is still in progress"
+)
+
+var scheduleNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// scheduleFile хранит список расписаний на диске.
+type scheduleFile struct {
+	Schedules []models.Schedule `json:"schedules"`
+}
+
+// ScheduleStore хранит расписания и историю их запусков в директории config.Schedule.
+type ScheduleStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewScheduleStore создает хранилище расписаний по настройкам конфигурации.
+func NewScheduleStore(cfg *config.Config) *ScheduleStore {
+	return &ScheduleStore{dir: cfg.Schedule.ResolvedDir()}
+}
+
+// ParseScheduleCron разбирает стандартное cron выражение из пяти полей или дескриптор вида @daily.
+func ParseScheduleCron(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+	return schedule, nil
+}
+
+// NextScheduleRun возвращает ближайшее время запуска после after.
+func NextScheduleRun(expr string, after time.Time) (time.Time, error) {
+	schedule, err := ParseScheduleCron(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(after), nil
+}
+
+func (s *ScheduleStore) path() string {
+	return filepath.Join(s.dir, scheduleFileName)
+}
+
+// RunsPath возвращает путь к журналу запусков в формате JSON Lines.
+func (s *ScheduleStore) RunsPath() string {
+	return filepath.Join(s.dir, scheduleRunsFileName)
+}
+
+// List возвращает расписания в порядке имени.
+func (s *ScheduleStore) List() ([]models.Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+func (s *ScheduleStore) load() ([]models.Schedule, error) {
+	data, err := os.ReadFile(s.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	var file scheduleFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules %s: %w", s.path(), err)
+	}
+	sort.Slice(file.Schedules, func(i, j int) bool { return file.Schedules[i].Name < file.Schedules[j].Name })
+	return file.Schedules, nil
+}
+
+func (s *ScheduleStore) save(schedules []models.Schedule) error {
+	if schedules == nil {
+		schedules = []models.Schedule{}
+	}
+	if err := writeJSONFileAtomic(s.path(), scheduleFile{Schedules: schedules}); err != nil {
+		return fmt.Errorf("failed to save schedules: %w", err)
+	}
+	return nil
+}
+
+// Add сохраняет новое расписание; имя должно быть уникальным, а cron выражение корректным.
+func (s *ScheduleStore) Add(schedule models.Schedule) error {
+	if !scheduleNamePattern.MatchString(schedule.Name) {
+		return fmt.Errorf("invalid schedule name %q: use letters, digits, '.', '_' or '-'", schedule.Name)
+	}
+	if _, err := ParseScheduleCron(schedule.Cron); err != nil {
+		return err
+	}
+	if len(schedule.Plan.Targets) == 0 {
+		return fmt.Errorf("schedule %q has no databases", schedule.Name)
+	}
+	if schedule.CreatedAt.IsZero() {
+		schedule.CreatedAt = time.Now()
+	}
+	schedule.Cron = strings.TrimSpace(schedule.Cron)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedules, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, existing := range schedules {
+		if existing.Name == schedule.Name {
+			return fmt.Errorf("schedule %q already exists", schedule.Name)
+		}
+	}
+	return s.save(append(schedules, schedule))
+}
+
+// Remove удаляет расписание по имени; история запусков сохраняется.
+func (s *ScheduleStore) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schedules, err := s.load()
+	if err != nil {
+		return err
+	}
+	kept := schedules[:0]
+	for _, schedule := range schedules {
+		if schedule.Name != name {
+			kept = append(kept, schedule)
+		}
+	}
+	if len(kept) == len(schedules) {
+		return fmt.Errorf("schedule %q not found", name)
+	}
+	return s.save(kept)
+}
+
+// RecordRun дописывает запуск в журнал и обновляет последний запуск расписания.
+func (s *ScheduleStore) RecordRun(run models.ScheduleRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create schedule directory: %w", err)
+	}
+	line, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode schedule run: %w", err)
+	}
+	file, err := os.OpenFile(s.RunsPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open schedule runs: %w", err)
+	}
+	_, writeErr := file.Write(append(line, '\n'))
+	closeErr := file.Close()
+	if writeErr != nil {
+		return fmt.Errorf("failed to write schedule run: %w", writeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write schedule run: %w", closeErr)
+	}
+
+	schedules, err := s.load()
+	if err != nil {
+		return err
+	}
+	for i := range schedules {
+		if schedules[i].Name == run.ScheduleName {
+			last := run
+			last.Results = nil
+			schedules[i].LastRun = &last
+			return s.save(schedules)
+		}
+	}
+	return nil
+}
+
+// Statuses возвращает расписания с ближайшим временем запуска, отсортированные по нему.
+func (s *ScheduleStore) Statuses(now time.Time) ([]models.ScheduleStatus, error) {
+	schedules, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]models.ScheduleStatus, 0, len(schedules))
+	for _, schedule := range schedules {
+		status := models.ScheduleStatus{Schedule: schedule}
+		if next, err := NextScheduleRun(schedule.Cron, now); err == nil {
+			status.NextRun = next
+		}
+		statuses = append(statuses, status)
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if statuses[i].NextRun.IsZero() != statuses[j].NextRun.IsZero() {
+			return !statuses[i].NextRun.IsZero()
+		}
+		return statuses[i].NextRun.Before(statuses[j].NextRun)
+	})
+	return statuses, nil
+}
+
+// ListScheduleStatuses возвращает расписания из конфигурации сервиса с ближайшими запусками.
+func (s *MySQLShellService) ListScheduleStatuses() ([]models.ScheduleStatus, error) {
+	return NewScheduleStore(s.config).Statuses(time.Now())
+}
+
+// ScheduleDaemon выполняет наступившие расписания через ExecutePlan. Запуск пропускается и
+// записывается как skipped, если предыдущий запуск того же расписания еще не завершился.
+type ScheduleDaemon struct {
+	store   *ScheduleStore
+	runner  SyncServiceInterface
+	runtime models.RuntimeOptions
+	logf    func(format string, args ...any)
+	now     func() time.Time
+
+	mu      sync.Mutex
+	running map[string]bool
+	next    map[string]time.Time
+	wg      sync.WaitGroup
+}
+
+// NewScheduleDaemon создает daemon; logf получает строки журнала и может быть nil.
+func NewScheduleDaemon(store *ScheduleStore, runner SyncServiceInterface, runtime models.RuntimeOptions, logf func(format string, args ...any)) *ScheduleDaemon {
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	return &ScheduleDaemon{
+		store:   store,
+		runner:  runner,
+		runtime: runtime,
+		logf:    logf,
+		now:     time.Now,
+		running: make(map[string]bool),
+		next:    make(map[string]time.Time),
+	}
+}
+
+// Run проверяет расписания до отмены ctx и дожидается завершения начатых запусков.
+func (d *ScheduleDaemon) Run(ctx context.Context) error {
+	for {
+		wait, err := d.tick(d.now())
+		if err != nil {
+			d.logf("⚠️  %v", err)
+			wait = scheduleReloadInterval
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.wg.Wait()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Wait блокируется до завершения всех начатых запусков.
+func (d *ScheduleDaemon) Wait() {
+	d.wg.Wait()
+}
+
+// tick запускает наступившие расписания и возвращает время до следующей проверки.
+// Расписание, впервые увиденное daemon, планируется от текущего момента без догоняющих запусков.
+func (d *ScheduleDaemon) tick(now time.Time) (time.Duration, error) {
+	schedules, err := d.store.List()
+	if err != nil {
+		return 0, err
+	}
+
+	wait := scheduleReloadInterval
+	seen := make(map[string]struct{}, len(schedules))
+	for _, schedule := range schedules {
+		cronSchedule, err := ParseScheduleCron(schedule.Cron)
+		if err != nil {
+			d.logf("⚠️  Schedule %s: %v", schedule.Name, err)
+			continue
+		}
+		key := schedule.Name + "\x00" + schedule.Cron
+		seen[key] = struct{}{}
+		next, ok := d.next[key]
+		if !ok {
+			next = cronSchedule.Next(now)
+		}
+		if !now.Before(next) {
+			d.start(schedule, now)
+			next = cronSchedule.Next(now)
+		}
+		d.next[key] = next
+		wait = min(wait, next.Sub(now))
+	}
+	for key := range d.next {
+		if _, ok := seen[key]; !ok {
+			delete(d.next, key)
+		}
+	}
+	return max(wait, 0), nil
+}
+
+func (d *ScheduleDaemon) start(schedule models.Schedule, now time.Time) {
+	d.mu.Lock()
+	if d.running[schedule.Name] {
+		d.mu.Unlock()
+		d.record(models.ScheduleRun{ScheduleName: schedule.Name, Status: models.ScheduleRunSkipped, StartedAt: now, FinishedAt: now, Error: scheduleSkippedReason})
+		return
+	}
+	d.running[schedule.Name] = true
+	d.mu.Unlock()
+
+	d.logf("▶️  Schedule %s: syncing %s", schedule.Name, strings.Join(schedulePlanDatabases(schedule.Plan), ", "))
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() {
+			d.mu.Lock()
+			delete(d.running, schedule.Name)
+			d.mu.Unlock()
+		}()
+
+		plan := schedule.Plan
+		plan.Targets = append([]models.SyncTarget(nil), schedule.Plan.Targets...)
+		plan.CreatedAt = now
+		results, err := d.runner.ExecutePlan(&plan, d.runtime, nil)
+		run := models.ScheduleRun{ScheduleName: schedule.Name, Status: models.ScheduleRunSuccess, StartedAt: now, FinishedAt: d.now(), Results: results}
+		if err == nil {
+			for _, result := range results {
+				if !result.Success {
+					err = fmt.Errorf("%s: %s", result.DatabaseName, result.Error)
+					break
+				}
+			}
+		}
+		if err != nil {
+			run.Status = models.ScheduleRunFailed
+			run.Error = err.Error()
+		}
+		d.record(run)
+	}()
+}
+
+func (d *ScheduleDaemon) record(run models.ScheduleRun) {
+	switch run.Status {
+	case models.ScheduleRunSuccess:
+		d.logf("✅ Schedule %s: done in %s", run.ScheduleName, run.Duration().Truncate(time.Second))
+	case models.ScheduleRunSkipped:
+		d.logf("⏭️  Schedule %s: skipped, %s", run.ScheduleName, run.Error)
+	default:
+		d.logf("❌ Schedule %s: failed: %s", run.ScheduleName, run.Error)
+	}
+	if err := d.store.RecordRun(run); err != nil {
+		d.logf("⚠️  Schedule %s: %v", run.ScheduleName, err)
+	}
+}
+
+// schedulePlanDatabases возвращает имена баз плана в порядке целей.
+func schedulePlanDatabases(plan models.SyncPlan) []string {
+	names := make([]string, 0, len(plan.Targets))
+	for _, target := range plan.Targets {
+		names = append(names, target.DatabaseName)
+	}
+	return names
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+type blockingPlanRunner struct {
+	mu      sync.Mutex
+	calls   int
+	release chan struct{}
+	err     error
+}
+
+func (r *blockingPlanRunner) ExecutePlan(plan *models.SyncPlan, runtime models.RuntimeOptions, observer models.ProgressObserver) ([]models.SyncResult, error) {
+	r.mu.Lock()
+	r.calls++
+	r.mu.Unlock()
+	<-r.release
+	results := make([]models.SyncResult, 0, len(plan.Targets))
+	for _, target := range plan.Targets {
+		results = append(results, models.SyncResult{Success: r.err == nil, DatabaseName: target.DatabaseName})
+	}
+	return results, r.err
+}
+
+func (r *blockingPlanRunner) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+func newTestScheduleStore(t *testing.T) *ScheduleStore {
+	t.Helper()
+	return NewScheduleStore(&config.Config{Schedule: config.ScheduleConfig{Dir: t.TempDir()}})
+}
+
+func catalogSchedule(cronExpr string) models.Schedule {
+	return models.Schedule{
+		Name: "catalog-morning",
+		Cron: cronExpr,
+		Plan: models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "catalog", ReplaceEntireDatabase: true}}},
+	}
+}
+
+func TestScheduleStoreAddListRemove(t *testing.T) {
+	store := newTestScheduleStore(t)
+
+	if err := store.Add(catalogSchedule("0 7 * * 1-5")); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := store.Add(catalogSchedule("0 8 * * *")); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("duplicate schedule must be rejected, got %v", err)
+	}
+	invalid := catalogSchedule("every morning")
+	invalid.Name = "broken"
+	if err := store.Add(invalid); err == nil || !strings.Contains(err.Error(), "invalid cron expression") {
+		t.Fatalf("invalid cron must be rejected, got %v", err)
+	}
+	empty := catalogSchedule("@daily")
+	empty.Name = "empty"
+	empty.Plan.Targets = nil
+	if err := store.Add(empty); err == nil {
+		t.Fatal("schedule without databases must be rejected")
+	}
+
+	friday := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	statuses, err := store.Statuses(friday)
+	if err != nil || len(statuses) != 1 {
+		t.Fatalf("unexpected statuses: %+v err=%v", statuses, err)
+	}
+	if want := time.Date(2026, 10, 19, 7, 0, 0, 0, time.Local); !statuses[0].NextRun.Equal(want) {
+		t.Fatalf("next run = %v, want %v", statuses[0].NextRun, want)
+	}
+	if statuses[0].Plan.Targets[0].DatabaseName != "catalog" {
+		t.Fatalf("plan must be stored with the schedule, got %+v", statuses[0].Plan)
+	}
+
+	if err := store.Remove("catalog-morning"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := store.Remove("catalog-morning"); err == nil {
+		t.Fatal("removing a missing schedule must fail")
+	}
+}
+
+func TestScheduleDaemonSkipsOverlappingRunsAndRecordsResults(t *testing.T) {
+	store := newTestScheduleStore(t)
+	if err := store.Add(catalogSchedule("*/5 * * * *")); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	runner := &blockingPlanRunner{release: make(chan struct{})}
+	daemon := NewScheduleDaemon(store, runner, models.RuntimeOptions{Force: true}, nil)
+	start := time.Date(2026, 10, 19, 6, 58, 0, 0, time.UTC)
+	daemon.now = func() time.Time { return start.Add(12 * time.Minute) }
+
+	wait, err := daemon.tick(start)
+	if err != nil || wait != scheduleReloadInterval || runner.callCount() != 0 {
+		t.Fatalf("first tick must only plan the next run, wait=%v calls=%d err=%v", wait, runner.callCount(), err)
+	}
+	if _, err := daemon.tick(start.Add(2 * time.Minute)); err != nil {
+		t.Fatalf("tick() error = %v", err)
+	}
+	for runner.callCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := daemon.tick(start.Add(7 * time.Minute)); err != nil {
+		t.Fatalf("tick() error = %v", err)
+	}
+
+	schedules, _ := store.List()
+	if last := schedules[0].LastRun; last == nil || last.Status != models.ScheduleRunSkipped {
+		t.Fatalf("overlapping run must be recorded as skipped, got %+v", last)
+	}
+
+	runner.err = errors.New("mysqlsh failed")
+	close(runner.release)
+	daemon.Wait()
+	if runner.callCount() != 1 {
+		t.Fatalf("ExecutePlan() calls = %d, want 1", runner.callCount())
+	}
+	schedules, _ = store.List()
+	last := schedules[0].LastRun
+	if last == nil || last.Status != models.ScheduleRunFailed || !strings.Contains(last.Error, "mysqlsh failed") || last.Duration() != 10*time.Minute {
+		t.Fatalf("unexpected last run: %+v", last)
+	}
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+// DiffSchema сравнивает структуру БД на remote и local.
+func (ds *DatabaseService) DiffSchema(databaseName string) (*models.SchemaDiff, error) {
+	return BuildSchemaDiff(ds, databaseName)
+}
+
+// BuildSchemaDiff читает information_schema на обеих сторонах и строит структурный diff.
+func BuildSchemaDiff(dbService DatabaseServiceInterface, databaseName string) (*models.SchemaDiff, error) {
+	if err := dbService.ValidateDatabaseName(databaseName); err != nil {
+		return nil, fmt.Errorf("invalid database name: %w", err)
+	}
+
+	remoteExists, err := dbService.DatabaseExists(databaseName, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check remote database: %w", err)
+	}
+	if !remoteExists {
+		return nil, fmt.Errorf("database '%s' not found on remote server", databaseName)
+	}
+	localExists, err := dbService.DatabaseExists(databaseName, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check local database: %w", err)
+	}
+
+	remote, err := dbService.DescribeSchema(databaseName, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote schema: %w", err)
+	}
+	var local []models.TableSchema
+	if localExists {
+		local, err = dbService.DescribeSchema(databaseName, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read local schema: %w", err)
+		}
+	}
+
+	diff := DiffSchemas(databaseName, remote, local)
+	diff.LocalExists = localExists
+	return diff, nil
+}
+
+// DiffSchemas сравнивает структуры таблиц remote и local.
+func DiffSchemas(databaseName string, remote []models.TableSchema, local []models.TableSchema) *models.SchemaDiff {
+	diff := &models.SchemaDiff{DatabaseName: databaseName, LocalExists: true, GeneratedAt: time.Now()}
+
+	localByName := make(map[string]models.TableSchema, len(local))
+	for _, table := range local {
+		localByName[table.Name] = table
+	}
+	remoteByName := make(map[string]bool, len(remote))
+	for _, remoteTable := range remote {
+		remoteByName[remoteTable.Name] = true
+		localTable, ok := localByName[remoteTable.Name]
+		if !ok {
+			diff.MissingTables = append(diff.MissingTables, remoteTable)
+			continue
+		}
+		tableDiff := diffTable(remoteTable, localTable)
+		if tableDiff.HasSchemaChanges() || tableDiff.RowDelta() != 0 {
+			diff.Tables = append(diff.Tables, tableDiff)
+		}
+	}
+	for _, localTable := range local {
+		if !remoteByName[localTable.Name] {
+			diff.ExtraTables = append(diff.ExtraTables, localTable)
+		}
+	}
+
+	sort.Slice(diff.MissingTables, func(i, j int) bool { return diff.MissingTables[i].Name < diff.MissingTables[j].Name })
+	sort.Slice(diff.ExtraTables, func(i, j int) bool { return diff.ExtraTables[i].Name < diff.ExtraTables[j].Name })
+	sort.Slice(diff.Tables, func(i, j int) bool { return diff.Tables[i].Name < diff.Tables[j].Name })
+	return diff
+}
+
+func diffTable(remote models.TableSchema, local models.TableSchema) models.TableDiff {
+	tableDiff := models.TableDiff{
+		Name:            remote.Name,
+		RemoteRows:      remote.Rows,
+		LocalRows:       local.Rows,
+		RowsApproximate: remote.RowsApprox || local.RowsApprox,
+	}
+	if !strings.EqualFold(remote.Engine, local.Engine) {
+		tableDiff.EngineChanged = true
+		tableDiff.RemoteEngine = remote.Engine
+		tableDiff.LocalEngine = local.Engine
+	}
+
+	localColumns := make(map[string]models.ColumnSchema, len(local.Columns))
+	for _, column := range local.Columns {
+		localColumns[column.Name] = column
+	}
+	remoteColumns := make(map[string]bool, len(remote.Columns))
+	for _, remoteColumn := range remote.Columns {
+		remoteColumns[remoteColumn.Name] = true
+		localColumn, ok := localColumns[remoteColumn.Name]
+		switch {
+		case !ok:
+			tableDiff.MissingColumns = append(tableDiff.MissingColumns, remoteColumn)
+		case !columnsEqual(remoteColumn, localColumn):
+			tableDiff.ChangedColumns = append(tableDiff.ChangedColumns, models.ColumnChange{Name: remoteColumn.Name, Remote: remoteColumn, Local: localColumn})
+		}
+	}
+	for _, localColumn := range local.Columns {
+		if !remoteColumns[localColumn.Name] {
+			tableDiff.ExtraColumns = append(tableDiff.ExtraColumns, localColumn)
+		}
+	}
+
+	localIndexes := make(map[string]models.IndexSchema, len(local.Indexes))
+	for _, index := range local.Indexes {
+		localIndexes[index.Name] = index
+	}
+	remoteIndexes := make(map[string]bool, len(remote.Indexes))
+	for _, remoteIndex := range remote.Indexes {
+		remoteIndexes[remoteIndex.Name] = true
+		localIndex, ok := localIndexes[remoteIndex.Name]
+		switch {
+		case !ok:
+			tableDiff.MissingIndexes = append(tableDiff.MissingIndexes, remoteIndex)
+		case !indexesEqual(remoteIndex, localIndex):
+			tableDiff.ChangedIndexes = append(tableDiff.ChangedIndexes, models.IndexChange{Name: remoteIndex.Name, Remote: remoteIndex, Local: localIndex})
+		}
+	}
+	for _, localIndex := range local.Indexes {
+		if !remoteIndexes[localIndex.Name] {
+			tableDiff.ExtraIndexes = append(tableDiff.ExtraIndexes, localIndex)
+		}
+	}
+	return tableDiff
+}
+
+func columnsEqual(left models.ColumnSchema, right models.ColumnSchema) bool {
+	if !strings.EqualFold(left.Type, right.Type) || left.Nullable != right.Nullable || left.Collation != right.Collation {
+		return false
+	}
+	if (left.Default == nil) != (right.Default == nil) || (left.Default != nil && *left.Default != *right.Default) {
+		return false
+	}
+	return normalizeColumnExtra(left.Extra) == normalizeColumnExtra(right.Extra)
+}
+
+func indexesEqual(left models.IndexSchema, right models.IndexSchema) bool {
+	return left.Unique == right.Unique && strings.EqualFold(left.Type, right.Type) && strings.Join(left.Columns, ",") == strings.Join(right.Columns, ",")
+}
+
+// normalizeColumnExtra убирает служебный маркер MySQL 8 DEFAULT_GENERATED.
+func normalizeColumnExtra(extra string) string {
+	extra = strings.ReplaceAll(strings.ToLower(extra), "default_generated", "")
+	return strings.Join(strings.Fields(extra), " ")
+}
+
+// SchemaAlterStatements возвращает SQL, который повторяет локальные изменения поверх схемы remote.
+// Так локальные миграции можно сохранить перед перезаписью и применить заново после синхронизации.
+func SchemaAlterStatements(diff *models.SchemaDiff) []string {
+	if diff == nil {
+		return nil
+	}
+	statements := make([]string, 0)
+	for _, table := range diff.MissingTables {
+		statements = append(statements, fmt.Sprintf("DROP TABLE %s;", quoteIdentifier(table.Name)))
+	}
+	for _, table := range diff.ExtraTables {
+		statements = append(statements, createTableStatement(table))
+	}
+	for _, table := range diff.Tables {
+		clauses := alterTableClauses(table)
+		if len(clauses) == 0 {
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s\n  %s;", quoteIdentifier(table.Name), strings.Join(clauses, ",\n  ")))
+	}
+	return statements
+}
+
+func alterTableClauses(table models.TableDiff) []string {
+	clauses := make([]string, 0)
+	for _, index := range table.MissingIndexes {
+		clauses = append(clauses, dropIndexClause(index))
+	}
+	for _, change := range table.ChangedIndexes {
+		clauses = append(clauses, dropIndexClause(change.Remote))
+	}
+	for _, column := range table.MissingColumns {
+		clauses = append(clauses, "DROP COLUMN "+quoteIdentifier(column.Name))
+	}
+	for _, column := range table.ExtraColumns {
+		clauses = append(clauses, "ADD COLUMN "+columnDefinition(column))
+	}
+	for _, change := range table.ChangedColumns {
+		clauses = append(clauses, "MODIFY COLUMN "+columnDefinition(change.Local))
+	}
+	for _, index := range table.ExtraIndexes {
+		clauses = append(clauses, "ADD "+indexDefinition(index))
+	}
+	for _, change := range table.ChangedIndexes {
+		clauses = append(clauses, "ADD "+indexDefinition(change.Local))
+	}
+	if table.EngineChanged && table.LocalEngine != "" {
+		clauses = append(clauses, "ENGINE="+table.LocalEngine)
+	}
+	return clauses
+}
+
+func createTableStatement(table models.TableSchema) string {
+	definitions := make([]string, 0, len(table.Columns)+len(table.Indexes))
+	for _, column := range table.Columns {
+		definitions = append(definitions, columnDefinition(column))
+	}
+	for _, index := range table.Indexes {
+		definitions = append(definitions, indexDefinition(index))
+	}
+	statement := fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", quoteIdentifier(table.Name), strings.Join(definitions, ",\n  "))
+	if table.Engine != "" {
+		statement += " ENGINE=" + table.Engine
+	}
+	if table.Collation != "" {
+		statement += " COLLATE=" + table.Collation
+	}
+	return statement + ";"
+}
+
+func columnDefinition(column models.ColumnSchema) string {
+	parts := []string{quoteIdentifier(column.Name), column.Type}
+	if column.Collation != "" {
+		parts = append(parts, "COLLATE "+column.Collation)
+	}
+	if column.Nullable {
+		parts = append(parts, "NULL")
+	} else {
+		parts = append(parts, "NOT NULL")
+	}
+	if column.Default != nil {
+		parts = append(parts, "DEFAULT "+columnDefaultLiteral(column))
+	}
+	if extra := normalizeColumnExtra(column.Extra); extra != "" {
+		parts = append(parts, strings.ToUpper(extra))
+	}
+	return strings.Join(parts, " ")
+}
+
+func columnDefaultLiteral(column models.ColumnSchema) string {
+	value := *column.Default
+	upper := strings.ToUpper(value)
+	if strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || upper == "NULL" {
+		return value
+	}
+	// Выражения по умолчанию в MySQL 8 помечены DEFAULT_GENERATED и пишутся в скобках.
+	if strings.Contains(strings.ToLower(column.Extra), "default_generated") {
+		return "(" + value + ")"
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func indexDefinition(index models.IndexSchema) string {
+	columns := make([]string, 0, len(index.Columns))
+	for _, column := range index.Columns {
+		columns = append(columns, quoteIdentifier(column))
+	}
+	columnList := "(" + strings.Join(columns, ", ") + ")"
+	switch {
+	case index.Name == "PRIMARY":
+		return "PRIMARY KEY " + columnList
+	case strings.EqualFold(index.Type, "FULLTEXT"):
+		return "FULLTEXT KEY " + quoteIdentifier(index.Name) + " " + columnList
+	case strings.EqualFold(index.Type, "SPATIAL"):
+		return "SPATIAL KEY " + quoteIdentifier(index.Name) + " " + columnList
+	case index.Unique:
+		return "UNIQUE KEY " + quoteIdentifier(index.Name) + " " + columnList
+	default:
+		return "KEY " + quoteIdentifier(index.Name) + " " + columnList
+	}
+}
+
+func dropIndexClause(index models.IndexSchema) string {
+	if index.Name == "PRIMARY" {
+		return "DROP PRIMARY KEY"
+	}
+	return "DROP INDEX " + quoteIdentifier(index.Name)
+}
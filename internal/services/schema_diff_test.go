@@ -0,0 +1,133 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func testRemoteSchema() []models.TableSchema {
+	return []models.TableSchema{
+		{
+			Name:   "users",
+			Engine: "InnoDB",
+			Rows:   10,
+			Columns: []models.ColumnSchema{
+				{Name: "id", Position: 1, Type: "int unsigned", Extra: "auto_increment"},
+				{Name: "email", Position: 2, Type: "varchar(255)", Collation: "utf8mb4_0900_ai_ci"},
+				{Name: "legacy", Position: 3, Type: "tinyint(1)", Nullable: true},
+			},
+			Indexes: []models.IndexSchema{
+				{Name: "PRIMARY", Unique: true, Type: "BTREE", Columns: []string{"id"}},
+				{Name: "users_email", Unique: false, Type: "BTREE", Columns: []string{"email"}},
+			},
+		},
+		{Name: "sessions", Engine: "InnoDB", Rows: 3, Columns: []models.ColumnSchema{{Name: "id", Position: 1, Type: "int"}}},
+		{Name: "orders", Engine: "InnoDB", Rows: 5, Columns: []models.ColumnSchema{{Name: "id", Position: 1, Type: "int"}}},
+	}
+}
+
+func testLocalSchema() []models.TableSchema {
+	return []models.TableSchema{
+		{
+			Name:   "users",
+			Engine: "InnoDB",
+			Rows:   12,
+			Columns: []models.ColumnSchema{
+				{Name: "id", Position: 1, Type: "int unsigned", Extra: "auto_increment"},
+				{Name: "email", Position: 2, Type: "varchar(320)", Collation: "utf8mb4_0900_ai_ci"},
+				{Name: "created_at", Position: 3, Type: "timestamp", Default: stringPtr("CURRENT_TIMESTAMP"), Extra: "DEFAULT_GENERATED"},
+			},
+			Indexes: []models.IndexSchema{
+				{Name: "PRIMARY", Unique: true, Type: "BTREE", Columns: []string{"id"}},
+				{Name: "users_email", Unique: true, Type: "BTREE", Columns: []string{"email"}},
+			},
+		},
+		{Name: "orders", Engine: "InnoDB", Rows: 5, Columns: []models.ColumnSchema{{Name: "id", Position: 1, Type: "int"}}},
+		{Name: "feature_flags", Engine: "InnoDB", Columns: []models.ColumnSchema{{Name: "name", Position: 1, Type: "varchar(64)", Default: stringPtr("off")}}, Indexes: []models.IndexSchema{{Name: "PRIMARY", Unique: true, Columns: []string{"name"}}}},
+	}
+}
+
+func TestDiffSchemas(t *testing.T) {
+	diff := DiffSchemas("shop", testRemoteSchema(), testLocalSchema())
+
+	if len(diff.MissingTables) != 1 || diff.MissingTables[0].Name != "sessions" {
+		t.Fatalf("unexpected missing tables: %+v", diff.MissingTables)
+	}
+	if len(diff.ExtraTables) != 1 || diff.ExtraTables[0].Name != "feature_flags" {
+		t.Fatalf("unexpected extra tables: %+v", diff.ExtraTables)
+	}
+	if len(diff.Tables) != 1 {
+		t.Fatalf("unchanged orders table must be omitted, got %+v", diff.Tables)
+	}
+
+	users := diff.Tables[0]
+	if users.RowDelta() != 2 {
+		t.Fatalf("unexpected row delta: %d", users.RowDelta())
+	}
+	if len(users.MissingColumns) != 1 || users.MissingColumns[0].Name != "legacy" {
+		t.Fatalf("unexpected missing columns: %+v", users.MissingColumns)
+	}
+	if len(users.ExtraColumns) != 1 || users.ExtraColumns[0].Name != "created_at" {
+		t.Fatalf("unexpected extra columns: %+v", users.ExtraColumns)
+	}
+	if len(users.ChangedColumns) != 1 || users.ChangedColumns[0].Local.Type != "varchar(320)" {
+		t.Fatalf("unexpected changed columns: %+v", users.ChangedColumns)
+	}
+	if len(users.ChangedIndexes) != 1 || users.ChangedIndexes[0].Name != "users_email" {
+		t.Fatalf("unexpected changed indexes: %+v", users.ChangedIndexes)
+	}
+	if len(diff.ChangedTables()) != 1 || diff.Empty() {
+		t.Fatal("diff should report schema changes")
+	}
+	if !DiffSchemas("shop", testRemoteSchema(), testRemoteSchema()).Empty() {
+		t.Fatal("identical schemas must produce an empty diff")
+	}
+}
+
+func TestSchemaAlterStatements(t *testing.T) {
+	statements := SchemaAlterStatements(DiffSchemas("shop", testRemoteSchema(), testLocalSchema()))
+	if len(statements) != 3 {
+		t.Fatalf("expected 3 statements, got %d:\n%s", len(statements), strings.Join(statements, "\n"))
+	}
+	if statements[0] != "DROP TABLE `sessions`;" {
+		t.Fatalf("unexpected first statement: %s", statements[0])
+	}
+	wantCreate := "CREATE TABLE `feature_flags` (\n  `name` varchar(64) NOT NULL DEFAULT 'off',\n  PRIMARY KEY (`name`)\n) ENGINE=InnoDB;"
+	if statements[1] != wantCreate {
+		t.Fatalf("unexpected create statement:\n%s", statements[1])
+	}
+	wantAlter := "ALTER TABLE `users`\n" +
+		"  DROP INDEX `users_email`,\n" +
+		"  DROP COLUMN `legacy`,\n" +
+		"  ADD COLUMN `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+		"  MODIFY COLUMN `email` varchar(320) COLLATE utf8mb4_0900_ai_ci NOT NULL,\n" +
+		"  ADD UNIQUE KEY `users_email` (`email`);"
+	if statements[2] != wantAlter {
+		t.Fatalf("unexpected alter statement:\n%s", statements[2])
+	}
+}
+
+func TestBuildSchemaDiffRequiresRemoteDatabase(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{}
+	if _, err := BuildSchemaDiff(dbService, "shop"); err == nil || !strings.Contains(err.Error(), "not found on remote") {
+		t.Fatalf("expected missing remote database error, got %v", err)
+	}
+
+	dbService.DatabaseExistsResult = true
+	dbService.RemoteSchema = testRemoteSchema()
+	dbService.LocalSchema = testLocalSchema()
+	diff, err := BuildSchemaDiff(dbService, "shop")
+	if err != nil {
+		t.Fatalf("BuildSchemaDiff() error = %v", err)
+	}
+	if !diff.LocalExists || len(diff.Tables) != 1 {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+const (
+	smartReasonDisabled = "smart sync disabled"
+
+	tableReasonNoSnapshot      = "no snapshot from last sync"
+	tableReasonMissingLocal    = "missing locally"
+	tableReasonChecksumSame    = "checksum unchanged"
+	tableReasonChecksumChanged = "checksum changed"
+	tableReasonUnknownUpdate   = "update time unknown"
+	tableReasonRemoteChanged   = "changed on remote"
+	tableReasonLocalChanged    = "changed locally"
+	tableReasonUnchanged       = "unchanged since last sync"
+)
+
+// tableSnapshotFile хранит снимки remote таблиц одной базы на момент последней успешной синхронизации.
+type tableSnapshotFile struct {
+	DatabaseName string                          `json:"database_name"`
+	Profile      string                          `json:"profile"`
+	Tables       map[string]models.TableSnapshot `json:"tables"`
+	UpdatedAt    time.Time                       `json:"updated_at"`
+}
+
+func (s *MySQLShellService) tableSnapshotPath(databaseName string) string {
+	return filepath.Join(s.config.Smart.ResolvedDir(), s.syncProfile(), databaseName+".json")
+}
+
+func loadTableSnapshots(path string) (map[string]models.TableSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]models.TableSnapshot{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table snapshots: %w", err)
+	}
+	var file tableSnapshotFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse table snapshots %s: %w", path, err)
+	}
+	if file.Tables == nil {
+		file.Tables = map[string]models.TableSnapshot{}
+	}
+	return file.Tables, nil
+}
+
+// PlanSmartSync определяет, какие таблицы цели не изменились с прошлой синхронизации и могут быть пропущены.
+func (s *MySQLShellService) PlanSmartSync(target models.SyncTarget) (*models.SmartSyncPlan, error) {
+	service, cleanup, err := s.routeService(target)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	plan, _, err := service.planSmartSync(target)
+	return plan, err
+}
+
+// planSmartSync возвращает план и текущие снимки remote таблиц цели для записи после успешной синхронизации.
+func (s *MySQLShellService) planSmartSync(target models.SyncTarget) (*models.SmartSyncPlan, map[string]models.TableSnapshot, error) {
+	databaseName := target.DatabaseName
+	plan := &models.SmartSyncPlan{DatabaseName: databaseName}
+	if !s.config.Smart.Enabled {
+		plan.Reason = smartReasonDisabled
+		return plan, nil, nil
+	}
+
+	remoteTables, err := s.dbService.ListTables(databaseName, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list remote tables: %w", err)
+	}
+	effective := target.EffectiveTables()
+	if len(effective) > 0 {
+		remoteTables = filterTablesByName(remoteTables, effective)
+	}
+	tables := make([]models.Table, 0, len(remoteTables))
+	tableNames := make([]string, 0, len(remoteTables))
+	for _, table := range remoteTables {
+		if isViewTable(table) {
+			continue
+		}
+		tables = append(tables, table)
+		tableNames = append(tableNames, table.Name)
+	}
+
+	current := make(map[string]models.TableSnapshot, len(tables))
+	for _, table := range tables {
+		current[table.Name] = models.TableSnapshot{Rows: table.Rows, DataLength: table.DataSize, UpdateTime: table.UpdateTime}
+	}
+	if s.config.Smart.Checksum {
+		fingerprints, err := s.dbService.TableFingerprints(databaseName, tableNames, tableNames, true)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to checksum remote tables: %w", err)
+		}
+		for tableName, fingerprint := range fingerprints {
+			snapshot := current[tableName]
+			snapshot.Rows = fingerprint.Rows
+			snapshot.Checksum = fingerprint.Checksum
+			current[tableName] = snapshot
+		}
+	}
+
+	refreshAll := func(reason string) (*models.SmartSyncPlan, map[string]models.TableSnapshot, error) {
+		plan.Reason = reason
+		for _, table := range tables {
+			plan.Tables = append(plan.Tables, models.TableDecision{Name: table.Name, Action: models.TableActionRefresh, Reason: reason, Bytes: table.DataSize})
+		}
+		return plan, current, nil
+	}
+	if target.ForceFull {
+		return refreshAll(incrementalReasonForced)
+	}
+	localExists, err := s.dbService.DatabaseExists(databaseName, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check if local database exists: %w", err)
+	}
+	if !localExists {
+		return refreshAll(incrementalReasonNoLocal)
+	}
+
+	localTables, err := s.dbService.ListTables(databaseName, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list local tables: %w", err)
+	}
+	localByName := make(map[string]models.Table, len(localTables))
+	for _, table := range localTables {
+		localByName[table.Name] = table
+	}
+	previous, err := loadTableSnapshots(s.tableSnapshotPath(databaseName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, table := range tables {
+		snapshot, hasPrevious := previous[table.Name]
+		local, hasLocal := localByName[table.Name]
+		action, reason := decideTableSync(current[table.Name], snapshot, hasPrevious, local, hasLocal)
+		plan.Tables = append(plan.Tables, models.TableDecision{Name: table.Name, Action: action, Reason: reason, Bytes: table.DataSize})
+	}
+	return plan, current, nil
+}
+
+// decideTableSync сравнивает текущий снимок remote таблицы с записанным при прошлой синхронизации.
+// Любая неуверенность ведет к перезагрузке: пропуск допустим, только если таблица точно не менялась.
+func decideTableSync(current models.TableSnapshot, previous models.TableSnapshot, hasPrevious bool, local models.Table, hasLocal bool) (models.TableSyncAction, string) {
+	if !hasPrevious {
+		return models.TableActionRefresh, tableReasonNoSnapshot
+	}
+	if !hasLocal {
+		return models.TableActionRefresh, tableReasonMissingLocal
+	}
+	if !local.UpdateTime.IsZero() && local.UpdateTime.After(previous.SyncedAt) {
+		return models.TableActionRefresh, tableReasonLocalChanged
+	}
+	// Число строк в снимке точное только при checksum; оценки InnoDB сравнивать нельзя.
+	if previous.Checksum != "" && !local.RowsApprox && local.Rows != previous.Rows {
+		return models.TableActionRefresh, tableReasonLocalChanged
+	}
+	if current.Checksum != "" && previous.Checksum != "" {
+		if current.Checksum != previous.Checksum || current.Rows != previous.Rows {
+			return models.TableActionRefresh, tableReasonChecksumChanged
+		}
+		return models.TableActionSkip, tableReasonChecksumSame
+	}
+	if current.UpdateTime.IsZero() || previous.UpdateTime.IsZero() {
+		return models.TableActionRefresh, tableReasonUnknownUpdate
+	}
+	if !current.UpdateTime.Equal(previous.UpdateTime) || current.DataLength != previous.DataLength {
+		return models.TableActionRefresh, tableReasonRemoteChanged
+	}
+	return models.TableActionSkip, tableReasonUnchanged
+}
+
+// storeTableSnapshots сохраняет снимки таблиц цели после успешной синхронизации.
+// Снимки таблиц вне цели не трогаются; пустой tableNames означает всю базу.
+func (s *MySQLShellService) storeTableSnapshots(databaseName string, tableNames []string, current map[string]models.TableSnapshot, syncedAt time.Time) error {
+	path := s.tableSnapshotPath(databaseName)
+	stored, err := loadTableSnapshots(path)
+	if err != nil {
+		return err
+	}
+	if len(tableNames) == 0 {
+		stored = map[string]models.TableSnapshot{}
+	}
+	for _, tableName := range tableNames {
+		delete(stored, tableName)
+	}
+	for tableName, snapshot := range current {
+		snapshot.SyncedAt = syncedAt
+		stored[tableName] = snapshot
+	}
+	if err := writeJSONFileAtomic(path, tableSnapshotFile{DatabaseName: databaseName, Profile: s.syncProfile(), Tables: stored, UpdatedAt: syncedAt}); err != nil {
+		return fmt.Errorf("failed to write table snapshots: %w", err)
+	}
+	return nil
+}
+
+// stagingPlan возвращает план переноса из staging-схемы для цели с пропущенными таблицами.
+func stagingPlan(databaseName string, incremental *models.IncrementalPlan, smart *models.SmartSyncPlan) *models.IncrementalPlan {
+	if incremental != nil {
+		return incremental
+	}
+	return &models.IncrementalPlan{DatabaseName: databaseName, Full: smart.RefreshedTables()}
+}
+
+// dumpTableNames возвращает таблицы для includeTables: без пропущенных smart sync таблиц и представлений.
+func dumpTableNames(target models.SyncTarget, remoteTables []models.Table) []string {
+	effective := target.EffectiveTables()
+	if len(target.SkipTables) == 0 {
+		return effective
+	}
+	if len(effective) == 0 {
+		for _, table := range remoteTables {
+			if !isViewTable(table) {
+				effective = append(effective, table.Name)
+			}
+		}
+	}
+	skipped := make(map[string]bool, len(target.SkipTables))
+	for _, tableName := range target.SkipTables {
+		skipped[tableName] = true
+	}
+	names := make([]string, 0, len(effective))
+	for _, tableName := range effective {
+		if !skipped[tableName] {
+			names = append(names, tableName)
+		}
+	}
+	return names
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func TestDecideTableSync(t *testing.T) {
+	updated := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	syncedAt := updated.Add(time.Hour)
+	previous := models.TableSnapshot{Rows: 10, DataLength: 16384, UpdateTime: updated, SyncedAt: syncedAt}
+	local := models.Table{Name: "orders", Rows: 10, RowsApprox: true, UpdateTime: updated.Add(30 * time.Minute)}
+
+	tests := []struct {
+		name        string
+		current     models.TableSnapshot
+		previous    models.TableSnapshot
+		hasPrevious bool
+		local       models.Table
+		hasLocal    bool
+		action      models.TableSyncAction
+		reason      string
+	}{
+		{name: "first sync", current: previous, hasLocal: true, local: local, action: models.TableActionRefresh, reason: tableReasonNoSnapshot},
+		{name: "missing locally", current: previous, previous: previous, hasPrevious: true, action: models.TableActionRefresh, reason: tableReasonMissingLocal},
+		{name: "unchanged", current: previous, previous: previous, hasPrevious: true, local: local, hasLocal: true, action: models.TableActionSkip, reason: tableReasonUnchanged},
+		{name: "remote update time", current: models.TableSnapshot{Rows: 10, DataLength: 16384, UpdateTime: updated.Add(2 * time.Hour)}, previous: previous, hasPrevious: true, local: local, hasLocal: true, action: models.TableActionRefresh, reason: tableReasonRemoteChanged},
+		{name: "remote data length", current: models.TableSnapshot{Rows: 10, DataLength: 32768, UpdateTime: updated}, previous: previous, hasPrevious: true, local: local, hasLocal: true, action: models.TableActionRefresh, reason: tableReasonRemoteChanged},
+		{name: "unknown update time", current: models.TableSnapshot{Rows: 10, DataLength: 16384}, previous: previous, hasPrevious: true, local: local, hasLocal: true, action: models.TableActionRefresh, reason: tableReasonUnknownUpdate},
+		{name: "local write", current: previous, previous: previous, hasPrevious: true, local: models.Table{Name: "orders", UpdateTime: syncedAt.Add(time.Minute)}, hasLocal: true, action: models.TableActionRefresh, reason: tableReasonLocalChanged},
+		{name: "checksum same", current: models.TableSnapshot{Rows: 10, Checksum: "42"}, previous: models.TableSnapshot{Rows: 10, Checksum: "42", SyncedAt: syncedAt}, hasPrevious: true, local: models.Table{Name: "orders", Rows: 10}, hasLocal: true, action: models.TableActionSkip, reason: tableReasonChecksumSame},
+		{name: "checksum changed", current: models.TableSnapshot{Rows: 10, Checksum: "43"}, previous: models.TableSnapshot{Rows: 10, Checksum: "42", SyncedAt: syncedAt}, hasPrevious: true, local: models.Table{Name: "orders", Rows: 10}, hasLocal: true, action: models.TableActionRefresh, reason: tableReasonChecksumChanged},
+		{name: "local rows with checksum", current: models.TableSnapshot{Rows: 10, Checksum: "42"}, previous: models.TableSnapshot{Rows: 10, Checksum: "42", SyncedAt: syncedAt}, hasPrevious: true, local: models.Table{Name: "orders", Rows: 9}, hasLocal: true, action: models.TableActionRefresh, reason: tableReasonLocalChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, reason := decideTableSync(tt.current, tt.previous, tt.hasPrevious, tt.local, tt.hasLocal)
+			if action != tt.action || reason != tt.reason {
+				t.Fatalf("got %s (%s), want %s (%s)", action, reason, tt.action, tt.reason)
+			}
+		})
+	}
+}
+
+func TestPlanSmartSyncUsesStoredSnapshots(t *testing.T) {
+	updated := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	dbService := &mocks.MockDatabaseService{
+		DatabaseExistsResult: true,
+		TableList: []models.Table{
+			{Name: "orders", Engine: "InnoDB", Rows: 100, RowsApprox: true, DataSize: 8192, UpdateTime: updated},
+			{Name: "users", Engine: "InnoDB", Rows: 20, RowsApprox: true, DataSize: 4096, UpdateTime: updated},
+			{Name: "order_totals", Type: "VIEW"},
+		},
+	}
+	cfg := &config.Config{
+		Remote: config.MySQLConfig{Host: "db.example.com", Port: 3306, User: "sync"},
+		Local:  config.MySQLConfig{Host: "localhost", Port: 3306},
+		Smart:  config.SmartConfig{Enabled: true, Dir: t.TempDir()},
+	}
+	service := NewMySQLShellService(cfg, dbService)
+	service.SetQuiet(true)
+	target := models.SyncTarget{DatabaseName: "shop", ReplaceEntireDatabase: true}
+
+	plan, current, err := service.planSmartSync(target)
+	if err != nil {
+		t.Fatalf("planSmartSync() error = %v", err)
+	}
+	if len(plan.Tables) != 2 || len(plan.SkippedTables()) != 0 || plan.Tables[0].Reason != tableReasonNoSnapshot {
+		t.Fatalf("first sync must refresh every base table, got %+v", plan)
+	}
+	if err := service.storeTableSnapshots("shop", nil, current, time.Now()); err != nil {
+		t.Fatalf("storeTableSnapshots() error = %v", err)
+	}
+
+	dbService.TableList[0].DataSize = 12288
+	plan, err = service.PlanSmartSync(target)
+	if err != nil {
+		t.Fatalf("PlanSmartSync() error = %v", err)
+	}
+	if strings.Join(plan.SkippedTables(), ",") != "users" || strings.Join(plan.RefreshedTables(), ",") != "orders" || plan.SavedBytes() != 4096 {
+		t.Fatalf("unexpected smart plan: %+v", plan)
+	}
+
+	target.ForceFull = true
+	plan, err = service.PlanSmartSync(target)
+	if err != nil || len(plan.SkippedTables()) != 0 || plan.Reason != incrementalReasonForced {
+		t.Fatalf("forced target must refresh everything, got %+v err=%v", plan, err)
+	}
+
+	target.ForceFull = false
+	target.SkipTables = []string{"users"}
+	if names := dumpTableNames(target, dbService.TableList); strings.Join(names, ",") != "orders" {
+		t.Fatalf("dump must exclude skipped tables and views, got %v", names)
+	}
+	staging := stagingPlan("shop", (&models.IncrementalPlan{Full: []string{"orders", "users"}}).Without(target.SkipTables), plan)
+	if staging.Active() || strings.Join(staging.Full, ",") != "orders" {
+		t.Fatalf("unexpected staging plan: %+v", staging)
+	}
+}
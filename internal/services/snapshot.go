@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// snapshotMetadataLimit — сколько байт начала дампа mysqldump читать в поисках GTID_PURGED.
+const snapshotMetadataLimit = 1 << 20
+
+var (
+	mydumperBinlogFilePattern = regexp.MustCompile(`(?m)^\s*(?:Log:|File\s*=)\s*"?([^"\s]+)"?\s*$`)
+	mydumperBinlogPosPattern  = regexp.MustCompile(`(?m)^\s*(?:Pos:|Position\s*=)\s*"?(\d+)"?\s*$`)
+	mydumperGTIDPattern       = regexp.MustCompile(`(?m)^\s*(?:GTID:|Executed_Gtid_Set\s*=)\s*"?([^"\r\n]*)"?\s*$`)
+	mysqldumpGTIDPattern      = regexp.MustCompile(`(?s)SET @@GLOBAL\.GTID_PURGED=(?:/\*!80000 '\+'\*/ )?'([^']*)'`)
+)
+
+// snapshotRequested сообщает, нужен ли цели согласованный снимок: флаг цели или dump.consistent.
+func (s *MySQLShellService) snapshotRequested(target models.SyncTarget) bool {
+	return target.Consistent || s.config.Dump.Consistent
+}
+
+// snapshotLock выбирает блокировку, с которой движок engineName снимет согласованный дамп при
+// привилегиях grants. Если согласованный дамп снять нельзя, возвращается причина.
+func snapshotLock(engineName string, grants grantSet) (models.SnapshotLock, string) {
+	switch engineName {
+	case config.DumpEngineMysqldump:
+		return models.SnapshotLockTransaction, ""
+	case config.DumpEngineNative:
+		return "", "the native engine reads each table in its own transaction, without a snapshot shared across tables"
+	}
+	if global, _ := grants.has("RELOAD"); global {
+		return models.SnapshotLockFTWRL, ""
+	}
+	if global, _ := grants.has("BACKUP_ADMIN"); global {
+		// LOCK TABLES нужна только на выгружаемые схемы.
+		if global, scopes := grants.has("LOCK TABLES"); global || len(scopes) > 0 || engineName == config.DumpEngineMydumper {
+			return models.SnapshotLockBackup, ""
+		}
+	}
+	if engineName == config.DumpEngineMydumper {
+		return "", "source user has neither RELOAD nor BACKUP_ADMIN"
+	}
+	return "", "source user has neither RELOAD nor BACKUP_ADMIN with LOCK TABLES"
+}
+
+// planSnapshot решает до дампа, будет ли он согласованным. Без запроса согласованный снимок дают только
+// mysqldump (--single-transaction) и mydumper, который всегда блокирует инстанс; при запросе
+// блокировка выбирается по привилегиям источника, а если их нет — дамп идет без нее с предупреждением.
+func (s *MySQLShellService) planSnapshot(target models.SyncTarget, engineName string) *models.DumpSnapshot {
+	snapshot := &models.DumpSnapshot{Requested: s.snapshotRequested(target)}
+	if !snapshot.Requested {
+		switch engineName {
+		case config.DumpEngineMysqldump:
+			snapshot.Consistent, snapshot.Lock = true, models.SnapshotLockTransaction
+		case config.DumpEngineMydumper:
+			snapshot.Consistent = true
+		}
+		return snapshot
+	}
+
+	var grants grantSet
+	if engineName != config.DumpEngineMysqldump && engineName != config.DumpEngineNative {
+		settings, err := s.dbService.ServerSettings(true)
+		if err != nil {
+			snapshot.Warning = "failed to check source privileges: " + err.Error()
+			return snapshot
+		}
+		grants = parseGrants(settings.Grants)
+	}
+	snapshot.Lock, snapshot.Warning = snapshotLock(engineName, grants)
+	snapshot.Consistent = snapshot.Lock != ""
+	return snapshot
+}
+
+// warnSnapshot сообщает, что запрошенный согласованный дамп будет снят без блокировки.
+func (s *MySQLShellService) warnSnapshot(databaseName string, snapshot *models.DumpSnapshot) {
+	if snapshot.Requested && !snapshot.Consistent {
+		s.printStatusf("⚠️  %s: %s, dumping without a consistent snapshot\n", databaseName, snapshot.Warning)
+	}
+}
+
+// readDumpSnapshot дополняет согласованный снимок позицией binlog и GTID из метаданных дампа:
+// @.json у mysqlsh, metadata у mydumper и GTID_PURGED в начале файла mysqldump.
+func readDumpSnapshot(engineName string, dumpDir string, databaseName string, snapshot *models.DumpSnapshot) {
+	if !snapshot.Consistent {
+		return
+	}
+	switch engineName {
+	case config.DumpEngineMySQLShell:
+		data, err := os.ReadFile(filepath.Join(dumpDir, "@.json"))
+		if err != nil {
+			return
+		}
+		var metadata struct {
+			Consistent     *bool  `json:"consistent"`
+			GTIDExecuted   string `json:"gtidExecuted"`
+			BinlogFile     string `json:"binlogFile"`
+			BinlogPosition int64  `json:"binlogPosition"`
+		}
+		if json.Unmarshal(data, &metadata) != nil {
+			return
+		}
+		if metadata.Consistent != nil && !*metadata.Consistent {
+			snapshot.Consistent = false
+			snapshot.Warning = "mysqlsh could not lock the source"
+			return
+		}
+		snapshot.GTIDExecuted = metadata.GTIDExecuted
+		snapshot.BinlogFile = metadata.BinlogFile
+		snapshot.BinlogPosition = metadata.BinlogPosition
+	case config.DumpEngineMydumper:
+		data, err := os.ReadFile(filepath.Join(dumpDir, "metadata"))
+		if err != nil {
+			return
+		}
+		parseMydumperMetadata(string(data), snapshot)
+	case config.DumpEngineMysqldump:
+		file, err := os.Open(mysqldumpFilePath(dumpDir, databaseName))
+		if err != nil {
+			return
+		}
+		defer file.Close()
+		head, err := io.ReadAll(io.LimitReader(file, snapshotMetadataLimit))
+		if err != nil {
+			return
+		}
+		if match := mysqldumpGTIDPattern.FindSubmatch(head); match != nil {
+			snapshot.GTIDExecuted = normalizeGTIDSet(string(match[1]))
+		}
+	}
+}
+
+// parseMydumperMetadata читает позицию источника из metadata mydumper: старого формата
+// (SHOW MASTER STATUS: Log/Pos/GTID) и ini-формата 0.12+ (File/Position/Executed_Gtid_Set).
+// Первая секция описывает сам источник, следующие — его каналы репликации.
+func parseMydumperMetadata(metadata string, snapshot *models.DumpSnapshot) {
+	if match := mydumperBinlogFilePattern.FindStringSubmatch(metadata); match != nil {
+		snapshot.BinlogFile = match[1]
+	}
+	if match := mydumperBinlogPosPattern.FindStringSubmatch(metadata); match != nil {
+		snapshot.BinlogPosition, _ = strconv.ParseInt(match[1], 10, 64)
+	}
+	if match := mydumperGTIDPattern.FindStringSubmatch(metadata); match != nil {
+		snapshot.GTIDExecuted = normalizeGTIDSet(match[1])
+	}
+}
+
+// normalizeGTIDSet убирает переводы строк и пробелы, которыми MySQL разбивает длинные GTID-наборы.
+func normalizeGTIDSet(gtidSet string) string {
+	return strings.Join(strings.Fields(gtidSet), "")
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func TestSnapshotLock(t *testing.T) {
+	tests := []struct {
+		name   string
+		engine string
+		grants []string
+		want   models.SnapshotLock
+	}{
+		{"reload", config.DumpEngineMySQLShell, []string{"GRANT SELECT, RELOAD ON *.* TO `reader`@`%`"}, models.SnapshotLockFTWRL},
+		{"backup lock", config.DumpEngineMySQLShell, []string{"GRANT SELECT ON *.* TO `reader`@`%`", "GRANT BACKUP_ADMIN ON *.* TO `reader`@`%`", "GRANT LOCK TABLES ON `shop`.* TO `reader`@`%`"}, models.SnapshotLockBackup},
+		{"backup lock without lock tables", config.DumpEngineMySQLShell, []string{"GRANT SELECT ON *.* TO `reader`@`%`", "GRANT BACKUP_ADMIN ON *.* TO `reader`@`%`"}, ""},
+		{"no privileges", config.DumpEngineMySQLShell, []string{"GRANT SELECT ON *.* TO `reader`@`%`"}, ""},
+		{"mydumper backup lock", config.DumpEngineMydumper, []string{"GRANT BACKUP_ADMIN ON *.* TO `reader`@`%`"}, models.SnapshotLockBackup},
+		{"mysqldump", config.DumpEngineMysqldump, nil, models.SnapshotLockTransaction},
+		{"native", config.DumpEngineNative, []string{"GRANT ALL PRIVILEGES ON *.* TO `reader`@`%`"}, ""},
+	}
+	for _, tt := range tests {
+		lock, reason := snapshotLock(tt.engine, parseGrants(tt.grants))
+		if lock != tt.want || (lock == "") == (reason == "") {
+			t.Errorf("%s: snapshotLock() = %q, %q; want %q", tt.name, lock, reason, tt.want)
+		}
+	}
+}
+
+func TestPlanSnapshot(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{RemoteSettings: &models.ServerSettings{Grants: []string{"GRANT SELECT ON *.* TO `reader`@`%`"}}}
+	service := NewMySQLShellService(&config.Config{}, dbService)
+	target := models.SyncTarget{DatabaseName: "shop"}
+
+	if snapshot := service.planSnapshot(target, config.DumpEngineMySQLShell); snapshot.Requested || snapshot.Consistent {
+		t.Fatalf("mysqlsh dumps are not consistent by default, got %+v", snapshot)
+	}
+	if snapshot := service.planSnapshot(target, config.DumpEngineMysqldump); !snapshot.Consistent || snapshot.Lock != models.SnapshotLockTransaction {
+		t.Fatalf("mysqldump always uses --single-transaction, got %+v", snapshot)
+	}
+
+	target.Consistent = true
+	snapshot := service.planSnapshot(target, config.DumpEngineMySQLShell)
+	if !snapshot.Requested || snapshot.Consistent || !strings.Contains(snapshot.Warning, "neither RELOAD") {
+		t.Fatalf("missing privileges must fall back with a warning, got %+v", snapshot)
+	}
+
+	dbService.RemoteSettings.Grants = append(dbService.RemoteSettings.Grants, "GRANT RELOAD ON *.* TO `reader`@`%`")
+	if snapshot := service.planSnapshot(target, config.DumpEngineMySQLShell); !snapshot.Consistent || snapshot.Lock != models.SnapshotLockFTWRL {
+		t.Fatalf("RELOAD must allow FTWRL, got %+v", snapshot)
+	}
+
+	dbService.ServerSettingsError = errors.New("access denied")
+	if snapshot := service.planSnapshot(target, config.DumpEngineMySQLShell); snapshot.Consistent || !strings.Contains(snapshot.Warning, "access denied") {
+		t.Fatalf("privilege check error must fall back with a warning, got %+v", snapshot)
+	}
+}
+
+func TestMySQLShellConsistentArgs(t *testing.T) {
+	service := NewMySQLShellService(&config.Config{}, nil)
+	request := DumpRequest{DatabaseName: "shop", Dir: "/tmp/dump"}
+
+	joined := strings.Join(service.mysqlShellDumpArgs("mysql://reader@127.0.0.1:3306", request), " ")
+	if !strings.Contains(joined, "--consistent=false --skipConsistencyChecks") {
+		t.Fatalf("inconsistent dump must skip consistency checks: %s", joined)
+	}
+	request.Consistent = true
+	joined = strings.Join(service.mysqlShellDumpArgs("mysql://reader@127.0.0.1:3306", request), " ")
+	if !strings.Contains(joined, "--consistent=true") || strings.Contains(joined, "--skipConsistencyChecks") {
+		t.Fatalf("consistent dump must keep consistency checks: %s", joined)
+	}
+}
+
+func TestReadDumpSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	metadata := `{"dumper": "mysqlsh Ver 8.4.3", "consistent": true, "binlogFile": "binlog.000042", "binlogPosition": 1337, "gtidExecuted": "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"}`
+	if err := os.WriteFile(filepath.Join(dir, "@.json"), []byte(metadata), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := &models.DumpSnapshot{Requested: true, Consistent: true, Lock: models.SnapshotLockFTWRL}
+	readDumpSnapshot(config.DumpEngineMySQLShell, dir, "shop", snapshot)
+	if snapshot.GTIDExecuted != "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5" || snapshot.BinlogFile != "binlog.000042" || snapshot.BinlogPosition != 1337 {
+		t.Fatalf("unexpected mysqlsh snapshot %+v", snapshot)
+	}
+
+	dump := "-- MySQL dump 10.13\nSET @@GLOBAL.GTID_PURGED=/*!80000 '+'*/ '3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,\n4e11fa47-71ca-11e1-9e33-c80aa9429562:1-7';\n"
+	if err := os.WriteFile(mysqldumpFilePath(dir, "shop"), []byte(dump), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	snapshot = &models.DumpSnapshot{Consistent: true, Lock: models.SnapshotLockTransaction}
+	readDumpSnapshot(config.DumpEngineMysqldump, dir, "shop", snapshot)
+	if snapshot.GTIDExecuted != "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,4e11fa47-71ca-11e1-9e33-c80aa9429562:1-7" {
+		t.Fatalf("unexpected mysqldump GTID %q", snapshot.GTIDExecuted)
+	}
+}
+
+func TestParseMydumperMetadata(t *testing.T) {
+	legacy := "Started dump at: 2024-01-01 10:00:00\nSHOW MASTER STATUS:\n\tLog: mysql-bin.000007\n\tPos: 4242\n\tGTID:3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5\n\nFinished dump at: 2024-01-01 10:01:00\n"
+	current := "# Started dump at: 2024-01-01 10:00:00\n[source]\n# Channel_Name = ''\nFile = mysql-bin.000007\nPosition = 4242\nExecuted_Gtid_Set = 3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5\n"
+	for name, metadata := range map[string]string{"legacy": legacy, "current": current} {
+		snapshot := &models.DumpSnapshot{Consistent: true}
+		parseMydumperMetadata(metadata, snapshot)
+		if snapshot.BinlogFile != "mysql-bin.000007" || snapshot.BinlogPosition != 4242 || snapshot.GTIDExecuted != "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5" {
+			t.Errorf("%s metadata: unexpected snapshot %+v", name, snapshot)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+// archiveExtension — расширение архивов dbsync export, в том числе в бакете.
+const archiveExtension = ".dbsync"
+
+// UploadArchive загружает архив dbsync export в бакет как <prefix>/<db>/<db>_<время>.dbsync.
+func (s *MySQLShellService) UploadArchive(archivePath string, manifest *models.ArchiveManifest, observer models.ProgressObserver) (*models.StorageObject, error) {
+	storage, err := newObjectStorage(s.config.Storage)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	key := storage.objectKey(manifest.DatabaseName, fmt.Sprintf("%s_%s%s", manifest.DatabaseName, manifest.CreatedAt.UTC().Format(backupTimestampLayout), archiveExtension))
+	s.printStatusf("☁️  Uploading to %s...", storage.describe(key))
+	progress := s.archiveProgress(models.SyncPhaseDump, manifest.DatabaseName, "Uploading archive", observer)
+	if err := storage.Put(s.runContext(), key, file, info.Size(), progress); err != nil {
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+	s.printStatusf("\r✅ Uploaded %s (%s)                    \n", storage.describe(key), FormatSize(info.Size()))
+	return &models.StorageObject{Key: key, Size: info.Size(), LastModified: time.Now()}, nil
+}
+
+// ListBucketArchives возвращает архивы БД в бакете, начиная с самого нового.
+func (s *MySQLShellService) ListBucketArchives(databaseName string) ([]models.StorageObject, error) {
+	storage, err := newObjectStorage(s.config.Storage)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := storage.List(s.runContext(), storage.objectKey(databaseName)+"/")
+	if err != nil {
+		return nil, err
+	}
+	archives := make([]models.StorageObject, 0, len(objects))
+	for _, object := range objects {
+		// Во вложенных каталогах лежат архивы других БД с тем же префиксом имени.
+		if strings.HasSuffix(object.Key, archiveExtension) && path.Dir(object.Key) == storage.objectKey(databaseName) {
+			archives = append(archives, object)
+		}
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		if !archives[i].LastModified.Equal(archives[j].LastModified) {
+			return archives[i].LastModified.After(archives[j].LastModified)
+		}
+		return archives[i].Key > archives[j].Key
+	})
+	return archives, nil
+}
+
+// PullFromBucket скачивает архив БД из бакета и импортирует его в локальную БД asName.
+// Пустой key означает самый новый архив БД, пустой asName — исходное имя БД.
+func (s *MySQLShellService) PullFromBucket(databaseName string, key string, asName string, observer models.ProgressObserver) (*models.ArchiveManifest, error) {
+	if asName == "" {
+		asName = databaseName
+	}
+	// Политика проверяется до скачивания; prepareLocalDatabase повторит проверку перед DROP.
+	if err := s.checkDestinationPolicy(asName); err != nil {
+		return nil, err
+	}
+	storage, err := newObjectStorage(s.config.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		archives, err := s.ListBucketArchives(databaseName)
+		if err != nil {
+			return nil, err
+		}
+		if len(archives) == 0 {
+			return nil, fmt.Errorf("no archives of '%s' found in %s", databaseName, storage.describe(storage.objectKey(databaseName)+"/"))
+		}
+		key = archives[0].Key
+	}
+
+	path, file, err := s.createWorkTemp("dbsync_pull_*"+archiveExtension, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create download file: %w", err)
+	}
+	defer removeWorkDir(path)
+	defer file.Close()
+
+	s.printStatusf("☁️  Downloading %s...", storage.describe(key))
+	progress := s.archiveProgress(models.SyncPhaseDump, databaseName, "Downloading archive", observer)
+	size, err := storage.Get(s.runContext(), key, file, progress)
+	if err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("download failed: %w", err)
+	}
+	s.printStatusf("\r✅ Downloaded %s (%s)                    \n", storage.describe(key), FormatSize(size))
+	return s.ImportArchive(file.Name(), asName, observer)
+}
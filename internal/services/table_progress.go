@@ -0,0 +1,445 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+const (
+	tableProgressPollInterval = 500 * time.Millisecond
+	loadProgressFileName      = "load-progress.dbsync.json"
+)
+
+// tableProgressEntry хранит состояние таблицы вместе с временными метками фаз.
+type tableProgressEntry struct {
+	progress       models.TableProgress
+	dumpStartedAt  time.Time
+	dumpFinishedAt time.Time
+	loadStartedAt  time.Time
+	loadFinishedAt time.Time
+	lastDataDoneAt time.Time
+	finalChunkSeen bool
+}
+
+// tableProgressTracker собирает per-table прогресс из файлов дампа и load-progress mysqlsh.
+type tableProgressTracker struct {
+	mu           sync.Mutex
+	databaseName string
+	order        []string
+	entries      map[string]*tableProgressEntry
+	current      string
+	loadOffset   int64
+	// loadSchema — схема, в которую load-dump грузит данные, если она отличается от databaseName.
+	loadSchema string
+}
+
+type mysqlShellLoadProgressEntry struct {
+	Op       string `json:"op"`
+	Done     bool   `json:"done"`
+	Schema   string `json:"schema"`
+	Table    string `json:"table"`
+	Bytes    int64  `json:"bytes"`
+	RawBytes int64  `json:"raw_bytes"`
+	Rows     int64  `json:"rows"`
+}
+
+func newTableProgressTracker(databaseName string, tables []models.Table) *tableProgressTracker {
+	tracker := &tableProgressTracker{
+		databaseName: databaseName,
+		entries:      make(map[string]*tableProgressEntry, len(tables)),
+	}
+	for _, table := range tables {
+		entry := tracker.ensure(table.Name)
+		entry.progress.SourceBytes = table.DataSize
+		entry.progress.RowsTotal = table.Rows
+		entry.progress.RowsApprox = table.RowsApprox
+		entry.progress.View = isViewTable(table)
+	}
+	return tracker
+}
+
+func (t *tableProgressTracker) ensure(tableName string) *tableProgressEntry {
+	entry, ok := t.entries[tableName]
+	if ok {
+		return entry
+	}
+	entry = &tableProgressEntry{progress: models.TableProgress{Name: tableName, State: models.TableStatePending}}
+	t.entries[tableName] = entry
+	t.order = append(t.order, tableName)
+	return entry
+}
+
+// CurrentTable возвращает таблицу, по которой последней пришло изменение.
+func (t *tableProgressTracker) CurrentTable() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current
+}
+
+// Snapshot возвращает копию per-table состояния в исходном порядке.
+func (t *tableProgressTracker) Snapshot(now time.Time) []models.TableProgress {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tables := make([]models.TableProgress, 0, len(t.order))
+	for _, name := range t.order {
+		entry := t.entries[name]
+		progress := entry.progress
+		progress.DumpDuration = phaseDuration(entry.dumpStartedAt, entry.dumpFinishedAt, now)
+		progress.LoadDuration = phaseDuration(entry.loadStartedAt, entry.loadFinishedAt, now)
+		tables = append(tables, progress)
+	}
+	return tables
+}
+
+func phaseDuration(startedAt time.Time, finishedAt time.Time, now time.Time) time.Duration {
+	if startedAt.IsZero() {
+		return 0
+	}
+	if finishedAt.IsZero() {
+		finishedAt = now
+	}
+	if finishedAt.Before(startedAt) {
+		return 0
+	}
+	return finishedAt.Sub(startedAt)
+}
+
+// ObserveDumpDir сканирует директорию дампа и обновляет состояние таблиц по chunk-файлам.
+func (t *tableProgressTracker) ObserveDumpDir(dumpDir string, now time.Time) bool {
+	if t == nil || dumpDir == "" {
+		return false
+	}
+	entries, err := os.ReadDir(dumpDir)
+	if err != nil {
+		return false
+	}
+
+	type dumpFileStats struct {
+		bytes    int64
+		final    bool
+		finished bool
+	}
+	stats := make(map[string]*dumpFileStats)
+	for _, dirEntry := range entries {
+		if dirEntry.IsDir() {
+			continue
+		}
+		file, ok := parseDumpDataFileName(t.databaseName, dirEntry.Name())
+		if !ok {
+			continue
+		}
+		current := stats[file.table]
+		if current == nil {
+			current = &dumpFileStats{}
+			stats[file.table] = current
+		}
+		if file.index {
+			if file.final {
+				current.finished = true
+			}
+			continue
+		}
+		if file.final {
+			current.final = true
+		}
+		if info, err := dirEntry.Info(); err == nil {
+			current.bytes += info.Size()
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	changed := false
+	for tableName, stat := range stats {
+		entry := t.ensure(tableName)
+		if entry.dumpStartedAt.IsZero() {
+			entry.dumpStartedAt = now
+			entry.progress.State = models.TableStateDumping
+			t.current = tableName
+			changed = true
+		}
+		if stat.bytes != entry.progress.DumpBytes {
+			entry.progress.DumpBytes = stat.bytes
+			t.current = tableName
+			changed = true
+		}
+		entry.finalChunkSeen = entry.finalChunkSeen || stat.final
+		if stat.finished && entry.dumpFinishedAt.IsZero() {
+			entry.dumpFinishedAt = now
+			entry.progress.State = models.TableStateDumped
+			changed = true
+		}
+	}
+	return changed
+}
+
+// ObserveDumpChunk учитывает записанный native движком chunk таблицы; done отмечает конец выгрузки таблицы.
+func (t *tableProgressTracker) ObserveDumpChunk(tableName string, bytes int64, done bool, now time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry := t.ensure(tableName)
+	if entry.dumpStartedAt.IsZero() {
+		entry.dumpStartedAt = now
+		entry.progress.State = models.TableStateDumping
+	}
+	entry.progress.DumpBytes += bytes
+	if done {
+		entry.dumpFinishedAt = now
+		entry.progress.State = models.TableStateDumped
+	}
+	t.current = tableName
+}
+
+// FinishDump отмечает все таблицы выгруженными после успешного завершения mysqlsh dump.
+func (t *tableProgressTracker) FinishDump(now time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, name := range t.order {
+		entry := t.entries[name]
+		if entry.dumpStartedAt.IsZero() {
+			entry.dumpStartedAt = now
+		}
+		if entry.dumpFinishedAt.IsZero() {
+			entry.dumpFinishedAt = now
+		}
+		entry.progress.State = models.TableStateDumped
+	}
+	t.current = ""
+}
+
+// SetLoadSchema учитывает записи load-progress для схемы, в которую дамп грузится под другим именем.
+func (t *tableProgressTracker) SetLoadSchema(schema string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.loadSchema = schema
+}
+
+// ObserveLoadProgress дочитывает progress-файл mysqlsh load-dump и обновляет состояние таблиц.
+func (t *tableProgressTracker) ObserveLoadProgress(progressFile string, now time.Time) bool {
+	if t == nil || progressFile == "" {
+		return false
+	}
+	file, err := os.Open(progressFile)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	t.mu.Lock()
+	offset := t.loadOffset
+	t.mu.Unlock()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return false
+	}
+	data, err := io.ReadAll(file)
+	if err != nil || len(data) == 0 {
+		return false
+	}
+	// Незавершенную последнюю строку дочитаем на следующем тике.
+	complete := bytes.LastIndexByte(data, '\n')
+	if complete < 0 {
+		return false
+	}
+	data = data[:complete+1]
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.loadOffset = offset + int64(len(data))
+	changed := false
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var record mysqlShellLoadProgressEntry
+		if err := json.Unmarshal(line, &record); err != nil {
+			continue
+		}
+		if t.applyLoadRecord(record, now) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (t *tableProgressTracker) applyLoadRecord(record mysqlShellLoadProgressEntry, now time.Time) bool {
+	if record.Table == "" || (record.Schema != "" && record.Schema != t.databaseName && record.Schema != t.loadSchema) {
+		return false
+	}
+	op := strings.ToUpper(record.Op)
+	entry := t.ensure(record.Table)
+	switch {
+	case strings.HasPrefix(op, "TABLE-DATA"):
+		if entry.loadStartedAt.IsZero() {
+			entry.loadStartedAt = now
+		}
+		if entry.progress.State != models.TableStateIndexing && entry.progress.State != models.TableStateDone {
+			entry.progress.State = models.TableStateLoading
+		}
+		if record.Done {
+			loaded := record.RawBytes
+			if loaded == 0 {
+				loaded = record.Bytes
+			}
+			entry.progress.LoadedBytes += loaded
+			entry.progress.Rows += record.Rows
+			entry.lastDataDoneAt = now
+		}
+	case strings.HasPrefix(op, "TABLE-INDEX"):
+		if entry.loadStartedAt.IsZero() {
+			entry.loadStartedAt = now
+		}
+		if record.Done {
+			entry.progress.State = models.TableStateDone
+			entry.loadFinishedAt = now
+		} else {
+			entry.progress.State = models.TableStateIndexing
+		}
+	default:
+		return false
+	}
+	t.current = record.Table
+	return true
+}
+
+// ObserveLoadChunk учитывает загруженный native движком chunk таблицы; done отмечает конец загрузки таблицы.
+func (t *tableProgressTracker) ObserveLoadChunk(tableName string, bytes int64, rows int64, done bool, now time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry := t.ensure(tableName)
+	if entry.loadStartedAt.IsZero() {
+		entry.loadStartedAt = now
+		entry.progress.State = models.TableStateLoading
+	}
+	entry.progress.LoadedBytes += bytes
+	entry.progress.Rows += rows
+	if bytes > 0 {
+		entry.lastDataDoneAt = now
+	}
+	if done {
+		entry.loadFinishedAt = now
+		entry.progress.State = models.TableStateDone
+	}
+	t.current = tableName
+}
+
+// FinishLoad отмечает все таблицы восстановленными после успешного load-dump.
+func (t *tableProgressTracker) FinishLoad(now time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, name := range t.order {
+		entry := t.entries[name]
+		if entry.loadStartedAt.IsZero() {
+			entry.loadStartedAt = now
+		}
+		if entry.loadFinishedAt.IsZero() {
+			entry.loadFinishedAt = now
+			if !entry.lastDataDoneAt.IsZero() {
+				entry.loadFinishedAt = entry.lastDataDoneAt
+			}
+		}
+		entry.progress.State = models.TableStateDone
+	}
+	t.current = ""
+}
+
+type dumpDataFile struct {
+	table string
+	final bool
+	index bool
+}
+
+// parseDumpDataFileName разбирает имя data-файла mysqlsh вида schema@table@@0.tsv.zst.
+func parseDumpDataFileName(databaseName string, fileName string) (dumpDataFile, bool) {
+	rawSchema, rest, ok := strings.Cut(fileName, "@")
+	if !ok || rawSchema == "" {
+		return dumpDataFile{}, false
+	}
+	if schema, err := url.PathUnescape(rawSchema); err != nil || schema != databaseName {
+		return dumpDataFile{}, false
+	}
+	if !strings.Contains(rest, ".tsv") && !strings.Contains(rest, ".csv") && !strings.Contains(rest, ".txt") {
+		return dumpDataFile{}, false
+	}
+
+	file := dumpDataFile{index: strings.HasSuffix(rest, ".idx")}
+	rawTable := rest
+	if before, _, found := strings.Cut(rest, "@@"); found {
+		rawTable = before
+		file.final = true
+	} else if before, _, found := strings.Cut(rest, "@"); found {
+		rawTable = before
+	} else {
+		rawTable, _, _ = strings.Cut(rest, ".")
+		// Таблица без чанков пишется одним файлом, он же последний.
+		file.final = true
+	}
+	table, err := url.PathUnescape(rawTable)
+	if err != nil || table == "" {
+		return dumpDataFile{}, false
+	}
+	file.table = table
+	return file, true
+}
+
+// emitTableProgressSnapshots периодически опрашивает источник per-table прогресса и шлет snapshots.
+func emitTableProgressSnapshots(stop <-chan struct{}, phase models.SyncPhase, databaseName string, tracker *tableProgressTracker, poll func(time.Time) bool, observer models.ProgressObserver) {
+	if tracker == nil || poll == nil || observer == nil {
+		return
+	}
+
+	ticker := time.NewTicker(tableProgressPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if !poll(now) {
+				continue
+			}
+			observer(models.ProgressSnapshot{
+				Phase:        phase,
+				DatabaseName: databaseName,
+				TableName:    tracker.CurrentTable(),
+				Tables:       tracker.Snapshot(now),
+				Timestamp:    now,
+			})
+		}
+	}
+}
+
+func loadProgressFilePath(dumpDir string) string {
+	return filepath.Join(dumpDir, loadProgressFileName)
+}
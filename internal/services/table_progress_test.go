@@ -0,0 +1,124 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/models"
+)
+
+func TestParseDumpDataFileName(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileName  string
+		wantOK    bool
+		wantTable string
+		wantFinal bool
+		wantIndex bool
+	}{
+		{name: "chunk", fileName: "shop@orders@3.tsv.zst", wantOK: true, wantTable: "orders"},
+		{name: "final chunk", fileName: "shop@orders@@4.tsv.zst", wantOK: true, wantTable: "orders", wantFinal: true},
+		{name: "final chunk index", fileName: "shop@orders@@4.tsv.zst.idx", wantOK: true, wantTable: "orders", wantFinal: true, wantIndex: true},
+		{name: "non chunked table", fileName: "shop@users.tsv.zst", wantOK: true, wantTable: "users", wantFinal: true},
+		{name: "escaped table name", fileName: "shop@order%40items@0.tsv", wantOK: true, wantTable: "order@items"},
+		{name: "table metadata", fileName: "shop@orders.json", wantOK: false},
+		{name: "other schema", fileName: "other@orders@0.tsv.zst", wantOK: false},
+		{name: "global metadata", fileName: "@.done.json", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, ok := parseDumpDataFileName("shop", tt.fileName)
+			if ok != tt.wantOK {
+				t.Fatalf("parseDumpDataFileName() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if file.table != tt.wantTable || file.final != tt.wantFinal || file.index != tt.wantIndex {
+				t.Fatalf("parseDumpDataFileName() = %+v, want table=%s final=%v index=%v", file, tt.wantTable, tt.wantFinal, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestTableProgressTrackerObserveDumpDir(t *testing.T) {
+	dumpDir := t.TempDir()
+	tracker := newTableProgressTracker("shop", []models.Table{{Name: "orders", DataSize: 4096, Rows: 10}, {Name: "users", DataSize: 1024}})
+	start := time.Unix(100, 0)
+
+	writeDumpFile(t, dumpDir, "shop@orders@0.tsv.zst", 300)
+	if !tracker.ObserveDumpDir(dumpDir, start) {
+		t.Fatal("expected first chunk to change tracker state")
+	}
+	if tracker.ObserveDumpDir(dumpDir, start.Add(time.Second)) {
+		t.Fatal("expected unchanged dump dir to be a no-op")
+	}
+
+	writeDumpFile(t, dumpDir, "shop@orders@@1.tsv.zst", 200)
+	writeDumpFile(t, dumpDir, "shop@orders@@1.tsv.zst.idx", 8)
+	tracker.ObserveDumpDir(dumpDir, start.Add(4*time.Second))
+
+	tables := tracker.Snapshot(start.Add(5 * time.Second))
+	if len(tables) != 2 {
+		t.Fatalf("expected 2 tables, got %d", len(tables))
+	}
+	if tables[0].State != models.TableStateDumped || tables[0].DumpBytes != 500 || tables[0].DumpDuration != 4*time.Second {
+		t.Fatalf("unexpected orders progress: %+v", tables[0])
+	}
+	if tables[1].State != models.TableStatePending || tables[1].SourceBytes != 1024 {
+		t.Fatalf("unexpected users progress: %+v", tables[1])
+	}
+}
+
+func TestTableProgressTrackerObserveLoadProgress(t *testing.T) {
+	progressFile := filepath.Join(t.TempDir(), loadProgressFileName)
+	tracker := newTableProgressTracker("shop", []models.Table{{Name: "orders"}})
+	start := time.Unix(200, 0)
+
+	appendProgressLines(t, progressFile,
+		`{"op":"TABLE-DATA","done":false,"schema":"shop","table":"orders","chunk":0}`+"\n",
+		`{"op":"TABLE-DATA","done":true,"schema":"shop","table":"orders","chunk":0,"bytes":100,"raw_bytes":400,"rows":25}`+"\n",
+		`{"op":"TABLE-INDEX","done":false,"schema":"shop","tab`,
+	)
+	if !tracker.ObserveLoadProgress(progressFile, start) {
+		t.Fatal("expected load progress to change tracker state")
+	}
+	tables := tracker.Snapshot(start)
+	if tables[0].State != models.TableStateLoading || tables[0].LoadedBytes != 400 || tables[0].Rows != 25 {
+		t.Fatalf("unexpected progress after data chunk: %+v", tables[0])
+	}
+
+	appendProgressLines(t, progressFile,
+		`le":"orders"}`+"\n",
+		`{"op":"TABLE-INDEX","done":true,"schema":"shop","table":"orders"}`+"\n",
+	)
+	tracker.ObserveLoadProgress(progressFile, start.Add(3*time.Second))
+	tables = tracker.Snapshot(start.Add(10 * time.Second))
+	if tables[0].State != models.TableStateDone || tables[0].LoadDuration != 3*time.Second || tables[0].Rows != 25 {
+		t.Fatalf("unexpected progress after index rebuild: %+v", tables[0])
+	}
+}
+
+func writeDumpFile(t *testing.T, dir string, name string, size int) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0o644); err != nil {
+		t.Fatalf("write dump file: %v", err)
+	}
+}
+
+func appendProgressLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatalf("open progress file: %v", err)
+	}
+	defer file.Close()
+	for _, line := range lines {
+		if _, err := file.WriteString(line); err != nil {
+			t.Fatalf("write progress file: %v", err)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// tuningJournalPrefix — журнал исходных значений переменных в рабочей директории. Если dbsync убит
+// посреди загрузки, следующая сессия тюнинга сначала возвращает значения из журнала.
+const tuningJournalPrefix = "dbsync_tuning_"
+
+// redoLogVariable — redo log InnoDB: переключается ALTER INSTANCE, состояние читается из статуса
+// Innodb_redo_log_enabled (MySQL 8.0.21+).
+const redoLogVariable = "innodb_redo_log"
+
+// tunedVariables — глобальные переменные, которые может менять сессия тюнинга. Переменных, которых
+// нет на сервере (innodb_ddl_buffer_size до 8.0.27), сессия не трогает.
+var tunedVariables = []string{
+	"local_infile",
+	redoLogVariable,
+	"innodb_flush_log_at_trx_commit",
+	"innodb_log_buffer_size",
+	"innodb_ddl_buffer_size",
+}
+
+var tuningValuePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// tuningSetting — значение, которое сессия тюнинга выставляет на время загрузки.
+type tuningSetting struct {
+	name  string
+	value string
+	// required: без настройки загрузка не пройдет, ошибка ее применения прерывает загрузку.
+	required bool
+}
+
+// tuningJournal хранит исходные значения измененных переменных до их восстановления.
+type tuningJournal struct {
+	PID       int                     `json:"pid"`
+	Server    string                  `json:"server"`
+	Variables []models.ServerVariable `json:"variables"`
+}
+
+// tuningSession — глобальные переменные локального MySQL, измененные на время загрузки.
+type tuningSession struct {
+	s         *MySQLShellService
+	db        *sql.DB
+	cleanup   func()
+	journal   string
+	variables []models.ServerVariable
+}
+
+// tuningPlan выбирает переменные для изменения по tuning.mode, движку загрузки и текущим значениям.
+// local_infile включается всегда, когда движок грузит через LOAD DATA LOCAL; mysqlsh без него не
+// работает, native переходит на INSERT. Остальное меняется только в режиме fast.
+func tuningPlan(cfg config.TuningConfig, engineName string, current map[string]string) []tuningSetting {
+	var settings []tuningSetting
+	want := func(name string, value string, required bool) {
+		if currentValue, ok := current[name]; ok && !strings.EqualFold(currentValue, value) {
+			settings = append(settings, tuningSetting{name: name, value: value, required: required})
+		}
+	}
+	switch engineName {
+	case config.DumpEngineMySQLShell:
+		want("local_infile", "ON", true)
+	case config.DumpEngineNative:
+		want("local_infile", "ON", false)
+	}
+	if cfg.Mode != config.TuningModeFast {
+		return settings
+	}
+	want(redoLogVariable, "OFF", false)
+	want("innodb_flush_log_at_trx_commit", "2", false)
+	bufferSize := int64(cfg.BufferSizeMB) * 1024 * 1024
+	for _, name := range []string{"innodb_log_buffer_size", "innodb_ddl_buffer_size"} {
+		// Буферы только увеличиваются: уменьшать настроенный разработчиком сервер незачем.
+		if size, err := strconv.ParseInt(current[name], 10, 64); err == nil && size < bufferSize {
+			settings = append(settings, tuningSetting{name: name, value: strconv.FormatInt(bufferSize, 10)})
+		}
+	}
+	return settings
+}
+
+// tuningStatement строит SQL, выставляющий переменной значение. Имена и значения из журнала
+// проверяются, чтобы поврежденный файл не превратился в произвольный SQL.
+func tuningStatement(name string, value string) (string, error) {
+	if !slices.Contains(tunedVariables, name) || !tuningValuePattern.MatchString(value) {
+		return "", fmt.Errorf("unexpected server variable %s=%q", name, value)
+	}
+	if name == redoLogVariable {
+		if strings.EqualFold(value, "OFF") {
+			return "ALTER INSTANCE DISABLE INNODB REDO_LOG", nil
+		}
+		return "ALTER INSTANCE ENABLE INNODB REDO_LOG", nil
+	}
+	return fmt.Sprintf("SET GLOBAL %s = %s", name, value), nil
+}
+
+func applyTuning(ctx context.Context, db *sql.DB, name string, value string) error {
+	statement, err := tuningStatement(name, value)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, statement)
+	return err
+}
+
+// readTuningVariables возвращает текущие значения tunedVariables, которые есть на сервере.
+func readTuningVariables(ctx context.Context, db *sql.DB) (map[string]string, error) {
+	names := make([]string, 0, len(tunedVariables))
+	for _, name := range tunedVariables {
+		if name != redoLogVariable {
+			names = append(names, "'"+name+"'")
+		}
+	}
+	rows, err := db.QueryContext(ctx, "SHOW GLOBAL VARIABLES WHERE Variable_name IN ("+strings.Join(names, ", ")+")")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	current := make(map[string]string, len(tunedVariables))
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		current[strings.ToLower(name)] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var name, value string
+	err = db.QueryRowContext(ctx, "SHOW GLOBAL STATUS LIKE 'Innodb_redo_log_enabled'").Scan(&name, &value)
+	if err == nil {
+		current[redoLogVariable] = value
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return current, nil
+}
+
+// tuningJournalPath возвращает путь журнала для локального сервера сервиса.
+func (s *MySQLShellService) tuningJournalPath() string {
+	server := syncProfileUnsafe.ReplaceAllString(fmt.Sprintf("%s_%d", s.config.Local.Host, s.config.Local.Port), "_")
+	return filepath.Join(s.config.Dump.ResolvedWorkDir(), tuningJournalPrefix+server+".json")
+}
+
+// withLoadTuning выполняет load в сессии тюнинга. Исходные значения возвращаются и после ошибки
+// загрузки, и после отмены синхронизации; результат перечисляет измененные и восстановленные переменные.
+func (s *MySQLShellService) withLoadTuning(engineName string, load func() error) (variables []models.ServerVariable, err error) {
+	session, err := s.beginTuning(engineName)
+	if err != nil {
+		return nil, fmt.Errorf("server tuning failed: %w", err)
+	}
+	defer func() {
+		variables = session.restore()
+	}()
+	return nil, load()
+}
+
+// beginTuning запоминает исходные значения переменных локального MySQL, записывает их в журнал
+// и применяет настройки загрузки для движка engineName.
+func (s *MySQLShellService) beginTuning(engineName string) (*tuningSession, error) {
+	db, cleanup, err := openMySQLConnection(s.config.Local, "")
+	if err != nil {
+		return nil, err
+	}
+	session := &tuningSession{s: s, db: db, cleanup: cleanup, journal: s.tuningJournalPath()}
+	ctx := s.runContext()
+	owner, err := session.recover()
+	if err != nil {
+		session.close()
+		return nil, err
+	}
+	if owner != 0 {
+		// Сервер уже настроен другой загрузкой: ее исходные значения вернет она сама.
+		s.printStatusf("⚠️  Local MySQL is tuned by another dbsync run (pid %d), leaving server variables as is\n", owner)
+		session.journal = ""
+		return session, nil
+	}
+
+	current, err := readTuningVariables(ctx, db)
+	if err != nil {
+		session.close()
+		return nil, fmt.Errorf("failed to read server variables: %w", err)
+	}
+	for _, setting := range tuningPlan(s.config.Tuning, engineName, current) {
+		variable := models.ServerVariable{Name: setting.name, Original: current[setting.name], Applied: setting.value}
+		// Журнал пишется до изменения: значение вернется, даже если процесс умрет сразу после SET GLOBAL.
+		if err := session.writeJournal(append(session.variables, variable)); err != nil {
+			session.restore()
+			return nil, err
+		}
+		if err := applyTuning(ctx, db, setting.name, setting.value); err != nil {
+			if setting.required {
+				session.restore()
+				return nil, fmt.Errorf("failed to enable %s: %w", setting.name, err)
+			}
+			s.printStatusf("⚠️  Skipping %s=%s: %v\n", setting.name, setting.value, err)
+			continue
+		}
+		session.variables = append(session.variables, variable)
+	}
+	if len(session.variables) == 0 {
+		os.Remove(session.journal)
+		return session, nil
+	}
+	if err := session.writeJournal(session.variables); err != nil {
+		session.restore()
+		return nil, err
+	}
+	s.printStatusf("🔧 Tuned local MySQL for load: %s\n", formatServerVariables(session.variables, false))
+	return session, nil
+}
+
+// recover возвращает значения, оставленные сессией процесса, который завершился до восстановления.
+// Если журнал принадлежит работающему процессу, возвращается его PID.
+func (t *tuningSession) recover() (int, error) {
+	data, err := os.ReadFile(t.journal)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read tuning journal: %w", err)
+	}
+	var journal tuningJournal
+	if err := json.Unmarshal(data, &journal); err != nil {
+		return 0, fmt.Errorf("failed to parse tuning journal %s: %w", t.journal, err)
+	}
+	if processAlive(journal.PID) {
+		return journal.PID, nil
+	}
+	t.variables = journal.Variables
+	restored := t.restoreVariables()
+	if len(restored) > 0 {
+		t.s.printStatusf("↩️  Restored server variables left by an interrupted run: %s\n", formatServerVariables(restored, true))
+	}
+	// Журнал не перезаписывается, пока в нем есть невосстановленные значения.
+	if len(restored) < len(t.variables) {
+		return 0, fmt.Errorf("failed to restore server variables left by an interrupted run, set them manually and remove %s", t.journal)
+	}
+	t.variables = nil
+	return 0, nil
+}
+
+// restore возвращает исходные значения и закрывает соединение. Контекст синхронизации не используется:
+// после отмены загрузки сервер все равно должен вернуться к исходным настройкам.
+func (t *tuningSession) restore() []models.ServerVariable {
+	defer t.close()
+	if restored := t.restoreVariables(); len(restored) > 0 {
+		t.s.printStatusf("🔧 Restored local MySQL: %s\n", formatServerVariables(restored, true))
+	}
+	return t.variables
+}
+
+// restoreVariables возвращает исходные значения в обратном порядке и удаляет журнал, если вернулись все.
+func (t *tuningSession) restoreVariables() []models.ServerVariable {
+	ctx := context.Background()
+	restored := make([]models.ServerVariable, 0, len(t.variables))
+	failed := false
+	for i := len(t.variables) - 1; i >= 0; i-- {
+		variable := &t.variables[i]
+		if err := applyTuning(ctx, t.db, variable.Name, variable.Original); err != nil {
+			variable.Error = err.Error()
+			failed = true
+			t.s.printStatusf("⚠️  Failed to restore %s=%s: %v\n", variable.Name, variable.Original, err)
+			continue
+		}
+		variable.Restored = true
+		restored = append(restored, *variable)
+	}
+	if !failed && t.journal != "" {
+		os.Remove(t.journal)
+	}
+	return restored
+}
+
+func (t *tuningSession) writeJournal(variables []models.ServerVariable) error {
+	journal := tuningJournal{PID: os.Getpid(), Server: t.s.config.Local.Address(), Variables: variables}
+	if err := writeJSONFileAtomic(t.journal, journal); err != nil {
+		return fmt.Errorf("failed to write tuning journal: %w", err)
+	}
+	return nil
+}
+
+func (t *tuningSession) close() {
+	t.db.Close()
+	t.cleanup()
+}
+
+// formatServerVariables перечисляет переменные с примененными или исходными значениями.
+func formatServerVariables(variables []models.ServerVariable, original bool) string {
+	parts := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		value := variable.Applied
+		if original {
+			value = variable.Original
+		}
+		parts = append(parts, variable.Name+"="+value)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+func TestTuningPlan(t *testing.T) {
+	current := map[string]string{
+		"local_infile":                   "OFF",
+		redoLogVariable:                  "ON",
+		"innodb_flush_log_at_trx_commit": "1",
+		"innodb_log_buffer_size":         "16777216",
+		"innodb_ddl_buffer_size":         "1073741824",
+	}
+	names := func(settings []tuningSetting) []string {
+		var result []string
+		for _, setting := range settings {
+			result = append(result, setting.name+"="+setting.value)
+		}
+		return result
+	}
+
+	safe := tuningPlan(config.TuningConfig{Mode: config.TuningModeSafe, BufferSizeMB: 256}, config.DumpEngineMySQLShell, current)
+	if got := names(safe); !reflect.DeepEqual(got, []string{"local_infile=ON"}) || !safe[0].required {
+		t.Fatalf("safe mode must only enable local_infile for mysqlsh, got %v", safe)
+	}
+	if native := tuningPlan(config.TuningConfig{Mode: config.TuningModeSafe}, config.DumpEngineNative, current); len(native) != 1 || native[0].required {
+		t.Fatalf("native falls back to INSERT, local_infile must be optional, got %v", native)
+	}
+	if mydumper := tuningPlan(config.TuningConfig{Mode: config.TuningModeSafe}, config.DumpEngineMydumper, current); len(mydumper) != 0 {
+		t.Fatalf("mydumper does not use local_infile, got %v", mydumper)
+	}
+
+	fast := tuningPlan(config.TuningConfig{Mode: config.TuningModeFast, BufferSizeMB: 256}, config.DumpEngineMySQLShell, current)
+	want := []string{"local_infile=ON", "innodb_redo_log=OFF", "innodb_flush_log_at_trx_commit=2", "innodb_log_buffer_size=268435456"}
+	if got := names(fast); !reflect.DeepEqual(got, want) {
+		t.Fatalf("fast plan = %v, want %v (larger buffers must stay)", got, want)
+	}
+
+	// До 8.0.21 статуса Innodb_redo_log_enabled нет, и redo log не трогается.
+	delete(current, redoLogVariable)
+	current["local_infile"] = "ON"
+	fast = tuningPlan(config.TuningConfig{Mode: config.TuningModeFast, BufferSizeMB: 256}, config.DumpEngineMySQLShell, current)
+	if got := names(fast); !reflect.DeepEqual(got, []string{"innodb_flush_log_at_trx_commit=2", "innodb_log_buffer_size=268435456"}) {
+		t.Fatalf("unexpected plan without redo log status: %v", got)
+	}
+}
+
+func TestTuningStatement(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"local_infile", "OFF", "SET GLOBAL local_infile = OFF"},
+		{"innodb_flush_log_at_trx_commit", "2", "SET GLOBAL innodb_flush_log_at_trx_commit = 2"},
+		{redoLogVariable, "OFF", "ALTER INSTANCE DISABLE INNODB REDO_LOG"},
+		{redoLogVariable, "ON", "ALTER INSTANCE ENABLE INNODB REDO_LOG"},
+	}
+	for _, tt := range tests {
+		got, err := tuningStatement(tt.name, tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("tuningStatement(%s, %s) = %q, %v; want %q", tt.name, tt.value, got, err, tt.want)
+		}
+	}
+	for _, bad := range [][2]string{{"local_infile", "1; DROP DATABASE shop"}, {"sql_mode", "ANSI"}} {
+		if _, err := tuningStatement(bad[0], bad[1]); err == nil {
+			t.Errorf("tuningStatement(%q, %q) must be rejected", bad[0], bad[1])
+		}
+	}
+}
+
+func TestTuningRecoverSkipsJournalOfRunningProcess(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	service := NewMySQLShellService(&config.Config{
+		Local: config.MySQLConfig{Host: "127.0.0.1", Port: 3306},
+		Dump:  config.DumpConfig{WorkDir: t.TempDir()},
+	}, nil)
+	session := &tuningSession{s: service, journal: service.tuningJournalPath()}
+	if !strings.HasPrefix(session.journal, service.config.Dump.ResolvedWorkDir()) || !strings.Contains(session.journal, tuningJournalPrefix+"127.0.0.1_3306") {
+		t.Fatalf("unexpected journal path %s", session.journal)
+	}
+	if owner, err := session.recover(); err != nil || owner != 0 {
+		t.Fatalf("missing journal: owner=%d err=%v", owner, err)
+	}
+
+	variables := []models.ServerVariable{{Name: "local_infile", Original: "OFF", Applied: "ON"}}
+	if err := session.writeJournal(variables); err != nil {
+		t.Fatalf("writeJournal() error = %v", err)
+	}
+	owner, err := session.recover()
+	if err != nil || owner != os.Getpid() {
+		t.Fatalf("journal of a running process must be left to it: owner=%d err=%v", owner, err)
+	}
+	if _, err := os.Stat(session.journal); err != nil {
+		t.Fatalf("journal of a running process must stay: %v", err)
+	}
+	if stale, err := service.FindStaleDumps(); err != nil || len(stale) != 0 {
+		t.Fatalf("tuning journal must not be treated as a stale dump: %v %v", stale, err)
+	}
+}
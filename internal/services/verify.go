@@ -0,0 +1,160 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+const (
+	verifyMismatchMissingLocal  = "missing locally"
+	verifyMismatchMissingRemote = "missing on remote"
+	verifyMismatchRows          = "row count"
+	verifyMismatchChecksum      = "checksum"
+	verifyMismatchSchema        = "schema"
+)
+
+var (
+	autoIncrementOptionPattern = regexp.MustCompile(`\s+AUTO_INCREMENT=\d+`)
+	rowFormatOptionPattern     = regexp.MustCompile(`(?i)\s+ROW_FORMAT=\w+`)
+	charsetClausePattern       = regexp.MustCompile(`(?i)\s+(DEFAULT\s+)?(CHARSET|CHARACTER\s+SET)(\s*=\s*|\s+)\w+`)
+	collateClausePattern       = regexp.MustCompile(`(?i)\s+(DEFAULT\s+)?COLLATE(\s*=\s*|\s+)\w+`)
+	integerDisplayWidthPattern = regexp.MustCompile(`(?i)\b(tinyint|smallint|mediumint|int|bigint|year)\(\d+\)`)
+	engineOptionPattern        = regexp.MustCompile(`(?i)\bENGINE\s*=\s*(\w+)`)
+)
+
+// verifyTarget сверяет remote и local по эффективным таблицам цели после восстановления.
+// DDL источника сравнивается после исправлений compat, которые внес движок engineName.
+// Представления не сверяются: SHOW CREATE TABLE и COUNT(*) для них не описывают данные цели.
+func (s *MySQLShellService) verifyTarget(databaseName string, engineName string, tables []models.TableProgress, observer models.ProgressObserver) *models.VerificationResult {
+	startedAt := time.Now()
+	if observer != nil {
+		observer(models.ProgressSnapshot{Phase: models.SyncPhaseVerify, DatabaseName: databaseName, Message: "Verifying local copy against remote", Timestamp: startedAt})
+	}
+	s.printStatusf("🔎 Verifying %s (%d tables)...\n", databaseName, len(tables))
+
+	tableNames := make([]string, 0, len(tables))
+	var checksumTables []string
+	checksumLimit := s.config.Verify.ChecksumMaxBytes()
+	for _, table := range tables {
+		if table.View {
+			continue
+		}
+		tableNames = append(tableNames, table.Name)
+		if s.config.Verify.Checksum && table.SourceBytes <= checksumLimit {
+			checksumTables = append(checksumTables, table.Name)
+		}
+	}
+
+	result := &models.VerificationResult{}
+	remote, err := s.dbService.TableFingerprints(databaseName, tableNames, checksumTables, true)
+	if err != nil {
+		result.Error = fmt.Sprintf("remote: %v", err)
+		result.Duration = time.Since(startedAt)
+		return result
+	}
+	local, err := s.dbService.TableFingerprints(databaseName, tableNames, checksumTables, false)
+	if err != nil {
+		result.Error = fmt.Sprintf("local: %v", err)
+		result.Duration = time.Since(startedAt)
+		return result
+	}
+
+	source := make(map[string]models.TableFingerprint, len(remote))
+	for tableName, fingerprint := range remote {
+		fingerprint.DDL = compatTableDDL(s.config.Compat, engineName, fingerprint.DDL)
+		source[tableName] = fingerprint
+	}
+	result.Tables = compareTableFingerprints(tableNames, checksumTables, s.config.Verify.Checksum, source, local)
+	result.Duration = time.Since(startedAt)
+	return result
+}
+
+// verificationError превращает расхождения сверки в ошибку цели.
+func verificationError(result *models.VerificationResult) error {
+	if result == nil {
+		return nil
+	}
+	if result.Error != "" {
+		return fmt.Errorf("verification failed: %s", result.Error)
+	}
+	mismatched := result.MismatchedTables()
+	if len(mismatched) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(mismatched))
+	for _, table := range mismatched {
+		names = append(names, table.Name)
+	}
+	return fmt.Errorf("verification failed: %d table(s) differ from remote: %s", len(mismatched), strings.Join(names, ", "))
+}
+
+func compareTableFingerprints(tableNames []string, checksumTables []string, checksumEnabled bool, remote map[string]models.TableFingerprint, local map[string]models.TableFingerprint) []models.TableVerification {
+	withChecksum := make(map[string]bool, len(checksumTables))
+	for _, tableName := range checksumTables {
+		withChecksum[tableName] = true
+	}
+
+	sortedNames := append([]string(nil), tableNames...)
+	sort.Strings(sortedNames)
+
+	verifications := make([]models.TableVerification, 0, len(sortedNames))
+	for _, tableName := range sortedNames {
+		remoteFingerprint, remoteOK := remote[tableName]
+		localFingerprint, localOK := local[tableName]
+		verification := models.TableVerification{
+			Name:            tableName,
+			RemoteRows:      remoteFingerprint.Rows,
+			LocalRows:       localFingerprint.Rows,
+			RemoteChecksum:  remoteFingerprint.Checksum,
+			LocalChecksum:   localFingerprint.Checksum,
+			ChecksumSkipped: checksumEnabled && !withChecksum[tableName],
+		}
+		switch {
+		case !localOK:
+			verification.Mismatches = append(verification.Mismatches, verifyMismatchMissingLocal)
+		case !remoteOK:
+			verification.Mismatches = append(verification.Mismatches, verifyMismatchMissingRemote)
+		default:
+			if remoteFingerprint.Rows != localFingerprint.Rows {
+				verification.Mismatches = append(verification.Mismatches, verifyMismatchRows)
+			}
+			// CHECKSUM TABLE возвращает NULL для движков без поддержки — такие таблицы не сравниваем.
+			if remoteFingerprint.Checksum != "" && localFingerprint.Checksum != "" && remoteFingerprint.Checksum != localFingerprint.Checksum {
+				verification.Mismatches = append(verification.Mismatches, verifyMismatchChecksum)
+			}
+			if normalizeCreateTable(remoteFingerprint.DDL) != normalizeCreateTable(localFingerprint.DDL) {
+				verification.Mismatches = append(verification.Mismatches, verifyMismatchSchema)
+			}
+		}
+		verifications = append(verifications, verification)
+	}
+	return verifications
+}
+
+// compatTableDDL применяет к DDL таблицы источника исправления compat, которые движок engineName вносит при дампе.
+func compatTableDDL(compat config.CompatConfig, engineName string, ddl string) string {
+	if compat.ForceInnoDB && !slices.Contains(ignoredCompat(compat, engineName), "force_innodb") {
+		ddl = engineOptionPattern.ReplaceAllString(ddl, "ENGINE=InnoDB")
+	}
+	return ddl
+}
+
+// normalizeCreateTable убирает из DDL части, которые законно расходятся после загрузки дампа
+// или между версиями MySQL: AUTO_INCREMENT, ROW_FORMAT, кодировки и сравнения по умолчанию,
+// ширину отображения целых типов и регистр имени движка.
+func normalizeCreateTable(ddl string) string {
+	ddl = autoIncrementOptionPattern.ReplaceAllString(ddl, "")
+	ddl = rowFormatOptionPattern.ReplaceAllString(ddl, "")
+	ddl = charsetClausePattern.ReplaceAllString(ddl, "")
+	ddl = collateClausePattern.ReplaceAllString(ddl, "")
+	ddl = integerDisplayWidthPattern.ReplaceAllString(ddl, "$1")
+	ddl = engineOptionPattern.ReplaceAllStringFunc(ddl, strings.ToLower)
+	return strings.Join(strings.Fields(ddl), " ")
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/test/mocks"
+)
+
+func TestCompareTableFingerprints(t *testing.T) {
+	remote := map[string]models.TableFingerprint{
+		"users":  {Rows: 3, Checksum: "111", DDL: "CREATE TABLE `users` (\n  `id` int\n) ENGINE=InnoDB AUTO_INCREMENT=4"},
+		"orders": {Rows: 10, Checksum: "222", DDL: "CREATE TABLE `orders` (`id` int)"},
+		"events": {Rows: 5, DDL: "CREATE TABLE `events` (`id` int)"},
+		"logs":   {Rows: 1, DDL: "CREATE TABLE `logs` (`id` int)"},
+	}
+	local := map[string]models.TableFingerprint{
+		"users":  {Rows: 3, Checksum: "111", DDL: "CREATE TABLE `users` (  `id` int ) ENGINE=InnoDB AUTO_INCREMENT=9"},
+		"orders": {Rows: 9, Checksum: "333", DDL: "CREATE TABLE `orders` (`id` bigint)"},
+		"events": {Rows: 5, DDL: "CREATE TABLE `events` (`id` int)"},
+	}
+
+	verifications := compareTableFingerprints([]string{"users", "orders", "events", "logs"}, []string{"users", "orders"}, true, remote, local)
+	byName := make(map[string]models.TableVerification, len(verifications))
+	for _, verification := range verifications {
+		byName[verification.Name] = verification
+	}
+
+	if !byName["users"].Matches() {
+		t.Fatalf("users should match after normalizing DDL, got %+v", byName["users"])
+	}
+	if got := strings.Join(byName["orders"].Mismatches, ","); got != "row count,checksum,schema" {
+		t.Fatalf("unexpected orders mismatches: %s", got)
+	}
+	if !byName["events"].Matches() || !byName["events"].ChecksumSkipped {
+		t.Fatalf("events should match with skipped checksum, got %+v", byName["events"])
+	}
+	if got := strings.Join(byName["logs"].Mismatches, ","); got != verifyMismatchMissingLocal {
+		t.Fatalf("unexpected logs mismatches: %s", got)
+	}
+	if verifications[0].Name != "events" {
+		t.Fatalf("verifications should be sorted by name, got %s first", verifications[0].Name)
+	}
+}
+
+func TestVerifyTargetUsesChecksumLimit(t *testing.T) {
+	fingerprints := map[string]models.TableFingerprint{
+		"small": {Rows: 2, Checksum: "42", DDL: "CREATE TABLE `small` (`id` int)"},
+		"large": {Rows: 7, Checksum: "77", DDL: "CREATE TABLE `large` (`id` int)"},
+	}
+	dbService := &mocks.MockDatabaseService{RemoteFingerprints: fingerprints, LocalFingerprints: fingerprints}
+	service := NewMySQLShellService(&config.Config{Verify: config.VerifyConfig{Enabled: true, Checksum: true, ChecksumMaxMB: 1}}, dbService)
+	service.SetQuiet(true)
+
+	result := service.verifyTarget("shop", config.DumpEngineMySQLShell, []models.TableProgress{
+		{Name: "small", SourceBytes: 1024},
+		{Name: "large", SourceBytes: 2 * 1024 * 1024},
+	}, nil)
+	if !result.Passed() {
+		t.Fatalf("expected verification to pass, got %+v", result)
+	}
+	for _, table := range result.Tables {
+		if table.Name == "large" && (!table.ChecksumSkipped || table.LocalChecksum != "") {
+			t.Fatalf("large table must skip checksum, got %+v", table)
+		}
+		if table.Name == "small" && table.LocalChecksum != "42" {
+			t.Fatalf("small table must be checksummed, got %+v", table)
+		}
+	}
+	if verificationError(result) != nil {
+		t.Fatalf("passed verification must not produce an error")
+	}
+
+	dbService.LocalFingerprints = map[string]models.TableFingerprint{"small": fingerprints["small"]}
+	result = service.verifyTarget("shop", config.DumpEngineMySQLShell, []models.TableProgress{{Name: "small"}, {Name: "large"}}, nil)
+	err := verificationError(result)
+	if err == nil || !strings.Contains(err.Error(), "1 table(s) differ from remote: large") {
+		t.Fatalf("unexpected verification error: %v", err)
+	}
+}
+
+func TestVerifyTargetAppliesCompatTransforms(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{
+		RemoteFingerprints: map[string]models.TableFingerprint{
+			"legacy": {Rows: 1, DDL: "CREATE TABLE `legacy` (\n  `id` int(11) NOT NULL,\n  `name` varchar(32) CHARACTER SET utf8mb3 COLLATE utf8mb3_general_ci DEFAULT NULL\n) ENGINE=MyISAM AUTO_INCREMENT=3 DEFAULT CHARSET=utf8mb3 ROW_FORMAT=DYNAMIC"},
+		},
+		LocalFingerprints: map[string]models.TableFingerprint{
+			"legacy": {Rows: 1, DDL: "CREATE TABLE `legacy` (\n  `id` int NOT NULL,\n  `name` varchar(32) DEFAULT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci"},
+		},
+	}
+	service := NewMySQLShellService(&config.Config{Verify: config.VerifyConfig{Enabled: true}, Compat: config.CompatConfig{ForceInnoDB: true}}, dbService)
+	service.SetQuiet(true)
+	tables := []models.TableProgress{{Name: "legacy"}}
+
+	if result := service.verifyTarget("shop", config.DumpEngineMySQLShell, tables, nil); !result.Passed() {
+		t.Fatalf("force_innodb applied by mysqlsh must not count as a schema mismatch, got %+v", result.Tables)
+	}
+	result := service.verifyTarget("shop", config.DumpEngineMysqldump, tables, nil)
+	if len(result.Tables) != 1 || strings.Join(result.Tables[0].Mismatches, ",") != verifyMismatchSchema {
+		t.Fatalf("engine that ignores force_innodb must report the engine change, got %+v", result.Tables)
+	}
+}
+
+func TestVerifyTargetSkipsViews(t *testing.T) {
+	dbService := &mocks.MockDatabaseService{
+		RemoteFingerprints: map[string]models.TableFingerprint{
+			"orders":       {Rows: 2, DDL: "CREATE TABLE `orders` (`id` int)"},
+			"order_totals": {Rows: 2, DDL: "CREATE ALGORITHM=UNDEFINED VIEW `order_totals` AS select 1"},
+		},
+		LocalFingerprints: map[string]models.TableFingerprint{
+			"orders": {Rows: 2, DDL: "CREATE TABLE `orders` (`id` int)"},
+		},
+	}
+	service := NewMySQLShellService(&config.Config{Verify: config.VerifyConfig{Enabled: true}}, dbService)
+	service.SetQuiet(true)
+
+	tracker := newTableProgressTracker("shop", []models.Table{{Name: "orders"}, {Name: "order_totals", Type: "VIEW"}})
+	result := service.verifyTarget("shop", config.DumpEngineMySQLShell, tracker.Snapshot(time.Now()), nil)
+	if !result.Passed() || len(result.Tables) != 1 || result.Tables[0].Name != "orders" {
+		t.Fatalf("views must be left out of verification, got %+v", result.Tables)
+	}
+}
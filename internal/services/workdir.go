@@ -0,0 +1,195 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+)
+
+// workLockSuffix — суффикс lock-файла рядом с дампом. Внутри директории дампа его держать нельзя:
+// mysqlsh требует пустую директорию для util.dumpSchemas.
+const workLockSuffix = ".lock"
+
+// legacyDumpAge — возраст, после которого дамп без lock-файла считается брошенным.
+const legacyDumpAge = 24 * time.Hour
+
+// workEntryPrefixes — префиксы временных дампов (<engine>_<db>_<unix>, <engine>_batch_<unix>),
+// распакованных архивов и скачанных из бакета файлов.
+var workEntryPrefixes = []string{
+	config.DumpEngineMySQLShell + "_",
+	config.DumpEngineMydumper + "_",
+	config.DumpEngineMysqldump + "_",
+	config.DumpEngineNative + "_",
+	"dbsync_import_",
+	"dbsync_pull_",
+}
+
+// createWorkDir создает директорию name в рабочей директории дампов. Lock-файл с PID текущего
+// процесса создается до директории, чтобы очистка не приняла ее за брошенную.
+func (s *MySQLShellService) createWorkDir(name string) (string, error) {
+	workDir := s.config.Dump.ResolvedWorkDir()
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return "", err
+	}
+	dir := filepath.Join(workDir, name)
+	if err := writeWorkLock(dir); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		os.Remove(dir + workLockSuffix)
+		return "", err
+	}
+	return dir, nil
+}
+
+// createWorkTemp создает временную директорию или файл по шаблону pattern в рабочей директории.
+// Lock-файл пишется сразу после создания; свежие записи без lock-файла очистка не трогает.
+func (s *MySQLShellService) createWorkTemp(pattern string, file bool) (string, *os.File, error) {
+	workDir := s.config.Dump.ResolvedWorkDir()
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return "", nil, err
+	}
+	var path string
+	var handle *os.File
+	if file {
+		created, err := os.CreateTemp(workDir, pattern)
+		if err != nil {
+			return "", nil, err
+		}
+		path, handle = created.Name(), created
+	} else {
+		created, err := os.MkdirTemp(workDir, pattern)
+		if err != nil {
+			return "", nil, err
+		}
+		path = created
+	}
+	if err := writeWorkLock(path); err != nil {
+		if handle != nil {
+			handle.Close()
+		}
+		os.RemoveAll(path)
+		return "", nil, err
+	}
+	return path, handle, nil
+}
+
+func writeWorkLock(path string) error {
+	if err := os.WriteFile(path+workLockSuffix, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %w", err)
+	}
+	return nil
+}
+
+// removeWorkDir удаляет временный дамп или файл вместе с его lock-файлом.
+func removeWorkDir(path string) error {
+	err := os.RemoveAll(path)
+	os.Remove(path + workLockSuffix)
+	return err
+}
+
+// readWorkLock возвращает PID из lock-файла записи. ok=false, если lock-файла нет.
+func readWorkLock(path string) (pid int, ok bool) {
+	data, err := os.ReadFile(path + workLockSuffix)
+	if err != nil {
+		return 0, false
+	}
+	pid, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, true
+}
+
+// workDirs возвращает рабочую директорию и системную временную директорию, куда писали дампы
+// до появления dump.work_dir.
+func (s *MySQLShellService) workDirs() []string {
+	dirs := []string{filepath.Clean(s.config.Dump.ResolvedWorkDir())}
+	if tempDir := filepath.Clean(os.TempDir()); tempDir != dirs[0] {
+		dirs = append(dirs, tempDir)
+	}
+	return dirs
+}
+
+// FindStaleDumps ищет в рабочей и временной директориях дампы, архивы и скачанные файлы, брошенные
+// запусками, которые упали или были убиты: процесс из lock-файла больше не работает, а записи без
+// lock-файла не менялись дольше legacyDumpAge. Результат отсортирован по пути.
+func (s *MySQLShellService) FindStaleDumps() ([]models.StaleDump, error) {
+	now := time.Now()
+	var stale []models.StaleDump
+	for _, dir := range s.workDirs() {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read work directory: %w", err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if strings.HasSuffix(name, workLockSuffix) || !hasWorkEntryPrefix(name) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			path := filepath.Join(dir, name)
+			pid, locked := readWorkLock(path)
+			if locked && processAlive(pid) {
+				continue
+			}
+			if !locked && now.Sub(info.ModTime()) < legacyDumpAge {
+				continue
+			}
+			stale = append(stale, models.StaleDump{Path: path, SizeBytes: directorySize(path), PID: pid, ModifiedAt: info.ModTime()})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Path < stale[j].Path })
+	return stale, nil
+}
+
+func hasWorkEntryPrefix(name string) bool {
+	for _, prefix := range workEntryPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveStaleDumps удаляет найденные FindStaleDumps записи и возвращает освобожденный объем.
+func (s *MySQLShellService) RemoveStaleDumps(stale []models.StaleDump) (int64, error) {
+	var reclaimed int64
+	var removeErrors []error
+	for _, dump := range stale {
+		if err := removeWorkDir(dump.Path); err != nil {
+			removeErrors = append(removeErrors, err)
+			continue
+		}
+		reclaimed += dump.SizeBytes
+	}
+	return reclaimed, errors.Join(removeErrors...)
+}
+
+// SweepStaleDumps удаляет при запуске дампы, lock-файлы которых остались от завершившихся
+// процессов. Записи без lock-файла удаляет только dbsync cleanup.
+func (s *MySQLShellService) SweepStaleDumps() (int, int64, error) {
+	stale, err := s.FindStaleDumps()
+	if err != nil {
+		return 0, 0, err
+	}
+	locked := stale[:0]
+	for _, dump := range stale {
+		if dump.PID != 0 {
+			locked = append(locked, dump)
+		}
+	}
+	reclaimed, err := s.RemoveStaleDumps(locked)
+	return len(locked), reclaimed, err
+}
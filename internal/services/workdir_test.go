@@ -0,0 +1,102 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+)
+
+// exitedPID возвращает PID процесса, который уже завершился.
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run helper process: %v", err)
+	}
+	return cmd.Process.Pid
+}
+
+func TestStaleDumps(t *testing.T) {
+	workDir := t.TempDir()
+	// Системная временная директория тоже сканируется, поэтому подменяем ее.
+	t.Setenv("TMPDIR", workDir)
+	t.Setenv("TMP", workDir)
+	service := NewMySQLShellService(&config.Config{Dump: config.DumpConfig{WorkDir: workDir}}, nil)
+
+	running, err := service.createWorkDir("mysqlsh_shop_1700000000")
+	if err != nil {
+		t.Fatalf("createWorkDir() error = %v", err)
+	}
+	if pid, ok := readWorkLock(running); !ok || pid != os.Getpid() {
+		t.Fatalf("expected lock with current pid, got %d, %v", pid, ok)
+	}
+
+	crashed := filepath.Join(workDir, "mysqlsh_batch_1700000001")
+	if err := os.MkdirAll(crashed, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(crashed, "@.json"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadPID := exitedPID(t)
+	if err := os.WriteFile(crashed+workLockSuffix, []byte(strconv.Itoa(deadPID)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := filepath.Join(workDir, "mydumper_shop_1600000000")
+	fresh := filepath.Join(workDir, "dbsync_import_123")
+	unrelated := filepath.Join(workDir, "other_shop_1600000000")
+	for _, dir := range []string{legacy, fresh, unrelated} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * legacyDumpAge)
+	for _, dir := range []string{legacy, unrelated} {
+		if err := os.Chtimes(dir, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stale, err := service.FindStaleDumps()
+	if err != nil {
+		t.Fatalf("FindStaleDumps() error = %v", err)
+	}
+	found := map[string]int{}
+	for _, dump := range stale {
+		if filepath.Dir(dump.Path) == workDir {
+			found[dump.Path] = dump.PID
+		}
+	}
+	if len(found) != 2 || found[crashed] != deadPID || found[legacy] != 0 {
+		t.Fatalf("expected crashed and legacy dumps, got %+v", stale)
+	}
+
+	// При запуске удаляются только дампы с lock-файлом, старые записи — через dbsync cleanup.
+	removed, reclaimed, err := service.SweepStaleDumps()
+	if err != nil || removed != 1 || reclaimed != 100 {
+		t.Fatalf("SweepStaleDumps() = %d, %d, %v", removed, reclaimed, err)
+	}
+	for _, path := range []string{crashed, crashed + workLockSuffix} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s must be removed, got %v", path, err)
+		}
+	}
+	for _, path := range []string{running, running + workLockSuffix, legacy, fresh, unrelated} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("%s must be kept: %v", path, err)
+		}
+	}
+
+	if err := removeWorkDir(running); err != nil {
+		t.Fatalf("removeWorkDir() error = %v", err)
+	}
+	if _, err := os.Stat(running + workLockSuffix); !os.IsNotExist(err) {
+		t.Fatalf("lock must be removed with the dump, got %v", err)
+	}
+}
@@ -27,6 +27,52 @@ type SyncExecutor interface {
 	ExecutePlan(plan *models.SyncPlan, runtime models.RuntimeOptions, observer models.ProgressObserver) ([]models.SyncResult, error)
 }
 
+// BackupRestorer опционально реализуется SyncExecutor для отката синхронизации из локального бэкапа.
+type BackupRestorer interface {
+	RestoreLocalBackup(databaseName string, backupPath string, observer models.ProgressObserver) (*models.LocalBackup, error)
+}
+
+// BucketPuller опционально реализуется SyncExecutor для восстановления БД из архива в S3-совместимом хранилище.
+type BucketPuller interface {
+	PullFromBucket(databaseName string, key string, asName string, observer models.ProgressObserver) (*models.ArchiveManifest, error)
+}
+
+// DatabaseConfirmer опционально реализуется SyncExecutor: защищенные БД, подтвержденные в TUI,
+// передаются ему перед откатом и pull, которые выполняются вне плана синхронизации.
+type DatabaseConfirmer interface {
+	SetConfirmedDatabases(names []string)
+}
+
+// SchemaDiffer опционально реализуется DatabaseBrowser для сравнения схем remote и local.
+type SchemaDiffer interface {
+	DiffSchema(databaseName string) (*models.SchemaDiff, error)
+}
+
+// IncrementalPlanner опционально реализуется SyncExecutor для предпросмотра incremental/full таблиц цели.
+type IncrementalPlanner interface {
+	PlanIncremental(target models.SyncTarget) (*models.IncrementalPlan, error)
+}
+
+// SmartSyncPlanner опционально реализуется SyncExecutor для предпросмотра пропускаемых неизменившихся таблиц.
+type SmartSyncPlanner interface {
+	PlanSmartSync(target models.SyncTarget) (*models.SmartSyncPlan, error)
+}
+
+// DiskSpaceChecker опционально реализуется SyncExecutor для оценки свободного места под дамп и загрузку плана.
+type DiskSpaceChecker interface {
+	CheckDiskSpace(plan *models.SyncPlan) (*models.DiskPreflight, error)
+}
+
+// Diagnoser опционально реализуется SyncExecutor для проверки окружения, версий и привилегий.
+type Diagnoser interface {
+	Diagnose(from string, to string) (*models.DoctorReport, error)
+}
+
+// ScheduleLister опционально реализуется SyncExecutor для показа ближайших запусков расписаний daemon.
+type ScheduleLister interface {
+	ListScheduleStatuses() ([]models.ScheduleStatus, error)
+}
+
 type view int
 
 const (
@@ -37,6 +83,8 @@ const (
 	viewSettings
 	viewRunning
 	viewReport
+	viewDiff
+	viewDoctor
 )
 
 type confirmChoice int
@@ -46,6 +94,13 @@ const (
 	confirmSync
 )
 
+const (
+	runningTablePanelRows     = 8
+	reportSlowestTablesLimit  = 5
+	reportHookOutputLines     = 5
+	reportVerifyMismatchLimit = 8
+)
+
 type settingsFieldKind int
 
 const (
@@ -111,6 +166,48 @@ type planRunDone struct {
 
 type runTickMsg time.Time
 
+type schemaDiffLoadedMsg struct {
+	DatabaseName string
+	Diff         *models.SchemaDiff
+	Err          error
+}
+
+type incrementalPlansLoadedMsg struct {
+	Plans  map[string]*models.IncrementalPlan
+	Errors map[string]string
+}
+
+type smartPlansLoadedMsg struct {
+	Plans  map[string]*models.SmartSyncPlan
+	Errors map[string]string
+}
+
+type diskPreflightLoadedMsg struct {
+	Preflight *models.DiskPreflight
+	Err       error
+}
+
+type schedulesLoadedMsg struct {
+	Schedules []models.ScheduleStatus
+	Err       error
+}
+
+type doctorLoadedMsg struct {
+	Report *models.DoctorReport
+	Err    error
+}
+
+type bucketPullDoneMsg struct {
+	DatabaseName string
+	Manifest     *models.ArchiveManifest
+	Err          error
+}
+
+type backupRestoreDoneMsg struct {
+	Restored []string
+	Err      error
+}
+
 // AppResult хранит результат работы unified TUI shell.
 type AppResult struct {
 	Cancelled bool
@@ -171,6 +268,43 @@ type AppModel struct {
 	runDoneCh            chan planRunDone
 	runningError         string
 	phaseTimings         map[string]*phaseTimingTracker
+	tableProgress        map[string][]models.TableProgress
+	tablePanelOffset     int
+	undoArmed            bool
+	undoRunning          bool
+	undoStatus           string
+
+	diffDatabase string
+	diffLoading  bool
+	diff         *models.SchemaDiff
+	diffError    string
+	diffOffset   int
+
+	doctorReport  *models.DoctorReport
+	doctorLoading bool
+	doctorError   string
+	doctorOffset  int
+
+	forceFull          map[string]bool
+	consistent         map[string]bool
+	protectedConfirmed map[string]bool
+	protectedEditing   bool
+	protectedBuffer    string
+	incrementalPlans   map[string]*models.IncrementalPlan
+	incrementalErrors  map[string]string
+	incrementalLoading bool
+	smartPlans         map[string]*models.SmartSyncPlan
+	smartErrors        map[string]string
+	smartLoading       bool
+	diskPreflight      *models.DiskPreflight
+	diskError          string
+	diskLoading        bool
+
+	schedules     []models.ScheduleStatus
+	scheduleError string
+
+	pullArmed   string
+	pullRunning bool
 
 	result AppResult
 
@@ -196,20 +330,28 @@ func NewAppModel(cfg *config.Config, browser DatabaseBrowser, runner SyncExecuto
 	copyList := append(models.DatabaseList(nil), databases...)
 	copyList.SortBySize()
 	model := &AppModel{
-		cfg:               cfg,
-		browser:           browser,
-		runner:            runner,
-		databases:         copyList,
-		view:              viewList,
-		previousView:      viewList,
-		confirmChoice:     confirmCancel,
-		selectedDatabases: make(map[string]bool),
-		tableStates:       make(map[string]*databaseTableState),
-		savePath:          config.DefaultEnvPath(),
-		width:             120,
-		height:            36,
-		runningNow:        time.Now(),
-		phaseTimings:      make(map[string]*phaseTimingTracker),
+		cfg:                cfg,
+		browser:            browser,
+		runner:             runner,
+		databases:          copyList,
+		view:               viewList,
+		previousView:       viewList,
+		confirmChoice:      confirmCancel,
+		selectedDatabases:  make(map[string]bool),
+		tableStates:        make(map[string]*databaseTableState),
+		savePath:           config.DefaultEnvPath(),
+		width:              120,
+		height:             36,
+		runningNow:         time.Now(),
+		phaseTimings:       make(map[string]*phaseTimingTracker),
+		tableProgress:      make(map[string][]models.TableProgress),
+		forceFull:          make(map[string]bool),
+		consistent:         make(map[string]bool),
+		protectedConfirmed: make(map[string]bool),
+		incrementalPlans:   make(map[string]*models.IncrementalPlan),
+		incrementalErrors:  make(map[string]string),
+		smartPlans:         make(map[string]*models.SmartSyncPlan),
+		smartErrors:        make(map[string]string),
 	}
 	model.initSettingsFields()
 	model.updateFilter()
@@ -231,10 +373,10 @@ func RunApp(cfg *config.Config, browser DatabaseBrowser, runner SyncExecutor, da
 
 func (m *AppModel) Init() tea.Cmd {
 	if len(m.databases) > 0 {
-		return nil
+		return m.loadSchedulesCmd()
 	}
 	m.databasesLoading = true
-	return m.reloadDatabasesCmd()
+	return tea.Batch(m.reloadDatabasesCmd(), m.loadSchedulesCmd())
 }
 
 func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -295,6 +437,76 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.setNotice(status)
 		return m, nil
+	case schemaDiffLoadedMsg:
+		if msg.DatabaseName != m.diffDatabase {
+			return m, nil
+		}
+		m.diffLoading = false
+		m.diffError = ""
+		m.diff = msg.Diff
+		if msg.Err != nil {
+			m.diffError = msg.Err.Error()
+		}
+		return m, nil
+	case doctorLoadedMsg:
+		m.doctorLoading = false
+		m.doctorError = ""
+		m.doctorReport = msg.Report
+		if msg.Err != nil {
+			m.doctorError = msg.Err.Error()
+		}
+		return m, nil
+	case incrementalPlansLoadedMsg:
+		m.incrementalLoading = false
+		m.incrementalPlans = msg.Plans
+		m.incrementalErrors = msg.Errors
+		return m, nil
+	case smartPlansLoadedMsg:
+		m.smartLoading = false
+		m.smartPlans = msg.Plans
+		m.smartErrors = msg.Errors
+		return m, nil
+	case diskPreflightLoadedMsg:
+		m.diskLoading = false
+		m.diskPreflight = msg.Preflight
+		m.diskError = ""
+		if msg.Err != nil {
+			m.diskError = msg.Err.Error()
+		}
+		return m, nil
+	case schedulesLoadedMsg:
+		m.schedules = msg.Schedules
+		m.scheduleError = ""
+		if msg.Err != nil {
+			m.scheduleError = msg.Err.Error()
+		}
+		return m, nil
+	case bucketPullDoneMsg:
+		m.pullRunning = false
+		if msg.Err != nil {
+			m.setNotice(dangerStyle.Render(fmt.Sprintf("Pull of %s failed: %v", msg.DatabaseName, msg.Err)))
+			return m, nil
+		}
+		m.setNotice(okStyle.Render(fmt.Sprintf("Pulled %s from bucket (%d tables, exported %s from %s)", msg.DatabaseName, len(msg.Manifest.Tables), msg.Manifest.CreatedAt.Local().Format("2006-01-02 15:04"), msg.Manifest.SourceHost)))
+		return m, nil
+	case backupRestoreDoneMsg:
+		m.undoRunning = false
+		restored := make(map[string]bool, len(msg.Restored))
+		for _, databaseName := range msg.Restored {
+			restored[databaseName] = true
+		}
+		for index := range m.runningResults {
+			if restored[m.runningResults[index].DatabaseName] {
+				m.runningResults[index].RolledBack = true
+			}
+		}
+		m.result.Results = append([]models.SyncResult(nil), m.runningResults...)
+		if msg.Err != nil {
+			m.undoStatus = dangerStyle.Render("Undo failed: " + msg.Err.Error())
+		} else {
+			m.undoStatus = okStyle.Render(fmt.Sprintf("Restored %d database(s) from local backups", len(msg.Restored)))
+		}
+		return m, nil
 	case runTickMsg:
 		m.runningNow = time.Time(msg)
 		if done, hasDone := m.drainRunChannels(); hasDone {
@@ -302,6 +514,8 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.result.Results = append([]models.SyncResult(nil), done.Results...)
 			m.running = false
 			m.runningTargetName = ""
+			m.undoArmed = false
+			m.undoStatus = ""
 			if done.Err != nil {
 				m.runningError = done.Err.Error()
 			}
@@ -330,6 +544,10 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleRunningKey(msg)
 		case viewReport:
 			return m.handleReportKey(msg)
+		case viewDiff:
+			return m.handleDiffKey(msg)
+		case viewDoctor:
+			return m.handleDoctorKey(msg)
 		}
 	}
 	return m, nil
@@ -399,13 +617,24 @@ func (m *AppModel) handleListKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "c":
 		m.selectedDatabases = make(map[string]bool)
 	case "r":
-		return m, m.reloadDatabasesCmd()
+		return m, tea.Batch(m.reloadDatabasesCmd(), m.loadSchedulesCmd())
 	case "s":
 		m.previousView = m.view
 		m.view = viewSettings
+	case "d":
+		if db := m.currentDatabase(); db != nil {
+			return m, m.openDiff(db.Name)
+		}
+	case "i":
+		return m, m.openDoctor()
+	case "p", "P":
+		if db := m.currentDatabase(); db != nil {
+			return m, m.pullFromBucket(db.Name)
+		}
 	case "y", "Y":
 		if len(m.buildPlan().Targets) > 0 {
 			m.view = viewPlan
+			return m, tea.Batch(m.loadSmartPlansCmd(), m.loadDiskPreflightCmd())
 		}
 	case "enter", "ctrl+m", "right", "l":
 		if db := m.currentDatabase(); db != nil {
@@ -486,6 +715,7 @@ func (m *AppModel) handleTablesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.view = viewSettings
 	case "y", "Y", "enter", "ctrl+m":
 		m.view = viewPlan
+		return m, tea.Batch(m.loadSmartPlansCmd(), m.loadDiskPreflightCmd())
 	}
 	return m, nil
 }
@@ -518,6 +748,7 @@ func (m *AppModel) handlePlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if m.planCursor >= len(m.buildPlan().Targets) && m.planCursor > 0 {
 				m.planCursor--
 			}
+			return m, m.loadDiskPreflightCmd()
 		}
 	case "enter", "ctrl+m":
 		if target, ok := m.currentPlanTarget(plan); ok {
@@ -532,10 +763,20 @@ func (m *AppModel) handlePlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "c":
 		m.selectedDatabases = make(map[string]bool)
 		m.planCursor = 0
+	case "f", "F":
+		if target, ok := m.currentPlanTarget(plan); ok {
+			m.forceFull[target.DatabaseName] = !m.forceFull[target.DatabaseName]
+			return m, m.loadSmartPlansCmd()
+		}
+	case "o", "O":
+		if target, ok := m.currentPlanTarget(plan); ok {
+			m.consistent[target.DatabaseName] = !m.consistent[target.DatabaseName]
+		}
 	case "y", "Y":
 		if len(plan.Targets) > 0 {
 			m.view = viewConfirm
 			m.confirmChoice = confirmSync
+			return m, tea.Batch(m.loadIncrementalPlansCmd(), m.loadDiskPreflightCmd())
 		}
 	case "s":
 		m.previousView = m.view
@@ -545,6 +786,9 @@ func (m *AppModel) handlePlanKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *AppModel) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.protectedEditing {
+		return m.handleProtectedConfirmKey(msg)
+	}
 	switch msg.String() {
 	case "ctrl+c", "q":
 		m.result.Cancelled = true
@@ -563,25 +807,22 @@ func (m *AppModel) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.confirmChoice = confirmCancel
 	case "right", "l":
 		m.confirmChoice = confirmSync
-	case "y", "Y":
-		m.confirmChoice = confirmSync
+	case "f", "F":
 		plan := m.buildPlan()
-		if len(plan.Targets) == 0 {
-			return m, nil
+		forceAll := false
+		for _, target := range plan.Targets {
+			if !target.ForceFull {
+				forceAll = true
+				break
+			}
+		}
+		for _, target := range plan.Targets {
+			m.forceFull[target.DatabaseName] = forceAll
 		}
-		m.runningPlan = plan
-		m.runningResults = nil
-		m.runningCompleted = 0
-		m.runningStartedAt = time.Now()
-		m.runningTargetStarted = m.runningStartedAt
-		m.runningTargetName = plan.Targets[0].DatabaseName
-		m.currentProgress = models.ProgressSnapshot{Phase: models.SyncPhasePlanning, DatabaseName: plan.Targets[0].DatabaseName, Message: "Launching sync plan", Timestamp: time.Now()}
-		m.runningError = ""
-		m.runProgressCh = make(chan models.ProgressSnapshot, 256)
-		m.runDoneCh = make(chan planRunDone, 1)
-		m.running = true
-		m.view = viewRunning
-		return m, tea.Batch(m.startSyncCmd(), tickCmd())
+		return m, m.loadIncrementalPlansCmd()
+	case "y", "Y":
+		m.confirmChoice = confirmSync
+		return m.startPlan()
 	case "tab":
 		if m.confirmChoice == confirmCancel {
 			m.confirmChoice = confirmSync
@@ -590,23 +831,7 @@ func (m *AppModel) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "enter", "ctrl+m":
 		if m.confirmChoice == confirmSync {
-			plan := m.buildPlan()
-			if len(plan.Targets) == 0 {
-				return m, nil
-			}
-			m.runningPlan = plan
-			m.runningResults = nil
-			m.runningCompleted = 0
-			m.runningStartedAt = time.Now()
-			m.runningTargetStarted = m.runningStartedAt
-			m.runningTargetName = plan.Targets[0].DatabaseName
-			m.currentProgress = models.ProgressSnapshot{Phase: models.SyncPhasePlanning, DatabaseName: plan.Targets[0].DatabaseName, Message: "Launching sync plan", Timestamp: time.Now()}
-			m.runningError = ""
-			m.runProgressCh = make(chan models.ProgressSnapshot, 256)
-			m.runDoneCh = make(chan planRunDone, 1)
-			m.running = true
-			m.view = viewRunning
-			return m, tea.Batch(m.startSyncCmd(), tickCmd())
+			return m.startPlan()
 		}
 		if m.viewBeforeConfirm() == viewPlan {
 			m.view = viewPlan
@@ -619,6 +844,80 @@ func (m *AppModel) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// startPlan запускает план; если в нем есть неподтвержденная защищенная БД, сначала просит ввести ее имя.
+func (m *AppModel) startPlan() (tea.Model, tea.Cmd) {
+	plan := m.buildPlan()
+	if len(plan.Targets) == 0 {
+		return m, nil
+	}
+	if m.diskPreflight != nil && m.diskPreflight.Blocking {
+		m.setNotice(dangerStyle.Render("Not enough disk space for this plan; free some space or set DBSYNC_PREFLIGHT_DISK=warn"))
+		return m, nil
+	}
+	if m.pendingProtectedDatabase(plan) != "" {
+		m.protectedEditing = true
+		m.protectedBuffer = ""
+		return m, nil
+	}
+	m.runningPlan = plan
+	m.runningResults = nil
+	m.runningCompleted = 0
+	m.runningStartedAt = time.Now()
+	m.runningTargetStarted = m.runningStartedAt
+	m.runningTargetName = plan.Targets[0].DatabaseName
+	m.currentProgress = models.ProgressSnapshot{Phase: models.SyncPhasePlanning, DatabaseName: plan.Targets[0].DatabaseName, Message: "Launching sync plan", Timestamp: time.Now()}
+	m.runningError = ""
+	m.tableProgress = make(map[string][]models.TableProgress)
+	m.tablePanelOffset = 0
+	m.runProgressCh = make(chan models.ProgressSnapshot, 256)
+	m.runDoneCh = make(chan planRunDone, 1)
+	m.running = true
+	m.view = viewRunning
+	return m, tea.Batch(m.startSyncCmd(), tickCmd())
+}
+
+// pendingProtectedDatabase возвращает первую защищенную БД плана, имя которой еще не введено.
+func (m *AppModel) pendingProtectedDatabase(plan *models.SyncPlan) string {
+	for _, target := range plan.Targets {
+		if m.cfg.Safety.IsProtected(target.DatabaseName) && !m.protectedConfirmed[target.DatabaseName] {
+			return target.DatabaseName
+		}
+	}
+	return ""
+}
+
+func (m *AppModel) handleProtectedConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.result.Cancelled = true
+		return m, tea.Quit
+	case "esc":
+		m.protectedEditing = false
+		m.protectedBuffer = ""
+		m.setNotice(subtleStyle.Render("Protected database was not confirmed"))
+	case "enter", "ctrl+m":
+		name := m.pendingProtectedDatabase(m.buildPlan())
+		if m.protectedBuffer != name {
+			m.protectedBuffer = ""
+			m.setNotice(dangerStyle.Render(fmt.Sprintf("Name does not match '%s'", name)))
+			return m, nil
+		}
+		m.protectedConfirmed[name] = true
+		m.protectedEditing = false
+		m.protectedBuffer = ""
+		return m.startPlan()
+	case "backspace":
+		if len(m.protectedBuffer) > 0 {
+			m.protectedBuffer = m.protectedBuffer[:len(m.protectedBuffer)-1]
+		}
+	default:
+		if len(msg.String()) == 1 {
+			m.protectedBuffer += msg.String()
+		}
+	}
+	return m, nil
+}
+
 func (m *AppModel) handleSettingsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.settingsEditing {
 		return m.handleSettingsEditingKey(msg)
@@ -713,6 +1012,16 @@ func (m *AppModel) handleRunningKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "?":
 		m.showHelp = true
+	case "up", "k":
+		m.scrollTablePanel(-1)
+	case "down", "j":
+		m.scrollTablePanel(1)
+	case "pgup":
+		m.scrollTablePanel(-runningTablePanelRows)
+	case "pgdown":
+		m.scrollTablePanel(runningTablePanelRows)
+	case "home":
+		m.tablePanelOffset = 0
 	case "q", "ctrl+c":
 		if !m.running {
 			m.result.Cancelled = true
@@ -729,11 +1038,275 @@ func (m *AppModel) handleReportKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "enter", "q", "ctrl+c", "esc":
 		return m, tea.Quit
 	case "b":
+		m.undoArmed = false
 		m.view = viewList
+	case "u":
+		if m.undoRunning {
+			return m, nil
+		}
+		if len(m.undoableResults()) == 0 {
+			m.undoStatus = subtleStyle.Render("Nothing to undo: no local backups were taken in this run")
+			return m, nil
+		}
+		if !m.undoArmed {
+			m.undoArmed = true
+			m.undoStatus = warnStyle.Render("Press U again to restore local databases from their pre-sync backups")
+			return m, nil
+		}
+		m.undoArmed = false
+		m.undoRunning = true
+		m.undoStatus = warnStyle.Render("Restoring local backups...")
+		return m, m.restoreBackupsCmd()
+	default:
+		m.undoArmed = false
 	}
 	return m, nil
 }
 
+func (m *AppModel) openDiff(databaseName string) tea.Cmd {
+	m.previousView = m.view
+	m.view = viewDiff
+	m.diffDatabase = databaseName
+	m.diffLoading = true
+	m.diff = nil
+	m.diffError = ""
+	m.diffOffset = 0
+	return m.loadDiffCmd(databaseName)
+}
+
+func (m *AppModel) handleDiffKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.result.Cancelled = true
+		return m, tea.Quit
+	case "?":
+		m.showHelp = true
+	case "esc", "q", "b", "left", "h":
+		m.view = m.previousView
+	case "up", "k":
+		m.diffOffset = maxInt(m.diffOffset-1, 0)
+	case "down", "j":
+		m.diffOffset = clampInt(m.diffOffset+1, 0, m.maxDiffOffset())
+	case "pgup":
+		m.diffOffset = maxInt(m.diffOffset-m.diffPageRows(), 0)
+	case "pgdown":
+		m.diffOffset = clampInt(m.diffOffset+m.diffPageRows(), 0, m.maxDiffOffset())
+	case "home":
+		m.diffOffset = 0
+	case "r":
+		if !m.diffLoading {
+			m.diffLoading = true
+			return m, m.loadDiffCmd(m.diffDatabase)
+		}
+	}
+	return m, nil
+}
+
+func (m *AppModel) openDoctor() tea.Cmd {
+	m.previousView = m.view
+	m.view = viewDoctor
+	m.doctorLoading = true
+	m.doctorReport = nil
+	m.doctorError = ""
+	m.doctorOffset = 0
+	return m.loadDoctorCmd()
+}
+
+func (m *AppModel) handleDoctorKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.result.Cancelled = true
+		return m, tea.Quit
+	case "?":
+		m.showHelp = true
+	case "esc", "q", "b", "left", "h":
+		m.view = m.previousView
+	case "up", "k":
+		m.doctorOffset = maxInt(m.doctorOffset-1, 0)
+	case "down", "j":
+		m.doctorOffset = clampInt(m.doctorOffset+1, 0, m.maxDoctorOffset())
+	case "home":
+		m.doctorOffset = 0
+	case "r":
+		if !m.doctorLoading {
+			m.doctorLoading = true
+			return m, m.loadDoctorCmd()
+		}
+	}
+	return m, nil
+}
+
+// loadDoctorCmd запускает диагностику маршрута remote → local в фоне.
+func (m *AppModel) loadDoctorCmd() tea.Cmd {
+	diagnoser, ok := m.runner.(Diagnoser)
+	return func() tea.Msg {
+		if !ok {
+			return doctorLoadedMsg{Err: fmt.Errorf("diagnostics are not supported by the sync runner")}
+		}
+		report, err := diagnoser.Diagnose("", "")
+		return doctorLoadedMsg{Report: report, Err: err}
+	}
+}
+
+func (m *AppModel) loadDiffCmd(databaseName string) tea.Cmd {
+	differ, ok := m.browser.(SchemaDiffer)
+	return func() tea.Msg {
+		if !ok {
+			return schemaDiffLoadedMsg{DatabaseName: databaseName, Err: fmt.Errorf("schema diff is not supported by the database browser")}
+		}
+		diff, err := differ.DiffSchema(databaseName)
+		return schemaDiffLoadedMsg{DatabaseName: databaseName, Diff: diff, Err: err}
+	}
+}
+
+// loadIncrementalPlansCmd запрашивает разбиение целей на incremental и full таблицы.
+func (m *AppModel) loadIncrementalPlansCmd() tea.Cmd {
+	planner, ok := m.runner.(IncrementalPlanner)
+	if !ok || m.cfg == nil || !m.cfg.Incremental.Enabled {
+		m.incrementalPlans = make(map[string]*models.IncrementalPlan)
+		m.incrementalErrors = make(map[string]string)
+		return nil
+	}
+	m.incrementalLoading = true
+	targets := m.buildPlan().Targets
+	return func() tea.Msg {
+		msg := incrementalPlansLoadedMsg{Plans: make(map[string]*models.IncrementalPlan), Errors: make(map[string]string)}
+		for _, target := range targets {
+			plan, err := planner.PlanIncremental(target)
+			if err != nil {
+				msg.Errors[target.DatabaseName] = err.Error()
+				continue
+			}
+			msg.Plans[target.DatabaseName] = plan
+		}
+		return msg
+	}
+}
+
+// loadDiskPreflightCmd запрашивает оценку свободного места под дамп и загрузку плана.
+func (m *AppModel) loadDiskPreflightCmd() tea.Cmd {
+	checker, ok := m.runner.(DiskSpaceChecker)
+	if !ok {
+		m.diskPreflight = nil
+		m.diskError = ""
+		return nil
+	}
+	m.diskLoading = true
+	plan := m.buildPlan()
+	return func() tea.Msg {
+		preflight, err := checker.CheckDiskSpace(plan)
+		return diskPreflightLoadedMsg{Preflight: preflight, Err: err}
+	}
+}
+
+// loadSmartPlansCmd запрашивает решения skip/refresh по таблицам целей плана.
+func (m *AppModel) loadSmartPlansCmd() tea.Cmd {
+	planner, ok := m.runner.(SmartSyncPlanner)
+	if !ok || m.cfg == nil || !m.cfg.Smart.Enabled {
+		m.smartPlans = make(map[string]*models.SmartSyncPlan)
+		m.smartErrors = make(map[string]string)
+		return nil
+	}
+	m.smartLoading = true
+	targets := m.buildPlan().Targets
+	return func() tea.Msg {
+		msg := smartPlansLoadedMsg{Plans: make(map[string]*models.SmartSyncPlan), Errors: make(map[string]string)}
+		for _, target := range targets {
+			plan, err := planner.PlanSmartSync(target)
+			if err != nil {
+				msg.Errors[target.DatabaseName] = err.Error()
+				continue
+			}
+			msg.Plans[target.DatabaseName] = plan
+		}
+		return msg
+	}
+}
+
+func (m *AppModel) diffPageRows() int {
+	return maxInt(m.height-14, 5)
+}
+
+func (m *AppModel) maxDoctorOffset() int {
+	return maxInt(len(renderDoctorLines(m.doctorReport))-m.diffPageRows(), 0)
+}
+
+func (m *AppModel) maxDiffOffset() int {
+	return maxInt(len(renderSchemaDiffLines(m.diff))-m.diffPageRows(), 0)
+}
+
+func (m *AppModel) undoableResults() []models.SyncResult {
+	var results []models.SyncResult
+	for _, result := range m.runningResults {
+		if result.Backup != nil && !result.RolledBack {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// pullFromBucket по первому нажатию P запрашивает подтверждение, по второму заменяет локальную БД
+// самым новым архивом из бакета.
+func (m *AppModel) pullFromBucket(databaseName string) tea.Cmd {
+	if m.pullRunning {
+		return nil
+	}
+	if m.cfg == nil || !m.cfg.Storage.Enabled() {
+		m.setNotice(warnStyle.Render("Object storage is not configured: set Storage Bucket in settings"))
+		return nil
+	}
+	if m.pullArmed != databaseName {
+		m.pullArmed = databaseName
+		m.setNotice(warnStyle.Render(fmt.Sprintf("Press P again to replace local %s with its newest bucket archive", databaseName)))
+		return nil
+	}
+	if m.cfg.Safety.IsProtected(databaseName) && !m.protectedConfirmed[databaseName] {
+		m.pullArmed = ""
+		m.setNotice(dangerStyle.Render(fmt.Sprintf("%s is protected by the safety policy: use dbsync pull --confirm %s", databaseName, databaseName)))
+		return nil
+	}
+	m.pullArmed = ""
+	m.pullRunning = true
+	m.setNotice(warnStyle.Render(fmt.Sprintf("Pulling %s from bucket %s...", databaseName, m.cfg.Storage.Bucket)))
+	puller, ok := m.runner.(BucketPuller)
+	return func() tea.Msg {
+		if !ok {
+			return bucketPullDoneMsg{DatabaseName: databaseName, Err: fmt.Errorf("bucket pull is not supported by the sync executor")}
+		}
+		if confirmer, ok := puller.(DatabaseConfirmer); ok {
+			confirmer.SetConfirmedDatabases([]string{databaseName})
+		}
+		manifest, err := puller.PullFromBucket(databaseName, "", "", nil)
+		return bucketPullDoneMsg{DatabaseName: databaseName, Manifest: manifest, Err: err}
+	}
+}
+
+func (m *AppModel) restoreBackupsCmd() tea.Cmd {
+	results := m.undoableResults()
+	restorer, ok := m.runner.(BackupRestorer)
+	return func() tea.Msg {
+		if !ok {
+			return backupRestoreDoneMsg{Err: fmt.Errorf("backup restore is not supported by the sync executor")}
+		}
+		// Откатываемые БД уже подтверждены планом, который их заменил.
+		if confirmer, ok := restorer.(DatabaseConfirmer); ok {
+			names := make([]string, 0, len(results))
+			for _, result := range results {
+				names = append(names, result.DatabaseName)
+			}
+			confirmer.SetConfirmedDatabases(names)
+		}
+		var restored []string
+		for _, result := range results {
+			if _, err := restorer.RestoreLocalBackup(result.DatabaseName, result.Backup.Path, nil); err != nil {
+				return backupRestoreDoneMsg{Restored: restored, Err: fmt.Errorf("%s: %w", result.DatabaseName, err)}
+			}
+			restored = append(restored, result.DatabaseName)
+		}
+		return backupRestoreDoneMsg{Restored: restored}
+	}
+}
+
 func (m *AppModel) View() string {
 	base := pageStyle.Render(strings.Join([]string{m.renderHeader(), "", m.renderBody(), "", m.renderFooter()}, "\n"))
 	if m.showHelp {
@@ -755,7 +1328,52 @@ func (m *AppModel) renderHeader() string {
 	} else {
 		stats = append(stats, fmt.Sprintf("Mode: %s", okStyle.Render("DIRECT")))
 	}
-	return strings.Join([]string{headerStyle.Render("DBSync Control Center"), subtleStyle.Render(strings.Join(stats, "   "))}, "\n")
+	lines := []string{headerStyle.Render("DBSync Control Center"), subtleStyle.Render(strings.Join(stats, "   "))}
+	if schedules := m.renderSchedulesLine(time.Now()); schedules != "" {
+		lines = append(lines, schedules)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSchedulesLine показывает ближайшие запуски расписаний daemon и итог последнего запуска.
+func (m *AppModel) renderSchedulesLine(now time.Time) string {
+	if m.scheduleError != "" {
+		return warnStyle.Render("Schedules: " + m.scheduleError)
+	}
+	if len(m.schedules) == 0 {
+		return ""
+	}
+	const shown = 3
+	parts := make([]string, 0, shown+1)
+	for i, status := range m.schedules {
+		if i == shown {
+			parts = append(parts, fmt.Sprintf("+%d more", len(m.schedules)-shown))
+			break
+		}
+		part := sizeStyle.Render(status.Name)
+		if !status.NextRun.IsZero() {
+			layout := "Mon 15:04"
+			if status.NextRun.Sub(now) >= 6*24*time.Hour {
+				layout = "Jan 02 15:04"
+			}
+			part += " " + status.NextRun.Format(layout)
+			if until := status.NextRun.Sub(now); until > 0 {
+				part += " (in " + ui.FormatDuration(until.Truncate(time.Minute)) + ")"
+			}
+		}
+		if last := status.LastRun; last != nil {
+			switch last.Status {
+			case models.ScheduleRunFailed:
+				part += " " + dangerStyle.Render("last failed")
+			case models.ScheduleRunSkipped:
+				part += " " + warnStyle.Render("last skipped")
+			default:
+				part += " " + okStyle.Render("last ok")
+			}
+		}
+		parts = append(parts, part)
+	}
+	return subtleStyle.Render("Next scheduled: ") + strings.Join(parts, subtleStyle.Render(", "))
 }
 
 func (m *AppModel) renderBody() string {
@@ -779,6 +1397,10 @@ func (m *AppModel) renderMainContent(width int) string {
 		return m.renderRunningView(width)
 	case viewReport:
 		return m.renderReportView(width)
+	case viewDiff:
+		return m.renderDiffView(width)
+	case viewDoctor:
+		return m.renderDoctorView(width)
 	default:
 		return ""
 	}
@@ -893,21 +1515,44 @@ func (m *AppModel) renderConfirmView(width int) string {
 	if len(plan.Targets) == 0 {
 		return wrapLines([]string{"No sync targets selected."}, width)
 	}
-	lines := []string{headerStyle.UnsetBackground().Render("Confirm Sync Plan"), "", fmt.Sprintf("Databases: %d", len(plan.Targets)), fmt.Sprintf("Estimated source data: %s", ui.FormatSize(plan.EstimatedLogicalSize)), ""}
+	lines := []string{headerStyle.UnsetBackground().Render("Confirm Sync Plan"), "", fmt.Sprintf("Databases: %d", len(plan.Targets)), fmt.Sprintf("Estimated source data: %s", ui.FormatSize(plan.EstimatedLogicalSize)), fmt.Sprintf("Destination: %s", m.cfg.Local.Address())}
+	if !m.cfg.Local.IsLocalhost() {
+		lines = append(lines, dangerStyle.Render("⚠ Destination is not localhost: its databases will be dropped and replaced"))
+	}
+	if !m.cfg.Safety.DestinationAllowed(m.cfg.Local) {
+		lines = append(lines, dangerStyle.Render("⚠ Destination is not listed in DBSYNC_SAFETY_ALLOWED_HOSTS: the safety policy will refuse the sync"))
+	}
+	lines = append(lines, m.renderDiskPreflight()...)
+	lines = append(lines, "")
 	for _, target := range plan.Targets {
 		mode := okStyle.Render("FULL DB")
 		if len(target.SelectedTables) > 0 {
 			mode = warnStyle.Render(fmt.Sprintf("%d selected tables", len(target.SelectedTables)))
 		}
 		line := fmt.Sprintf("%s  %s", selectedRowStyle.Render(target.DatabaseName), mode)
+		if m.cfg.Safety.IsProtected(target.DatabaseName) {
+			if m.protectedConfirmed[target.DatabaseName] {
+				line += "  " + warnStyle.Render("PROTECTED, confirmed")
+			} else {
+				line += "  " + dangerStyle.Render("PROTECTED")
+			}
+		}
+		if target.Consistent {
+			line += "  " + okStyle.Render("consistent snapshot")
+		}
 		lines = append(lines, line)
 		if len(target.AutoIncludedTables) > 0 {
 			lines = append(lines, subtleStyle.Render("  auto: "+strings.Join(target.AutoIncludedTables, ", ")))
 		}
+		lines = append(lines, m.renderIncrementalPlan(target)...)
 	}
 	cancelButton := renderButton("Cancel", m.confirmChoice == confirmCancel, false)
 	syncButton := renderButton("Sync", m.confirmChoice == confirmSync, true)
 	lines = append(lines, "", lipgloss.JoinHorizontal(lipgloss.Top, cancelButton, "   ", syncButton))
+	if m.protectedEditing {
+		name := m.pendingProtectedDatabase(plan)
+		lines = append(lines, "", dangerStyle.Render(fmt.Sprintf("Type '%s' to confirm replacing the protected database", name)), fmt.Sprintf("%s%s", m.protectedBuffer, cursorSuffix()))
+	}
 	return wrapLines(lines, width)
 }
 
@@ -916,7 +1561,7 @@ func (m *AppModel) renderPlanView(width int) string {
 	if len(plan.Targets) == 0 {
 		return wrapLines([]string{"No sync targets selected."}, width)
 	}
-	lines := []string{headerStyle.UnsetBackground().Render("Plan Editor"), "", subtleStyle.Render("Review the queue before destructive sync. Enter edits tables, X removes a target, Y continues."), ""}
+	lines := []string{headerStyle.UnsetBackground().Render("Plan Editor"), "", subtleStyle.Render("Review the queue before destructive sync. Enter edits tables, X removes a target, F forces a full resync, O takes a consistent snapshot, Y continues."), ""}
 	for index, target := range plan.Targets {
 		prefix := "  "
 		mode := okStyle.Render("full database")
@@ -924,6 +1569,13 @@ func (m *AppModel) renderPlanView(width int) string {
 			mode = warnStyle.Render(fmt.Sprintf("%d tables", len(target.SelectedTables)))
 		}
 		row := fmt.Sprintf("%s  %s  %s", padRight(target.DatabaseName, 28), mode, subtleStyle.Render(ui.FormatSize(m.targetLogicalSize(target))))
+		if target.ForceFull {
+			row += "  " + warnStyle.Render("force full")
+		}
+		if target.Consistent {
+			row += "  " + okStyle.Render("consistent")
+		}
+		row += m.renderSmartSummary(target)
 		if index == m.planCursor {
 			prefix = keyStyle.Render("▸ ")
 			row = selectedRowStyle.Render(row)
@@ -932,8 +1584,12 @@ func (m *AppModel) renderPlanView(width int) string {
 		if index == m.planCursor && len(target.AutoIncludedTables) > 0 {
 			lines = append(lines, subtleStyle.Render("    auto: "+strings.Join(target.AutoIncludedTables, ", ")))
 		}
+		if index == m.planCursor {
+			lines = append(lines, m.renderSmartDecisions(target)...)
+		}
 	}
 	lines = append(lines, "", fmt.Sprintf("Estimated source data: %s", sizeStyle.Render(ui.FormatSize(plan.EstimatedLogicalSize))))
+	lines = append(lines, m.renderDiskPreflight()...)
 	return wrapLines(lines, width)
 }
 
@@ -1021,6 +1677,7 @@ func (m *AppModel) renderRunningView(width int) string {
 		fmt.Sprintf("Current step: %s", m.runningMessage()),
 	}
 	lines = append(lines, m.renderRunningPhaseBreakdown()...)
+	lines = append(lines, m.renderRunningTablePanel(width)...)
 	lines = append(lines,
 		"",
 		subtleStyle.Render("Speed and ETA are oriented around downloaded dump traffic; compressed dump size is a separate local disk metric."),
@@ -1036,6 +1693,9 @@ func (m *AppModel) renderReportView(width int) string {
 	if m.runningError != "" {
 		lines = append(lines, dangerStyle.Render("Run stopped with error: "+m.runningError), "")
 	}
+	if m.undoStatus != "" {
+		lines = append(lines, m.undoStatus, "")
+	}
 	var totalLogical int64
 	var totalIndex int64
 	var totalDownloaded int64
@@ -1067,6 +1727,15 @@ func (m *AppModel) renderReportView(width int) string {
 			fmt.Sprintf("  dump phase: %s   restore phase: %s", ui.FormatDuration(result.DumpDuration), ui.FormatDuration(result.RestoreDuration)),
 		)
 		lines = append(lines, m.renderPhaseBreakdown(result.DatabaseName)...)
+		lines = append(lines, m.renderSlowestTables(result)...)
+		lines = append(lines, renderHookResults(result.Hooks)...)
+		lines = append(lines, renderBackupStatus(result)...)
+		lines = append(lines, renderVerification(result.Verification)...)
+		lines = append(lines, renderIncrementalResult(result.Incremental)...)
+		lines = append(lines, renderSmartSyncResult(result.SmartSync)...)
+		lines = append(lines, renderSnapshot(result.Snapshot)...)
+		lines = append(lines, renderCompatibility(result.Compatibility)...)
+		lines = append(lines, renderServerTuning(result.ServerTuning)...)
 		lines = append(lines,
 			fmt.Sprintf("  %s", dumpLabel),
 			fmt.Sprintf("  total duration: %s", ui.FormatDuration(result.Duration)),
@@ -1077,7 +1746,9 @@ func (m *AppModel) renderReportView(width int) string {
 			fmt.Sprintf("  %s", indexLabel),
 			"",
 		)
-		if result.Error != "" {
+		if result.FailureType == models.FailureTypePolicy {
+			lines = append(lines, dangerStyle.Render("  safety policy: "+result.Error))
+		} else if result.Error != "" {
 			lines = append(lines, dangerStyle.Render("  "+result.Error))
 		}
 	}
@@ -1098,22 +1769,29 @@ func (m *AppModel) renderReportView(width int) string {
 func (m *AppModel) renderFooter() string {
 	switch m.view {
 	case viewList:
-		return subtleStyle.Render(fmt.Sprintf("%s move   %s select DB   %s tables   %s select all   %s clear   %s reload   %s confirm   %s settings", keyStyle.Render("↑/↓"), keyStyle.Render("Space"), keyStyle.Render("Enter"), keyStyle.Render("A"), keyStyle.Render("C"), keyStyle.Render("R"), keyStyle.Render("Y"), keyStyle.Render("S")))
+		return subtleStyle.Render(fmt.Sprintf("%s move   %s select DB   %s tables   %s select all   %s clear   %s reload   %s diff   %s diagnostics   %s pull from bucket   %s confirm   %s settings", keyStyle.Render("↑/↓"), keyStyle.Render("Space"), keyStyle.Render("Enter"), keyStyle.Render("A"), keyStyle.Render("C"), keyStyle.Render("R"), keyStyle.Render("D"), keyStyle.Render("I"), keyStyle.Render("P"), keyStyle.Render("Y"), keyStyle.Render("S")))
 	case viewTables:
 		return subtleStyle.Render(fmt.Sprintf("%s move   %s toggle table   %s filter   %s select all   %s clear   %s confirm", keyStyle.Render("↑/↓"), keyStyle.Render("Space"), keyStyle.Render("/"), keyStyle.Render("A"), keyStyle.Render("C"), keyStyle.Render("Y/Enter")))
 	case viewPlan:
-		return subtleStyle.Render(fmt.Sprintf("%s move   %s edit target   %s remove   %s clear   %s continue", keyStyle.Render("↑/↓"), keyStyle.Render("Enter"), keyStyle.Render("X"), keyStyle.Render("C"), keyStyle.Render("Y")))
+		return subtleStyle.Render(fmt.Sprintf("%s move   %s edit target   %s remove   %s clear   %s force full   %s consistent   %s continue", keyStyle.Render("↑/↓"), keyStyle.Render("Enter"), keyStyle.Render("X"), keyStyle.Render("C"), keyStyle.Render("F"), keyStyle.Render("O"), keyStyle.Render("Y")))
 	case viewConfirm:
-		return subtleStyle.Render(fmt.Sprintf("%s switch   %s start sync   %s arm/start   %s back", keyStyle.Render("←/→/Tab"), keyStyle.Render("Enter"), keyStyle.Render("Y"), keyStyle.Render("Esc")))
+		if m.protectedEditing {
+			return subtleStyle.Render(fmt.Sprintf("%s database name   %s confirm   %s cancel", keyStyle.Render("Type"), keyStyle.Render("Enter"), keyStyle.Render("Esc")))
+		}
+		return subtleStyle.Render(fmt.Sprintf("%s switch   %s start sync   %s arm/start   %s force full   %s back", keyStyle.Render("←/→/Tab"), keyStyle.Render("Enter"), keyStyle.Render("Y"), keyStyle.Render("F"), keyStyle.Render("Esc")))
 	case viewSettings:
 		if m.settingsEditing {
 			return subtleStyle.Render(fmt.Sprintf("%s input   %s apply   %s cancel", keyStyle.Render("Type"), keyStyle.Render("Enter"), keyStyle.Render("Esc")))
 		}
 		return subtleStyle.Render(fmt.Sprintf("%s move   %s edit   %s toggle   %s remote test   %s local test   %s save", keyStyle.Render("↑/↓"), keyStyle.Render("Enter"), keyStyle.Render("Space"), keyStyle.Render("R"), keyStyle.Render("L"), keyStyle.Render("W")))
 	case viewRunning:
-		return subtleStyle.Render("Sync is running. Press ? for help.")
+		return subtleStyle.Render(fmt.Sprintf("Sync is running.   %s scroll tables   %s help", keyStyle.Render("↑/↓/PgUp/PgDn"), keyStyle.Render("?")))
 	case viewReport:
-		return subtleStyle.Render(fmt.Sprintf("%s quit   %s back to list", keyStyle.Render("Enter/Q/Esc"), keyStyle.Render("B")))
+		return subtleStyle.Render(fmt.Sprintf("%s quit   %s back to list   %s undo from backup", keyStyle.Render("Enter/Q/Esc"), keyStyle.Render("B"), keyStyle.Render("U")))
+	case viewDiff:
+		return subtleStyle.Render(fmt.Sprintf("%s scroll   %s reload   %s back", keyStyle.Render("↑/↓/PgUp/PgDn"), keyStyle.Render("R"), keyStyle.Render("Esc/B")))
+	case viewDoctor:
+		return subtleStyle.Render(fmt.Sprintf("%s scroll   %s re-run checks   %s back", keyStyle.Render("↑/↓"), keyStyle.Render("R"), keyStyle.Render("Esc/B")))
 	default:
 		return ""
 	}
@@ -1126,7 +1804,10 @@ func (m *AppModel) renderHelpDialog() string {
 		"List view",
 		"  Space toggles databases into the sync queue",
 		"  Enter opens table drill-down for the current database",
-		"  R reloads the remote database inventory with current settings",
+		"  R reloads the remote database inventory and scheduled runs",
+		"  D compares remote and local schema of the current database",
+		"  I checks dump tools, server versions, privileges and settings",
+		"  P twice restores the current database from its newest bucket archive",
 		"  Y opens the plan editor for all selected databases",
 		"",
 		"Tables view",
@@ -1137,12 +1818,17 @@ func (m *AppModel) renderHelpDialog() string {
 		"Plan view",
 		"  Enter re-opens the highlighted target for editing",
 		"  X removes a target from the queue",
+		"  F toggles a forced full resync for the target",
+		"  O toggles a consistent snapshot dump for the target",
+		"  Shows skip/refresh decisions per table when smart sync is on",
 		"  Y continues to destructive confirmation",
 		"",
 		"Confirm view",
 		"  Sync is selected by default",
 		"  Enter starts the sync",
 		"  Y also arms sync immediately",
+		"  Lists incremental and full tables when incremental sync is on",
+		"  F toggles a forced full resync for all targets",
 		"",
 		"Settings view",
 		"  Enter edits the selected field",
@@ -1152,6 +1838,13 @@ func (m *AppModel) renderHelpDialog() string {
 		"",
 		"Running view",
 		"  Shows queue progress, elapsed time, ETA estimate and average transfer metrics",
+		"  Up/Down and PgUp/PgDn scroll the per-table panel",
+		"",
+		"Report view",
+		"  Lists the slowest tables of every target",
+		"  Shows hook results with the tail of their output",
+		"  Shows post-sync verification mismatches per table",
+		"  U twice restores local databases from their pre-sync backups",
 		"",
 		subtleStyle.Render("Press Esc, Enter, Space or ? to close help."),
 	}
@@ -1178,6 +1871,9 @@ func (m *AppModel) viewLabel() string {
 	case viewPlan:
 		return "Reviewing and editing sync plan"
 	case viewConfirm:
+		if m.protectedEditing {
+			return dangerStyle.Render("Confirming protected database")
+		}
 		return dangerStyle.Render("Awaiting destructive confirmation")
 	case viewSettings:
 		if m.settingsEditing {
@@ -1188,6 +1884,10 @@ func (m *AppModel) viewLabel() string {
 		return warnStyle.Render("Running destructive sync queue")
 	case viewReport:
 		return okStyle.Render("Run finished")
+	case viewDiff:
+		return "Comparing remote and local schema"
+	case viewDoctor:
+		return "Checking environment and privileges"
 	default:
 		return ""
 	}
@@ -1375,7 +2075,7 @@ func (m *AppModel) targetForDatabase(name string) models.SyncTarget {
 		auto = append(auto, tableName)
 	}
 	sort.Strings(auto)
-	target := models.SyncTarget{DatabaseName: name, ReplaceEntireDatabase: true}
+	target := models.SyncTarget{DatabaseName: name, ReplaceEntireDatabase: true, ForceFull: m.forceFull[name], Consistent: m.consistent[name] || m.cfg.Dump.Consistent}
 	if len(effective) > 0 {
 		target.SelectedTables = effective
 		target.AutoIncludedTables = auto
@@ -1392,6 +2092,9 @@ func (m *AppModel) buildPlan() *models.SyncPlan {
 		target := m.targetForDatabase(name)
 		plan.Targets = append(plan.Targets, target)
 		plan.EstimatedLogicalSize += m.targetLogicalSize(target)
+		if m.protectedConfirmed[name] {
+			plan.ConfirmedDatabases = append(plan.ConfirmedDatabases, name)
+		}
 	}
 	return plan
 }
@@ -1464,6 +2167,17 @@ func (m *AppModel) connectionTestCmd(remote bool) tea.Cmd {
 	}
 }
 
+func (m *AppModel) loadSchedulesCmd() tea.Cmd {
+	lister, ok := m.runner.(ScheduleLister)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		schedules, err := lister.ListScheduleStatuses()
+		return schedulesLoadedMsg{Schedules: schedules, Err: err}
+	}
+}
+
 func (m *AppModel) reloadDatabasesCmd() tea.Cmd {
 	return func() tea.Msg {
 		if m.browser == nil {
@@ -1643,6 +2357,8 @@ func (m *AppModel) runningPhaseDetailLabel() string {
 		return "Dump subphase"
 	case models.SyncPhaseRestore:
 		return "Restore subphase"
+	case models.SyncPhaseHooks:
+		return "Running hook"
 	default:
 		return "Phase detail"
 	}
@@ -1687,9 +2403,13 @@ func (m *AppModel) drainRunChannels() (planRunDone, bool) {
 			case snapshot := <-m.runProgressCh:
 				m.currentProgress = mergeProgressSnapshot(m.currentProgress, snapshot)
 				m.recordPhaseTiming(m.currentProgress)
+				if len(snapshot.Tables) > 0 && m.currentProgress.DatabaseName != "" {
+					m.tableProgress[m.currentProgress.DatabaseName] = snapshot.Tables
+				}
 				if snapshot.DatabaseName != "" && snapshot.DatabaseName != m.runningTargetName {
 					m.runningTargetName = snapshot.DatabaseName
 					m.runningTargetStarted = snapshot.Timestamp
+					m.tablePanelOffset = 0
 				}
 				if snapshot.Phase == models.SyncPhaseDone {
 					m.finalizePhaseTiming(snapshot.DatabaseName, snapshot.Timestamp)
@@ -1774,6 +2494,9 @@ func (m *AppModel) renderPhaseBreakdown(databaseName string) []string {
 	if restoreLines := renderPhaseDurationLines("  restore breakdown:", tracker.durations[models.SyncPhaseRestore], []string{"Preparing local restore", "Applying schema metadata", "Loading table data", "Rebuilding indexes", "Finalizing restore"}); len(restoreLines) > 0 {
 		lines = append(lines, restoreLines...)
 	}
+	if hookLines := renderPhaseDurationLines("  hooks breakdown:", tracker.durations[models.SyncPhaseHooks], nil); len(hookLines) > 0 {
+		lines = append(lines, hookLines...)
+	}
 	return lines
 }
 
@@ -1792,9 +2515,509 @@ func (m *AppModel) renderRunningPhaseBreakdown() []string {
 	if restoreLines := renderPhaseDurationLines("  restore breakdown:", durations[models.SyncPhaseRestore], []string{"Preparing local restore", "Applying schema metadata", "Loading table data", "Rebuilding indexes", "Finalizing restore"}); len(restoreLines) > 0 {
 		lines = append(lines, restoreLines...)
 	}
+	if hookLines := renderPhaseDurationLines("  hooks breakdown:", durations[models.SyncPhaseHooks], nil); len(hookLines) > 0 {
+		lines = append(lines, hookLines...)
+	}
+	return lines
+}
+
+func (m *AppModel) scrollTablePanel(delta int) {
+	total := len(m.tableProgress[m.runningTargetName])
+	maxOffset := maxInt(total-runningTablePanelRows, 0)
+	m.tablePanelOffset = clampInt(m.tablePanelOffset+delta, 0, maxOffset)
+}
+
+func (m *AppModel) renderRunningTablePanel(width int) []string {
+	tables := m.tableProgress[m.runningTargetName]
+	if len(tables) == 0 {
+		return nil
+	}
+	finished := 0
+	for _, table := range tables {
+		if table.IsFinished() {
+			finished++
+		}
+	}
+	offset := clampInt(m.tablePanelOffset, 0, maxInt(len(tables)-runningTablePanelRows, 0))
+	end := minInt(offset+runningTablePanelRows, len(tables))
+	nameWidth := clampInt(width-54, 12, 40)
+	lines := []string{
+		"",
+		subtleStyle.Render(fmt.Sprintf("Tables: %d/%d done   showing %d-%d", finished, len(tables), offset+1, end)),
+		subtleStyle.Render(fmt.Sprintf("  %s %s %s %s %s", padRight("table", nameWidth), padRight("state", 9), padLeft("dumped", 10), padLeft("loaded", 10), padLeft("rows", 14))),
+	}
+	for _, table := range tables[offset:end] {
+		name := truncateTableName(table.Name, nameWidth)
+		if table.Name == m.currentProgress.TableName {
+			name = selectedRowStyle.Render(name)
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s %s %s %s",
+			padRight(name, nameWidth),
+			padRight(renderTableState(table.State), 9),
+			padLeft(formatOptionalSize(table.DumpBytes), 10),
+			padLeft(formatOptionalSize(table.LoadedBytes), 10),
+			padLeft(tableRowsLabel(table), 14),
+		))
+	}
+	if len(tables) > runningTablePanelRows {
+		lines = append(lines, subtleStyle.Render("  ↑/↓ and PgUp/PgDn scroll the table list"))
+	}
+	return lines
+}
+
+func (m *AppModel) renderSlowestTables(result models.SyncResult) []string {
+	if len(result.Tables) == 0 {
+		result.Tables = m.tableProgress[result.DatabaseName]
+	}
+	slowest := result.SlowestTables(reportSlowestTablesLimit)
+	if len(slowest) == 0 {
+		return nil
+	}
+	lines := []string{"  slowest tables:"}
+	for _, table := range slowest {
+		lines = append(lines, fmt.Sprintf("    %s  %s (dump %s, load %s)  %s",
+			selectedRowStyle.Render(table.Name),
+			ui.FormatDuration(table.Duration()),
+			ui.FormatDuration(table.DumpDuration),
+			ui.FormatDuration(table.LoadDuration),
+			mutedValueStyle.Render(ui.FormatSize(table.SourceBytes)),
+		))
+	}
+	return lines
+}
+
+func renderHookResults(hooks []models.HookResult) []string {
+	if len(hooks) == 0 {
+		return nil
+	}
+	lines := []string{"  hooks:"}
+	for _, hook := range hooks {
+		status := okStyle.Render("OK")
+		if !hook.Success {
+			status = dangerStyle.Render("FAILED")
+		}
+		lines = append(lines, fmt.Sprintf("    %s %s %s (%s, %s)", status, subtleStyle.Render(string(hook.Point)), hook.Name, hook.Kind, ui.FormatDuration(hook.Duration)))
+		for _, outputLine := range lastLines(hook.Output, reportHookOutputLines) {
+			lines = append(lines, mutedValueStyle.Render("      "+outputLine))
+		}
+		if hook.Error != "" {
+			lines = append(lines, dangerStyle.Render("      "+hook.Error))
+		}
+	}
 	return lines
 }
 
+func (m *AppModel) renderDiffView(width int) string {
+	lines := []string{headerStyle.UnsetBackground().Render("Schema Diff: " + m.diffDatabase), subtleStyle.Render("Local compared to remote; use `dbsync diff --format sql|json` to export."), ""}
+	if m.diffLoading {
+		return wrapLines(append(lines, warnStyle.Render("Reading information_schema on both servers...")), width)
+	}
+	if m.diffError != "" {
+		return wrapLines(append(lines, dangerStyle.Render(m.diffError)), width)
+	}
+	diffLines := renderSchemaDiffLines(m.diff)
+	offset := clampInt(m.diffOffset, 0, maxInt(len(diffLines)-m.diffPageRows(), 0))
+	end := minInt(offset+m.diffPageRows(), len(diffLines))
+	lines = append(lines, diffLines[offset:end]...)
+	if len(diffLines) > end-offset {
+		lines = append(lines, "", subtleStyle.Render(fmt.Sprintf("lines %d-%d of %d", offset+1, end, len(diffLines))))
+	}
+	return wrapLines(lines, width)
+}
+
+func (m *AppModel) renderDoctorView(width int) string {
+	lines := []string{headerStyle.UnsetBackground().Render("Diagnostics: remote → local"), subtleStyle.Render("Use `dbsync doctor --from --to` to check other endpoints."), ""}
+	if m.doctorLoading {
+		return wrapLines(append(lines, warnStyle.Render("Checking dump tools, servers and privileges...")), width)
+	}
+	if m.doctorError != "" {
+		return wrapLines(append(lines, dangerStyle.Render(m.doctorError)), width)
+	}
+	doctorLines := renderDoctorLines(m.doctorReport)
+	offset := clampInt(m.doctorOffset, 0, maxInt(len(doctorLines)-m.diffPageRows(), 0))
+	end := minInt(offset+m.diffPageRows(), len(doctorLines))
+	lines = append(lines, doctorLines[offset:end]...)
+	if len(doctorLines) > end-offset {
+		lines = append(lines, "", subtleStyle.Render(fmt.Sprintf("lines %d-%d of %d", offset+1, end, len(doctorLines))))
+	}
+	return wrapLines(lines, width)
+}
+
+func renderDoctorLines(report *models.DoctorReport) []string {
+	if report == nil {
+		return nil
+	}
+	var lines []string
+	for _, check := range report.Checks {
+		switch check.Status {
+		case models.DoctorStatusFail:
+			lines = append(lines, dangerStyle.Render("✗ "+check.Name)+"  "+check.Detail)
+		case models.DoctorStatusWarn:
+			lines = append(lines, warnStyle.Render("! "+check.Name)+"  "+check.Detail)
+		default:
+			lines = append(lines, okStyle.Render("✓ "+check.Name)+"  "+subtleStyle.Render(check.Detail))
+		}
+		if check.Hint != "" {
+			lines = append(lines, mutedValueStyle.Render("    → "+check.Hint))
+		}
+	}
+	lines = append(lines, "")
+	if failures := report.Failures(); failures > 0 {
+		lines = append(lines, dangerStyle.Render(fmt.Sprintf("%d of %d checks failed", failures, len(report.Checks))))
+	} else {
+		lines = append(lines, okStyle.Render("All required checks passed"))
+	}
+	return lines
+}
+
+func renderSchemaDiffLines(diff *models.SchemaDiff) []string {
+	if diff == nil {
+		return nil
+	}
+	if !diff.LocalExists {
+		return []string{warnStyle.Render(fmt.Sprintf("Local database does not exist; %d remote tables will be created by sync", len(diff.MissingTables)))}
+	}
+	if diff.Empty() {
+		return []string{okStyle.Render("Local database matches remote schema and row counts")}
+	}
+	lines := []string{fmt.Sprintf("missing locally: %s   only local: %s   changed: %s",
+		sizeStyle.Render(strconv.Itoa(len(diff.MissingTables))),
+		sizeStyle.Render(strconv.Itoa(len(diff.ExtraTables))),
+		sizeStyle.Render(strconv.Itoa(len(diff.Tables)))), ""}
+	for _, table := range diff.MissingTables {
+		lines = append(lines, dangerStyle.Render("- "+table.Name)+subtleStyle.Render(fmt.Sprintf("  missing locally, %s rows on remote", formatGroupedInt64(table.Rows))))
+	}
+	for _, table := range diff.ExtraTables {
+		lines = append(lines, okStyle.Render("+ "+table.Name)+subtleStyle.Render(fmt.Sprintf("  only local, %s rows", formatGroupedInt64(table.Rows))))
+	}
+	for _, table := range diff.Tables {
+		lines = append(lines, warnStyle.Render("~ "+table.Name))
+		if table.EngineChanged {
+			lines = append(lines, fmt.Sprintf("    engine: %s → %s", table.RemoteEngine, table.LocalEngine))
+		}
+		for _, column := range table.MissingColumns {
+			lines = append(lines, dangerStyle.Render("    - column ")+column.Name+" "+mutedValueStyle.Render(column.Type))
+		}
+		for _, column := range table.ExtraColumns {
+			lines = append(lines, okStyle.Render("    + column ")+column.Name+" "+mutedValueStyle.Render(column.Type))
+		}
+		for _, change := range table.ChangedColumns {
+			lines = append(lines, warnStyle.Render("    ~ column ")+change.Name+" "+mutedValueStyle.Render(columnSummary(change.Remote)+" → "+columnSummary(change.Local)))
+		}
+		for _, index := range table.MissingIndexes {
+			lines = append(lines, dangerStyle.Render("    - index ")+index.Name+" "+mutedValueStyle.Render("("+strings.Join(index.Columns, ", ")+")"))
+		}
+		for _, index := range table.ExtraIndexes {
+			lines = append(lines, okStyle.Render("    + index ")+index.Name+" "+mutedValueStyle.Render("("+strings.Join(index.Columns, ", ")+")"))
+		}
+		for _, change := range table.ChangedIndexes {
+			lines = append(lines, warnStyle.Render("    ~ index ")+change.Name+" "+mutedValueStyle.Render("("+strings.Join(change.Remote.Columns, ", ")+") → ("+strings.Join(change.Local.Columns, ", ")+")"))
+		}
+		if delta := table.RowDelta(); delta != 0 {
+			approx := ""
+			if table.RowsApproximate {
+				approx = " ~"
+			}
+			lines = append(lines, fmt.Sprintf("    rows: remote %s, local %s (%+d)%s", formatGroupedInt64(table.RemoteRows), formatGroupedInt64(table.LocalRows), delta, approx))
+		}
+	}
+	return lines
+}
+
+func columnSummary(column models.ColumnSchema) string {
+	summary := column.Type
+	if column.Nullable {
+		summary += " NULL"
+	} else {
+		summary += " NOT NULL"
+	}
+	if column.Default != nil {
+		summary += " DEFAULT " + *column.Default
+	}
+	return summary
+}
+
+func renderVerification(verification *models.VerificationResult) []string {
+	if verification == nil {
+		return nil
+	}
+	if verification.Error != "" {
+		return []string{dangerStyle.Render("  verification error: " + verification.Error)}
+	}
+	mismatched := verification.MismatchedTables()
+	if len(mismatched) == 0 {
+		return []string{fmt.Sprintf("  verification: %s (%s)", okStyle.Render(fmt.Sprintf("%d tables match remote", len(verification.Tables))), ui.FormatDuration(verification.Duration))}
+	}
+	lines := []string{fmt.Sprintf("  verification: %s (%s)", dangerStyle.Render(fmt.Sprintf("%d/%d tables differ from remote", len(mismatched), len(verification.Tables))), ui.FormatDuration(verification.Duration))}
+	for index, table := range mismatched {
+		if index == reportVerifyMismatchLimit {
+			lines = append(lines, subtleStyle.Render(fmt.Sprintf("    … %d more tables", len(mismatched)-index)))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("    %s %s: %s (rows remote %s, local %s)", dangerStyle.Render("✗"), table.Name, strings.Join(table.Mismatches, ", "), formatGroupedInt64(table.RemoteRows), formatGroupedInt64(table.LocalRows)))
+	}
+	return lines
+}
+
+// renderDiskPreflight показывает оценку дампа и загрузки плана и проблемы со свободным местом.
+func (m *AppModel) renderDiskPreflight() []string {
+	if m.diskLoading {
+		return []string{subtleStyle.Render("Disk space: checking...")}
+	}
+	if m.diskError != "" {
+		return []string{warnStyle.Render("Disk space check failed: " + m.diskError)}
+	}
+	preflight := m.diskPreflight
+	if preflight == nil {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("Estimated dump on disk: %s, restored size: %s", ui.FormatSize(preflight.DumpBytes), ui.FormatSize(preflight.RestoreBytes))}
+	for _, check := range preflight.Checks {
+		label := fmt.Sprintf("%s %s: need ~%s, free %s", check.Purpose, check.Path, ui.FormatSize(check.RequiredBytes), ui.FormatSize(check.FreeBytes))
+		switch {
+		case check.Status == models.DiskSpaceInsufficient && preflight.Blocking:
+			lines = append(lines, dangerStyle.Render("⚠ Not enough disk space, "+label))
+		case check.Status == models.DiskSpaceInsufficient:
+			lines = append(lines, warnStyle.Render("⚠ Not enough disk space, "+label))
+		case check.Status == models.DiskSpaceLow:
+			lines = append(lines, warnStyle.Render("⚠ Low disk space, "+label))
+		default:
+			lines = append(lines, subtleStyle.Render(label))
+		}
+	}
+	return lines
+}
+
+// renderIncrementalPlan показывает на экране подтверждения, какие таблицы цели пойдут incremental, а какие full.
+func (m *AppModel) renderIncrementalPlan(target models.SyncTarget) []string {
+	if m.cfg == nil || !m.cfg.Incremental.Enabled {
+		if target.ForceFull {
+			return []string{warnStyle.Render("  forced full resync")}
+		}
+		return nil
+	}
+	if m.incrementalLoading {
+		return []string{subtleStyle.Render("  incremental: checking watermarks...")}
+	}
+	if message, ok := m.incrementalErrors[target.DatabaseName]; ok {
+		return []string{dangerStyle.Render("  incremental check failed: " + message)}
+	}
+	plan := m.incrementalPlans[target.DatabaseName]
+	if plan == nil {
+		return nil
+	}
+	if !plan.Active() {
+		style := subtleStyle
+		if target.ForceFull {
+			style = warnStyle
+		}
+		return []string{style.Render(fmt.Sprintf("  full resync: %s", plan.Reason))}
+	}
+	lines := []string{fmt.Sprintf("  %s %s", okStyle.Render(fmt.Sprintf("incremental (%d):", len(plan.Incremental))), strings.Join(plan.IncrementalTableNames(), ", "))}
+	if len(plan.Full) > 0 {
+		lines = append(lines, fmt.Sprintf("  %s %s", warnStyle.Render(fmt.Sprintf("full (%d):", len(plan.Full))), strings.Join(plan.Full, ", ")))
+	}
+	return lines
+}
+
+func renderIncrementalResult(plan *models.IncrementalPlan) []string {
+	if plan == nil {
+		return nil
+	}
+	if !plan.Active() {
+		return []string{subtleStyle.Render("  incremental: full resync (" + plan.Reason + ")")}
+	}
+	return []string{fmt.Sprintf("  incremental: %s, full: %d", okStyle.Render(fmt.Sprintf("%d tables upserted past watermark", len(plan.Incremental))), len(plan.Full))}
+}
+
+// renderSmartSummary возвращает краткую сводку smart sync для строки цели в редакторе плана.
+func (m *AppModel) renderSmartSummary(target models.SyncTarget) string {
+	if m.cfg == nil || !m.cfg.Smart.Enabled {
+		return ""
+	}
+	if m.smartLoading {
+		return "  " + subtleStyle.Render("checking tables...")
+	}
+	if _, ok := m.smartErrors[target.DatabaseName]; ok {
+		return "  " + dangerStyle.Render("smart check failed")
+	}
+	plan := m.smartPlans[target.DatabaseName]
+	if plan == nil {
+		return ""
+	}
+	skipped := plan.SkippedTables()
+	summary := fmt.Sprintf("skip %d / refresh %d", len(skipped), len(plan.RefreshedTables()))
+	if len(skipped) == 0 {
+		return "  " + subtleStyle.Render(summary)
+	}
+	return "  " + okStyle.Render(summary) + "  " + subtleStyle.Render("saves "+ui.FormatSize(plan.SavedBytes()))
+}
+
+// renderSmartDecisions раскрывает решения skip/refresh по таблицам выбранной цели.
+func (m *AppModel) renderSmartDecisions(target models.SyncTarget) []string {
+	if m.cfg == nil || !m.cfg.Smart.Enabled || m.smartLoading {
+		return nil
+	}
+	if message, ok := m.smartErrors[target.DatabaseName]; ok {
+		return []string{dangerStyle.Render("    smart check failed: " + message)}
+	}
+	plan := m.smartPlans[target.DatabaseName]
+	if plan == nil || len(plan.Tables) == 0 {
+		return nil
+	}
+	var lines []string
+	if skipped := plan.SkippedTables(); len(skipped) > 0 {
+		lines = append(lines, fmt.Sprintf("    %s %s", okStyle.Render(fmt.Sprintf("skip (%d):", len(skipped))), strings.Join(skipped, ", ")))
+	}
+	if plan.Reason != "" {
+		return append(lines, subtleStyle.Render(fmt.Sprintf("    refresh all: %s", plan.Reason)))
+	}
+	refreshed := make([]string, 0, len(plan.Tables))
+	for _, decision := range plan.Tables {
+		if decision.Action == models.TableActionRefresh {
+			refreshed = append(refreshed, fmt.Sprintf("%s (%s)", decision.Name, decision.Reason))
+		}
+	}
+	if len(refreshed) > 0 {
+		lines = append(lines, fmt.Sprintf("    %s %s", warnStyle.Render(fmt.Sprintf("refresh (%d):", len(refreshed))), strings.Join(refreshed, ", ")))
+	}
+	return lines
+}
+
+func renderSmartSyncResult(plan *models.SmartSyncPlan) []string {
+	if plan == nil || len(plan.Tables) == 0 {
+		return nil
+	}
+	skipped := plan.SkippedTables()
+	if len(skipped) == 0 {
+		return []string{subtleStyle.Render(fmt.Sprintf("  smart sync: all %d tables refreshed", len(plan.Tables)))}
+	}
+	return []string{fmt.Sprintf("  smart sync: %s, refreshed: %d, saved %s", okStyle.Render(fmt.Sprintf("%d unchanged tables skipped", len(skipped))), len(plan.RefreshedTables()), ui.FormatSize(plan.SavedBytes()))}
+}
+
+// renderSnapshot показывает, был ли дамп согласованным снимком, и его GTID.
+func renderSnapshot(snapshot *models.DumpSnapshot) []string {
+	if snapshot == nil || (!snapshot.Requested && !snapshot.Consistent) {
+		return nil
+	}
+	if !snapshot.Consistent {
+		return []string{warnStyle.Render("  snapshot: not consistent, " + snapshot.Warning)}
+	}
+	line := "  snapshot: " + okStyle.Render("consistent")
+	if snapshot.Lock != "" {
+		line += " (" + string(snapshot.Lock) + ")"
+	}
+	switch {
+	case snapshot.GTIDExecuted != "":
+		line += ", GTID " + mutedValueStyle.Render(snapshot.GTIDExecuted)
+	case snapshot.BinlogFile != "":
+		line += fmt.Sprintf(", binlog %s:%d", snapshot.BinlogFile, snapshot.BinlogPosition)
+	}
+	return []string{line}
+}
+
+// renderCompatibility перечисляет исправления совместимости, примененные к объектам источника.
+func renderCompatibility(fixes []models.CompatibilityFix) []string {
+	if len(fixes) == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("  compatibility: %s", warnStyle.Render(fmt.Sprintf("%d fixes applied", len(fixes))))}
+	for _, fix := range fixes {
+		lines = append(lines, fmt.Sprintf("    %s: %s", fix.Object, mutedValueStyle.Render(fix.Fix)))
+	}
+	return lines
+}
+
+// renderServerTuning показывает переменные локального MySQL, измененные на время загрузки, и их восстановление.
+func renderServerTuning(variables []models.ServerVariable) []string {
+	if len(variables) == 0 {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("  server tuning: %d variables changed for load", len(variables))}
+	for _, variable := range variables {
+		status := okStyle.Render("restored")
+		switch {
+		case variable.Error != "":
+			status = dangerStyle.Render("restore failed: " + variable.Error)
+		case !variable.Restored:
+			status = warnStyle.Render("not restored")
+		}
+		lines = append(lines, fmt.Sprintf("    %s: %s → %s, %s", variable.Name, variable.Original, variable.Applied, status))
+	}
+	return lines
+}
+
+func renderBackupStatus(result models.SyncResult) []string {
+	if result.Backup == nil {
+		return nil
+	}
+	lines := []string{fmt.Sprintf("  local backup: %s (%s)", mutedValueStyle.Render(result.Backup.Path), ui.FormatSize(result.Backup.SizeBytes))}
+	switch {
+	case result.RolledBack:
+		lines = append(lines, warnStyle.Render("  restored from local backup"))
+	case result.RollbackError != "":
+		lines = append(lines, dangerStyle.Render("  rollback failed: "+result.RollbackError))
+	}
+	return lines
+}
+
+func lastLines(value string, limit int) []string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	lines := strings.Split(value, "\n")
+	if len(lines) > limit {
+		lines = append([]string{fmt.Sprintf("… %d more lines", len(lines)-limit)}, lines[len(lines)-limit:]...)
+	}
+	return lines
+}
+
+func renderTableState(state models.TableSyncState) string {
+	switch state {
+	case models.TableStateDone:
+		return okStyle.Render(string(state))
+	case models.TableStateDumping, models.TableStateLoading, models.TableStateIndexing:
+		return warnStyle.Render(string(state))
+	case models.TableStateDumped:
+		return mutedValueStyle.Render(string(state))
+	default:
+		return subtleStyle.Render(string(state))
+	}
+}
+
+func tableRowsLabel(table models.TableProgress) string {
+	if table.Rows == 0 && table.RowsTotal == 0 {
+		return "-"
+	}
+	if table.RowsTotal == 0 {
+		return formatGroupedInt64(table.Rows)
+	}
+	total := formatGroupedInt64(table.RowsTotal)
+	if table.RowsApprox {
+		total = "~" + total
+	}
+	if table.Rows == 0 && !table.IsFinished() {
+		return total
+	}
+	return formatGroupedInt64(table.Rows) + "/" + total
+}
+
+func formatOptionalSize(value int64) string {
+	if value <= 0 {
+		return "-"
+	}
+	return ui.FormatSize(value)
+}
+
+func truncateTableName(name string, width int) string {
+	runes := []rune(name)
+	if len(runes) <= width || width < 2 {
+		return name
+	}
+	return string(runes[:width-1]) + "…"
+}
+
 func (m *AppModel) runningPhaseDurationsSnapshot(databaseName string, now time.Time) map[models.SyncPhase]map[string]time.Duration {
 	tracker := m.phaseTimings[databaseName]
 	if tracker == nil {
@@ -2158,6 +3381,234 @@ func (m *AppModel) initSettingsFields() {
 			cfg.Log.Format = strings.TrimSpace(value)
 			return cfg.Validate()
 		}},
+		{Label: "Hooks File", Description: "JSON file with per-database pre/post-sync hooks (default ~/.dbsync.hooks.json).", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Hooks.File }, Set: func(cfg *config.Config, value string) error {
+			cfg.Hooks.File = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Backup Before Drop", Description: "Back up the local database before a sync overwrites it and roll back on failure.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Backup.Enabled) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("backup before drop must be true or false")
+			}
+			cfg.Backup.Enabled = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Backup Keep", Description: "How many local backups to keep per database.", Kind: settingsFieldInt, Get: func(cfg *config.Config) string { return strconv.Itoa(cfg.Backup.Keep) }, Set: func(cfg *config.Config, value string) error {
+			keep, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("backup keep must be a number")
+			}
+			cfg.Backup.Keep = keep
+			return cfg.Validate()
+		}},
+		{Label: "Verify After Sync", Description: "Compare row counts, checksums and table DDL against remote after restore.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Verify.Enabled) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("verify after sync must be true or false")
+			}
+			cfg.Verify.Enabled = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Verify Checksum", Description: "Run CHECKSUM TABLE on both sides for tables under the size limit.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Verify.Checksum) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("verify checksum must be true or false")
+			}
+			cfg.Verify.Checksum = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Checksum Max MB", Description: "Tables larger than this are verified by row count and DDL only.", Kind: settingsFieldInt, Get: func(cfg *config.Config) string { return strconv.Itoa(cfg.Verify.ChecksumMaxMB) }, Set: func(cfg *config.Config, value string) error {
+			limit, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("checksum max mb must be a number")
+			}
+			cfg.Verify.ChecksumMaxMB = limit
+			return cfg.Validate()
+		}},
+		{Label: "Fail On Mismatch", Description: "Fail the target (and roll back when backups are on) if verification finds differences.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Verify.FailOnMismatch) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("fail on mismatch must be true or false")
+			}
+			cfg.Verify.FailOnMismatch = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Incremental Sync", Description: "Upsert only rows past the last high-water mark for tables with updated_at or an AUTO_INCREMENT key.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Incremental.Enabled) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("incremental sync must be true or false")
+			}
+			cfg.Incremental.Enabled = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Incremental Column", Description: "Timestamp column used as the incremental high-water mark (default updated_at).", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Incremental.Column }, Set: func(cfg *config.Config, value string) error {
+			cfg.Incremental.Column = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Smart Sync", Description: "Skip tables unchanged since the last sync (UPDATE_TIME, rows, data length) and keep the local copies.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Smart.Enabled) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("smart sync must be true or false")
+			}
+			cfg.Smart.Enabled = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Smart Checksum", Description: "Compare CHECKSUM TABLE on the remote instead of metadata only (slower, exact).", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Smart.Checksum) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("smart checksum must be true or false")
+			}
+			cfg.Smart.Checksum = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Dump Engine", Description: "auto, mysqlsh, mydumper, mysqldump or native; auto falls back when MySQL Shell is not installed.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Dump.Engine }, Set: func(cfg *config.Config, value string) error {
+			cfg.Dump.Engine = value
+			return cfg.Validate()
+		}},
+		{Label: "Stream Copy", Description: "Copy full databases with mysqlsh copy-schemas straight into local MySQL, without a dump directory.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Dump.Copy) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("stream copy must be true or false")
+			}
+			cfg.Dump.Copy = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Dump Batch Size", Description: "Dump and load up to N whole databases with one mysqlsh call; 1 disables batching.", Kind: settingsFieldInt, Get: func(cfg *config.Config) string { return strconv.Itoa(cfg.Dump.BatchSize) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("dump batch size must be a number")
+			}
+			cfg.Dump.BatchSize = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Storage Endpoint", Description: "S3-compatible endpoint like http://localhost:9000 for MinIO; empty means AWS S3.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Storage.Endpoint }, Set: func(cfg *config.Config, value string) error {
+			cfg.Storage.Endpoint = value
+			return cfg.Validate()
+		}},
+		{Label: "Storage Bucket", Description: "Bucket for archives from dbsync export --to-bucket; P on the list pulls from it.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Storage.Bucket }, Set: func(cfg *config.Config, value string) error {
+			cfg.Storage.Bucket = value
+			return cfg.Validate()
+		}},
+		{Label: "Storage Prefix", Description: "Key prefix for archives inside the bucket.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Storage.Prefix }, Set: func(cfg *config.Config, value string) error {
+			cfg.Storage.Prefix = value
+			return cfg.Validate()
+		}},
+		{Label: "Storage Access Key", Description: "Access key ID for the bucket; empty sends anonymous requests.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Storage.AccessKey }, Set: func(cfg *config.Config, value string) error {
+			cfg.Storage.AccessKey = value
+			return cfg.Validate()
+		}},
+		{Label: "Storage Secret Key", Description: "Secret access key for the bucket.", Kind: settingsFieldPassword, MaskValue: true, Get: func(cfg *config.Config) string { return cfg.Storage.SecretKey }, Set: func(cfg *config.Config, value string) error {
+			cfg.Storage.SecretKey = value
+			return cfg.Validate()
+		}},
+		{Label: "Local Proxy URL", Description: "Optional socks5/http proxy URL for writes to the destination server.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Local.ProxyURL }, Set: func(cfg *config.Config, value string) error {
+			cfg.Local.ProxyURL = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Endpoints File", Description: "JSON file with named source/destination servers for dbsync sync --from/--to (default ~/.dbsync.endpoints.json).", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Endpoints.File }, Set: func(cfg *config.Config, value string) error {
+			cfg.Endpoints.File = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Protected Databases", Description: "Comma-separated name patterns (e.g. prod_*,billing) that require typing the database name before sync; built-in names like mysql, prod and main are always protected.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Safety.ProtectedDatabases }, Set: func(cfg *config.Config, value string) error {
+			cfg.Safety.ProtectedDatabases = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Allowed Hosts", Description: "Comma-separated destination hosts, globs or CIDRs allowed besides loopback, e.g. *.staging.internal,10.0.0.0/8.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Safety.AllowedHosts }, Set: func(cfg *config.Config, value string) error {
+			cfg.Safety.AllowedHosts = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Disk Preflight", Description: "What to do when the dump or the restore may not fit on disk: block, warn or off.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Preflight.Disk }, Set: func(cfg *config.Config, value string) error {
+			cfg.Preflight.Disk = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Disk Headroom %", Description: "Free space to keep on top of the estimate before warning about low disk space.", Kind: settingsFieldInt, Get: func(cfg *config.Config) string { return strconv.Itoa(cfg.Preflight.HeadroomPercent) }, Set: func(cfg *config.Config, value string) error {
+			percent, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("disk headroom must be a number")
+			}
+			cfg.Preflight.HeadroomPercent = percent
+			return cfg.Validate()
+		}},
+		{Label: "Dump Work Dir", Description: "Directory for temporary dumps, unpacked archives and downloads; empty uses the system temp directory.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Dump.WorkDir }, Set: func(cfg *config.Config, value string) error {
+			cfg.Dump.WorkDir = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Load Tuning", Description: "Local MySQL tuning during load: safe enables only local_infile, fast also disables the redo log and relaxes flushing. Originals are restored afterwards.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Tuning.Mode }, Set: func(cfg *config.Config, value string) error {
+			cfg.Tuning.Mode = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Tuning Buffer MB", Description: "InnoDB log and DDL buffer size used by fast load tuning; larger server values are kept.", Kind: settingsFieldInt, Get: func(cfg *config.Config) string { return strconv.Itoa(cfg.Tuning.BufferSizeMB) }, Set: func(cfg *config.Config, value string) error {
+			size, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || size <= 0 {
+				return fmt.Errorf("tuning buffer size must be a positive number")
+			}
+			cfg.Tuning.BufferSizeMB = size
+			return cfg.Validate()
+		}},
+		{Label: "Consistent Dump", Description: "Dump every target as a consistent snapshot with FTWRL or a backup lock and record its GTID; O in the plan editor enables it for one target.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Dump.Consistent) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("consistent dump must be true or false")
+			}
+			cfg.Dump.Consistent = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Definers", Description: "DEFINER of views, routines, events and triggers: keep, strip (mysqlsh strip_definers) or rewrite to Definer Account.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Compat.Definers }, Set: func(cfg *config.Config, value string) error {
+			cfg.Compat.Definers = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Definer Account", Description: "Account used by definers=rewrite: user@host or CURRENT_USER.", Kind: settingsFieldString, Get: func(cfg *config.Config) string { return cfg.Compat.DefinerAccount }, Set: func(cfg *config.Config, value string) error {
+			cfg.Compat.DefinerAccount = strings.TrimSpace(value)
+			return cfg.Validate()
+		}},
+		{Label: "Strip Restricted Grants", Description: "Remove privileges the destination refuses to grant, such as SUPER (mysqlsh only).", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Compat.StripRestrictedGrants) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("strip restricted grants must be true or false")
+			}
+			cfg.Compat.StripRestrictedGrants = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Skip Invalid Accounts", Description: "Skip accounts without a password or with unsupported authentication plugins (mysqlsh only).", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Compat.SkipInvalidAccounts) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("skip invalid accounts must be true or false")
+			}
+			cfg.Compat.SkipInvalidAccounts = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Force InnoDB", Description: "Convert tables in other storage engines such as MyISAM to InnoDB (mysqlsh only).", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Compat.ForceInnoDB) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("force innodb must be true or false")
+			}
+			cfg.Compat.ForceInnoDB = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Skip Routines", Description: "Do not dump stored procedures and functions.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Compat.SkipRoutines) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("skip routines must be true or false")
+			}
+			cfg.Compat.SkipRoutines = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Skip Events", Description: "Do not dump scheduled events.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Compat.SkipEvents) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("skip events must be true or false")
+			}
+			cfg.Compat.SkipEvents = parsed
+			return cfg.Validate()
+		}},
+		{Label: "Skip Triggers", Description: "Do not dump triggers.", Kind: settingsFieldBool, Get: func(cfg *config.Config) string { return strconv.FormatBool(cfg.Compat.SkipTriggers) }, Set: func(cfg *config.Config, value string) error {
+			parsed, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				return fmt.Errorf("skip triggers must be true or false")
+			}
+			cfg.Compat.SkipTriggers = parsed
+			return cfg.Validate()
+		}},
 	}
 }
 
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -25,6 +26,14 @@ type mockBrowser struct {
 	remoteErr     error
 	localErr      error
 	listTablesErr error
+	diffsByDB     map[string]*models.SchemaDiff
+}
+
+func (m *mockBrowser) DiffSchema(databaseName string) (*models.SchemaDiff, error) {
+	if diff, ok := m.diffsByDB[databaseName]; ok {
+		return diff, nil
+	}
+	return nil, fmt.Errorf("no diff for %s", databaseName)
 }
 
 func (m *mockBrowser) TestConnection(isRemote bool) (*models.ConnectionInfo, error) {
@@ -50,8 +59,44 @@ func (m *mockBrowser) ListTableDependencies(databaseName string, tableNames []st
 }
 
 type mockRunner struct {
-	results map[string]*models.SyncResult
-	errs    map[string]error
+	results  map[string]*models.SyncResult
+	errs     map[string]error
+	restored []string
+	pulled   []string
+}
+
+func (m *mockRunner) PullFromBucket(databaseName string, key string, asName string, observer models.ProgressObserver) (*models.ArchiveManifest, error) {
+	m.pulled = append(m.pulled, databaseName)
+	return &models.ArchiveManifest{DatabaseName: databaseName, SourceHost: "db.example.com", Tables: []models.ArchiveTable{{Name: "users"}}, CreatedAt: time.Now()}, nil
+}
+
+func (m *mockRunner) RestoreLocalBackup(databaseName string, backupPath string, observer models.ProgressObserver) (*models.LocalBackup, error) {
+	m.restored = append(m.restored, databaseName+"@"+backupPath)
+	return &models.LocalBackup{DatabaseName: databaseName, Path: backupPath}, nil
+}
+
+func (m *mockRunner) PlanIncremental(target models.SyncTarget) (*models.IncrementalPlan, error) {
+	if target.ForceFull {
+		return &models.IncrementalPlan{DatabaseName: target.DatabaseName, Full: []string{"orders", "users"}, Reason: "full resync forced"}, nil
+	}
+	return &models.IncrementalPlan{
+		DatabaseName: target.DatabaseName,
+		Incremental:  []models.IncrementalTable{{Name: "orders", Column: "updated_at", Kind: models.WatermarkTimestamp, Since: "2026-01-01 00:00:00"}},
+		Full:         []string{"users"},
+	}, nil
+}
+
+func (m *mockRunner) PlanSmartSync(target models.SyncTarget) (*models.SmartSyncPlan, error) {
+	if target.ForceFull {
+		return &models.SmartSyncPlan{DatabaseName: target.DatabaseName, Reason: "full resync forced", Tables: []models.TableDecision{
+			{Name: "orders", Action: models.TableActionRefresh, Reason: "full resync forced", Bytes: 4096},
+			{Name: "users", Action: models.TableActionRefresh, Reason: "full resync forced", Bytes: 2048},
+		}}, nil
+	}
+	return &models.SmartSyncPlan{DatabaseName: target.DatabaseName, Tables: []models.TableDecision{
+		{Name: "orders", Action: models.TableActionRefresh, Reason: "changed on remote", Bytes: 4096},
+		{Name: "users", Action: models.TableActionSkip, Reason: "unchanged since last sync", Bytes: 2048},
+	}}, nil
 }
 
 func (m *mockRunner) ExecuteTarget(target models.SyncTarget) (*models.SyncResult, error) {
@@ -181,6 +226,55 @@ func TestConfirmEnterStartsRunning(t *testing.T) {
 	assert.Equal(t, "beta", app.runningTargetName)
 }
 
+func TestConfirmProtectedDatabaseRequiresTypedName(t *testing.T) {
+	model := newTestModel()
+	model.cfg.Safety.ProtectedDatabases = "be*"
+	model.selectedDatabases["beta"] = true
+	model.view = viewConfirm
+	model.confirmChoice = confirmSync
+
+	assert.Contains(t, stripANSI(model.renderConfirmView(120)), "PROTECTED")
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app := updated.(*AppModel)
+	require.True(t, app.protectedEditing)
+	assert.False(t, app.running)
+	assert.Contains(t, stripANSI(app.renderConfirmView(120)), "Type 'beta' to confirm")
+
+	for _, r := range "bet" {
+		updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app = updated.(*AppModel)
+	assert.False(t, app.running, "a wrong name must not start the sync")
+
+	for _, r := range "beta" {
+		updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	updated, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app = updated.(*AppModel)
+	require.NotNil(t, cmd)
+	assert.True(t, app.running)
+	assert.Equal(t, []string{"beta"}, app.runningPlan.ConfirmedDatabases)
+}
+
+func TestConfirmBlockedByDiskPreflight(t *testing.T) {
+	model := newTestModel()
+	model.selectedDatabases["beta"] = true
+	model.view = viewConfirm
+	model.confirmChoice = confirmSync
+	model.diskPreflight = &models.DiskPreflight{
+		DumpBytes: 2048,
+		Checks:    []models.DiskSpaceCheck{{Purpose: "dump", Path: "/tmp", RequiredBytes: 2048, FreeBytes: 1024, Status: models.DiskSpaceInsufficient}},
+		Blocking:  true,
+	}
+
+	assert.Contains(t, stripANSI(model.renderConfirmView(120)), "Not enough disk space, dump /tmp")
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	app := updated.(*AppModel)
+	assert.False(t, app.running, "a blocking disk preflight must not start the sync")
+	assert.Contains(t, stripANSI(app.notice), "DBSYNC_PREFLIGHT_DISK=warn")
+}
+
 func TestListYOpensPlanView(t *testing.T) {
 	model := newTestModel()
 	model.selectedDatabases["beta"] = true
@@ -229,6 +323,20 @@ func TestConfirmCtrlMStartsRunning(t *testing.T) {
 	assert.True(t, app.running)
 }
 
+func TestRenderConfirmViewWarnsAboutRemoteDestination(t *testing.T) {
+	model := newTestModel()
+	model.selectedDatabases["beta"] = true
+	model.view = viewConfirm
+
+	rendered := stripANSI(model.renderConfirmView(120))
+	assert.Contains(t, rendered, "Destination: localhost:3306")
+	assert.NotContains(t, rendered, "not localhost")
+
+	model.cfg.Local.Host = "staging.internal"
+	rendered = stripANSI(model.renderConfirmView(120))
+	assert.Contains(t, rendered, "Destination is not localhost")
+}
+
 func TestRenderConfirmViewPlacesButtonsOnSameRow(t *testing.T) {
 	model := newTestModel()
 	model.selectedDatabases["beta"] = true
@@ -583,6 +691,376 @@ func TestRenderReportViewShowsDumpAndRestoreDurations(t *testing.T) {
 	assert.NotContains(t, rendered, "Total source")
 }
 
+func TestRenderRunningViewShowsScrollableTablePanel(t *testing.T) {
+	model := newTestModel()
+	model.view = viewRunning
+	model.running = true
+	model.runningPlan = &models.SyncPlan{Targets: []models.SyncTarget{{DatabaseName: "beta"}}}
+	model.runningTargetName = "beta"
+	model.runningStartedAt = time.Now().Add(-5 * time.Second)
+	model.runningNow = time.Now()
+	model.runProgressCh = make(chan models.ProgressSnapshot, 1)
+	tables := make([]models.TableProgress, 0, 12)
+	for index := 0; index < 12; index++ {
+		tables = append(tables, models.TableProgress{Name: fmt.Sprintf("table_%02d", index), State: models.TableStatePending})
+	}
+	tables[0] = models.TableProgress{Name: "table_00", State: models.TableStateLoading, DumpBytes: 2 * 1024 * 1024, LoadedBytes: 1024 * 1024, Rows: 1500, RowsTotal: 3000}
+	model.runProgressCh <- models.ProgressSnapshot{Phase: models.SyncPhaseRestore, DatabaseName: "beta", TableName: "table_00", Tables: tables, Timestamp: time.Now()}
+	model.drainRunChannels()
+
+	rendered := stripANSI(model.renderRunningView(100))
+	assert.Contains(t, rendered, "Tables: 0/12 done   showing 1-8")
+	assert.Contains(t, rendered, "loading")
+	assert.Contains(t, rendered, "1 500/3 000")
+	assert.NotContains(t, rendered, "table_11")
+
+	model.handleRunningKey(tea.KeyMsg{Type: tea.KeyPgDown})
+	rendered = stripANSI(model.renderRunningView(100))
+	assert.Contains(t, rendered, "showing 5-12")
+	assert.Contains(t, rendered, "table_11")
+	assert.NotContains(t, rendered, "table_00")
+}
+
+func TestRenderReportViewShowsSlowestTables(t *testing.T) {
+	model := newTestModel()
+	model.view = viewReport
+	model.runningResults = []models.SyncResult{{
+		DatabaseName: "beta",
+		Success:      true,
+		Tables: []models.TableProgress{
+			{Name: "users", State: models.TableStateDone, DumpDuration: time.Second, LoadDuration: time.Second},
+			{Name: "events", State: models.TableStateDone, SourceBytes: 5 * 1024 * 1024, DumpDuration: 20 * time.Second, LoadDuration: 10 * time.Second},
+		},
+	}}
+
+	rendered := stripANSI(model.renderReportView(120))
+	assert.Contains(t, rendered, "slowest tables:")
+	assert.Contains(t, rendered, "events  30.0s (dump 20.0s, load 10.0s)  5.0 MB")
+	assert.Less(t, strings.Index(rendered, "events"), strings.Index(rendered, "users"))
+}
+
+func TestRenderReportViewShowsHookResults(t *testing.T) {
+	model := newTestModel()
+	model.view = viewReport
+	model.runningResults = []models.SyncResult{{
+		DatabaseName: "beta",
+		Success:      false,
+		Error:        `after_restore hook "migrate" failed: exit status 1`,
+		Hooks: []models.HookResult{
+			{Point: models.HookAfterRestore, Name: "reset.sql", Kind: "sql", Success: true, Duration: 2 * time.Second},
+			{Point: models.HookAfterRestore, Name: "migrate", Kind: "command", Output: "line1\nline2\nline3\nline4\nline5\nline6", Error: "exit status 1", Duration: time.Second},
+		},
+	}}
+
+	rendered := stripANSI(model.renderReportView(120))
+	assert.Contains(t, rendered, "hooks:")
+	assert.Contains(t, rendered, "OK after_restore reset.sql (sql, 2.0s)")
+	assert.Contains(t, rendered, "FAILED after_restore migrate (command, 1.0s)")
+	assert.Contains(t, rendered, "… 1 more lines")
+	assert.Contains(t, rendered, "line6")
+	assert.NotContains(t, rendered, "line1")
+}
+
+func TestRenderReportViewShowsVerification(t *testing.T) {
+	model := newTestModel()
+	model.view = viewReport
+	model.runningResults = []models.SyncResult{
+		{DatabaseName: "alpha", Success: true, Verification: &models.VerificationResult{Tables: []models.TableVerification{{Name: "users", RemoteRows: 3, LocalRows: 3}}, Duration: time.Second}},
+		{DatabaseName: "beta", Success: true, Verification: &models.VerificationResult{Tables: []models.TableVerification{
+			{Name: "orders", RemoteRows: 1500, LocalRows: 1499, Mismatches: []string{"row count", "checksum"}},
+			{Name: "users", RemoteRows: 3, LocalRows: 3},
+		}}},
+	}
+
+	rendered := stripANSI(model.renderReportView(120))
+	assert.Contains(t, rendered, "verification: 1 tables match remote (1.0s)")
+	assert.Contains(t, rendered, "verification: 1/2 tables differ from remote")
+	assert.Contains(t, rendered, "✗ orders: row count, checksum (rows remote 1 500, local 1 499)")
+}
+
+func TestRenderReportViewShowsServerTuning(t *testing.T) {
+	model := newTestModel()
+	model.view = viewReport
+	model.runningResults = []models.SyncResult{{
+		DatabaseName: "beta",
+		Success:      true,
+		ServerTuning: []models.ServerVariable{
+			{Name: "local_infile", Original: "OFF", Applied: "ON", Restored: true},
+			{Name: "innodb_redo_log", Original: "ON", Applied: "OFF", Error: "Access denied"},
+		},
+	}}
+
+	rendered := stripANSI(model.renderReportView(120))
+	assert.Contains(t, rendered, "server tuning: 2 variables changed for load")
+	assert.Contains(t, rendered, "local_infile: OFF → ON, restored")
+	assert.Contains(t, rendered, "innodb_redo_log: ON → OFF, restore failed: Access denied")
+}
+
+func TestPlanTogglesConsistentSnapshot(t *testing.T) {
+	model := newTestModel()
+	model.selectedDatabases["beta"] = true
+	model.view = viewPlan
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	app := updated.(*AppModel)
+	assert.True(t, app.buildPlan().Targets[0].Consistent)
+	assert.Contains(t, stripANSI(app.renderPlanView(120)), "consistent")
+	assert.Contains(t, stripANSI(app.renderConfirmView(120)), "consistent snapshot")
+
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	app = updated.(*AppModel)
+	assert.False(t, app.buildPlan().Targets[0].Consistent)
+
+	app.cfg.Dump.Consistent = true
+	assert.True(t, app.buildPlan().Targets[0].Consistent, "dump.consistent applies to every target")
+}
+
+func TestRenderReportViewShowsSnapshot(t *testing.T) {
+	model := newTestModel()
+	model.view = viewReport
+	model.runningResults = []models.SyncResult{
+		{DatabaseName: "beta", Success: true, Snapshot: &models.DumpSnapshot{Requested: true, Consistent: true, Lock: models.SnapshotLockFTWRL, GTIDExecuted: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"}},
+		{DatabaseName: "gamma", Success: true, Snapshot: &models.DumpSnapshot{Requested: true, Warning: "source user has neither RELOAD nor BACKUP_ADMIN with LOCK TABLES"}},
+	}
+
+	rendered := stripANSI(model.renderReportView(160))
+	assert.Contains(t, rendered, "snapshot: consistent (ftwrl), GTID 3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5")
+	assert.Contains(t, rendered, "snapshot: not consistent, source user has neither RELOAD")
+}
+
+func TestRenderReportViewShowsCompatibilityFixes(t *testing.T) {
+	model := newTestModel()
+	model.view = viewReport
+	model.runningResults = []models.SyncResult{{
+		DatabaseName: "beta",
+		Success:      true,
+		Compatibility: []models.CompatibilityFix{
+			{Object: "events", Fix: "skipped by DBSYNC_COMPAT_SKIP_EVENTS"},
+			{Schema: "beta", Object: "view `beta`.`report`", Fix: "definer clause removed"},
+		},
+	}}
+
+	rendered := stripANSI(model.renderReportView(120))
+	assert.Contains(t, rendered, "compatibility: 2 fixes applied")
+	assert.Contains(t, rendered, "view `beta`.`report`: definer clause removed")
+}
+
+func TestListDiffKeyOpensSchemaDiff(t *testing.T) {
+	model := newTestModel()
+	defaultValue := "0"
+	model.browser.(*mockBrowser).diffsByDB = map[string]*models.SchemaDiff{
+		"beta": {
+			DatabaseName:  "beta",
+			LocalExists:   true,
+			MissingTables: []models.TableSchema{{Name: "sessions", Rows: 1200}},
+			ExtraTables:   []models.TableSchema{{Name: "feature_flags", Rows: 2}},
+			Tables: []models.TableDiff{{
+				Name:           "users",
+				ExtraColumns:   []models.ColumnSchema{{Name: "nickname", Type: "varchar(64)"}},
+				ChangedColumns: []models.ColumnChange{{Name: "score", Remote: models.ColumnSchema{Type: "int"}, Local: models.ColumnSchema{Type: "bigint", Default: &defaultValue}}},
+				RemoteRows:     10,
+				LocalRows:      12,
+			}},
+		},
+	}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	app := updated.(*AppModel)
+	assert.Equal(t, viewDiff, app.view)
+	assert.Equal(t, "beta", app.diffDatabase)
+	assert.Contains(t, stripANSI(app.renderDiffView(120)), "Reading information_schema")
+
+	if assert.NotNil(t, cmd) {
+		updated, _ = app.Update(cmd())
+		app = updated.(*AppModel)
+	}
+	rendered := stripANSI(app.renderDiffView(120))
+	assert.Contains(t, rendered, "missing locally: 1   only local: 1   changed: 1")
+	assert.Contains(t, rendered, "- sessions  missing locally, 1 200 rows on remote")
+	assert.Contains(t, rendered, "+ column nickname varchar(64)")
+	assert.Contains(t, rendered, "~ column score int NOT NULL → bigint NOT NULL DEFAULT 0")
+	assert.Contains(t, rendered, "rows: remote 10, local 12 (+2)")
+
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, viewList, updated.(*AppModel).view)
+}
+
+func TestReportUndoRestoresLocalBackups(t *testing.T) {
+	model := newTestModel()
+	model.view = viewReport
+	model.runningResults = []models.SyncResult{
+		{DatabaseName: "beta", Success: true, Backup: &models.LocalBackup{DatabaseName: "beta", Path: "/backups/beta/20260101T000000", SizeBytes: 2048}},
+		{DatabaseName: "gamma", Success: true},
+	}
+	assert.Contains(t, stripANSI(model.renderReportView(120)), "local backup: /backups/beta/20260101T000000 (2.0 KB)")
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	app := updated.(*AppModel)
+	assert.Nil(t, cmd)
+	assert.True(t, app.undoArmed)
+
+	updated, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	app = updated.(*AppModel)
+	if assert.NotNil(t, cmd) {
+		updated, _ = app.Update(cmd())
+		app = updated.(*AppModel)
+	}
+	assert.Equal(t, []string{"beta@/backups/beta/20260101T000000"}, app.runner.(*mockRunner).restored)
+	assert.True(t, app.runningResults[0].RolledBack)
+	rendered := stripANSI(app.renderReportView(120))
+	assert.Contains(t, rendered, "Restored 1 database(s) from local backups")
+	assert.Contains(t, rendered, "restored from local backup")
+}
+
+func TestListPullKeyRestoresFromBucket(t *testing.T) {
+	model := newTestModel()
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	app := updated.(*AppModel)
+	assert.Nil(t, cmd)
+	assert.Contains(t, stripANSI(app.notice), "Object storage is not configured")
+
+	app.cfg.Storage.Bucket = "dumps"
+	updated, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	app = updated.(*AppModel)
+	assert.Nil(t, cmd)
+	assert.Contains(t, stripANSI(app.notice), "Press P again to replace local beta")
+
+	updated, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	app = updated.(*AppModel)
+	if assert.NotNil(t, cmd) {
+		assert.True(t, app.pullRunning)
+		updated, _ = app.Update(cmd())
+		app = updated.(*AppModel)
+	}
+	assert.False(t, app.pullRunning)
+	assert.Equal(t, []string{"beta"}, app.runner.(*mockRunner).pulled)
+	assert.Contains(t, stripANSI(app.notice), "Pulled beta from bucket (1 tables")
+}
+
+func TestConfirmShowsIncrementalPlanAndForcesFull(t *testing.T) {
+	model := newTestModel()
+	model.cfg.Incremental.Enabled = true
+	model.selectedDatabases["beta"] = true
+	model.view = viewPlan
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	app := updated.(*AppModel)
+	assert.Equal(t, viewConfirm, app.view)
+	if assert.NotNil(t, cmd) {
+		updated, _ = app.Update(cmd())
+		app = updated.(*AppModel)
+	}
+	rendered := stripANSI(app.renderConfirmView(120))
+	assert.Contains(t, rendered, "incremental (1): orders")
+	assert.Contains(t, rendered, "full (1): users")
+
+	updated, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	app = updated.(*AppModel)
+	assert.True(t, app.buildPlan().Targets[0].ForceFull)
+	if assert.NotNil(t, cmd) {
+		updated, _ = app.Update(cmd())
+		app = updated.(*AppModel)
+	}
+	assert.Contains(t, stripANSI(app.renderConfirmView(120)), "full resync: full resync forced")
+}
+
+func TestPlanShowsSmartSyncDecisions(t *testing.T) {
+	model := newTestModel()
+	model.cfg.Smart.Enabled = true
+	model.selectedDatabases["beta"] = true
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	app := updated.(*AppModel)
+	assert.Equal(t, viewPlan, app.view)
+	if assert.NotNil(t, cmd) {
+		updated, _ = app.Update(cmd())
+		app = updated.(*AppModel)
+	}
+	rendered := stripANSI(app.renderPlanView(160))
+	assert.Contains(t, rendered, "skip 1 / refresh 1")
+	assert.Contains(t, rendered, "saves 2.0 KB")
+	assert.Contains(t, rendered, "skip (1): users")
+	assert.Contains(t, rendered, "refresh (1): orders (changed on remote)")
+
+	updated, cmd = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	app = updated.(*AppModel)
+	if assert.NotNil(t, cmd) {
+		updated, _ = app.Update(cmd())
+		app = updated.(*AppModel)
+	}
+	rendered = stripANSI(app.renderPlanView(160))
+	assert.Contains(t, rendered, "skip 0 / refresh 2")
+	assert.Contains(t, rendered, "refresh all: full resync forced")
+}
+
+type scheduleRunner struct {
+	*mockRunner
+	schedules []models.ScheduleStatus
+}
+
+func (r *scheduleRunner) ListScheduleStatuses() ([]models.ScheduleStatus, error) {
+	return r.schedules, nil
+}
+
+func TestHeaderShowsNextScheduledRuns(t *testing.T) {
+	model := newTestModel()
+	assert.NotContains(t, stripANSI(model.renderHeader()), "Next scheduled")
+
+	now := time.Now()
+	next := time.Date(now.Year(), now.Month(), now.Day(), 7, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	model.runner = &scheduleRunner{mockRunner: model.runner.(*mockRunner), schedules: []models.ScheduleStatus{
+		{Schedule: models.Schedule{Name: "catalog-morning", Cron: "0 7 * * *", LastRun: &models.ScheduleRun{Status: models.ScheduleRunFailed}}, NextRun: next},
+		{Schedule: models.Schedule{Name: "shop-weekly", Cron: "@weekly"}, NextRun: next.AddDate(0, 0, 7)},
+	}}
+
+	cmd := model.Init()
+	if assert.NotNil(t, cmd) {
+		updated, _ := model.Update(cmd())
+		model = updated.(*AppModel)
+	}
+	rendered := stripANSI(model.renderHeader())
+	assert.Contains(t, rendered, "Next scheduled: catalog-morning "+next.Format("Mon 15:04")+" (in ")
+	assert.Contains(t, rendered, "last failed")
+	assert.Contains(t, rendered, "shop-weekly "+next.AddDate(0, 0, 7).Format("Jan 02 15:04"))
+}
+
+type doctorRunner struct {
+	*mockRunner
+	report *models.DoctorReport
+}
+
+func (r *doctorRunner) Diagnose(from string, to string) (*models.DoctorReport, error) {
+	return r.report, nil
+}
+
+func TestListDiagnosticsKeyOpensDoctor(t *testing.T) {
+	model := newTestModel()
+	model.runner = &doctorRunner{mockRunner: model.runner.(*mockRunner), report: &models.DoctorReport{Checks: []models.DoctorCheck{
+		{Name: "mysqlsh", Status: models.DoctorStatusOK, Detail: "8.4.3 at /usr/bin/mysqlsh"},
+		{Name: "source privileges", Status: models.DoctorStatusFail, Detail: "missing EVENT", Hint: "GRANT EVENT ON *.* TO `reader`@`%`;"},
+		{Name: "sql_mode", Status: models.DoctorStatusWarn, Detail: "only on source: NO_ZERO_DATE"},
+	}}}
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	app := updated.(*AppModel)
+	assert.Equal(t, viewDoctor, app.view)
+	assert.Contains(t, stripANSI(app.renderDoctorView(120)), "Checking dump tools")
+
+	if assert.NotNil(t, cmd) {
+		updated, _ = app.Update(cmd())
+		app = updated.(*AppModel)
+	}
+	rendered := stripANSI(app.renderDoctorView(120))
+	assert.Contains(t, rendered, "✓ mysqlsh  8.4.3 at /usr/bin/mysqlsh")
+	assert.Contains(t, rendered, "✗ source privileges  missing EVENT")
+	assert.Contains(t, rendered, "→ GRANT EVENT ON *.* TO `reader`@`%`;")
+	assert.Contains(t, rendered, "1 of 3 checks failed")
+
+	updated, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, viewList, updated.(*AppModel).view)
+}
+
 func TestViewDoesNotRenderSummarySidebar(t *testing.T) {
 	model := newTestModel()
 	model.selectedDatabases["beta"] = true
@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FollowFunc запускает follow режим и блокируется до отмены ctx или ошибки.
+type FollowFunc func(ctx context.Context, observer models.FollowObserver) error
+
+const (
+	followLagWarn   = 5 * time.Second
+	followLagDanger = time.Minute
+)
+
+type followStatusMsg models.FollowStatus
+
+type followDoneMsg struct {
+	Err error
+}
+
+type followTickMsg time.Time
+
+// FollowModel показывает состояние follow режима: позицию binlog, отставание и примененные изменения.
+type FollowModel struct {
+	cfg          *config.Config
+	databaseName string
+	status       models.FollowStatus
+	cancel       context.CancelFunc
+	stopping     bool
+	done         bool
+	err          error
+	startedAt    time.Time
+	now          time.Time
+	width        int
+}
+
+// NewFollowModel создает экран follow режима; cancel останавливает поток binlog.
+func NewFollowModel(cfg *config.Config, databaseName string, cancel context.CancelFunc) *FollowModel {
+	now := time.Now()
+	return &FollowModel{
+		cfg:          cfg,
+		databaseName: databaseName,
+		status:       models.FollowStatus{Phase: models.FollowPhaseInitialSync, DatabaseName: databaseName, Message: "Connecting"},
+		cancel:       cancel,
+		startedAt:    now,
+		now:          now,
+		width:        120,
+	}
+}
+
+// RunFollow запускает follow в фоне и показывает его состояние до выхода пользователя или ошибки.
+func RunFollow(cfg *config.Config, databaseName string, follow FollowFunc) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	program := tea.NewProgram(NewFollowModel(cfg, databaseName, cancel), tea.WithAltScreen())
+	go func() {
+		err := follow(ctx, func(status models.FollowStatus) {
+			program.Send(followStatusMsg(status))
+		})
+		program.Send(followDoneMsg{Err: err})
+	}()
+	finalModel, err := program.Run()
+	if err != nil {
+		return fmt.Errorf("failed to run follow view: %w", err)
+	}
+	return finalModel.(*FollowModel).err
+}
+
+func (m *FollowModel) Init() tea.Cmd {
+	return followTickCmd()
+}
+
+func followTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return followTickMsg(t) })
+}
+
+func (m *FollowModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+	case followStatusMsg:
+		m.status = models.FollowStatus(msg)
+		return m, nil
+	case followDoneMsg:
+		m.done = true
+		m.err = msg.Err
+		if m.err == nil || m.stopping {
+			return m, tea.Quit
+		}
+		return m, nil
+	case followTickMsg:
+		m.now = time.Time(msg)
+		if m.done {
+			return m, nil
+		}
+		return m, followTickCmd()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			if m.done {
+				return m, tea.Quit
+			}
+			m.stopping = true
+			if m.cancel != nil {
+				m.cancel()
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m *FollowModel) View() string {
+	width := maxInt(m.width-4, 64)
+	header := headerStyle.Render("DBSync Follow")
+	if m.cfg != nil {
+		header += "\n" + subtleStyle.Render(fmt.Sprintf("Remote: %s:%d   Local: %s:%d", m.cfg.Remote.Host, m.cfg.Remote.Port, m.cfg.Local.Host, m.cfg.Local.Port))
+	}
+	body := panelStyle.Width(width).Render(m.renderFollowStatus(width - 4))
+	footer := subtleStyle.Render(fmt.Sprintf("%s stop following", keyStyle.Render("Q/Esc")))
+	if m.done {
+		footer = subtleStyle.Render(fmt.Sprintf("%s exit", keyStyle.Render("Q/Esc")))
+	}
+	return pageStyle.Render(strings.Join([]string{header, "", body, "", footer}, "\n"))
+}
+
+func (m *FollowModel) renderFollowStatus(width int) string {
+	status := m.status
+	lines := []string{
+		fmt.Sprintf("Database: %s", sizeStyle.Render(m.databaseName)),
+		fmt.Sprintf("Phase: %s", m.renderFollowPhase()),
+		fmt.Sprintf("Position: %s", mutedValueStyle.Render(status.Position.String())),
+	}
+	if status.Phase == models.FollowPhaseStreaming || status.Phase == models.FollowPhaseStopped {
+		lines = append(lines,
+			fmt.Sprintf("Lag: %s", renderFollowLag(status.Lag)),
+			fmt.Sprintf("Transactions applied: %s", formatGroupedInt64(status.Transactions)),
+			fmt.Sprintf("Rows applied: %s", formatGroupedInt64(status.RowsApplied)),
+		)
+		if !status.LastEventAt.IsZero() {
+			lines = append(lines, fmt.Sprintf("Last remote change: %s (%s ago)", status.LastEventAt.Format("15:04:05"), ui.FormatDuration(m.now.Sub(status.LastEventAt).Truncate(time.Second))))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Following for: %s", ui.FormatDuration(m.now.Sub(m.startedAt).Truncate(time.Second))))
+	if status.Message != "" && status.Phase != models.FollowPhaseFailed {
+		lines = append(lines, "", subtleStyle.Render(status.Message))
+	}
+	if m.err != nil {
+		lines = append(lines, "", dangerStyle.Render("Follow failed: "+m.err.Error()))
+	} else if m.stopping && !m.done {
+		lines = append(lines, "", warnStyle.Render("Stopping, saving binlog position..."))
+	}
+	return wrapLines(lines, width)
+}
+
+func (m *FollowModel) renderFollowPhase() string {
+	switch m.status.Phase {
+	case models.FollowPhaseStreaming:
+		return okStyle.Render("streaming")
+	case models.FollowPhaseStopped:
+		return subtleStyle.Render("stopped")
+	case models.FollowPhaseFailed:
+		return dangerStyle.Render("failed")
+	default:
+		return warnStyle.Render("initial sync")
+	}
+}
+
+func renderFollowLag(lag time.Duration) string {
+	label := ui.FormatDuration(lag.Truncate(time.Second))
+	if lag < time.Second {
+		label = "caught up"
+	}
+	switch {
+	case lag >= followLagDanger:
+		return dangerStyle.Render(label)
+	case lag >= followLagWarn:
+		return warnStyle.Render(label)
+	default:
+		return okStyle.Render(label)
+	}
+}
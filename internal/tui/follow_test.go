@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowModelShowsLagAndStops(t *testing.T) {
+	cfg := &config.Config{Remote: config.MySQLConfig{Host: "remote.example.com", Port: 3306}, Local: config.MySQLConfig{Host: "localhost", Port: 3306}}
+	cancelled := false
+	model := NewFollowModel(cfg, "shop", func() { cancelled = true })
+	assert.Contains(t, stripANSI(model.View()), "initial sync")
+
+	lastEvent := model.now.Add(-12 * time.Second)
+	updated, _ := model.Update(followStatusMsg(models.FollowStatus{
+		Phase:        models.FollowPhaseStreaming,
+		DatabaseName: "shop",
+		Position:     models.BinlogPosition{File: "binlog.000042", Position: 1337},
+		Lag:          12 * time.Second,
+		Transactions: 1200,
+		RowsApplied:  34567,
+		LastEventAt:  lastEvent,
+	}))
+	app := updated.(*FollowModel)
+	rendered := stripANSI(app.View())
+	assert.Contains(t, rendered, "streaming")
+	assert.Contains(t, rendered, "binlog.000042:1337")
+	assert.Contains(t, rendered, "Lag: 12.0s")
+	assert.Contains(t, rendered, "Rows applied: 34 567")
+
+	updated, _ = app.Update(followStatusMsg(models.FollowStatus{Phase: models.FollowPhaseStreaming, DatabaseName: "shop"}))
+	app = updated.(*FollowModel)
+	assert.Contains(t, stripANSI(app.View()), "Lag: caught up")
+
+	updated, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	app = updated.(*FollowModel)
+	assert.True(t, cancelled)
+	assert.Nil(t, cmd)
+	assert.Contains(t, stripANSI(app.View()), "Stopping")
+
+	_, cmd = app.Update(followDoneMsg{})
+	assert.NotNil(t, cmd)
+}
+
+func TestFollowModelKeepsFailure(t *testing.T) {
+	model := NewFollowModel(nil, "shop", nil)
+	updated, cmd := model.Update(followDoneMsg{Err: errors.New("binlog stream failed: EOF")})
+	app := updated.(*FollowModel)
+	assert.Nil(t, cmd)
+	assert.Contains(t, stripANSI(app.View()), "Follow failed: binlog stream failed: EOF")
+	assert.Contains(t, stripANSI(app.View()), "exit")
+}
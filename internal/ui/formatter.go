@@ -216,6 +216,12 @@ func (f *Formatter) FormatSyncResult(result *models.SyncResult) string {
 				statsLines = append(statsLines, fmt.Sprintf("Average dump speed: %s/s", FormatSize(int64(bytesPerSecond))))
 			}
 		}
+		if result.StreamCopy {
+			statsLines = append(statsLines, "Mode: streaming copy (no dump directory)")
+		}
+		if len(result.Batch) > 1 {
+			statsLines = append(statsLines, fmt.Sprintf("Batched with: %s (shared dump and load)", strings.Join(result.Batch, ", ")))
+		}
 		if result.DumpDuration > 0 || result.RestoreDuration > 0 {
 			statsLines = append(statsLines, fmt.Sprintf("Dump phase: %s", FormatDuration(result.DumpDuration)))
 			statsLines = append(statsLines, fmt.Sprintf("Restore phase: %s", FormatDuration(result.RestoreDuration)))
@@ -0,0 +1,124 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"db-sync-cli/internal/config"
+	"db-sync-cli/internal/models"
+	"db-sync-cli/internal/services"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+const followTestDatabase = "dbsync_follow_it"
+
+// TestMySQLShellService_Follow_Integration рассчитан на два локальных MySQL: один играет роль remote
+// (binlog_format=ROW, binlog_row_image=FULL), второй — локальной копии.
+func TestMySQLShellService_Follow_Integration(t *testing.T) {
+	if os.Getenv("DBSYNC_TEST_FOLLOW") != "1" {
+		t.Skip("Follow integration test requires DBSYNC_TEST_FOLLOW=1 and writes to the remote server")
+	}
+
+	cfg := integrationConfig(t)
+	cfg.Follow.Dir = t.TempDir()
+	remote := openIntegrationDB(t, cfg.Remote)
+	local := openIntegrationDB(t, cfg.Local)
+
+	mustExec(t, remote, "DROP DATABASE IF EXISTS "+followTestDatabase)
+	mustExec(t, remote, "CREATE DATABASE "+followTestDatabase)
+	mustExec(t, remote, "CREATE TABLE "+followTestDatabase+".items (id INT PRIMARY KEY AUTO_INCREMENT, name VARCHAR(64) NOT NULL, qty INT UNSIGNED NOT NULL)")
+	mustExec(t, remote, "INSERT INTO "+followTestDatabase+".items (name, qty) VALUES ('seed', 1)")
+	defer mustExec(t, remote, "DROP DATABASE IF EXISTS "+followTestDatabase)
+	defer mustExec(t, local, "DROP DATABASE IF EXISTS "+followTestDatabase)
+
+	dbService := services.NewDatabaseService(cfg)
+	service := services.NewMySQLShellService(cfg, dbService)
+	service.SetQuiet(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	streaming := make(chan struct{})
+	var streamingOnce bool
+	done := make(chan error, 1)
+	go func() {
+		done <- service.Follow(ctx, models.SyncTarget{DatabaseName: followTestDatabase, ReplaceEntireDatabase: true}, false, func(status models.FollowStatus) {
+			if status.Phase == models.FollowPhaseStreaming && !streamingOnce {
+				streamingOnce = true
+				close(streaming)
+			}
+		})
+	}()
+
+	select {
+	case <-streaming:
+	case err := <-done:
+		t.Fatalf("Follow() stopped before streaming: %v", err)
+	case <-time.After(cfg.Dump.Timeout):
+		t.Fatal("Follow() did not reach streaming phase")
+	}
+
+	mustExec(t, remote, "INSERT INTO "+followTestDatabase+".items (name, qty) VALUES ('fresh', 4294967295)")
+	mustExec(t, remote, "UPDATE "+followTestDatabase+".items SET name = 'seed-updated' WHERE name = 'seed'")
+	mustExec(t, remote, "DELETE FROM "+followTestDatabase+".items WHERE name = 'fresh'")
+	mustExec(t, remote, "INSERT INTO "+followTestDatabase+".items (name, qty) VALUES ('tail', 2)")
+
+	want := "seed-updated:1,tail:2"
+	deadline := time.Now().Add(30 * time.Second)
+	var got string
+	for time.Now().Before(deadline) {
+		got = localItems(t, local)
+		if got == want {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	if got != want {
+		t.Fatalf("local rows = %q, want %q", got, want)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+	position, err := service.LoadFollowPosition(followTestDatabase)
+	if err != nil || position == nil {
+		t.Fatalf("follow position must be saved on stop, got %+v err=%v", position, err)
+	}
+}
+
+func openIntegrationDB(t *testing.T, mysqlConfig config.MySQLConfig) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(%s:%d)/", mysqlConfig.User, mysqlConfig.Password, mysqlConfig.Host, mysqlConfig.Port))
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", mysqlConfig.Host, err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string) {
+	t.Helper()
+
+	if _, err := db.Exec(query); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+func localItems(t *testing.T, db *sql.DB) string {
+	t.Helper()
+
+	var items sql.NullString
+	query := "SELECT GROUP_CONCAT(CONCAT(name, ':', qty) ORDER BY id) FROM " + followTestDatabase + ".items"
+	if err := db.QueryRow(query).Scan(&items); err != nil {
+		return ""
+	}
+	return items.String
+}
@@ -0,0 +1,64 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"db-sync-cli/internal/services"
+)
+
+// TestMySQLShellService_BucketRoundTrip_Integration экспортирует БД в архив, загружает его в бакет
+// (например, локальный MinIO) и восстанавливает под другим именем через pull.
+func TestMySQLShellService_BucketRoundTrip_Integration(t *testing.T) {
+	if os.Getenv("DBSYNC_TEST_DESTRUCTIVE") != "1" {
+		t.Skip("Bucket round-trip test requires DBSYNC_TEST_DESTRUCTIVE=1")
+	}
+	targetDatabase := strings.TrimSpace(os.Getenv("DBSYNC_TEST_DATABASE"))
+	if targetDatabase == "" {
+		t.Skip("Bucket round-trip test requires DBSYNC_TEST_DATABASE")
+	}
+
+	cfg := integrationConfig(t)
+	overrideString(&cfg.Storage.Endpoint, "DBSYNC_TEST_STORAGE_ENDPOINT")
+	overrideString(&cfg.Storage.Bucket, "DBSYNC_TEST_STORAGE_BUCKET")
+	overrideString(&cfg.Storage.AccessKey, "DBSYNC_TEST_STORAGE_ACCESS_KEY")
+	overrideString(&cfg.Storage.SecretKey, "DBSYNC_TEST_STORAGE_SECRET_KEY")
+	if !cfg.Storage.Enabled() {
+		t.Skip("Bucket round-trip test requires DBSYNC_TEST_STORAGE_BUCKET (e.g. a local MinIO bucket)")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("invalid integration config: %v", err)
+	}
+
+	dbService := services.NewDatabaseService(cfg)
+	service := services.NewMySQLShellService(cfg, dbService)
+	service.SetQuiet(true)
+
+	archivePath := filepath.Join(t.TempDir(), targetDatabase+".dbsync")
+	manifest, err := service.ExportDatabase(targetDatabase, archivePath, nil)
+	if err != nil {
+		t.Fatalf("ExportDatabase() error = %v", err)
+	}
+	object, err := service.UploadArchive(archivePath, manifest, nil)
+	if err != nil {
+		t.Fatalf("UploadArchive() error = %v", err)
+	}
+
+	restoredName := targetDatabase + "_bucket_copy"
+	pulled, err := service.PullFromBucket(targetDatabase, object.Key, restoredName, nil)
+	if err != nil {
+		t.Fatalf("PullFromBucket() error = %v", err)
+	}
+	if pulled.DatabaseName != targetDatabase || len(pulled.Tables) != len(manifest.Tables) {
+		t.Fatalf("unexpected pulled manifest: %+v", pulled)
+	}
+	exists, err := dbService.DatabaseExists(restoredName, false)
+	if err != nil || !exists {
+		t.Fatalf("restored database %s is missing: %v", restoredName, err)
+	}
+}
@@ -14,6 +14,13 @@ type MockDatabaseService struct {
 	ValidateNameError     error
 	DatabaseExistsError   error
 	GetDatabaseInfoError  error
+	FingerprintsError     error
+	DescribeSchemaError   error
+	IncrementalError      error
+	BinlogStatusError     error
+	ServerIdentityError   error
+	DataDirError          error
+	ServerSettingsError   error
 
 	RemoteConnInfo       *models.ConnectionInfo
 	LocalConnInfo        *models.ConnectionInfo
@@ -22,6 +29,18 @@ type MockDatabaseService struct {
 	TableDependencies    []models.TableDependency
 	DatabaseExistsResult bool
 	DatabaseInfo         *models.Database
+	RemoteFingerprints   map[string]models.TableFingerprint
+	LocalFingerprints    map[string]models.TableFingerprint
+	RemoteSchema         []models.TableSchema
+	LocalSchema          []models.TableSchema
+	IncrementalColumnMap map[string]models.IncrementalColumn
+	RemoteMaxValues      map[string]string
+	BinlogStatusResult   *models.BinlogStatus
+	RemoteIdentity       *models.ServerIdentity
+	LocalIdentity        *models.ServerIdentity
+	DataDirResult        string
+	RemoteSettings       *models.ServerSettings
+	LocalSettings        *models.ServerSettings
 
 	// Поля для отслеживания вызовов
 	TestConnectionCalled   bool
@@ -31,6 +50,13 @@ type MockDatabaseService struct {
 	ValidateNameCalled     bool
 	DatabaseExistsCalled   bool
 	GetDatabaseInfoCalled  bool
+	FingerprintsCalled     bool
+	DescribeSchemaCalled   bool
+	IncrementalCalled      bool
+	BinlogStatusCalled     bool
+	ServerIdentityCalled   bool
+	DataDirCalled          bool
+	ServerSettingsCalled   bool
 
 	LastIsRemote      bool
 	LastDatabaseName  string
@@ -173,6 +199,151 @@ func (m *MockDatabaseService) GetDatabaseInfo(name string, isRemote bool) (*mode
 	}, nil
 }
 
+// TableFingerprints имитирует сбор данных для сверки таблиц.
+func (m *MockDatabaseService) TableFingerprints(databaseName string, tableNames []string, checksumTables []string, isRemote bool) (map[string]models.TableFingerprint, error) {
+	m.FingerprintsCalled = true
+	m.LastDatabaseName = databaseName
+	m.LastIsRemote = isRemote
+
+	if m.FingerprintsError != nil {
+		return nil, m.FingerprintsError
+	}
+
+	source := m.LocalFingerprints
+	if isRemote {
+		source = m.RemoteFingerprints
+	}
+	withChecksum := make(map[string]bool, len(checksumTables))
+	for _, tableName := range checksumTables {
+		withChecksum[tableName] = true
+	}
+	fingerprints := make(map[string]models.TableFingerprint, len(tableNames))
+	for _, tableName := range tableNames {
+		fingerprint, ok := source[tableName]
+		if !ok {
+			continue
+		}
+		if !withChecksum[tableName] {
+			fingerprint.Checksum = ""
+		}
+		fingerprints[tableName] = fingerprint
+	}
+	return fingerprints, nil
+}
+
+// DescribeSchema имитирует чтение структуры таблиц.
+func (m *MockDatabaseService) DescribeSchema(databaseName string, isRemote bool) ([]models.TableSchema, error) {
+	m.DescribeSchemaCalled = true
+	m.LastDatabaseName = databaseName
+	m.LastIsRemote = isRemote
+
+	if m.DescribeSchemaError != nil {
+		return nil, m.DescribeSchemaError
+	}
+
+	if isRemote {
+		return m.RemoteSchema, nil
+	}
+	return m.LocalSchema, nil
+}
+
+// IncrementalColumns имитирует поиск колонок для инкрементальной синхронизации.
+func (m *MockDatabaseService) IncrementalColumns(databaseName string, timestampColumn string, isRemote bool) (map[string]models.IncrementalColumn, error) {
+	m.IncrementalCalled = true
+	m.LastDatabaseName = databaseName
+	m.LastIsRemote = isRemote
+
+	if m.IncrementalError != nil {
+		return nil, m.IncrementalError
+	}
+	return m.IncrementalColumnMap, nil
+}
+
+// ColumnMaxValues имитирует чтение high-water marks на remote сервере.
+func (m *MockDatabaseService) ColumnMaxValues(databaseName string, columns map[string]models.IncrementalColumn, isRemote bool) (map[string]string, error) {
+	m.LastDatabaseName = databaseName
+	m.LastIsRemote = isRemote
+
+	if m.IncrementalError != nil {
+		return nil, m.IncrementalError
+	}
+	values := make(map[string]string, len(columns))
+	for tableName := range columns {
+		if value, ok := m.RemoteMaxValues[tableName]; ok {
+			values[tableName] = value
+		}
+	}
+	return values, nil
+}
+
+// BinlogStatus имитирует чтение позиции binlog.
+func (m *MockDatabaseService) BinlogStatus(isRemote bool) (*models.BinlogStatus, error) {
+	m.BinlogStatusCalled = true
+	m.LastIsRemote = isRemote
+
+	if m.BinlogStatusError != nil {
+		return nil, m.BinlogStatusError
+	}
+	if m.BinlogStatusResult != nil {
+		return m.BinlogStatusResult, nil
+	}
+	return &models.BinlogStatus{Position: models.BinlogPosition{File: "binlog.000001", Position: 4}, Format: "ROW", RowImage: "FULL"}, nil
+}
+
+// ServerIdentity имитирует чтение @@hostname и server_uuid; по умолчанию remote и local различаются.
+func (m *MockDatabaseService) ServerIdentity(isRemote bool) (*models.ServerIdentity, error) {
+	m.ServerIdentityCalled = true
+	m.LastIsRemote = isRemote
+
+	if m.ServerIdentityError != nil {
+		return nil, m.ServerIdentityError
+	}
+	if isRemote {
+		if m.RemoteIdentity != nil {
+			return m.RemoteIdentity, nil
+		}
+		return &models.ServerIdentity{Hostname: "remote-mysql", ServerUUID: "00000000-0000-0000-0000-000000000001"}, nil
+	}
+	if m.LocalIdentity != nil {
+		return m.LocalIdentity, nil
+	}
+	return &models.ServerIdentity{Hostname: "local-mysql", ServerUUID: "00000000-0000-0000-0000-000000000002"}, nil
+}
+
+// DataDir имитирует чтение @@datadir; по умолчанию каталог неизвестен.
+func (m *MockDatabaseService) DataDir(isRemote bool) (string, error) {
+	m.DataDirCalled = true
+	m.LastIsRemote = isRemote
+
+	if m.DataDirError != nil {
+		return "", m.DataDirError
+	}
+	return m.DataDirResult, nil
+}
+
+// ServerSettings имитирует чтение версии, привилегий и настроек сервера; по умолчанию
+// это MySQL 8.4 с пользователем, у которого есть все привилегии.
+func (m *MockDatabaseService) ServerSettings(isRemote bool) (*models.ServerSettings, error) {
+	m.ServerSettingsCalled = true
+	m.LastIsRemote = isRemote
+
+	if m.ServerSettingsError != nil {
+		return nil, m.ServerSettingsError
+	}
+	if isRemote && m.RemoteSettings != nil {
+		return m.RemoteSettings, nil
+	}
+	if !isRemote && m.LocalSettings != nil {
+		return m.LocalSettings, nil
+	}
+	return &models.ServerSettings{
+		Version:     "8.4.3",
+		Grants:      []string{"GRANT ALL PRIVILEGES ON *.* TO `dbsync`@`%`", "GRANT BACKUP_ADMIN,SYSTEM_VARIABLES_ADMIN ON *.* TO `dbsync`@`%`"},
+		LocalInfile: true,
+		SQLMode:     "ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION",
+	}, nil
+}
+
 // Reset сбрасывает состояние мока для нового теста
 func (m *MockDatabaseService) Reset() {
 	m.TestConnectionError = nil
@@ -182,6 +353,13 @@ func (m *MockDatabaseService) Reset() {
 	m.ValidateNameError = nil
 	m.DatabaseExistsError = nil
 	m.GetDatabaseInfoError = nil
+	m.FingerprintsError = nil
+	m.DescribeSchemaError = nil
+	m.IncrementalError = nil
+	m.BinlogStatusError = nil
+	m.ServerIdentityError = nil
+	m.DataDirError = nil
+	m.ServerSettingsError = nil
 	m.RemoteConnInfo = nil
 	m.LocalConnInfo = nil
 	m.DatabaseList = models.DatabaseList{}
@@ -189,6 +367,18 @@ func (m *MockDatabaseService) Reset() {
 	m.TableDependencies = nil
 	m.DatabaseExistsResult = false
 	m.DatabaseInfo = nil
+	m.RemoteFingerprints = nil
+	m.LocalFingerprints = nil
+	m.RemoteSchema = nil
+	m.LocalSchema = nil
+	m.IncrementalColumnMap = nil
+	m.RemoteMaxValues = nil
+	m.BinlogStatusResult = nil
+	m.RemoteIdentity = nil
+	m.LocalIdentity = nil
+	m.DataDirResult = ""
+	m.RemoteSettings = nil
+	m.LocalSettings = nil
 	m.TestConnectionCalled = false
 	m.ListDatabasesCalled = false
 	m.ListTablesCalled = false
@@ -196,6 +386,13 @@ func (m *MockDatabaseService) Reset() {
 	m.ValidateNameCalled = false
 	m.DatabaseExistsCalled = false
 	m.GetDatabaseInfoCalled = false
+	m.FingerprintsCalled = false
+	m.DescribeSchemaCalled = false
+	m.IncrementalCalled = false
+	m.BinlogStatusCalled = false
+	m.ServerIdentityCalled = false
+	m.DataDirCalled = false
+	m.ServerSettingsCalled = false
 	m.LastIsRemote = false
 	m.LastDatabaseName = ""
 	m.LastValidatedName = ""